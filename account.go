@@ -0,0 +1,640 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/google/uuid"
+)
+
+// 数据库未打开（例如加密数据库尚未解锁）时账号操作返回的错误
+var errDatabaseNotReady = errors.New("数据库尚未打开")
+
+// 账号保存在数据目录下单独的明文账号库中，每个账号的数据保存在各自的数据库文件中，
+// 数据库密码因此只作用于设置它的账号；加密数据库未解锁时仍可列出和切换账号。
+const (
+	accountsDBFile     = "accounts.db"
+	legacyDatabaseFile = "performance.db" // 旧版本所有账号共用的数据库，也是第一个账号的数据库
+	accountDatabaseDir = "accounts"
+)
+
+// accountsDB 账号库连接
+var accountsDB *sql.DB
+
+// GetAccounts 获取所有账号，不包含密码哈希
+func GetAccounts() ([]Account, error) {
+	accounts, err := loadAccounts()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range accounts {
+		accounts[i].LockHash = ""
+	}
+	return accounts, nil
+}
+
+// SaveAccount 保存/更新账号，密码只能通过 SetAccountLock 修改
+func SaveAccount(account Account) error {
+	if accountsDB == nil {
+		return errDatabaseNotReady
+	}
+
+	// 第一个账号使用当前打开的数据库（保留创建账号前的数据），之后的账号各自使用新的数据库文件
+	var count int
+	if err := accountsDB.QueryRow(`SELECT COUNT(*) FROM accounts`).Scan(&count); err != nil {
+		return fmt.Errorf("保存账号失败: %w", err)
+	}
+	dbFile := currentDBFile
+	if count > 0 {
+		dbFile = accountDatabaseDir + "/" + account.ID + ".db"
+	}
+
+	// 新账号不带密码，已有账号保留原有密码与数据库文件
+	_, err := accountsDB.Exec(
+		`INSERT INTO accounts (id, username, avatar_path, lock_type, lock_hash, db_file) VALUES (?, ?, ?, '', '', ?)
+		ON CONFLICT(id) DO UPDATE SET username = excluded.username, avatar_path = excluded.avatar_path`,
+		account.ID, account.Username, account.AvatarPath, dbFile,
+	)
+	if err != nil {
+		return fmt.Errorf("保存账号失败: %w", err)
+	}
+	return nil
+}
+
+// SwitchAccount 切换当前活跃账号并打开该账号的数据库，目标账号设置了密码或PIN时需先验证
+//
+// 当前账号已锁定时，切换到其他账号还需提供当前账号的密码或PIN（currentSecret），否则无法绕过锁定。
+// 目标账号的数据库已加密时切换后 db 为 nil，需调用 UnlockDatabase 解锁。
+func SwitchAccount(accountID, secret, currentSecret string) error {
+	target, err := findAccount(accountID)
+	if err != nil {
+		return err
+	}
+
+	session.mu.Lock()
+	currentID, locked := session.accountID, session.locked
+	session.mu.Unlock()
+
+	// 切换到已锁定的账号本身等同于解锁，只需验证目标账号
+	verified := false
+	if locked && currentID != accountID {
+		if currentSecret == "" {
+			return errAccountLocked
+		}
+		current, err := findAccount(currentID)
+		if err != nil {
+			return err
+		}
+		if err := verifyAccountSecret(current, currentSecret); err != nil {
+			return err
+		}
+		verified = true
+	}
+	if err := verifyAccountSecret(target, secret); err != nil {
+		return err
+	}
+
+	// 先打开目标账号的数据库，切换失败时重新打开原账号的数据库
+	if err := useAccountDatabase(accountID); err != nil && !errors.Is(err, errDatabaseLocked) {
+		reopenAccountDatabase(currentID)
+		return err
+	}
+	if err := switchSession(currentID, accountID, verified); err != nil {
+		reopenAccountDatabase(currentID)
+		return err
+	}
+
+	// 切换成功后才更新最后使用的账号ID，下次启动时打开该账号
+	return setLastUsedAccountID(accountsDB, accountID)
+}
+
+// 辅助函数：切换失败后重新打开原账号的数据库，已加密时等待解锁
+func reopenAccountDatabase(accountID string) {
+	if err := useAccountDatabase(accountID); err != nil && !errors.Is(err, errDatabaseLocked) {
+		log.Printf("重新打开数据库失败: %v", err)
+	}
+}
+
+// GetLastUsedAccount 获取最后使用的账号
+func GetLastUsedAccount() (*Account, error) {
+	account, err := loadLastUsedAccount()
+	if account != nil {
+		account.LockHash = ""
+	}
+	return account, err
+}
+
+// NewAccount 创建新账号
+func NewAccount(username, avatarPath string) (Account, error) {
+	username, err := validateUsername(username)
+	if err != nil {
+		return Account{}, err
+	}
+
+	// 如果是绝对路径，复制到应用目录
+	if filepath.IsAbs(avatarPath) {
+		avatarPath, err = CopyAvatarToAppDir(avatarPath)
+		if err != nil {
+			return Account{}, err
+		}
+	}
+
+	// 创建新账号
+	account := Account{
+		ID:            uuid.New().String(),
+		Username:      username,
+		AvatarPath:    avatarPath,
+		ThumbnailPath: avatarThumbnailPath(avatarPath),
+	}
+
+	// 保存账号
+	if err := SaveAccount(account); err != nil {
+		return Account{}, err
+	}
+
+	// 设置为当前使用的账号
+	if err := SwitchAccount(account.ID, "", ""); err != nil {
+		return Account{}, err
+	}
+
+	return account, nil
+}
+
+// DeleteAccount 删除账号及其头像文件与数据库，设置了密码或PIN时需先验证
+//
+// 删除的是当前账号时切换到剩余的第一个账号（该账号设置了密码时需重新解锁）。
+// 旧版本迁移来的账号共用同一个数据库，仍有其他账号使用时保留该数据库。
+func DeleteAccount(accountID, secret string) (err error) {
+	account, err := findAccount(accountID)
+	if err != nil {
+		return err
+	}
+	if err := verifyAccountSecret(account, secret); err != nil {
+		return err
+	}
+	dbFile, err := accountDatabaseFile(accountID)
+	if err != nil {
+		return err
+	}
+
+	// 开始事务
+	tx, err := accountsDB.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+		if err == nil {
+			err = afterAccountDeleted(account, dbFile)
+		}
+	}()
+
+	if _, err = tx.Exec(`DELETE FROM accounts WHERE id = ?`, accountID); err != nil {
+		return err
+	}
+
+	// 删除的是最后使用的账号时改为剩余的第一个账号
+	lastUsedID, err := getLastUsedAccountID(tx)
+	if err != nil {
+		return err
+	}
+	if lastUsedID != accountID {
+		return nil
+	}
+	var nextID string
+	err = tx.QueryRow(`SELECT id FROM accounts ORDER BY rowid LIMIT 1`).Scan(&nextID)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+	err = setLastUsedAccountID(tx, nextID)
+	return err
+}
+
+// 辅助函数：账号删除后清理头像文件与数据库，并更新会话
+func afterAccountDeleted(account Account, dbFile string) error {
+	if err := removeAvatarFiles(account.AvatarPath); err != nil {
+		return err
+	}
+
+	session.mu.Lock()
+	current := session.accountID
+	session.mu.Unlock()
+
+	if current == account.ID {
+		if err := InitSession(); err != nil {
+			return err
+		}
+	}
+
+	var users int
+	if err := accountsDB.QueryRow(`SELECT COUNT(*) FROM accounts WHERE db_file = ?`, dbFile).Scan(&users); err != nil {
+		return err
+	}
+	if users == 0 {
+		if dbFile == currentDBFile {
+			if err := closeAccountDatabase(); err != nil {
+				return err
+			}
+		}
+		if err := removeAccountDatabase(dbFile); err != nil {
+			return err
+		}
+	}
+
+	// 切换到剩余的第一个账号的数据库
+	if current == account.ID || db == nil {
+		lastUsedID, err := getLastUsedAccountID(accountsDB)
+		if err != nil {
+			return err
+		}
+		if err := useAccountDatabase(lastUsedID); err != nil && !errors.Is(err, errDatabaseLocked) {
+			return err
+		}
+	}
+	return nil
+}
+
+// 辅助函数：打开账号的数据库，accountID 为空时打开旧版本的共用数据库；已加密时返回 errDatabaseLocked
+func useAccountDatabase(accountID string) error {
+	dbFile := legacyDatabaseFile
+	if accountID != "" {
+		var err error
+		if dbFile, err = accountDatabaseFile(accountID); err != nil {
+			return err
+		}
+	}
+	return switchDatabase(dbFile)
+}
+
+// 辅助函数：读取账号使用的数据库文件（相对数据目录、以 / 分隔的路径）
+func accountDatabaseFile(accountID string) (string, error) {
+	if accountsDB == nil {
+		return "", errDatabaseNotReady
+	}
+
+	var dbFile sql.NullString
+	err := accountsDB.QueryRow(`SELECT db_file FROM accounts WHERE id = ?`, accountID).Scan(&dbFile)
+	if err == sql.ErrNoRows {
+		return "", fmt.Errorf("账号不存在")
+	}
+	if err != nil {
+		return "", err
+	}
+	if dbFile.String == "" {
+		return legacyDatabaseFile, nil
+	}
+	return dbFile.String, nil
+}
+
+// 辅助函数：删除账号的数据库文件（包括加密文件与日志文件）
+func removeAccountDatabase(dbFile string) error {
+	appDataDir, err := GetAppDataDir()
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(appDataDir, filepath.FromSlash(dbFile))
+	for _, suffix := range []string{"", encryptedDBSuffix, "-journal", "-wal", "-shm"} {
+		if err := os.Remove(path + suffix); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("删除账号数据失败: %w", err)
+		}
+	}
+	return nil
+}
+
+// 辅助函数：按创建顺序读取所有账号（包含密码哈希）
+func loadAccounts() ([]Account, error) {
+	if accountsDB == nil {
+		return nil, errDatabaseNotReady
+	}
+
+	rows, err := accountsDB.Query(`SELECT id, username, avatar_path, lock_type, lock_hash FROM accounts ORDER BY rowid`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	accounts := []Account{}
+	for rows.Next() {
+		account, err := scanAccount(rows)
+		if err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, account)
+	}
+	return accounts, rows.Err()
+}
+
+// 辅助函数：读取最后使用的账号（包含密码哈希）
+func loadLastUsedAccount() (*Account, error) {
+	if accountsDB == nil {
+		return nil, errDatabaseNotReady
+	}
+
+	lastUsedID, err := getLastUsedAccountID(accountsDB)
+	if err != nil || lastUsedID == "" {
+		return nil, err
+	}
+
+	account, err := findAccount(lastUsedID)
+	if err != nil {
+		// 账号已不存在
+		return nil, nil
+	}
+	return &account, nil
+}
+
+// 辅助函数：按ID查找账号（包含密码哈希）
+func findAccount(accountID string) (Account, error) {
+	if accountsDB == nil {
+		return Account{}, errDatabaseNotReady
+	}
+
+	row := accountsDB.QueryRow(`SELECT id, username, avatar_path, lock_type, lock_hash FROM accounts WHERE id = ?`, accountID)
+	account, err := scanAccount(row)
+	if err == sql.ErrNoRows {
+		return Account{}, fmt.Errorf("账号不存在")
+	}
+	return account, err
+}
+
+// 辅助函数：更新账号的密码类型与哈希
+func updateAccountLock(accountID, lockType, lockHash string) error {
+	result, err := accountsDB.Exec(`UPDATE accounts SET lock_type = ?, lock_hash = ? WHERE id = ?`, lockType, lockHash, accountID)
+	if err != nil {
+		return fmt.Errorf("保存账号密码失败: %w", err)
+	}
+	if count, _ := result.RowsAffected(); count == 0 {
+		return fmt.Errorf("账号不存在")
+	}
+	return nil
+}
+
+// 辅助函数：扫描一行账号数据
+func scanAccount(row interface{ Scan(...any) error }) (Account, error) {
+	var account Account
+	var avatarPath, lockType, lockHash sql.NullString
+	if err := row.Scan(&account.ID, &account.Username, &avatarPath, &lockType, &lockHash); err != nil {
+		return Account{}, err
+	}
+	account.AvatarPath = avatarPath.String
+	account.ThumbnailPath = avatarThumbnailPath(account.AvatarPath)
+	account.LockType = lockType.String
+	account.LockHash = lockHash.String
+	return account, nil
+}
+
+// 辅助函数：读取最后使用的账号ID，config 表只保存一行
+func getLastUsedAccountID(q interface {
+	QueryRow(string, ...any) *sql.Row
+}) (string, error) {
+	var lastUsedID sql.NullString
+	err := q.QueryRow(`SELECT last_used_id FROM config ORDER BY id LIMIT 1`).Scan(&lastUsedID)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return lastUsedID.String, err
+}
+
+// 辅助函数：保存最后使用的账号ID
+func setLastUsedAccountID(e interface {
+	Exec(string, ...any) (sql.Result, error)
+}, accountID string) error {
+	_, err := e.Exec(`DELETE FROM config WHERE id != 1`)
+	if err != nil {
+		return err
+	}
+	_, err = e.Exec(
+		`INSERT INTO config (id, last_used_id) VALUES (1, ?) ON CONFLICT(id) DO UPDATE SET last_used_id = excluded.last_used_id`,
+		accountID,
+	)
+	if err != nil {
+		return fmt.Errorf("保存当前账号失败: %w", err)
+	}
+	return nil
+}
+
+// 辅助函数：打开数据目录中的账号库并创建表结构
+func openAccountRegistry(appDataDir string) error {
+	database, err := sql.Open("sqlite", filepath.Join(appDataDir, accountsDBFile))
+	if err != nil {
+		return fmt.Errorf("打开账号库失败: %w", err)
+	}
+	if err := database.Ping(); err != nil {
+		database.Close()
+		return fmt.Errorf("账号库连接失败: %w", err)
+	}
+
+	_, err = database.Exec(`
+		CREATE TABLE IF NOT EXISTS accounts (
+			id TEXT PRIMARY KEY,
+			username TEXT,
+			avatar_path TEXT
+		);
+		CREATE TABLE IF NOT EXISTS config (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			last_used_id TEXT
+		)
+	`)
+	if err == nil {
+		err = addAccountColumns(database)
+	}
+	if err != nil {
+		database.Close()
+		return fmt.Errorf("创建账号表失败: %w", err)
+	}
+
+	accountsDB = database
+	if err := migrateConfigAccounts(); err != nil {
+		return fmt.Errorf("迁移账号失败: %w", err)
+	}
+	return nil
+}
+
+// 辅助函数：关闭账号库
+func closeAccountRegistry() error {
+	if accountsDB == nil {
+		return nil
+	}
+	err := accountsDB.Close()
+	accountsDB = nil
+	return err
+}
+
+// 辅助函数：为账号表补充密码与数据库文件相关的列
+func addAccountColumns(database *sql.DB) error {
+	columns := map[string]bool{}
+	rows, err := database.Query(`PRAGMA table_info(accounts)`)
+	if err != nil {
+		return fmt.Errorf("读取账号表结构失败: %w", err)
+	}
+	for rows.Next() {
+		var cid, notNull, pk int
+		var name, columnType string
+		var defaultValue sql.NullString
+		if err := rows.Scan(&cid, &name, &columnType, &notNull, &defaultValue, &pk); err != nil {
+			rows.Close()
+			return fmt.Errorf("读取账号表结构失败: %w", err)
+		}
+		columns[name] = true
+	}
+	rows.Close()
+
+	for _, column := range []string{"lock_type", "lock_hash", "db_file"} {
+		if columns[column] {
+			continue
+		}
+		if _, err := database.Exec(`ALTER TABLE accounts ADD COLUMN ` + column + ` TEXT DEFAULT ''`); err != nil {
+			return fmt.Errorf("更新账号表结构失败: %w", err)
+		}
+	}
+	return nil
+}
+
+// 辅助函数：将旧版本保存在数据库中的账号迁移到账号库，迁移后删除数据库中的账号表
+//
+// 旧版本所有账号共用同一个数据库，迁移后这些账号仍使用该数据库文件。
+// 加密数据库中的账号在解锁后才会迁移。
+func migrateDatabaseAccounts() (err error) {
+	var tables int
+	err = db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name IN ('accounts', 'config')`).Scan(&tables)
+	if err != nil || tables == 0 {
+		return err
+	}
+	if tables == 2 {
+		if err := copyDatabaseAccounts(); err != nil {
+			return err
+		}
+	}
+	return dropDatabaseAccountTables()
+}
+
+// 辅助函数：将数据库中的账号与最后使用的账号复制到账号库，账号库中已有的账号以账号库为准
+func copyDatabaseAccounts() (err error) {
+	if err := addAccountColumns(db); err != nil {
+		return err
+	}
+	accounts := []Account{}
+	rows, err := db.Query(`SELECT id, username, avatar_path, lock_type, lock_hash FROM accounts ORDER BY rowid`)
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		account, err := scanAccount(rows)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+		accounts = append(accounts, account)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	lastUsedID, err := getLastUsedAccountID(db)
+	if err != nil {
+		return err
+	}
+
+	// 开始事务
+	tx, err := accountsDB.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	for _, account := range accounts {
+		_, err = tx.Exec(
+			`INSERT OR IGNORE INTO accounts (id, username, avatar_path, lock_type, lock_hash, db_file) VALUES (?, ?, ?, ?, ?, ?)`,
+			account.ID, account.Username, account.AvatarPath, account.LockType, account.LockHash, currentDBFile,
+		)
+		if err != nil {
+			return fmt.Errorf("迁移账号失败: %w", err)
+		}
+	}
+
+	current, err := getLastUsedAccountID(tx)
+	if err != nil {
+		return err
+	}
+	if current == "" && lastUsedID != "" {
+		err = setLastUsedAccountID(tx, lastUsedID)
+	}
+	return err
+}
+
+// 辅助函数：删除数据库中旧版本的账号表，账号只保存在账号库中
+func dropDatabaseAccountTables() error {
+	if _, err := db.Exec(`DROP TABLE IF EXISTS accounts; DROP TABLE IF EXISTS config`); err != nil {
+		return fmt.Errorf("删除旧账号表失败: %w", err)
+	}
+	return nil
+}
+
+// migrateConfigAccounts 将旧版本保存在 config.json 中的账号迁移到账号库，只执行一次
+//
+// 迁移在一个事务中完成，成功后从 config.json 中移除账号，其他设置保持不变。
+func migrateConfigAccounts() (err error) {
+	config, err := LoadConfig()
+	if err != nil {
+		return err
+	}
+	if len(config.Accounts) == 0 && config.LastUsedID == "" {
+		return nil
+	}
+
+	// 开始事务
+	tx, err := accountsDB.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+		if err == nil {
+			// 提交成功后再从 config.json 中移除账号
+			err = UpdateConfig(func(config *Config) error {
+				config.Accounts = nil
+				config.LastUsedID = ""
+				return nil
+			})
+		}
+	}()
+
+	for _, account := range config.Accounts {
+		// 账号库中已有的账号以账号库为准
+		_, err = tx.Exec(
+			`INSERT OR IGNORE INTO accounts (id, username, avatar_path, lock_type, lock_hash, db_file) VALUES (?, ?, ?, ?, ?, ?)`,
+			account.ID, account.Username, account.AvatarPath, account.LockType, account.LockHash, legacyDatabaseFile,
+		)
+		if err != nil {
+			return fmt.Errorf("迁移账号失败: %w", err)
+		}
+	}
+
+	lastUsedID, err := getLastUsedAccountID(tx)
+	if err != nil {
+		return err
+	}
+	if lastUsedID == "" && config.LastUsedID != "" {
+		err = setLastUsedAccountID(tx, config.LastUsedID)
+	}
+	return err
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// 每个账号的数据保存在各自的数据库中，数据库密码只作用于设置它的账号
+func TestAccountDatabasesArePartitioned(t *testing.T) {
+	setupTestDB(t)
+	t.Cleanup(func() { InitSession() })
+
+	alice, err := NewAccount("alice", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	saveTestTask(t, "2026", "work", Task{ID: "t1", Title: "任务", Status: "not-started", Priority: "medium"})
+
+	bob, err := NewAccount("bob", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data := mustGetAllAnnualData(t); len(data) != 0 {
+		t.Fatalf("新账号读到了其他账号的数据: %v", data)
+	}
+	if err := EnableDatabaseEncryption("bob-passphrase"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := SwitchAccount(alice.ID, "", ""); err != nil {
+		t.Fatal(err)
+	}
+	if status, _ := GetDatabaseEncryptionStatus(); status.Encrypted {
+		t.Fatal("其他账号的数据库密码作用到了当前账号")
+	}
+	if _, ok := mustGetAllAnnualData(t)["2026"]; !ok {
+		t.Fatal("切换账号后数据丢失")
+	}
+
+	// 切换到已加密的账号后需要该账号的密码解锁
+	if err := SwitchAccount(bob.ID, "", ""); err != nil {
+		t.Fatal(err)
+	}
+	if status, _ := GetDatabaseEncryptionStatus(); !status.Locked || db != nil {
+		t.Fatalf("切换到加密账号后数据库状态 = %+v", status)
+	}
+	if err := ensureUnlocked(); !errors.Is(err, errDatabaseLocked) {
+		t.Fatalf("数据库未解锁时数据接口应返回 errDatabaseLocked，得到 %v", err)
+	}
+	if err := UnlockDatabase("bob-passphrase"); err != nil {
+		t.Fatal(err)
+	}
+	if data := mustGetAllAnnualData(t); len(data) != 0 {
+		t.Fatalf("解锁后读到了其他账号的数据: %v", data)
+	}
+
+	// 删除账号时一并删除它的数据库，并切换回剩余的账号
+	bobFile, err := accountDatabaseFile(bob.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := DeleteAccount(bob.ID, ""); err != nil {
+		t.Fatal(err)
+	}
+	path, err := databasePath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(path), filepath.FromSlash(bobFile)+encryptedDBSuffix)); !os.IsNotExist(err) {
+		t.Fatalf("删除账号后数据库仍存在: %v", err)
+	}
+	if status, _ := GetSessionStatus(); status.AccountID != alice.ID {
+		t.Fatalf("删除账号后会话 = %+v", status)
+	}
+	if _, ok := mustGetAllAnnualData(t)["2026"]; !ok {
+		t.Fatal("删除其他账号后数据丢失")
+	}
+}
+
+// 旧版本保存在数据库中的账号迁移到账号库，并继续使用原数据库
+func TestMigrateDatabaseAccounts(t *testing.T) {
+	setupTestDB(t)
+
+	_, err := db.Exec(`
+		CREATE TABLE accounts (id TEXT PRIMARY KEY, username TEXT, avatar_path TEXT);
+		CREATE TABLE config (id INTEGER PRIMARY KEY AUTOINCREMENT, last_used_id TEXT);
+		INSERT INTO accounts (id, username, avatar_path) VALUES ('a1', 'alice', '');
+		INSERT INTO config (id, last_used_id) VALUES (1, 'a1');
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := migrateDatabaseAccounts(); err != nil {
+		t.Fatal(err)
+	}
+
+	account, err := GetLastUsedAccount()
+	if err != nil || account == nil || account.Username != "alice" {
+		t.Fatalf("迁移后最后使用的账号 = %+v, %v", account, err)
+	}
+	if file, _ := accountDatabaseFile("a1"); file != legacyDatabaseFile {
+		t.Fatalf("迁移的账号使用的数据库 = %q", file)
+	}
+	var tables int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE name IN ('accounts', 'config')`).Scan(&tables); err != nil {
+		t.Fatal(err)
+	}
+	if tables != 0 {
+		t.Fatal("迁移后数据库中仍有账号表")
+	}
+}
+
+// 修改名称与头像写入账号库
+func TestRenameAccountAndReplaceAvatar(t *testing.T) {
+	setupTestDB(t)
+	t.Cleanup(func() { InitSession() })
+
+	account, err := NewAccount("alice", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := RenameAccount(account.ID, "alice2"); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 8, 8))); err != nil {
+		t.Fatal(err)
+	}
+	source := filepath.Join(t.TempDir(), "avatar.png")
+	if err := os.WriteFile(source, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	replaced, err := ReplaceAccountAvatar(account.ID, source)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	saved, err := findAccount(account.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.Username != "alice2" || saved.AvatarPath == "" || saved.AvatarPath != replaced.AvatarPath {
+		t.Fatalf("保存后的账号 = %+v", saved)
+	}
+
+	// 移除头像
+	if _, err := ReplaceAccountAvatar(account.ID, ""); err != nil {
+		t.Fatal(err)
+	}
+	if saved, _ := findAccount(account.ID); saved.AvatarPath != "" {
+		t.Fatalf("移除后头像 = %q", saved.AvatarPath)
+	}
+}
+
+// 无法打开目标账号的数据库时不切换，下次启动仍打开原账号
+func TestSwitchAccountFailureKeepsLastUsed(t *testing.T) {
+	setupTestDB(t)
+	t.Cleanup(func() { InitSession() })
+
+	alice, err := NewAccount("alice", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bob, err := NewAccount("bob", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := SwitchAccount(alice.ID, "", ""); err != nil {
+		t.Fatal(err)
+	}
+	saveTestTask(t, "2026", "work", Task{ID: "t1", Title: "任务", Status: "not-started", Priority: "medium"})
+
+	// 用目录替换 bob 的数据库文件，使其无法打开
+	bobFile, err := accountDatabaseFile(bob.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path, err := databasePath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	bobPath := filepath.Join(filepath.Dir(path), filepath.FromSlash(bobFile))
+	if err := os.Remove(bobPath); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(bobPath, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := SwitchAccount(bob.ID, "", ""); err == nil {
+		t.Fatal("无法打开数据库时应切换失败")
+	}
+	if account, _ := GetLastUsedAccount(); account == nil || account.ID != alice.ID {
+		t.Fatalf("切换失败后最后使用的账号 = %+v", account)
+	}
+	if status, _ := GetSessionStatus(); status.AccountID != alice.ID {
+		t.Fatalf("切换失败后会话 = %+v", status)
+	}
+	if _, ok := mustGetAllAnnualData(t)["2026"]; !ok {
+		t.Fatal("切换失败后未重新打开原账号的数据库")
+	}
+}
@@ -2,35 +2,50 @@ package main
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
 	"log"
-	"net/http"
-	"time"
+	"strings"
 
-	"github.com/hashicorp/go-version"
 	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
 // 当前应用版本号
 const currentVersion = "v0.1.0"
 
-// GitHubRelease 结构体用于解析GitHub Releases API返回的JSON数据
-type GitHubRelease struct {
-	TagName string `json:"tag_name"` // 版本标签
-	Body    string `json:"body"`     // 发布说明
-	HTMLURL string `json:"html_url"` // 发布页面URL
-}
-
 // App struct
 type App struct {
-	ctx context.Context
+	ctx       context.Context
+	reminders *Scheduler
+	digests   *Scheduler
+	vault     *Scheduler
+	caldav    *Scheduler
+	lanSync   *Scheduler
+	changes   *Scheduler
+	folders   *Scheduler
+	backups   *Scheduler
+	history   *Scheduler
+	updates   *Scheduler
+	idleLock  *Scheduler
+
+	restartOnExit bool // 退出后重新启动以安装已下载的更新
 }
 
 // NewApp creates a new App application struct
 func NewApp() *App {
-	return &App{}
+	return &App{
+		reminders: NewScheduler(checkReminders),
+		digests:   NewScheduler(checkDigests),
+		vault:     NewScheduler(checkMarkdownSync),
+		caldav:    NewScheduler(checkCalDAVSync),
+		lanSync:   NewScheduler(checkLANSync),
+		changes:   NewScheduler(checkLANSyncChanges),
+		folders:   NewScheduler(checkFolderSync),
+		backups:   NewScheduler(checkBackup),
+		history:   NewScheduler(checkGitHistory),
+		updates:   NewScheduler(checkUpdates),
+		idleLock:  NewScheduler(checkIdleLock),
+	}
 }
 
 // startup is called when the app starts. The context is saved
@@ -38,25 +53,110 @@ func NewApp() *App {
 func (a *App) startup(ctx context.Context) {
 	a.ctx = ctx
 
-	// 初始化数据库
-	if err := InitDatabase(); err != nil {
+	// 初始化数据库，已加密时等待前端输入密码解锁
+	if err := InitDatabase(); errors.Is(err, errDatabaseLocked) {
+		runtime.EventsEmit(ctx, "databaseLocked")
+		ConfirmUpdate()
+	} else if err != nil {
+		// 新版本无法打开数据库时不确认更新，由旧版本回滚
 		log.Printf("数据库初始化失败: %v", err)
 		fmt.Printf("数据库初始化失败: %v\n", err)
+	} else {
+		a.restoreSession()
+		ConfirmUpdate()
 	}
+	a.idleLock.Start(ctx)
+
+	// 启动截止日期提醒
+	a.reminders.Start(ctx)
+
+	// 启动每日/每周摘要
+	a.digests.Start(ctx)
+
+	// 启动Markdown库同步
+	a.vault.Start(ctx)
+
+	// 启动CalDAV任务同步
+	a.caldav.Start(ctx)
+
+	// 启动局域网同步，保存数据后记录修改时间
+	a.lanSync.Start(ctx)
+	a.changes.Start(ctx)
+
+	// 启动共享目录同步，启动时回放其他设备的操作日志
+	a.folders.Start(ctx)
+
+	// 启动定时远程备份
+	a.backups.Start(ctx)
+
+	// 启动Git历史记录，保存数据后立即提交
+	a.history.Start(ctx)
 
-	// 启动时自动检查更新
-	go func() {
-		result, err := a.CheckUpdate()
-		if err != nil {
-			log.Printf("自动检查更新失败: %v", err)
-			return
+	// 按设置的间隔自动检查更新
+	a.updates.Start(ctx)
+
+	// 升级后显示新版本的发布说明
+	go a.showWhatsNew(ctx)
+}
+
+// shutdown is called when the app is closing
+func (a *App) shutdown(ctx context.Context) {
+	a.reminders.Stop()
+	a.digests.Stop()
+	a.vault.Stop()
+	a.caldav.Stop()
+	a.lanSync.Stop()
+	a.changes.Stop()
+	a.folders.Stop()
+	a.backups.Stop()
+	a.history.Stop()
+	a.updates.Stop()
+	a.idleLock.Stop()
+	stopLANServer()
+
+	// 退出前将最后的修改写入共享目录
+	if settings, err := GetFolderSyncSettings(); err == nil && settings.Enabled && db != nil {
+		if _, err := SyncFolder(); err != nil {
+			log.Printf("共享目录同步失败: %v", err)
 		}
+	}
+
+	if err := CloseDatabase(); err != nil {
+		log.Printf("关闭数据库失败: %v", err)
+	}
 
-		// 如果有更新可用，向前端发送事件通知
-		if result.UpdateAvailable {
-			runtime.EventsEmit(ctx, "updateAvailable", result)
+	// 数据库关闭后再启动，由新进程替换程序并启动新版本
+	if a.restartOnExit {
+		if err := restartApp(); err != nil {
+			log.Printf("重新启动失败: %v", err)
 		}
-	}()
+	}
+}
+
+// 辅助函数：检测升级，有未查看的新内容时通知前端
+func (a *App) showWhatsNew(ctx context.Context) {
+	if _, err := DetectUpgrade(); err != nil {
+		log.Printf("检测版本升级失败: %v", err)
+		return
+	}
+	whatsNew, err := GetWhatsNew(ctx)
+	if err != nil {
+		log.Printf("获取发布说明失败: %v", err)
+		return
+	}
+	if whatsNew != nil && !whatsNew.Seen {
+		runtime.EventsEmit(ctx, whatsNewEvent, whatsNew)
+	}
+}
+
+// 辅助函数：数据库打开后恢复上次使用的账号（设置了密码时需要先解锁），并清理无用的头像文件
+func (a *App) restoreSession() {
+	if err := InitSession(); err != nil {
+		log.Printf("初始化会话失败: %v", err)
+	}
+	if _, err := CleanupAvatars(); err != nil {
+		log.Printf("清理头像文件失败: %v", err)
+	}
 }
 
 // Greet returns a greeting for the given name
@@ -66,47 +166,948 @@ func (a *App) Greet(name string) string {
 
 // GetAllAnnualData 获取所有年度数据
 func (a *App) GetAllAnnualData() (SystemData, error) {
+	if err := ensureUnlocked(); err != nil {
+		return nil, err
+	}
 	return GetAllAnnualData()
 }
 
 // GetAnnualData 获取特定年度的数据
 func (a *App) GetAnnualData(year string) (*AnnualData, error) {
+	if err := ensureUnlocked(); err != nil {
+		return nil, err
+	}
 	return GetAnnualData(year)
 }
 
 // SaveAnnualData 保存年度数据
 func (a *App) SaveAnnualData(data AnnualData) error {
-	return SaveAnnualData(data)
+	if err := ensureUnlocked(); err != nil {
+		return err
+	}
+	return a.recordChange(SaveAnnualData(data))
 }
 
 // DeleteAnnualData 删除年度数据
 func (a *App) DeleteAnnualData(year string) error {
-	return DeleteAnnualData(year)
+	if err := ensureUnlocked(); err != nil {
+		return err
+	}
+	return a.recordChange(DeleteAnnualData(year))
 }
 
 // AddTask 添加任务
 func (a *App) AddTask(task Task) error {
-	return AddTask(task)
+	if err := ensureUnlocked(); err != nil {
+		return err
+	}
+	return a.recordChange(AddTask(task))
 }
 
 // UpdateTask 更新任务
 func (a *App) UpdateTask(task Task) error {
-	return UpdateTask(task)
+	if err := ensureUnlocked(); err != nil {
+		return err
+	}
+	return a.recordChange(UpdateTask(task))
 }
 
 // DeleteTask 删除任务
 func (a *App) DeleteTask(taskID string) error {
-	return DeleteTask(taskID)
+	if err := ensureUnlocked(); err != nil {
+		return err
+	}
+	return a.recordChange(DeleteTask(taskID))
+}
+
+// QueryTasks 按条件查询任务
+func (a *App) QueryTasks(query TaskQuery) (*TaskQueryResult, error) {
+	if err := ensureUnlocked(); err != nil {
+		return nil, err
+	}
+	return QueryTasks(query)
+}
+
+// GetTags 获取所有标签
+func (a *App) GetTags() ([]Tag, error) {
+	if err := ensureUnlocked(); err != nil {
+		return nil, err
+	}
+	return GetTags()
+}
+
+// SaveTag 创建或更新标签
+func (a *App) SaveTag(tag Tag) (Tag, error) {
+	if err := ensureUnlocked(); err != nil {
+		return Tag{}, err
+	}
+	saved, err := SaveTag(tag)
+	return saved, a.recordChange(err)
+}
+
+// RenameTag 重命名标签
+func (a *App) RenameTag(tagID, newName string) error {
+	if err := ensureUnlocked(); err != nil {
+		return err
+	}
+	return a.recordChange(RenameTag(tagID, newName))
+}
+
+// MergeTags 合并标签
+func (a *App) MergeTags(sourceIDs []string, targetID string) error {
+	if err := ensureUnlocked(); err != nil {
+		return err
+	}
+	return a.recordChange(MergeTags(sourceIDs, targetID))
+}
+
+// DeleteTag 删除标签
+func (a *App) DeleteTag(tagID string) error {
+	if err := ensureUnlocked(); err != nil {
+		return err
+	}
+	return a.recordChange(DeleteTag(tagID))
+}
+
+// SetTaskTags 设置任务标签
+func (a *App) SetTaskTags(taskID string, tagNames []string) error {
+	if err := ensureUnlocked(); err != nil {
+		return err
+	}
+	return a.recordChange(SetTaskTags(taskID, tagNames))
+}
+
+// GetTagStats 获取标签统计
+func (a *App) GetTagStats(year string) ([]TagStats, error) {
+	if err := ensureUnlocked(); err != nil {
+		return nil, err
+	}
+	return GetTagStats(year)
+}
+
+// GetReminderSettings 获取提醒设置
+func (a *App) GetReminderSettings() (ReminderSettings, error) {
+	if err := ensureUnlocked(); err != nil {
+		return ReminderSettings{}, err
+	}
+	return GetReminderSettings()
+}
+
+// SaveReminderSettings 保存提醒设置并立即重新检查
+func (a *App) SaveReminderSettings(settings ReminderSettings) error {
+	if err := ensureUnlocked(); err != nil {
+		return err
+	}
+	if err := SaveReminderSettings(settings); err != nil {
+		return err
+	}
+	a.reminders.Refresh()
+	return nil
+}
+
+// GetPendingReminders 获取当前需要提醒的任务
+func (a *App) GetPendingReminders() ([]TaskReminder, error) {
+	if err := ensureUnlocked(); err != nil {
+		return nil, err
+	}
+	return GetPendingReminders()
+}
+
+// SnoozeReminder 推迟任务提醒
+func (a *App) SnoozeReminder(taskID string, minutes int) error {
+	if err := ensureUnlocked(); err != nil {
+		return err
+	}
+	return SnoozeReminder(taskID, minutes)
+}
+
+// GetDigestSettings 获取摘要设置
+func (a *App) GetDigestSettings() (DigestSettings, error) {
+	if err := ensureUnlocked(); err != nil {
+		return DigestSettings{}, err
+	}
+	return GetDigestSettings()
+}
+
+// SaveDigestSettings 保存摘要设置
+func (a *App) SaveDigestSettings(settings DigestSettings) error {
+	if err := ensureUnlocked(); err != nil {
+		return err
+	}
+	if err := SaveDigestSettings(settings); err != nil {
+		return err
+	}
+	a.digests.Refresh()
+	return nil
+}
+
+// GenerateDigest 生成每日/每周摘要
+func (a *App) GenerateDigest(kind, date string) (*Digest, error) {
+	if err := ensureUnlocked(); err != nil {
+		return nil, err
+	}
+	return GenerateDigest(kind, date)
+}
+
+// WriteDigest 生成摘要并写入输出目录
+func (a *App) WriteDigest(kind, date string) ([]string, error) {
+	if err := ensureUnlocked(); err != nil {
+		return nil, err
+	}
+	return WriteDigest(kind, date)
+}
+
+// GetAnnualGrade 获取年度绩效评级
+func (a *App) GetAnnualGrade(year string) (*GradeResult, error) {
+	if err := ensureUnlocked(); err != nil {
+		return nil, err
+	}
+	data, err := GetAnnualData(year)
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		return nil, fmt.Errorf("年度数据不存在: %s", year)
+	}
+
+	grade := EvaluateGrade(*data)
+	return &grade, nil
+}
+
+// GetReviewReport 获取年度绩效报告数据
+func (a *App) GetReviewReport(year string) (*ReviewReport, error) {
+	if err := ensureUnlocked(); err != nil {
+		return nil, err
+	}
+	return BuildReviewReport(year)
+}
+
+// GetReviewReportHTML 获取年度绩效报告的HTML，用于预览
+func (a *App) GetReviewReportHTML(year string) (string, error) {
+	if err := ensureUnlocked(); err != nil {
+		return "", err
+	}
+	report, err := BuildReviewReport(year)
+	if err != nil {
+		return "", err
+	}
+	return RenderReviewReportHTML(report)
+}
+
+// ExportReviewReport 选择保存位置并导出年度绩效报告，取消时返回空路径
+func (a *App) ExportReviewReport(year, format string) (string, error) {
+	if err := ensureUnlocked(); err != nil {
+		return "", err
+	}
+	extension := "." + format
+	path, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		Title:           "导出年度绩效报告",
+		DefaultFilename: fmt.Sprintf("%s年度绩效报告%s", year, extension),
+		Filters: []runtime.FileFilter{
+			{DisplayName: strings.ToUpper(format), Pattern: "*" + extension},
+		},
+	})
+	if err != nil || path == "" {
+		return "", err
+	}
+
+	if err := ExportReviewReport(year, format, path); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// SelectTeamFiles 选择团队成员导出的数据文件，取消时返回空列表
+func (a *App) SelectTeamFiles() ([]string, error) {
+	return runtime.OpenMultipleFilesDialog(a.ctx, runtime.OpenDialogOptions{
+		Title: "选择团队成员的数据文件",
+		Filters: []runtime.FileFilter{
+			{DisplayName: "JSON (*.json)", Pattern: "*.json"},
+		},
+	})
+}
+
+// BuildTeamReport 汇总团队成员导出的数据文件
+func (a *App) BuildTeamReport(paths []string, year string) (*TeamReport, error) {
+	if err := ensureUnlocked(); err != nil {
+		return nil, err
+	}
+	return BuildTeamReport(paths, year)
+}
+
+// ExportSpreadsheet 选择保存位置并导出任务与得分表格，取消时返回空列表
+func (a *App) ExportSpreadsheet(format, year string) ([]string, error) {
+	if err := ensureUnlocked(); err != nil {
+		return nil, err
+	}
+	name := "Manifest任务导出"
+	if year != "" {
+		name = fmt.Sprintf("Manifest任务导出-%s", year)
+	}
+	extension := "." + format
+	path, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		Title:           "导出表格",
+		DefaultFilename: name + extension,
+		Filters: []runtime.FileFilter{
+			{DisplayName: strings.ToUpper(format), Pattern: "*" + extension},
+		},
+	})
+	if err != nil || path == "" {
+		return []string{}, err
+	}
+
+	return ExportSpreadsheet(format, year, path)
+}
+
+// SelectImportFile 选择要导入的表格文件，取消时返回空路径
+func (a *App) SelectImportFile() (string, error) {
+	return runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{
+		Title: "选择要导入的表格",
+		Filters: []runtime.FileFilter{
+			{DisplayName: "表格文件 (*.csv;*.xlsx)", Pattern: "*.csv;*.xlsx"},
+		},
+	})
+}
+
+// PreviewSpreadsheet 预览表格标题与样例行
+func (a *App) PreviewSpreadsheet(path, sheet string) (*SpreadsheetPreview, error) {
+	if err := ensureUnlocked(); err != nil {
+		return nil, err
+	}
+	return PreviewSpreadsheet(path, sheet)
+}
+
+// ImportSpreadsheet 按列映射导入表格中的任务
+func (a *App) ImportSpreadsheet(path string, options ImportOptions) (*ImportResult, error) {
+	if err := ensureUnlocked(); err != nil {
+		return nil, err
+	}
+	result, err := ImportSpreadsheet(path, options)
+	return result, a.recordChange(err)
+}
+
+// ExportTodoTxt 选择保存位置并导出todo.txt，取消时返回空路径
+func (a *App) ExportTodoTxt(year string) (string, error) {
+	if err := ensureUnlocked(); err != nil {
+		return "", err
+	}
+	path, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		Title:           "导出todo.txt",
+		DefaultFilename: "todo.txt",
+		Filters: []runtime.FileFilter{
+			{DisplayName: "todo.txt (*.txt)", Pattern: "*.txt"},
+		},
+	})
+	if err != nil || path == "" {
+		return "", err
+	}
+
+	if err := ExportTodoTxt(year, path); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// ExportTaskwarrior 选择保存位置并导出Taskwarrior JSON，取消时返回空路径
+func (a *App) ExportTaskwarrior(year string) (string, error) {
+	if err := ensureUnlocked(); err != nil {
+		return "", err
+	}
+	path, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		Title:           "导出Taskwarrior任务",
+		DefaultFilename: "manifest-tasks.json",
+		Filters: []runtime.FileFilter{
+			{DisplayName: "JSON (*.json)", Pattern: "*.json"},
+		},
+	})
+	if err != nil || path == "" {
+		return "", err
+	}
+
+	if err := ExportTaskwarrior(year, path); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// SelectTaskFile 选择要导入的todo.txt或Taskwarrior文件，取消时返回空路径
+func (a *App) SelectTaskFile() (string, error) {
+	return runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{
+		Title: "选择任务文件",
+		Filters: []runtime.FileFilter{
+			{DisplayName: "任务文件 (*.txt;*.json)", Pattern: "*.txt;*.json"},
+		},
+	})
+}
+
+// ImportTodoTxt 从todo.txt导入任务
+func (a *App) ImportTodoTxt(path string, options TaskFileImportOptions) (*ImportResult, error) {
+	if err := ensureUnlocked(); err != nil {
+		return nil, err
+	}
+	result, err := ImportTodoTxt(path, options)
+	return result, a.recordChange(err)
+}
+
+// ImportTaskwarrior 从Taskwarrior导出文件导入任务
+func (a *App) ImportTaskwarrior(path string, options TaskFileImportOptions) (*ImportResult, error) {
+	if err := ensureUnlocked(); err != nil {
+		return nil, err
+	}
+	result, err := ImportTaskwarrior(path, options)
+	return result, a.recordChange(err)
+}
+
+// GetWorkspaceTemplates 获取所有工作区模板
+func (a *App) GetWorkspaceTemplates() ([]WorkspaceTemplate, error) {
+	if err := ensureUnlocked(); err != nil {
+		return nil, err
+	}
+	return GetWorkspaceTemplates()
+}
+
+// SaveWorkspaceTemplate 创建或更新工作区模板
+func (a *App) SaveWorkspaceTemplate(template WorkspaceTemplate) (WorkspaceTemplate, error) {
+	if err := ensureUnlocked(); err != nil {
+		return WorkspaceTemplate{}, err
+	}
+	return SaveWorkspaceTemplate(template)
+}
+
+// DeleteWorkspaceTemplate 删除工作区模板
+func (a *App) DeleteWorkspaceTemplate(templateID string) error {
+	if err := ensureUnlocked(); err != nil {
+		return err
+	}
+	return DeleteWorkspaceTemplate(templateID)
+}
+
+// CreateTemplateFromYear 以已有年度创建工作区模板
+func (a *App) CreateTemplateFromYear(year, name string) (WorkspaceTemplate, error) {
+	if err := ensureUnlocked(); err != nil {
+		return WorkspaceTemplate{}, err
+	}
+	return CreateTemplateFromYear(year, name)
+}
+
+// CreateYearFromTemplate 按工作区模板创建新的年度
+func (a *App) CreateYearFromTemplate(year, templateID string) (*AnnualData, error) {
+	if err := ensureUnlocked(); err != nil {
+		return nil, err
+	}
+	data, err := CreateYearFromTemplate(year, templateID)
+	return data, a.recordChange(err)
+}
+
+// ApplyWorkspaceTemplate 将工作区模板的最新版本重新应用到已有年度
+func (a *App) ApplyWorkspaceTemplate(year, templateID string) (*AnnualData, error) {
+	if err := ensureUnlocked(); err != nil {
+		return nil, err
+	}
+	data, err := ApplyWorkspaceTemplate(year, templateID)
+	return data, a.recordChange(err)
+}
+
+// GetYearTemplateStatuses 获取各年度使用的模板及是否有新版本
+func (a *App) GetYearTemplateStatuses() ([]YearTemplateStatus, error) {
+	if err := ensureUnlocked(); err != nil {
+		return nil, err
+	}
+	return GetYearTemplateStatuses()
+}
+
+// ExportWorkspaceTemplate 选择保存位置并导出工作区模板，取消时返回空路径
+func (a *App) ExportWorkspaceTemplate(templateID string) (string, error) {
+	if err := ensureUnlocked(); err != nil {
+		return "", err
+	}
+	path, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		Title:           "导出工作区模板",
+		DefaultFilename: "manifest-template.json",
+		Filters: []runtime.FileFilter{
+			{DisplayName: "JSON (*.json)", Pattern: "*.json"},
+		},
+	})
+	if err != nil || path == "" {
+		return "", err
+	}
+
+	if err := ExportWorkspaceTemplate(templateID, path); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// ImportWorkspaceTemplate 选择并导入工作区模板文件，取消时返回nil
+func (a *App) ImportWorkspaceTemplate() (*TemplateImportResult, error) {
+	if err := ensureUnlocked(); err != nil {
+		return nil, err
+	}
+	path, err := runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{
+		Title: "导入工作区模板",
+		Filters: []runtime.FileFilter{
+			{DisplayName: "JSON (*.json)", Pattern: "*.json"},
+		},
+	})
+	if err != nil || path == "" {
+		return nil, err
+	}
+	return ImportWorkspaceTemplate(path)
+}
+
+// GetMarkdownSyncSettings 获取Markdown同步设置
+func (a *App) GetMarkdownSyncSettings() (MarkdownSyncSettings, error) {
+	if err := ensureUnlocked(); err != nil {
+		return MarkdownSyncSettings{}, err
+	}
+	return GetMarkdownSyncSettings()
+}
+
+// SaveMarkdownSyncSettings 保存Markdown同步设置并立即按新设置同步
+func (a *App) SaveMarkdownSyncSettings(settings MarkdownSyncSettings) error {
+	if err := ensureUnlocked(); err != nil {
+		return err
+	}
+	if err := SaveMarkdownSyncSettings(settings); err != nil {
+		return err
+	}
+	a.vault.Refresh()
+	return nil
+}
+
+// SelectMarkdownVault 选择Markdown库目录，取消时返回空路径
+func (a *App) SelectMarkdownVault() (string, error) {
+	return runtime.OpenDirectoryDialog(a.ctx, runtime.OpenDialogOptions{
+		Title: "选择Markdown库目录",
+	})
+}
+
+// SyncMarkdownVault 立即与Markdown库同步
+func (a *App) SyncMarkdownVault() (*MarkdownSyncResult, error) {
+	if err := ensureUnlocked(); err != nil {
+		return nil, err
+	}
+	result, err := SyncMarkdownVault()
+	return result, a.recordChange(err)
+}
+
+// ResolveMarkdownConflict 处理Markdown同步冲突
+func (a *App) ResolveMarkdownConflict(year, dimensionKey, keep string) error {
+	if err := ensureUnlocked(); err != nil {
+		return err
+	}
+	return a.recordChange(ResolveMarkdownConflict(year, dimensionKey, keep))
+}
+
+// GetCalDAVSettings 获取CalDAV同步设置
+func (a *App) GetCalDAVSettings() (CalDAVSettings, error) {
+	if err := ensureUnlocked(); err != nil {
+		return CalDAVSettings{}, err
+	}
+	return GetCalDAVSettings()
+}
+
+// SaveCalDAVSettings 保存CalDAV同步设置并立即按新设置同步
+func (a *App) SaveCalDAVSettings(settings CalDAVSettings) error {
+	if err := ensureUnlocked(); err != nil {
+		return err
+	}
+	if err := SaveCalDAVSettings(settings); err != nil {
+		return err
+	}
+	a.caldav.Refresh()
+	return nil
+}
+
+// TestCalDAVConnection 测试CalDAV连接，返回日历名称
+func (a *App) TestCalDAVConnection(settings CalDAVSettings) (string, error) {
+	if err := ensureUnlocked(); err != nil {
+		return "", err
+	}
+	return TestCalDAVConnection(settings)
+}
+
+// SyncCalDAV 立即与CalDAV服务器同步
+func (a *App) SyncCalDAV() (*CalDAVSyncResult, error) {
+	if err := ensureUnlocked(); err != nil {
+		return nil, err
+	}
+	result, err := SyncCalDAV(a.ctx)
+	return result, a.recordChange(err)
+}
+
+// ResolveCalDAVConflict 处理CalDAV同步冲突，keep 为 server 或 local
+func (a *App) ResolveCalDAVConflict(taskID, keep string) error {
+	if err := ensureUnlocked(); err != nil {
+		return err
+	}
+	return a.recordChange(ResolveCalDAVConflict(a.ctx, taskID, keep))
+}
+
+// GetCalDAVSyncStatuses 获取所有任务的CalDAV同步状态
+func (a *App) GetCalDAVSyncStatuses() ([]CalDAVTaskStatus, error) {
+	if err := ensureUnlocked(); err != nil {
+		return nil, err
+	}
+	return GetCalDAVSyncStatuses()
+}
+
+// GetLANSyncSettings 获取局域网同步设置
+func (a *App) GetLANSyncSettings() (LANSyncSettings, error) {
+	if err := ensureUnlocked(); err != nil {
+		return LANSyncSettings{}, err
+	}
+	return GetLANSyncSettings()
+}
+
+// SaveLANSyncSettings 保存局域网同步设置并立即按新设置启动或停止同步服务
+func (a *App) SaveLANSyncSettings(settings LANSyncSettings) error {
+	if err := ensureUnlocked(); err != nil {
+		return err
+	}
+	if err := SaveLANSyncSettings(settings); err != nil {
+		return err
+	}
+	a.lanSync.Refresh()
+	return nil
+}
+
+// GetLANDeviceInfo 获取本机的同步设备信息
+func (a *App) GetLANDeviceInfo() (LANDeviceInfo, error) {
+	if err := ensureUnlocked(); err != nil {
+		return LANDeviceInfo{}, err
+	}
+	return GetLANDeviceInfo()
+}
+
+// StartLANPairing 生成配对码，在另一台设备上输入
+func (a *App) StartLANPairing() (LANPairingCode, error) {
+	if err := ensureUnlocked(); err != nil {
+		return LANPairingCode{}, err
+	}
+	return StartLANPairing()
+}
+
+// DiscoverLANPeers 查找局域网中的设备
+func (a *App) DiscoverLANPeers() ([]LANPeer, error) {
+	if err := ensureUnlocked(); err != nil {
+		return nil, err
+	}
+	return DiscoverLANPeers()
+}
+
+// PairLANPeer 使用配对码与设备配对，配对后立即同步
+func (a *App) PairLANPeer(address, code string) (LANPeer, error) {
+	if err := ensureUnlocked(); err != nil {
+		return LANPeer{}, err
+	}
+	peer, err := PairLANPeer(address, code)
+	if err != nil {
+		return LANPeer{}, err
+	}
+	a.lanSync.Refresh()
+	return peer, nil
+}
+
+// GetLANPeers 获取已配对的设备
+func (a *App) GetLANPeers() ([]LANPeer, error) {
+	if err := ensureUnlocked(); err != nil {
+		return nil, err
+	}
+	return GetLANPeers()
+}
+
+// RemoveLANPeer 取消与设备的配对
+func (a *App) RemoveLANPeer(deviceID string) error {
+	if err := ensureUnlocked(); err != nil {
+		return err
+	}
+	return RemoveLANPeer(deviceID)
+}
+
+// SyncLANPeers 立即与所有已配对的设备同步
+func (a *App) SyncLANPeers() ([]LANPeerSyncResult, error) {
+	if err := ensureUnlocked(); err != nil {
+		return nil, err
+	}
+	results, err := SyncLANPeers(a.ctx)
+	return results, a.recordChange(err)
+}
+
+// GetFolderSyncSettings 获取共享目录同步设置
+func (a *App) GetFolderSyncSettings() (FolderSyncSettings, error) {
+	if err := ensureUnlocked(); err != nil {
+		return FolderSyncSettings{}, err
+	}
+	return GetFolderSyncSettings()
+}
+
+// SaveFolderSyncSettings 保存共享目录同步设置并立即按新设置同步
+func (a *App) SaveFolderSyncSettings(settings FolderSyncSettings) error {
+	if err := ensureUnlocked(); err != nil {
+		return err
+	}
+	if err := SaveFolderSyncSettings(settings); err != nil {
+		return err
+	}
+	a.folders.Refresh()
+	return nil
+}
+
+// SelectSyncFolder 选择共享目录，取消时返回空路径
+func (a *App) SelectSyncFolder() (string, error) {
+	return runtime.OpenDirectoryDialog(a.ctx, runtime.OpenDialogOptions{
+		Title: "选择共享目录",
+	})
+}
+
+// SyncFolder 立即与共享目录同步
+func (a *App) SyncFolder() (*FolderSyncResult, error) {
+	if err := ensureUnlocked(); err != nil {
+		return nil, err
+	}
+	result, err := SyncFolder()
+	return result, a.recordChange(err)
+}
+
+// GetBackupSettings 获取远程备份设置
+func (a *App) GetBackupSettings() (BackupSettings, error) {
+	if err := ensureUnlocked(); err != nil {
+		return BackupSettings{}, err
+	}
+	return GetBackupSettings()
+}
+
+// SaveBackupSettings 保存远程备份设置并按新设置检查是否需要备份
+func (a *App) SaveBackupSettings(settings BackupSettings) error {
+	if err := ensureUnlocked(); err != nil {
+		return err
+	}
+	if err := SaveBackupSettings(settings); err != nil {
+		return err
+	}
+	a.backups.Refresh()
+	return nil
+}
+
+// TestBackupConnection 测试能否访问备份位置，返回已有的快照数量
+func (a *App) TestBackupConnection(settings BackupSettings) (int, error) {
+	if err := ensureUnlocked(); err != nil {
+		return 0, err
+	}
+	return TestBackupConnection(settings)
+}
+
+// GetBackupSnapshots 获取远程的快照，最新的在前
+func (a *App) GetBackupSnapshots() ([]BackupSnapshot, error) {
+	if err := ensureUnlocked(); err != nil {
+		return nil, err
+	}
+	return GetBackupSnapshots(a.ctx)
+}
+
+// CreateBackup 立即备份，进度通过 backupProgress 事件发送
+func (a *App) CreateBackup() (*BackupResult, error) {
+	if err := ensureUnlocked(); err != nil {
+		return nil, err
+	}
+	return CreateBackup(a.ctx, a.emitBackupProgress)
+}
+
+// RestoreBackup 恢复快照，passphrase 为空时使用设置中的备份密码；进度通过 backupProgress 事件发送
+func (a *App) RestoreBackup(name, passphrase string) error {
+	if err := ensureUnlocked(); err != nil {
+		return err
+	}
+	if err := RestoreBackup(a.ctx, name, passphrase, a.emitBackupProgress); err != nil {
+		return err
+	}
+	a.changes.Refresh()
+	a.history.Refresh()
+	return nil
+}
+
+// 辅助函数：向前端发送备份进度
+func (a *App) emitBackupProgress(progress BackupProgress) {
+	runtime.EventsEmit(a.ctx, backupProgressEvent, progress)
+}
+
+// GetGitHistorySettings 获取Git历史记录设置
+func (a *App) GetGitHistorySettings() (GitHistorySettings, error) {
+	if err := ensureUnlocked(); err != nil {
+		return GitHistorySettings{}, err
+	}
+	return GetGitHistorySettings()
+}
+
+// SaveGitHistorySettings 保存Git历史记录设置并立即提交一次
+func (a *App) SaveGitHistorySettings(settings GitHistorySettings) error {
+	if err := ensureUnlocked(); err != nil {
+		return err
+	}
+	if err := SaveGitHistorySettings(settings); err != nil {
+		return err
+	}
+	a.history.Refresh()
+	return nil
+}
+
+// SelectGitHistoryDir 选择历史仓库目录，取消时返回空路径
+func (a *App) SelectGitHistoryDir() (string, error) {
+	return runtime.OpenDirectoryDialog(a.ctx, runtime.OpenDialogOptions{
+		Title:                "选择历史仓库目录",
+		CanCreateDirectories: true,
+	})
+}
+
+// CommitGitHistory 立即提交当前数据
+func (a *App) CommitGitHistory() (*GitHistoryCommitResult, error) {
+	if err := ensureUnlocked(); err != nil {
+		return nil, err
+	}
+	return CommitGitHistory()
+}
+
+// GetGitHistory 获取历史提交，可按年度或维度筛选
+func (a *App) GetGitHistory(year, dimensionKey string, limit int) ([]GitHistoryEntry, error) {
+	if err := ensureUnlocked(); err != nil {
+		return nil, err
+	}
+	return GetGitHistory(year, dimensionKey, limit)
+}
+
+// RestoreGitHistory 将数据回滚到某次提交
+func (a *App) RestoreGitHistory(commit string) (*GitHistoryCommitResult, error) {
+	if err := ensureUnlocked(); err != nil {
+		return nil, err
+	}
+	result, err := RestoreGitHistory(commit)
+	return result, a.recordChange(err)
+}
+
+// 辅助函数：替换数据库连接期间停止使用数据库的后台任务，结束后重新启动
+//
+// Stop 会等待正在执行的任务结束，任务不会用到已关闭的连接或读到切换中的 db。
+func (a *App) withDatabaseJobsStopped(fn func() error) error {
+	jobs := []*Scheduler{a.idleLock, a.reminders, a.digests, a.vault, a.caldav, a.lanSync, a.changes, a.folders, a.backups, a.history}
+	for _, job := range jobs {
+		job.Stop()
+	}
+	// 局域网同步服务在任务停止后才会关闭，需等待正在处理的同步请求结束
+	stopLANServer()
+	defer func() {
+		for _, job := range jobs {
+			job.Start(a.ctx)
+		}
+	}()
+	return fn()
+}
+
+// 辅助函数：执行可能切换到其他账号数据库的操作，切换到的数据库已加密时通知前端输入密码
+func (a *App) withAccountDatabase(fn func() error) error {
+	err := a.withDatabaseJobsStopped(fn)
+	if db == nil {
+		runtime.EventsEmit(a.ctx, "databaseLocked")
+	}
+	return err
+}
+
+// 辅助函数：数据保存成功后记录同步修改时间并通知Git历史记录提交，返回原错误
+func (a *App) recordChange(err error) error {
+	if err == nil {
+		a.changes.Refresh()
+		a.history.Refresh()
+	}
+	return err
+}
+
+// GetDatabaseEncryptionStatus 获取数据库加密状态
+func (a *App) GetDatabaseEncryptionStatus() (DatabaseEncryptionStatus, error) {
+	return GetDatabaseEncryptionStatus()
+}
+
+// UnlockDatabase 使用密码解锁加密数据库
+func (a *App) UnlockDatabase(passphrase string) error {
+	if err := UnlockDatabase(passphrase); err != nil {
+		return err
+	}
+	// 旧版本的账号保存在加密数据库中，解锁并迁移到账号库后才能恢复上次使用的账号
+	if status, err := GetSessionStatus(); err == nil && status.AccountID == "" {
+		a.restoreSession()
+	}
+	return nil
+}
+
+// EnableDatabaseEncryption 设置密码并加密数据库
+func (a *App) EnableDatabaseEncryption(passphrase string) error {
+	if err := ensureUnlocked(); err != nil {
+		return err
+	}
+	return a.withDatabaseJobsStopped(func() error {
+		return EnableDatabaseEncryption(passphrase)
+	})
+}
+
+// DisableDatabaseEncryption 取消数据库加密
+func (a *App) DisableDatabaseEncryption(passphrase string) error {
+	if err := ensureUnlocked(); err != nil {
+		return err
+	}
+	return a.withDatabaseJobsStopped(func() error {
+		return DisableDatabaseEncryption(passphrase)
+	})
+}
+
+// ChangeDatabasePassphrase 修改数据库密码
+func (a *App) ChangeDatabasePassphrase(oldPassphrase, newPassphrase string) error {
+	if err := ensureUnlocked(); err != nil {
+		return err
+	}
+	return ChangeDatabasePassphrase(oldPassphrase, newPassphrase)
+}
+
+// GetDataDirInfo 获取当前使用的数据目录
+func (a *App) GetDataDirInfo() (DataDirInfo, error) {
+	return GetDataDirInfo()
+}
+
+// SelectDataDir 选择新的数据目录，取消时返回空路径
+func (a *App) SelectDataDir() (string, error) {
+	return runtime.OpenDirectoryDialog(a.ctx, runtime.OpenDialogOptions{
+		Title:                "选择数据目录",
+		CanCreateDirectories: true,
+	})
+}
+
+// RelocateDataDir 将所有数据迁移到新目录
+func (a *App) RelocateDataDir(target string) error {
+	if err := ensureSessionUnlocked(); err != nil {
+		return err
+	}
+	err := a.withDatabaseJobsStopped(func() error {
+		return RelocateDataDir(target)
+	})
+	if db == nil {
+		// 加密数据库迁移后需要重新输入密码
+		runtime.EventsEmit(a.ctx, "databaseLocked")
+	}
+	return err
 }
 
 // ResetAllData 重置所有数据
 func (a *App) ResetAllData() error {
-	return ResetAllData()
+	if err := ensureUnlocked(); err != nil {
+		return err
+	}
+	return a.recordChange(ResetAllData())
 }
 
 // ImportData 导入数据
 func (a *App) ImportData(data SystemData) error {
-	return ImportData(data)
+	if err := ensureUnlocked(); err != nil {
+		return err
+	}
+	return a.recordChange(ImportData(data))
 }
 
 // GetAccounts 获取所有账号
@@ -116,12 +1117,95 @@ func (a *App) GetAccounts() ([]Account, error) {
 
 // SaveAccount 保存/更新账号
 func (a *App) SaveAccount(account Account) error {
+	if err := ensureSessionUnlocked(); err != nil {
+		return err
+	}
 	return SaveAccount(account)
 }
 
-// SwitchAccount 切换当前活跃账号
-func (a *App) SwitchAccount(accountID string) error {
-	return SwitchAccount(accountID)
+// SwitchAccount 切换当前活跃账号，目标账号设置了密码或PIN时需提供；当前账号已锁定时还需提供当前账号的密码或PIN
+func (a *App) SwitchAccount(accountID, secret, currentSecret string) error {
+	return a.withAccountDatabase(func() error {
+		return SwitchAccount(accountID, secret, currentSecret)
+	})
+}
+
+// GetSessionStatus 获取当前账号及锁定状态
+func (a *App) GetSessionStatus() (SessionStatus, error) {
+	return GetSessionStatus()
+}
+
+// TouchSession 前端在用户操作时调用，重置空闲计时
+func (a *App) TouchSession() {
+	TouchSession()
+}
+
+// LockSession 立即锁定当前账号
+func (a *App) LockSession() error {
+	return LockSession()
+}
+
+// UnlockSession 验证密码或PIN并解锁当前账号
+func (a *App) UnlockSession(secret string) error {
+	return UnlockSession(secret)
+}
+
+// SetAccountLock 设置、修改或移除账号的密码/PIN
+func (a *App) SetAccountLock(accountID, lockType, currentSecret, newSecret string) error {
+	return SetAccountLock(accountID, lockType, currentSecret, newSecret)
+}
+
+// GetAutoLockSettings 获取空闲自动锁定设置
+func (a *App) GetAutoLockSettings() (AutoLockSettings, error) {
+	return GetAutoLockSettings()
+}
+
+// SaveAutoLockSettings 保存空闲自动锁定设置
+func (a *App) SaveAutoLockSettings(settings AutoLockSettings) error {
+	if err := ensureSessionUnlocked(); err != nil {
+		return err
+	}
+	if err := SaveAutoLockSettings(settings); err != nil {
+		return err
+	}
+	a.idleLock.Refresh()
+	return nil
+}
+
+// SelectAvatarFile 选择头像图片，取消时返回空路径
+func (a *App) SelectAvatarFile() (string, error) {
+	return runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{
+		Title: "选择头像",
+		Filters: []runtime.FileFilter{
+			{DisplayName: "图片 (*.png;*.jpg;*.jpeg;*.gif)", Pattern: "*.png;*.jpg;*.jpeg;*.gif"},
+		},
+	})
+}
+
+// RenameAccount 修改账号名称
+func (a *App) RenameAccount(accountID, username string) error {
+	if err := ensureSessionUnlocked(); err != nil {
+		return err
+	}
+	return RenameAccount(accountID, username)
+}
+
+// ReplaceAccountAvatar 更换账号头像，sourcePath 为空时移除头像
+func (a *App) ReplaceAccountAvatar(accountID, sourcePath string) (Account, error) {
+	if err := ensureSessionUnlocked(); err != nil {
+		return Account{}, err
+	}
+	return ReplaceAccountAvatar(accountID, sourcePath)
+}
+
+// DeleteAccount 删除账号及其头像，账号设置了密码或PIN时需提供
+func (a *App) DeleteAccount(accountID, secret string) error {
+	if err := ensureSessionUnlocked(); err != nil {
+		return err
+	}
+	return a.withAccountDatabase(func() error {
+		return DeleteAccount(accountID, secret)
+	})
 }
 
 // GetLastUsedAccount 获取最后使用的账号
@@ -131,7 +1215,15 @@ func (a *App) GetLastUsedAccount() (*Account, error) {
 
 // NewAccount 创建新账号
 func (a *App) NewAccount(username, avatarPath string) (Account, error) {
-	return NewAccount(username, avatarPath)
+	if err := ensureSessionUnlocked(); err != nil {
+		return Account{}, err
+	}
+	var account Account
+	err := a.withAccountDatabase(func() (err error) {
+		account, err = NewAccount(username, avatarPath)
+		return err
+	})
+	return account, err
 }
 
 // GetAvatarAbsolutePath 获取头像的绝对路径
@@ -139,72 +1231,74 @@ func (a *App) GetAvatarAbsolutePath(relativePath string) (string, error) {
 	return GetAvatarAbsolutePath(relativePath)
 }
 
-// CheckUpdateResult 定义更新检查结果的结构体
-type CheckUpdateResult struct {
-	UpdateAvailable bool   `json:"updateAvailable"` // 是否有更新可用
-	CurrentVersion  string `json:"currentVersion"`  // 当前版本
-	LatestVersion   string `json:"latestVersion"`   // 最新版本
-	ReleaseNotes    string `json:"releaseNotes"`    // 发布说明
-	DownloadURL     string `json:"downloadURL"`     // 下载URL
+// GetUpdateSettings 获取检查更新设置
+func (a *App) GetUpdateSettings() (UpdateSettings, error) {
+	return GetUpdateSettings()
 }
 
-// CheckUpdate 检查是否有新版本可用
-func (a *App) CheckUpdate() (*CheckUpdateResult, error) {
-	// 创建带超时的HTTP客户端
-	client := &http.Client{
-		Timeout: 10 * time.Second,
+// SaveUpdateSettings 保存检查更新设置并按新设置重新安排检查
+func (a *App) SaveUpdateSettings(settings UpdateSettings) error {
+	if err := SaveUpdateSettings(settings); err != nil {
+		return err
 	}
+	a.updates.Refresh()
+	return nil
+}
 
-	// 发送请求到GitHub Releases API
-	resp, err := client.Get("https://api.github.com/repos/PotatoZhou/Manifest/releases/latest")
-	if err != nil {
-		log.Printf("检查更新失败: %v", err)
-		return &CheckUpdateResult{UpdateAvailable: false}, nil
-	}
-	defer resp.Body.Close()
+// CheckUpdate 立即检查是否有新版本可用
+func (a *App) CheckUpdate() (*CheckUpdateResult, error) {
+	return CheckUpdate(a.ctx)
+}
 
-	// 读取响应体
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		log.Printf("读取更新响应失败: %v", err)
-		return &CheckUpdateResult{UpdateAvailable: false}, nil
-	}
+// SkipUpdateVersion 跳过某个版本，传空字符串取消跳过
+func (a *App) SkipUpdateVersion(version string) error {
+	return SkipUpdateVersion(version)
+}
 
-	// 解析JSON响应
-	var release GitHubRelease
-	if err := json.Unmarshal(body, &release); err != nil {
-		log.Printf("解析更新响应失败: %v", err)
-		return &CheckUpdateResult{UpdateAvailable: false}, nil
-	}
+// GetWhatsNew 获取最近一次升级的新内容，没有升级记录时返回 null
+func (a *App) GetWhatsNew() (*WhatsNew, error) {
+	return GetWhatsNew(a.ctx)
+}
 
-	// 解析当前版本
-	current, err := version.NewVersion(currentVersion)
-	if err != nil {
-		log.Printf("解析当前版本失败: %v", err)
-		return &CheckUpdateResult{UpdateAvailable: false}, nil
-	}
+// DismissWhatsNew 标记升级后的新内容已查看
+func (a *App) DismissWhatsNew() error {
+	return DismissWhatsNew()
+}
+
+// GetReleaseNotesHistory 获取所有版本的发布说明，refresh 为 true 时先从更新源获取
+func (a *App) GetReleaseNotesHistory(refresh bool) ([]ReleaseNote, error) {
+	return GetReleaseNotesHistory(a.ctx, refresh)
+}
+
+// InstallUpdate 下载并校验最新版本，进度通过 updateProgress 事件发送，完成后需重启安装
+func (a *App) InstallUpdate() (*UpdateInstallState, error) {
+	return InstallUpdate(a.ctx, func(progress UpdateProgress) {
+		runtime.EventsEmit(a.ctx, updateProgressEvent, progress)
+	})
+}
 
-	// 解析最新版本
-	latest, err := version.NewVersion(release.TagName)
+// GetUpdateInstallState 获取应用内更新状态，包括上次更新失败的原因
+func (a *App) GetUpdateInstallState() (*UpdateInstallState, error) {
+	return GetUpdateInstallState()
+}
+
+// CancelUpdate 放弃已下载的更新，或清除上次更新失败的记录
+func (a *App) CancelUpdate() error {
+	return CancelUpdate()
+}
+
+// RestartToUpdate 退出并重新启动以安装已下载的更新
+func (a *App) RestartToUpdate() error {
+	state, err := GetUpdateInstallState()
 	if err != nil {
-		log.Printf("解析最新版本失败: %v", err)
-		return &CheckUpdateResult{UpdateAvailable: false}, nil
+		return err
 	}
-
-	// 比较版本
-	if latest.GreaterThan(current) {
-		// 有新版本可用
-		return &CheckUpdateResult{
-			UpdateAvailable: true,
-			CurrentVersion:  currentVersion,
-			LatestVersion:   release.TagName,
-			ReleaseNotes:    release.Body,
-			DownloadURL:     release.HTMLURL,
-		}, nil
+	if state == nil || state.Status != UpdateInstallStaged {
+		return fmt.Errorf("没有等待安装的更新")
 	}
-
-	// 没有新版本
-	return &CheckUpdateResult{UpdateAvailable: false}, nil
+	a.restartOnExit = true
+	runtime.Quit(a.ctx)
+	return nil
 }
 
 // OpenDownloadURL 打开下载链接
@@ -0,0 +1,265 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/draw"
+	_ "image/gif"
+	_ "image/jpeg"
+	"image/png"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// 头像限制
+const (
+	avatarsDirName      = "avatars"
+	maxAvatarBytes      = 5 << 20 // 5MB
+	maxAvatarDimension  = 4096    // 宽高上限（像素）
+	avatarThumbnailSize = 128     // 缩略图边长（像素）
+	avatarThumbSuffix   = ".thumb.png"
+	maxUsernameLength   = 32
+)
+
+// 支持的头像格式及对应的扩展名
+var avatarExtensions = map[string]string{
+	"image/png":  ".png",
+	"image/jpeg": ".jpg",
+	"image/gif":  ".gif",
+}
+
+// RenameAccount 修改账号名称
+func RenameAccount(accountID, username string) error {
+	username, err := validateUsername(username)
+	if err != nil {
+		return err
+	}
+	if accountsDB == nil {
+		return errDatabaseNotReady
+	}
+
+	result, err := accountsDB.Exec(`UPDATE accounts SET username = ? WHERE id = ?`, username, accountID)
+	if err != nil {
+		return fmt.Errorf("修改账号名称失败: %w", err)
+	}
+	if count, _ := result.RowsAffected(); count == 0 {
+		return fmt.Errorf("账号不存在")
+	}
+	return nil
+}
+
+// ReplaceAccountAvatar 更换账号头像并删除旧头像文件，sourcePath 为空时移除头像
+func ReplaceAccountAvatar(accountID, sourcePath string) (Account, error) {
+	account, err := findAccount(accountID)
+	if err != nil {
+		return Account{}, err
+	}
+
+	avatarPath := ""
+	if sourcePath != "" {
+		if avatarPath, err = CopyAvatarToAppDir(sourcePath); err != nil {
+			return Account{}, err
+		}
+	}
+
+	if _, err := accountsDB.Exec(`UPDATE accounts SET avatar_path = ? WHERE id = ?`, avatarPath, accountID); err != nil {
+		removeAvatarFiles(avatarPath)
+		return Account{}, fmt.Errorf("保存头像失败: %w", err)
+	}
+	if err := removeAvatarFiles(account.AvatarPath); err != nil {
+		log.Printf("删除旧头像失败: %v", err)
+	}
+
+	account.AvatarPath = avatarPath
+	account.ThumbnailPath = avatarThumbnailPath(avatarPath)
+	account.LockHash = ""
+	return account, nil
+}
+
+// CleanupAvatars 删除头像目录中不再被任何账号使用的文件，并为缺少缩略图的头像补充缩略图，返回删除的文件数
+func CleanupAvatars() (int, error) {
+	accounts, err := loadAccounts()
+	if err != nil {
+		return 0, err
+	}
+	appDataDir, err := GetAppDataDir()
+	if err != nil {
+		return 0, err
+	}
+	avatarsDir := filepath.Join(appDataDir, avatarsDirName)
+
+	entries, err := os.ReadDir(avatarsDir)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("读取头像目录失败: %w", err)
+	}
+
+	used := make(map[string]bool)
+	for _, account := range accounts {
+		if !isManagedAvatar(account.AvatarPath) {
+			continue
+		}
+		used[filepath.Base(account.AvatarPath)] = true
+		used[filepath.Base(avatarThumbnailPath(account.AvatarPath))] = true
+
+		// 旧版本复制的头像没有缩略图
+		thumbPath := filepath.Join(appDataDir, avatarThumbnailPath(account.AvatarPath))
+		if _, err := os.Stat(thumbPath); os.IsNotExist(err) {
+			if err := createAvatarThumbnail(filepath.Join(appDataDir, account.AvatarPath), thumbPath); err != nil {
+				log.Printf("生成头像缩略图失败: %v", err)
+			}
+		}
+	}
+
+	removed := 0
+	for _, entry := range entries {
+		if entry.IsDir() || used[entry.Name()] {
+			continue
+		}
+		if err := os.Remove(filepath.Join(avatarsDir, entry.Name())); err != nil {
+			return removed, fmt.Errorf("删除头像文件失败: %w", err)
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+// 辅助函数：校验账号名称，返回去除首尾空白后的名称
+func validateUsername(username string) (string, error) {
+	username = strings.TrimSpace(username)
+	if username == "" {
+		return "", fmt.Errorf("账号名称不能为空")
+	}
+	if len([]rune(username)) > maxUsernameLength {
+		return "", fmt.Errorf("账号名称不能超过%d个字符", maxUsernameLength)
+	}
+	return username, nil
+}
+
+// 辅助函数：校验头像文件的格式、大小与尺寸，返回对应的扩展名与解码后的图片
+func decodeAvatar(content []byte) (string, image.Image, error) {
+	if len(content) > maxAvatarBytes {
+		return "", nil, fmt.Errorf("头像文件不能超过%dMB", maxAvatarBytes>>20)
+	}
+
+	extension, ok := avatarExtensions[http.DetectContentType(content)]
+	if !ok {
+		return "", nil, fmt.Errorf("头像只支持PNG、JPEG、GIF格式")
+	}
+
+	// 先读取尺寸，避免解码超大图片
+	config, _, err := image.DecodeConfig(bytes.NewReader(content))
+	if err != nil {
+		return "", nil, fmt.Errorf("无法识别的头像图片: %w", err)
+	}
+	if config.Width > maxAvatarDimension || config.Height > maxAvatarDimension {
+		return "", nil, fmt.Errorf("头像尺寸不能超过%dx%d", maxAvatarDimension, maxAvatarDimension)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(content))
+	if err != nil {
+		return "", nil, fmt.Errorf("无法识别的头像图片: %w", err)
+	}
+	return extension, img, nil
+}
+
+// 辅助函数：读取头像并生成缩略图
+func createAvatarThumbnail(avatarPath, thumbPath string) error {
+	content, err := os.ReadFile(avatarPath)
+	if err != nil {
+		return err
+	}
+	_, img, err := decodeAvatar(content)
+	if err != nil {
+		return err
+	}
+	return writeAvatarThumbnail(img, thumbPath)
+}
+
+// 辅助函数：居中裁剪为正方形并缩小，保存为 PNG
+func writeAvatarThumbnail(img image.Image, path string) error {
+	var b bytes.Buffer
+	if err := png.Encode(&b, thumbnailImage(img, avatarThumbnailSize)); err != nil {
+		return fmt.Errorf("生成头像缩略图失败: %w", err)
+	}
+	if err := os.WriteFile(path, b.Bytes(), 0644); err != nil {
+		return fmt.Errorf("写入头像缩略图失败: %w", err)
+	}
+	return nil
+}
+
+// 辅助函数：居中裁剪为正方形，按区域平均缩小到 size 像素（小图不放大）
+func thumbnailImage(img image.Image, size int) *image.RGBA {
+	bounds := img.Bounds()
+	side := min(bounds.Dx(), bounds.Dy())
+	origin := image.Pt(bounds.Min.X+(bounds.Dx()-side)/2, bounds.Min.Y+(bounds.Dy()-side)/2)
+
+	src := image.NewRGBA(image.Rect(0, 0, side, side))
+	draw.Draw(src, src.Bounds(), img, origin, draw.Src)
+	if side <= size {
+		return src
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, size, size))
+	for y := 0; y < size; y++ {
+		y0, y1 := y*side/size, (y+1)*side/size
+		for x := 0; x < size; x++ {
+			x0, x1 := x*side/size, (x+1)*side/size
+			var r, g, b, a, count uint32
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					offset := src.PixOffset(sx, sy)
+					r += uint32(src.Pix[offset])
+					g += uint32(src.Pix[offset+1])
+					b += uint32(src.Pix[offset+2])
+					a += uint32(src.Pix[offset+3])
+					count++
+				}
+			}
+			offset := dst.PixOffset(x, y)
+			dst.Pix[offset] = uint8(r / count)
+			dst.Pix[offset+1] = uint8(g / count)
+			dst.Pix[offset+2] = uint8(b / count)
+			dst.Pix[offset+3] = uint8(a / count)
+		}
+	}
+	return dst
+}
+
+// 辅助函数：头像对应的缩略图路径，头像不在应用目录中时返回空
+func avatarThumbnailPath(avatarPath string) string {
+	if !isManagedAvatar(avatarPath) {
+		return ""
+	}
+	return strings.TrimSuffix(avatarPath, filepath.Ext(avatarPath)) + avatarThumbSuffix
+}
+
+// 辅助函数：是否为复制到应用头像目录中的文件
+func isManagedAvatar(avatarPath string) bool {
+	return avatarPath != "" && !filepath.IsAbs(avatarPath) &&
+		filepath.Dir(filepath.Clean(avatarPath)) == avatarsDirName
+}
+
+// 辅助函数：删除应用目录中的头像及其缩略图
+func removeAvatarFiles(avatarPath string) error {
+	if !isManagedAvatar(avatarPath) {
+		return nil
+	}
+	appDataDir, err := GetAppDataDir()
+	if err != nil {
+		return err
+	}
+
+	for _, path := range []string{avatarPath, avatarThumbnailPath(avatarPath)} {
+		if err := os.Remove(filepath.Join(appDataDir, path)); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("删除头像文件失败: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,654 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// 备份进度事件名称
+const backupProgressEvent = "backupProgress"
+
+// 快照文件名：manifest-20060102-150405.backup（UTC 时间）
+const (
+	backupNamePrefix    = "manifest-"
+	backupNameSuffix    = ".backup"
+	backupNameLayout    = "20060102-150405"
+	backupDisplayLayout = "2006-01-02 15:04:05"
+)
+
+// 快照中的文件：数据库与导出的 JSON 数据
+const (
+	backupDatabaseFile = "performance.db"
+	backupExportFile   = "data.json"
+)
+
+// 快照存放位置
+const (
+	backupTargetWebDAV = "webdav"
+	backupTargetS3     = "s3"
+)
+
+// 备份请求超时时间，需要足够上传较大的数据库
+const backupRequestTimeout = 10 * time.Minute
+
+// 快照的最大长度，下载时超过视为异常响应，防止耗尽内存
+const maxBackupDownloadSize = 1 << 30
+
+// 快照列表与错误信息的最大长度
+const maxBackupResponseSize = 8 << 20
+
+// 系统钥匙串中保存备份密码的服务名称与账号名称
+const (
+	backupKeyringService = "Manifest Backup"
+	backupKeyringUser    = "passphrase"
+)
+
+// 备份阶段
+const (
+	backupStageSnapshot = "snapshot" // 生成并加密快照
+	backupStageUpload   = "upload"
+	backupStageDownload = "download"
+	backupStageRestore  = "restore" // 解密并恢复数据库
+	backupStageDone     = "done"
+)
+
+// 恢复时保留的本机同步状态，不随快照回退
+var backupPreservedTables = []string{"lan_sync_identity", "lan_sync_peers", "lan_sync_rows", "folder_sync_logs"}
+
+// BackupSettings 远程备份设置
+type BackupSettings struct {
+	Enabled       bool           `json:"enabled"` // 是否定时自动备份
+	Target        string         `json:"target"`  // webdav, s3
+	WebDAV        WebDAVSettings `json:"webdav"`
+	S3            S3Settings     `json:"s3"`
+	Passphrase    string         `json:"passphrase"`    // 加密快照的密码，恢复时需要；保存在系统钥匙串时为空
+	UseKeyring    bool           `json:"useKeyring"`    // 备份密码保存在系统钥匙串中，不写入配置文件
+	IntervalHours int            `json:"intervalHours"` // 自动备份间隔（小时）
+	KeepCount     int            `json:"keepCount"`     // 保留的快照数量，0 表示全部保留
+}
+
+// BackupSnapshot 远程的一个快照
+type BackupSnapshot struct {
+	Name      string `json:"name"`
+	Size      int64  `json:"size"`
+	CreatedAt string `json:"createdAt"`
+}
+
+// BackupResult 备份结果
+type BackupResult struct {
+	Snapshot BackupSnapshot `json:"snapshot"`
+	Removed  []string       `json:"removed"` // 超出保留数量被删除的快照
+}
+
+// BackupProgress 备份/恢复进度，通过 backupProgress 事件发送给前端
+type BackupProgress struct {
+	Operation string `json:"operation"` // backup, restore
+	Stage     string `json:"stage"`
+	Name      string `json:"name"`
+	Bytes     int64  `json:"bytes"`
+	Total     int64  `json:"total"` // 未知时为 0
+}
+
+// backupTarget 快照存放位置
+type backupTarget interface {
+	list(ctx context.Context) ([]BackupSnapshot, error)
+	upload(ctx context.Context, name string, content []byte, progress func(done int64)) error
+	download(ctx context.Context, name string, progress func(done, total int64)) ([]byte, error)
+	remove(ctx context.Context, name string) error
+}
+
+// progressReader 读取时报告已读取的字节数
+type progressReader struct {
+	reader io.Reader
+	done   int64
+	report func(done int64)
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	if n > 0 {
+		r.done += int64(n)
+		r.report(r.done)
+	}
+	return n, err
+}
+
+// DefaultBackupSettings 默认备份设置
+func DefaultBackupSettings() BackupSettings {
+	return BackupSettings{
+		Enabled:       false,
+		Target:        backupTargetWebDAV,
+		WebDAV:        WebDAVSettings{UseKeyring: true},
+		S3:            S3Settings{Region: "us-east-1", UseKeyring: true},
+		UseKeyring:    true,
+		IntervalHours: 24,
+		KeepCount:     10,
+	}
+}
+
+// GetBackupSettings 获取备份设置，未配置时返回默认值
+func GetBackupSettings() (BackupSettings, error) {
+	config, err := LoadConfig()
+	if err != nil {
+		return BackupSettings{}, err
+	}
+
+	if config.Backup == nil {
+		return DefaultBackupSettings(), nil
+	}
+	settings := *config.Backup
+	if settings.Target == "" {
+		settings.Target = backupTargetWebDAV
+	}
+	return settings, nil
+}
+
+// SaveBackupSettings 保存备份设置
+func SaveBackupSettings(settings BackupSettings) error {
+	switch settings.Target {
+	case backupTargetWebDAV, "":
+		settings.Target = backupTargetWebDAV
+		if settings.Enabled && settings.WebDAV.URL == "" {
+			return fmt.Errorf("请先设置WebDAV地址")
+		}
+		if settings.WebDAV.URL != "" {
+			if _, err := parseWebDAVURL(settings.WebDAV.URL); err != nil {
+				return err
+			}
+		}
+	case backupTargetS3:
+		if err := validateS3Settings(settings.S3); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("无效的备份位置: %s", settings.Target)
+	}
+	if settings.Enabled {
+		// 未填写密码时使用钥匙串中已保存的密码
+		passphrase, _ := loadBackupPassphrase(settings)
+		if len([]rune(passphrase)) < minPassphraseLength {
+			return fmt.Errorf("备份密码至少需要%d个字符", minPassphraseLength)
+		}
+	}
+	if settings.IntervalHours <= 0 {
+		return fmt.Errorf("备份间隔必须大于0")
+	}
+	if settings.KeepCount < 0 {
+		return fmt.Errorf("保留数量不能小于0")
+	}
+
+	// 密码与密钥保存在系统钥匙串中时不写入配置文件
+	if settings.UseKeyring {
+		if err := storeKeyringSecret(backupKeyringService, backupKeyringUser, &settings.Passphrase); err != nil {
+			return err
+		}
+	}
+	if settings.WebDAV.UseKeyring && settings.WebDAV.Username != "" {
+		if err := storeKeyringSecret(webdavKeyringService, webdavKeyringUser(settings.WebDAV), &settings.WebDAV.Password); err != nil {
+			return err
+		}
+	}
+	if err := storeS3Secret(&settings.S3); err != nil {
+		return err
+	}
+
+	return UpdateConfig(func(config *Config) error {
+		config.Backup = &settings
+		return nil
+	})
+}
+
+// TestBackupConnection 测试能否访问备份位置，返回已有的快照数量
+func TestBackupConnection(settings BackupSettings) (int, error) {
+	target, err := newBackupTarget(settings)
+	if err != nil {
+		return 0, err
+	}
+	snapshots, err := target.list(context.Background())
+	if err != nil {
+		return 0, err
+	}
+	return len(snapshots), nil
+}
+
+// GetBackupSnapshots 获取远程的快照，最新的在前
+func GetBackupSnapshots(ctx context.Context) ([]BackupSnapshot, error) {
+	settings, err := GetBackupSettings()
+	if err != nil {
+		return nil, err
+	}
+	target, err := newBackupTarget(settings)
+	if err != nil {
+		return nil, err
+	}
+	return listBackupSnapshots(ctx, target)
+}
+
+// CreateBackup 立即生成加密快照并上传，progress 接收进度，可为 nil
+func CreateBackup(ctx context.Context, progress func(BackupProgress)) (*BackupResult, error) {
+	settings, err := GetBackupSettings()
+	if err != nil {
+		return nil, err
+	}
+	if settings.Passphrase, err = loadBackupPassphrase(settings); err != nil {
+		return nil, err
+	}
+	target, err := newBackupTarget(settings)
+	if err != nil {
+		return nil, err
+	}
+	return createBackup(ctx, target, settings, time.Now(), progress)
+}
+
+// RestoreBackup 下载并恢复快照，passphrase 为空时使用设置中的备份密码
+//
+// 恢复会替换全部数据；本机的同步身份与同步记录保留不变，恢复后的数据会作为本机修改同步到其他设备。
+func RestoreBackup(ctx context.Context, name, passphrase string, progress func(BackupProgress)) error {
+	settings, err := GetBackupSettings()
+	if err != nil {
+		return err
+	}
+	if passphrase == "" {
+		if passphrase, err = loadBackupPassphrase(settings); err != nil {
+			return err
+		}
+	}
+	target, err := newBackupTarget(settings)
+	if err != nil {
+		return err
+	}
+	return restoreBackup(ctx, target, name, passphrase, progress)
+}
+
+// 辅助函数：定时备份，距最新的远程快照已超过备份间隔时备份，供 Scheduler 调用
+func checkBackup(ctx context.Context) time.Duration {
+	settings, err := GetBackupSettings()
+	if err != nil {
+		log.Printf("读取备份设置失败: %v", err)
+		return time.Hour
+	}
+	interval := time.Duration(settings.IntervalHours) * time.Hour
+	if interval <= 0 {
+		interval = 24 * time.Hour
+	}
+
+	if db == nil || !settings.Enabled {
+		return interval
+	}
+	if settings.Passphrase, err = loadBackupPassphrase(settings); err != nil {
+		log.Printf("自动备份失败: %v", err)
+		return time.Hour
+	}
+	target, err := newBackupTarget(settings)
+	if err != nil {
+		log.Printf("自动备份失败: %v", err)
+		return time.Hour
+	}
+
+	// 以远程最新快照的时间判断，重启应用不会重复备份
+	now := time.Now()
+	snapshots, err := listBackupSnapshots(ctx, target)
+	if err != nil {
+		log.Printf("自动备份失败: %v", err)
+		return time.Hour
+	}
+	if len(snapshots) > 0 {
+		if latest, ok := parseBackupName(snapshots[0].Name); ok && now.Sub(latest) < interval {
+			return interval - now.Sub(latest)
+		}
+	}
+
+	_, err = createBackup(ctx, target, settings, now, func(progress BackupProgress) {
+		runtime.EventsEmit(ctx, backupProgressEvent, progress)
+	})
+	if err != nil {
+		log.Printf("自动备份失败: %v", err)
+		return time.Hour
+	}
+	return interval
+}
+
+// 辅助函数：生成加密快照，上传后按保留数量删除旧快照
+func createBackup(ctx context.Context, target backupTarget, settings BackupSettings, now time.Time, progress func(BackupProgress)) (*BackupResult, error) {
+	if db == nil {
+		return nil, errDatabaseNotReady
+	}
+	if len([]rune(settings.Passphrase)) < minPassphraseLength {
+		return nil, fmt.Errorf("请先设置备份密码")
+	}
+	name := backupNamePrefix + now.UTC().Format(backupNameLayout) + backupNameSuffix
+	report := newBackupReporter("backup", name, progress)
+
+	report(backupStageSnapshot, 0, 0)
+	archive, err := createBackupArchive(ctx)
+	if err != nil {
+		return nil, err
+	}
+	content, err := encryptBackup(archive, settings.Passphrase)
+	if err != nil {
+		return nil, err
+	}
+	if len(content) > maxBackupDownloadSize {
+		return nil, fmt.Errorf("快照超过大小限制，无法恢复")
+	}
+
+	total := int64(len(content))
+	report(backupStageUpload, 0, total)
+	err = target.upload(ctx, name, content, func(done int64) {
+		report(backupStageUpload, done, total)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &BackupResult{
+		Snapshot: BackupSnapshot{Name: name, Size: total, CreatedAt: now.UTC().Format(backupDisplayLayout)},
+		Removed:  []string{},
+	}
+	if settings.KeepCount > 0 {
+		snapshots, err := listBackupSnapshots(ctx, target)
+		if err != nil {
+			return nil, err
+		}
+		for i := settings.KeepCount; i < len(snapshots); i++ {
+			if err := target.remove(ctx, snapshots[i].Name); err != nil {
+				log.Printf("删除旧备份失败: %v", err)
+				continue
+			}
+			result.Removed = append(result.Removed, snapshots[i].Name)
+		}
+	}
+
+	report(backupStageDone, total, total)
+	return result, nil
+}
+
+// 辅助函数：下载、解密并恢复快照
+func restoreBackup(ctx context.Context, target backupTarget, name, passphrase string, progress func(BackupProgress)) error {
+	if db == nil {
+		return errDatabaseNotReady
+	}
+	if _, ok := parseBackupName(name); !ok {
+		return fmt.Errorf("无效的备份名称: %s", name)
+	}
+	report := newBackupReporter("restore", name, progress)
+
+	report(backupStageDownload, 0, 0)
+	content, err := target.download(ctx, name, func(done, total int64) {
+		report(backupStageDownload, done, total)
+	})
+	if err != nil {
+		return err
+	}
+
+	report(backupStageRestore, 0, 0)
+	archive, err := decryptBackup(content, passphrase)
+	if err != nil {
+		return err
+	}
+	plain, err := readBackupArchive(archive)
+	if err != nil {
+		return err
+	}
+	if err := restoreBackupDatabase(ctx, plain); err != nil {
+		return err
+	}
+
+	// 加密数据库立即写回磁盘，不等待定时写回
+	encryptionMu.Lock()
+	if encrypted != nil {
+		err = flushEncryptedDatabase(true)
+	}
+	encryptionMu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	report(backupStageDone, int64(len(content)), int64(len(content)))
+	return nil
+}
+
+// 辅助函数：将快照中的数据库恢复到当前数据库
+//
+// 使用 SQLite 备份接口直接写入当前连接，明文与加密数据库都适用；本机同步状态先暂存在临时表中，恢复后写回。
+func restoreBackupDatabase(ctx context.Context, plain []byte) (err error) {
+	lanRowsMu.Lock()
+	defer lanRowsMu.Unlock()
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	for _, table := range backupPreservedTables {
+		if _, err := conn.ExecContext(ctx, fmt.Sprintf(`DROP TABLE IF EXISTS temp.kept_%s`, table)); err != nil {
+			return err
+		}
+		if _, err := conn.ExecContext(ctx, fmt.Sprintf(`CREATE TEMP TABLE kept_%s AS SELECT * FROM main.%s`, table, table)); err != nil {
+			return fmt.Errorf("暂存同步状态失败: %w", err)
+		}
+	}
+	defer func() {
+		for _, table := range backupPreservedTables {
+			conn.ExecContext(ctx, fmt.Sprintf(`DROP TABLE IF EXISTS temp.kept_%s`, table))
+		}
+	}()
+
+	if err := restoreDatabase(ctx, conn, plain); err != nil {
+		return err
+	}
+
+	// 旧版本的快照可能缺少新增的表
+	if err := createTables(); err != nil {
+		return fmt.Errorf("创建表结构失败: %w", err)
+	}
+
+	// 账号以账号库为准，不恢复旧版本快照中的账号表
+	if err := dropDatabaseAccountTables(); err != nil {
+		return err
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	for _, table := range backupPreservedTables {
+		if _, err = tx.ExecContext(ctx, fmt.Sprintf(`DELETE FROM main.%s`, table)); err != nil {
+			return err
+		}
+		if _, err = tx.ExecContext(ctx, fmt.Sprintf(`INSERT INTO main.%s SELECT * FROM temp.kept_%s`, table, table)); err != nil {
+			return fmt.Errorf("恢复同步状态失败: %w", err)
+		}
+	}
+	return nil
+}
+
+// 辅助函数：打包数据库与导出数据
+func createBackupArchive(ctx context.Context) ([]byte, error) {
+	plain, err := serializeDatabase(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	data, err := GetAllAnnualData()
+	if err != nil {
+		return nil, err
+	}
+	export, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for _, file := range []struct {
+		name    string
+		content []byte
+	}{
+		{backupDatabaseFile, plain},
+		{backupExportFile, export},
+	} {
+		writer, err := archive.Create(file.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := writer.Write(file.content); err != nil {
+			return nil, err
+		}
+	}
+	if err := archive.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// 辅助函数：读取快照中的数据库
+func readBackupArchive(content []byte) ([]byte, error) {
+	archive, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		return nil, fmt.Errorf("无法识别的备份文件: %w", err)
+	}
+	file, err := archive.Open(backupDatabaseFile)
+	if err != nil {
+		return nil, fmt.Errorf("备份中没有数据库文件")
+	}
+	defer file.Close()
+
+	plain, err := io.ReadAll(file)
+	if err != nil {
+		return nil, fmt.Errorf("读取备份失败: %w", err)
+	}
+	if !bytes.HasPrefix(plain, []byte("SQLite format 3\x00")) {
+		return nil, fmt.Errorf("备份中的数据库文件已损坏")
+	}
+	return plain, nil
+}
+
+// 辅助函数：加密快照，格式与数据库加密相同（Argon2id 派生密钥，AES-256-GCM）
+func encryptBackup(plain []byte, passphrase string) ([]byte, error) {
+	params, err := newKeyParams()
+	if err != nil {
+		return nil, err
+	}
+	return encryptDatabase(plain, params, deriveKey(passphrase, params))
+}
+
+// 辅助函数：解密快照
+func decryptBackup(content []byte, passphrase string) ([]byte, error) {
+	params, err := parseKeyParams(content)
+	if err != nil {
+		return nil, fmt.Errorf("无法识别的备份文件")
+	}
+	plain, err := decryptDatabase(content, deriveKey(passphrase, params))
+	if errors.Is(err, errWrongPassphrase) {
+		return nil, fmt.Errorf("备份密码错误或文件已损坏")
+	}
+	return plain, err
+}
+
+// 辅助函数：按设置创建快照存放位置
+func newBackupTarget(settings BackupSettings) (backupTarget, error) {
+	switch settings.Target {
+	case backupTargetWebDAV, "":
+		return newWebDAVClient(settings.WebDAV)
+	case backupTargetS3:
+		return newS3Client(settings.S3)
+	default:
+		return nil, fmt.Errorf("无效的备份位置: %s", settings.Target)
+	}
+}
+
+// 辅助函数：列出快照，最新的在前
+func listBackupSnapshots(ctx context.Context, target backupTarget) ([]BackupSnapshot, error) {
+	snapshots, err := target.list(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].Name > snapshots[j].Name
+	})
+	return snapshots, nil
+}
+
+// 辅助函数：设置中没有备份密码时从系统钥匙串读取
+func loadBackupPassphrase(settings BackupSettings) (string, error) {
+	if !settings.UseKeyring {
+		return settings.Passphrase, nil
+	}
+	return loadKeyringSecret(backupKeyringService, backupKeyringUser, settings.Passphrase)
+}
+
+// 辅助函数：读取下载的快照，超过 maxBackupDownloadSize 时返回错误
+func readBackupDownload(response *http.Response, progress func(done, total int64)) ([]byte, error) {
+	total := response.ContentLength
+	if total > maxBackupDownloadSize {
+		return nil, fmt.Errorf("备份超过大小限制")
+	}
+	body := &progressReader{
+		reader: io.LimitReader(response.Body, maxBackupDownloadSize+1),
+		report: func(done int64) { progress(done, total) },
+	}
+	content, err := io.ReadAll(body)
+	if err != nil {
+		return nil, fmt.Errorf("下载备份失败: %w", err)
+	}
+	if len(content) > maxBackupDownloadSize {
+		return nil, fmt.Errorf("备份超过大小限制")
+	}
+	return content, nil
+}
+
+// 辅助函数：从快照文件名解析备份时间
+func parseBackupName(name string) (time.Time, bool) {
+	if !strings.HasPrefix(name, backupNamePrefix) || !strings.HasSuffix(name, backupNameSuffix) {
+		return time.Time{}, false
+	}
+	stamp := strings.TrimSuffix(strings.TrimPrefix(name, backupNamePrefix), backupNameSuffix)
+	created, err := time.Parse(backupNameLayout, stamp)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return created, true
+}
+
+// 辅助函数：创建进度报告函数，同一阶段内每增加 1%（大小未知时每 1MB）才报告一次
+func newBackupReporter(operation, name string, progress func(BackupProgress)) func(stage string, done, total int64) {
+	lastStage, lastPercent := "", int64(-1)
+	return func(stage string, done, total int64) {
+		if progress == nil {
+			return
+		}
+		if total < 0 {
+			total = 0
+		}
+		percent := done >> 20
+		if total > 0 {
+			percent = done * 100 / total
+		}
+		if stage == lastStage && percent == lastPercent {
+			return
+		}
+		lastStage, lastPercent = stage, percent
+		progress(BackupProgress{Operation: operation, Stage: stage, Name: name, Bytes: done, Total: total})
+	}
+}
@@ -0,0 +1,932 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// CalDAV 同步完成事件名称
+const caldavSyncedEvent = "caldavSynced"
+
+// 冲突处理规则
+const (
+	caldavConflictManual = "manual" // 标记为冲突，等待手动处理
+	caldavConflictServer = "server" // 以服务器为准
+	caldavConflictLocal  = "local"  // 以本地为准
+)
+
+// 任务同步状态
+const (
+	caldavStatusSynced   = "synced"
+	caldavStatusPending  = "pending" // 本地有未上传的修改
+	caldavStatusConflict = "conflict"
+	caldavStatusError    = "error"
+)
+
+// CalDAV 请求超时时间
+const caldavRequestTimeout = 30 * time.Second
+
+// CalDAV 响应的最大长度
+const maxCalDAVResponseSize = 32 << 20 // 32MB
+
+// 系统钥匙串中保存 CalDAV 密码的服务名称
+const caldavKeyringService = "Manifest CalDAV"
+
+// CalDAVSettings CalDAV 同步设置
+type CalDAVSettings struct {
+	Enabled          bool   `json:"enabled"`     // 是否定时自动同步
+	CalendarURL      string `json:"calendarUrl"` // 任务日历集合的完整地址，例如 http://localhost:5232/user/tasks/
+	Username         string `json:"username"`
+	Password         string `json:"password"`         // 保存在系统钥匙串时为空
+	UseKeyring       bool   `json:"useKeyring"`       // 密码保存在系统钥匙串中，不写入配置文件
+	ConflictPolicy   string `json:"conflictPolicy"`   // manual, server, local
+	DefaultDimension string `json:"defaultDimension"` // 服务器上新建的任务未注明维度时放入的维度Key
+	IntervalMinutes  int    `json:"intervalMinutes"`  // 自动同步间隔（分钟）
+}
+
+// CalDAVTaskStatus 单个任务的同步状态
+type CalDAVTaskStatus struct {
+	TaskID   string `json:"taskId"`
+	Status   string `json:"status"` // synced, pending, conflict, error
+	Href     string `json:"href"`
+	ETag     string `json:"etag"`
+	Message  string `json:"message"`
+	SyncedAt string `json:"syncedAt"`
+}
+
+// CalDAVSyncError 同步单个任务时的错误
+type CalDAVSyncError struct {
+	TaskID  string `json:"taskId"`
+	Message string `json:"message"`
+}
+
+// CalDAVSyncResult 同步结果
+type CalDAVSyncResult struct {
+	SyncedAt      string            `json:"syncedAt"`
+	Uploaded      int               `json:"uploaded"`
+	Downloaded    int               `json:"downloaded"`
+	DeletedLocal  int               `json:"deletedLocal"`
+	DeletedRemote int               `json:"deletedRemote"`
+	Conflicts     []string          `json:"conflicts"` // 冲突的任务ID
+	Errors        []CalDAVSyncError `json:"errors"`
+}
+
+// caldavState 任务上次同步时的记录
+type caldavState struct {
+	href      string
+	etag      string
+	localHash string
+	status    string
+}
+
+// caldavObject 服务器上的日历对象
+type caldavObject struct {
+	href string
+	etag string
+	todo *icalTodo
+}
+
+// caldavClient 极简 CalDAV 客户端
+type caldavClient struct {
+	calendar *url.URL
+	username string
+	password string
+	http     *http.Client
+}
+
+// WebDAV 多状态响应
+type davMultistatus struct {
+	Responses []davResponse `xml:"DAV: response"`
+}
+
+type davResponse struct {
+	Href      string        `xml:"DAV: href"`
+	Propstats []davPropstat `xml:"DAV: propstat"`
+}
+
+type davPropstat struct {
+	Status string `xml:"DAV: status"`
+	Prop   struct {
+		ETag          string `xml:"DAV: getetag"`
+		CalendarData  string `xml:"urn:ietf:params:xml:ns:caldav calendar-data"`
+		DisplayName   string `xml:"DAV: displayname"`
+		ContentLength int64  `xml:"DAV: getcontentlength"`
+		LastModified  string `xml:"DAV: getlastmodified"`
+	} `xml:"DAV: prop"`
+}
+
+// 查询所有 VTODO 的 REPORT 请求体
+const caldavTodoQuery = `<?xml version="1.0" encoding="utf-8"?>
+<c:calendar-query xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav">
+  <d:prop><d:getetag/><c:calendar-data/></d:prop>
+  <c:filter><c:comp-filter name="VCALENDAR"><c:comp-filter name="VTODO"/></c:comp-filter></c:filter>
+</c:calendar-query>`
+
+// DefaultCalDAVSettings 默认 CalDAV 同步设置
+func DefaultCalDAVSettings() CalDAVSettings {
+	return CalDAVSettings{
+		Enabled:         false,
+		UseKeyring:      true,
+		ConflictPolicy:  caldavConflictManual,
+		IntervalMinutes: 15,
+	}
+}
+
+// GetCalDAVSettings 获取 CalDAV 同步设置，未配置时返回默认值
+func GetCalDAVSettings() (CalDAVSettings, error) {
+	config, err := LoadConfig()
+	if err != nil {
+		return CalDAVSettings{}, err
+	}
+
+	if config.CalDAV == nil {
+		return DefaultCalDAVSettings(), nil
+	}
+	return *config.CalDAV, nil
+}
+
+// SaveCalDAVSettings 保存 CalDAV 同步设置，日历地址变化时清除所有任务的同步记录
+func SaveCalDAVSettings(settings CalDAVSettings) error {
+	if settings.Enabled && settings.CalendarURL == "" {
+		return fmt.Errorf("请先设置CalDAV日历地址")
+	}
+	if settings.CalendarURL != "" {
+		if _, err := parseCalendarURL(settings.CalendarURL); err != nil {
+			return err
+		}
+	}
+	switch settings.ConflictPolicy {
+	case caldavConflictManual, caldavConflictServer, caldavConflictLocal:
+	case "":
+		settings.ConflictPolicy = caldavConflictManual
+	default:
+		return fmt.Errorf("无效的冲突处理规则: %s", settings.ConflictPolicy)
+	}
+	if settings.IntervalMinutes <= 0 {
+		return fmt.Errorf("同步间隔必须大于0")
+	}
+
+	// 密码保存在系统钥匙串中时不写入配置文件
+	if settings.UseKeyring {
+		if err := storeKeyringSecret(caldavKeyringService, caldavKeyringUser(settings), &settings.Password); err != nil {
+			return err
+		}
+	}
+
+	return UpdateConfig(func(config *Config) error {
+		// 同步记录中的地址与 ETag 属于原来的日历，继续使用会把新日历中不存在的任务当作已删除
+		if config.CalDAV != nil && !sameCalendarURL(config.CalDAV.CalendarURL, settings.CalendarURL) {
+			if err := clearCalDAVStates(); err != nil {
+				return err
+			}
+		}
+		config.CalDAV = &settings
+		return nil
+	})
+}
+
+// TestCalDAVConnection 测试能否访问日历，返回日历名称
+func TestCalDAVConnection(settings CalDAVSettings) (string, error) {
+	client, err := newCalDAVClient(settings)
+	if err != nil {
+		return "", err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), caldavRequestTimeout)
+	defer cancel()
+
+	body := `<?xml version="1.0" encoding="utf-8"?><d:propfind xmlns:d="DAV:"><d:prop><d:displayname/></d:prop></d:propfind>`
+	status, err := client.multistatus(ctx, "PROPFIND", "0", body)
+	if err != nil {
+		return "", err
+	}
+	for _, response := range status.Responses {
+		for _, propstat := range response.Propstats {
+			if propstat.Prop.DisplayName != "" {
+				return propstat.Prop.DisplayName, nil
+			}
+		}
+	}
+	return client.calendar.Path, nil
+}
+
+// 辅助函数：创建 CalDAV 同步状态表
+func createCalDAVTables() error {
+	// 记录每个任务对应的服务器对象、ETag 以及上次同步时的本地内容哈希
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS task_caldav_sync (
+			task_id TEXT PRIMARY KEY,
+			href TEXT,
+			etag TEXT,
+			local_hash TEXT,
+			status TEXT,
+			message TEXT,
+			synced_at TEXT
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("创建CalDAV同步状态表失败: %w", err)
+	}
+
+	return nil
+}
+
+// GetCalDAVSyncStatuses 获取所有任务的同步状态，未同步或本地有修改的任务为 pending
+func GetCalDAVSyncStatuses() ([]CalDAVTaskStatus, error) {
+	data, err := GetAllAnnualData()
+	if err != nil {
+		return nil, err
+	}
+	locations := locateTasks(data)
+
+	rows, err := db.Query(`SELECT task_id, href, etag, local_hash, status, message, synced_at FROM task_caldav_sync`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	statuses := make(map[string]CalDAVTaskStatus)
+	hashes := make(map[string]string)
+	for rows.Next() {
+		var status CalDAVTaskStatus
+		var hash string
+		var message, syncedAt sql.NullString
+		if err := rows.Scan(&status.TaskID, &status.Href, &status.ETag, &hash, &status.Status, &message, &syncedAt); err != nil {
+			return nil, err
+		}
+		status.Message = message.String
+		status.SyncedAt = syncedAt.String
+		statuses[status.TaskID] = status
+		hashes[status.TaskID] = hash
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	result := []CalDAVTaskStatus{}
+	for id, location := range locations {
+		status, ok := statuses[id]
+		if !ok {
+			status = CalDAVTaskStatus{TaskID: id, Status: caldavStatusPending}
+		} else if status.Status == caldavStatusSynced && hashes[id] != vtodoHash(location) {
+			status.Status = caldavStatusPending
+		}
+		result = append(result, status)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].TaskID < result[j].TaskID
+	})
+	return result, nil
+}
+
+// SyncCalDAV 与 CalDAV 服务器进行一次双向同步
+func SyncCalDAV(ctx context.Context) (*CalDAVSyncResult, error) {
+	settings, err := GetCalDAVSettings()
+	if err != nil {
+		return nil, err
+	}
+	return syncCalDAV(ctx, settings, time.Now())
+}
+
+// ResolveCalDAVConflict 处理任务同步冲突，keep 为 server 时以服务器为准，为 local 时以本地为准
+func ResolveCalDAVConflict(ctx context.Context, taskID, keep string) error {
+	if keep != caldavConflictServer && keep != caldavConflictLocal {
+		return fmt.Errorf("无效的冲突处理方式: %s", keep)
+	}
+	settings, err := GetCalDAVSettings()
+	if err != nil {
+		return err
+	}
+	settings.ConflictPolicy = keep
+
+	result, err := syncCalDAVTasks(ctx, settings, time.Now(), map[string]bool{taskID: true})
+	if err != nil {
+		return err
+	}
+	for _, syncErr := range result.Errors {
+		if syncErr.TaskID == taskID {
+			return fmt.Errorf("%s", syncErr.Message)
+		}
+	}
+	return nil
+}
+
+// 辅助函数：执行一次双向同步
+func syncCalDAV(ctx context.Context, settings CalDAVSettings, now time.Time) (*CalDAVSyncResult, error) {
+	return syncCalDAVTasks(ctx, settings, now, nil)
+}
+
+// 辅助函数：同步指定任务，only 为 nil 时同步全部
+//
+// 以上次同步时记录的 ETag 与本地内容哈希判断哪一方发生了修改：
+// 只有一方修改时以修改方为准，双方都修改时按冲突处理规则处理；
+// 一方删除而另一方未修改时同步删除，另一方已修改时保留修改后的任务。
+func syncCalDAVTasks(ctx context.Context, settings CalDAVSettings, now time.Time, only map[string]bool) (*CalDAVSyncResult, error) {
+	client, err := newCalDAVClient(settings)
+	if err != nil {
+		return nil, err
+	}
+
+	remote, unparsed, remoteErrors, err := client.listTodos(ctx)
+	if err != nil {
+		return nil, err
+	}
+	data, err := GetAllAnnualData()
+	if err != nil {
+		return nil, err
+	}
+	locations := locateTasks(data)
+	states, err := getCalDAVStates()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &CalDAVSyncResult{
+		SyncedAt:  now.Format(time.RFC3339),
+		Conflicts: []string{},
+		Errors:    remoteErrors,
+	}
+	fail := func(taskID string, err error) {
+		result.Errors = append(result.Errors, CalDAVSyncError{TaskID: taskID, Message: err.Error()})
+		if saveErr := setCalDAVStatus(taskID, caldavStatusError, err.Error()); saveErr != nil {
+			log.Printf("保存CalDAV同步状态失败: %v", saveErr)
+		}
+	}
+
+	ids := make(map[string]bool)
+	for id := range locations {
+		ids[id] = true
+	}
+	for id := range states {
+		ids[id] = true
+	}
+	for id := range remote {
+		ids[id] = true
+	}
+	sorted := []string{}
+	for id := range ids {
+		if only == nil || only[id] {
+			sorted = append(sorted, id)
+		}
+	}
+	sort.Strings(sorted)
+
+	downloads := []caldavObject{}
+	deletions := []string{}
+	upload := func(location taskLocation, href, etag string) {
+		if href == "" {
+			href = client.objectHref(location.task.ID)
+		}
+		newETag, err := client.put(ctx, href, renderVTODO(location, now), etag)
+		if err == errCalDAVPrecondition {
+			result.Conflicts = append(result.Conflicts, location.task.ID)
+			if err := setCalDAVStatus(location.task.ID, caldavStatusConflict, "服务器上的任务已被修改"); err != nil {
+				log.Printf("保存CalDAV同步状态失败: %v", err)
+			}
+			return
+		}
+		if err != nil {
+			fail(location.task.ID, err)
+			return
+		}
+		if err := saveCalDAVState(location.task.ID, caldavState{href: href, etag: newETag, localHash: vtodoHash(location), status: caldavStatusSynced}, now); err != nil {
+			fail(location.task.ID, err)
+			return
+		}
+		result.Uploaded++
+	}
+
+	for _, id := range sorted {
+		location, isLocal := locations[id]
+		state, hasState := states[id]
+		object, isRemote := remote[id]
+
+		switch {
+		case isLocal && !isRemote:
+			if hasState && state.href != "" && unparsed[client.hrefKey(state.href)] {
+				// 服务器上的对象仍然存在但无法解析（已记为错误），不能当作已删除
+				continue
+			}
+			if hasState && state.etag != "" && state.localHash == vtodoHash(location) {
+				// 服务器上已删除且本地未修改
+				deletions = append(deletions, id)
+				continue
+			}
+			upload(location, state.href, "")
+		case isLocal:
+			localChanged := !hasState || state.localHash != vtodoHash(location)
+			serverChanged := !hasState || state.etag != object.etag
+
+			switch {
+			case !localChanged && !serverChanged:
+			case !serverChanged:
+				upload(location, object.href, object.etag)
+			case !localChanged:
+				downloads = append(downloads, object)
+			case vtodoHash(mergeCalDAVTodo(object.todo, &location)) == vtodoHash(location):
+				// 双方修改后内容一致
+				err := saveCalDAVState(id, caldavState{href: object.href, etag: object.etag, localHash: vtodoHash(location), status: caldavStatusSynced}, now)
+				if err != nil {
+					fail(id, err)
+				}
+			case settings.ConflictPolicy == caldavConflictServer:
+				downloads = append(downloads, object)
+			case settings.ConflictPolicy == caldavConflictLocal:
+				upload(location, object.href, object.etag)
+			default:
+				result.Conflicts = append(result.Conflicts, id)
+				if err := setCalDAVStatus(id, caldavStatusConflict, "本地与服务器均有修改"); err != nil {
+					fail(id, err)
+				}
+			}
+		case isRemote && hasState && state.etag == object.etag:
+			// 本地已删除且服务器未修改
+			if err := client.delete(ctx, object.href, object.etag); err != nil {
+				fail(id, err)
+				continue
+			}
+			if err := deleteCalDAVState(id); err != nil {
+				fail(id, err)
+				continue
+			}
+			result.DeletedRemote++
+		case isRemote:
+			downloads = append(downloads, object)
+		default:
+			// 双方均已删除
+			if err := deleteCalDAVState(id); err != nil {
+				fail(id, err)
+			}
+		}
+	}
+
+	// 将服务器上的修改合并到本地
+	if len(downloads) > 0 {
+		count, err := applyCalDAVDownloads(downloads, data, locations, settings, now, fail)
+		if err != nil {
+			return nil, err
+		}
+		result.Downloaded = count
+	}
+	if len(deletions) > 0 {
+		count, err := applyCalDAVDeletions(deletions, data, locations, fail)
+		if err != nil {
+			return nil, err
+		}
+		result.DeletedLocal = count
+	}
+
+	return result, nil
+}
+
+// 辅助函数：合并服务器上新建或修改的任务并保存同步状态
+func applyCalDAVDownloads(objects []caldavObject, data SystemData, locations map[string]taskLocation, settings CalDAVSettings, now time.Time, fail func(string, error)) (int, error) {
+	rows := []importRow{}
+	imported := []caldavObject{}
+	for i, object := range objects {
+		var existing *taskLocation
+		if location, ok := locations[object.todo.UID]; ok {
+			existing = &location
+		}
+
+		row := importRow{line: i + 1, hasTags: true}
+		messages := []string{}
+		placementFail := func(field, value, message string) {
+			messages = append(messages, fmt.Sprintf("%s: %s", field, message))
+		}
+		merged := mergeCalDAVTodo(object.todo, existing)
+		row.task = merged.task
+
+		placement := taskPlacement{
+			year:      object.todo.Extra[icalPropYear],
+			dimension: object.todo.Extra[icalPropDimension],
+			month:     object.todo.Extra[icalPropMonth],
+		}
+		if placement.dimension == "" && existing == nil {
+			placement.dimension = settings.DefaultDimension
+		}
+		resolveTaskPlacement(&row, placement, existing, now.Format("2006"), data, placementFail)
+		if len(messages) > 0 {
+			fail(object.todo.UID, fmt.Errorf("无法确定任务位置: %s", strings.Join(messages, "; ")))
+			continue
+		}
+
+		rows = append(rows, row)
+		imported = append(imported, object)
+	}
+	if len(rows) == 0 {
+		return 0, nil
+	}
+
+	_, _, years := mergeImportRows(data, rows)
+	for _, year := range years {
+		if err := SaveAnnualData(data[year]); err != nil {
+			return 0, fmt.Errorf("保存年度数据失败: %w", err)
+		}
+	}
+
+	// 按合并后的任务记录本地内容哈希
+	merged := locateTasks(data)
+	for _, object := range imported {
+		location := merged[object.todo.UID]
+		state := caldavState{href: object.href, etag: object.etag, localHash: vtodoHash(location), status: caldavStatusSynced}
+		if err := saveCalDAVState(object.todo.UID, state, now); err != nil {
+			fail(object.todo.UID, err)
+		}
+	}
+	return len(imported), nil
+}
+
+// 辅助函数：删除服务器上已删除的任务
+func applyCalDAVDeletions(ids []string, data SystemData, locations map[string]taskLocation, fail func(string, error)) (int, error) {
+	touched := make(map[string]map[string]bool)
+	for _, id := range ids {
+		year := locations[id].year
+		if touched[year] == nil {
+			touched[year] = make(map[string]bool)
+		}
+		annual := data[year]
+		removeImportedTask(&annual, id, touched[year])
+		data[year] = annual
+	}
+
+	for year, keys := range touched {
+		annual := data[year]
+		for key := range keys {
+			dimData := annual.Dimensions[key]
+			updateDimensionStats(&dimData)
+			annual.Dimensions[key] = dimData
+		}
+		updateAnnualTotalScore(&annual)
+		if err := SaveAnnualData(annual); err != nil {
+			return 0, fmt.Errorf("保存年度数据失败: %w", err)
+		}
+	}
+
+	count := 0
+	for _, id := range ids {
+		if err := DeleteTask(id); err != nil {
+			fail(id, err)
+			continue
+		}
+		if err := deleteCalDAVState(id); err != nil {
+			fail(id, err)
+			continue
+		}
+		count++
+	}
+	return count, nil
+}
+
+// 辅助函数：将 VTODO 合并到任务，VTODO 中没有的优先级沿用已有任务
+func mergeCalDAVTodo(todo *icalTodo, existing *taskLocation) taskLocation {
+	location := taskLocation{}
+	if existing != nil {
+		location = *existing
+	}
+
+	task := location.task
+	task.ID = todo.UID
+	task.Title = todo.Summary
+	task.Description = todo.Description
+	task.Status = taskStatusFromICal(todo.Status)
+	if priority := taskPriorityFromICal(todo.Priority); priority != "" {
+		task.Priority = priority
+	} else if existing == nil {
+		task.Priority = "medium"
+	}
+	task.StartDate, task.EndDate = nil, nil
+	if todo.Start != "" {
+		start := todo.Start
+		task.StartDate = &start
+	}
+	if todo.Due != "" {
+		due := todo.Due
+		task.EndDate = &due
+	}
+	task.Tags = append([]string{}, todo.Categories...)
+	task.Score = 0
+	if value, ok := todo.Extra[icalPropScore]; ok {
+		// 解析 VTODO 时已校验得分
+		task.Score, _ = strconv.ParseFloat(value, 64)
+	}
+	location.task = task
+
+	if year := todo.Extra[icalPropYear]; year != "" {
+		location.year = year
+	}
+	if key := todo.Extra[icalPropDimension]; key != "" {
+		location.dimensionKey = key
+	}
+	if month, ok := parseImportMonth(todo.Extra[icalPropMonth]); ok {
+		location.month = month - 1
+	}
+	return location
+}
+
+// 辅助函数：计算任务用于比较的内容哈希
+func vtodoHash(location taskLocation) string {
+	return contentHash(renderVTODO(location, time.Time{}))
+}
+
+// 辅助函数：获取所有任务的同步记录
+func getCalDAVStates() (map[string]caldavState, error) {
+	rows, err := db.Query(`SELECT task_id, href, etag, local_hash, status FROM task_caldav_sync`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	states := make(map[string]caldavState)
+	for rows.Next() {
+		var id string
+		var state caldavState
+		if err := rows.Scan(&id, &state.href, &state.etag, &state.localHash, &state.status); err != nil {
+			return nil, err
+		}
+		states[id] = state
+	}
+	return states, rows.Err()
+}
+
+// 辅助函数：保存任务的同步记录
+func saveCalDAVState(taskID string, state caldavState, now time.Time) error {
+	_, err := db.Exec(
+		`INSERT OR REPLACE INTO task_caldav_sync (task_id, href, etag, local_hash, status, message, synced_at) VALUES (?, ?, ?, ?, ?, '', ?)`,
+		taskID, state.href, state.etag, state.localHash, state.status, now.Format(time.RFC3339),
+	)
+	return err
+}
+
+// 辅助函数：只更新任务的同步状态，保留上次成功同步的记录
+func setCalDAVStatus(taskID, status, message string) error {
+	_, err := db.Exec(
+		`INSERT INTO task_caldav_sync (task_id, href, etag, local_hash, status, message) VALUES (?, '', '', '', ?, ?)
+		ON CONFLICT(task_id) DO UPDATE SET status = excluded.status, message = excluded.message`,
+		taskID, status, message,
+	)
+	return err
+}
+
+// 辅助函数：删除任务的同步记录
+func deleteCalDAVState(taskID string) error {
+	_, err := db.Exec(`DELETE FROM task_caldav_sync WHERE task_id = ?`, taskID)
+	return err
+}
+
+// 辅助函数：清除所有任务的同步记录，之后的同步把双方的任务都当作新任务
+func clearCalDAVStates() error {
+	if db == nil {
+		return errDatabaseNotReady
+	}
+	if _, err := db.Exec(`DELETE FROM task_caldav_sync`); err != nil {
+		return fmt.Errorf("清除CalDAV同步记录失败: %w", err)
+	}
+	return nil
+}
+
+// 辅助函数：定时同步，供 Scheduler 调用
+func checkCalDAVSync(ctx context.Context) time.Duration {
+	settings, err := GetCalDAVSettings()
+	if err != nil {
+		log.Printf("读取CalDAV设置失败: %v", err)
+		return 15 * time.Minute
+	}
+	interval := time.Duration(settings.IntervalMinutes) * time.Minute
+	if interval <= 0 {
+		interval = 15 * time.Minute
+	}
+
+	if db == nil || !settings.Enabled || settings.CalendarURL == "" {
+		return interval
+	}
+
+	result, err := syncCalDAV(ctx, settings, time.Now())
+	if err != nil {
+		log.Printf("CalDAV同步失败: %v", err)
+		return interval
+	}
+	if result.Uploaded+result.Downloaded+result.DeletedLocal+result.DeletedRemote+len(result.Conflicts)+len(result.Errors) > 0 {
+		runtime.EventsEmit(ctx, caldavSyncedEvent, result)
+	}
+
+	return interval
+}
+
+// errCalDAVPrecondition 服务器上的对象已被修改（HTTP 412）
+var errCalDAVPrecondition = fmt.Errorf("服务器上的任务已被修改")
+
+// 辅助函数：校验日历地址，并确保以 / 结尾
+func parseCalendarURL(raw string) (*url.URL, error) {
+	calendar, err := url.Parse(raw)
+	if err != nil || (calendar.Scheme != "http" && calendar.Scheme != "https") || calendar.Host == "" {
+		return nil, fmt.Errorf("无效的CalDAV日历地址: %s", raw)
+	}
+	if !strings.HasSuffix(calendar.Path, "/") {
+		calendar.Path += "/"
+	}
+	return calendar, nil
+}
+
+// 辅助函数：两个日历地址是否指向同一个日历
+func sameCalendarURL(a, b string) bool {
+	parsedA, errA := parseCalendarURL(a)
+	parsedB, errB := parseCalendarURL(b)
+	if errA != nil || errB != nil {
+		return a == b
+	}
+	return parsedA.String() == parsedB.String()
+}
+
+// 辅助函数：钥匙串中的账号名称，按用户名与日历地址区分
+func caldavKeyringUser(settings CalDAVSettings) string {
+	return settings.Username + "@" + settings.CalendarURL
+}
+
+// 辅助函数：创建 CalDAV 客户端，设置中没有密码时从系统钥匙串读取
+func newCalDAVClient(settings CalDAVSettings) (*caldavClient, error) {
+	if settings.CalendarURL == "" {
+		return nil, fmt.Errorf("请先设置CalDAV日历地址")
+	}
+	calendar, err := parseCalendarURL(settings.CalendarURL)
+	if err != nil {
+		return nil, err
+	}
+	password := settings.Password
+	if settings.UseKeyring {
+		if password, err = loadKeyringSecret(caldavKeyringService, caldavKeyringUser(settings), password); err != nil {
+			return nil, err
+		}
+	}
+	return &caldavClient{
+		calendar: calendar,
+		username: settings.Username,
+		password: password,
+		http:     &http.Client{Timeout: caldavRequestTimeout},
+	}, nil
+}
+
+// 辅助函数：新任务在服务器上的地址
+func (c *caldavClient) objectHref(taskID string) string {
+	return c.calendar.Path + url.PathEscape(taskID) + ".ics"
+}
+
+// 辅助函数：发送请求
+func (c *caldavClient) do(ctx context.Context, method, href string, headers map[string]string, body string) (*http.Response, error) {
+	target := c.calendar
+	if href != "" {
+		ref, err := url.Parse(href)
+		if err != nil {
+			return nil, err
+		}
+		target = c.calendar.ResolveReference(ref)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, method, target.String(), strings.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range headers {
+		request.Header.Set(key, value)
+	}
+	if c.username != "" || c.password != "" {
+		request.SetBasicAuth(c.username, c.password)
+	}
+
+	response, err := c.http.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("连接CalDAV服务器失败: %w", err)
+	}
+	return response, nil
+}
+
+// 辅助函数：发送 PROPFIND/REPORT 请求并解析多状态响应
+func (c *caldavClient) multistatus(ctx context.Context, method, depth, body string) (*davMultistatus, error) {
+	response, err := c.do(ctx, method, "", map[string]string{
+		"Depth":        depth,
+		"Content-Type": "application/xml; charset=utf-8",
+	}, body)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusUnauthorized {
+		return nil, fmt.Errorf("CalDAV认证失败，请检查用户名和密码")
+	}
+	if response.StatusCode != http.StatusMultiStatus {
+		return nil, fmt.Errorf("CalDAV请求失败: %s", response.Status)
+	}
+
+	content, err := io.ReadAll(io.LimitReader(response.Body, maxCalDAVResponseSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(content) > maxCalDAVResponseSize {
+		return nil, fmt.Errorf("CalDAV响应超过%dMB", maxCalDAVResponseSize>>20)
+	}
+	var status davMultistatus
+	if err := xml.Unmarshal(content, &status); err != nil {
+		return nil, fmt.Errorf("解析CalDAV响应失败: %w", err)
+	}
+	return &status, nil
+}
+
+// 辅助函数：获取日历中的所有 VTODO，按 UID 索引；无法解析的对象记为错误，并按 hrefKey 返回其地址
+func (c *caldavClient) listTodos(ctx context.Context) (map[string]caldavObject, map[string]bool, []CalDAVSyncError, error) {
+	status, err := c.multistatus(ctx, "REPORT", "1", caldavTodoQuery)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	objects := make(map[string]caldavObject)
+	unparsed := make(map[string]bool)
+	errors := []CalDAVSyncError{}
+	for _, response := range status.Responses {
+		for _, propstat := range response.Propstats {
+			if !strings.Contains(propstat.Status, " 200 ") || propstat.Prop.CalendarData == "" {
+				continue
+			}
+			todo, err := parseVTODO(propstat.Prop.CalendarData)
+			if err != nil {
+				errors = append(errors, CalDAVSyncError{Message: fmt.Sprintf("%s: %v", response.Href, err)})
+				unparsed[c.hrefKey(response.Href)] = true
+				continue
+			}
+			objects[todo.UID] = caldavObject{href: response.Href, etag: propstat.Prop.ETag, todo: todo}
+		}
+	}
+	return objects, unparsed, errors, nil
+}
+
+// 辅助函数：对象地址的规范形式（解码后的完整路径），服务器返回的地址可能是相对或编码不同的形式
+func (c *caldavClient) hrefKey(href string) string {
+	ref, err := url.Parse(href)
+	if err != nil {
+		return href
+	}
+	return c.calendar.ResolveReference(ref).Path
+}
+
+// 辅助函数：上传对象，etag 为空时只允许新建，否则只在服务器未修改时覆盖；返回新的 ETag
+func (c *caldavClient) put(ctx context.Context, href, content, etag string) (string, error) {
+	headers := map[string]string{"Content-Type": "text/calendar; charset=utf-8"}
+	if etag == "" {
+		headers["If-None-Match"] = "*"
+	} else {
+		headers["If-Match"] = etag
+	}
+
+	response, err := c.do(ctx, http.MethodPut, href, headers, content)
+	if err != nil {
+		return "", err
+	}
+	defer response.Body.Close()
+	io.Copy(io.Discard, response.Body)
+
+	switch {
+	case response.StatusCode == http.StatusPreconditionFailed:
+		return "", errCalDAVPrecondition
+	case response.StatusCode < 200 || response.StatusCode >= 300:
+		return "", fmt.Errorf("上传任务失败: %s", response.Status)
+	}
+	// 未返回 ETag 时留空，下次同步会以服务器内容为准更新记录
+	return response.Header.Get("ETag"), nil
+}
+
+// 辅助函数：删除对象，服务器上已修改时放弃删除
+func (c *caldavClient) delete(ctx context.Context, href, etag string) error {
+	headers := map[string]string{}
+	if etag != "" {
+		headers["If-Match"] = etag
+	}
+
+	response, err := c.do(ctx, http.MethodDelete, href, headers, "")
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	io.Copy(io.Discard, response.Body)
+
+	switch {
+	case response.StatusCode == http.StatusPreconditionFailed:
+		return errCalDAVPrecondition
+	case response.StatusCode == http.StatusNotFound:
+		return nil
+	case response.StatusCode < 200 || response.StatusCode >= 300:
+		return fmt.Errorf("删除任务失败: %s", response.Status)
+	}
+	return nil
+}
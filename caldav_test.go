@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/zalando/go-keyring"
+)
+
+// 辅助函数：保存一个包含单个任务的年度
+func saveTestTask(t *testing.T, year, dimension string, task Task) {
+	t.Helper()
+	monthly := make([][]Task, 12)
+	monthly[0] = []Task{task}
+	err := SaveAnnualData(AnnualData{
+		Year:             year,
+		DimensionConfigs: []DimensionConfig{{Key: dimension, Title: dimension}},
+		Dimensions: map[string]DimensionData{
+			dimension: {QuarterlyGoals: make([]string, 4), MonthlyTasks: monthly},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// 服务器返回了对象但无法解析时，不能把本地任务当作已在服务器上删除
+func TestCalDAVKeepsTaskWithUnparsableObject(t *testing.T) {
+	setupTestDB(t)
+	saveTestTask(t, "2026", "work", Task{ID: "t1", Title: "任务", Status: "not-started", Priority: "medium"})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusMultiStatus)
+		fmt.Fprint(w, `<?xml version="1.0"?><d:multistatus xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav">
+<d:response><d:href>/cal/t1.ics</d:href><d:propstat><d:status>HTTP/1.1 200 OK</d:status>
+<d:prop><d:getetag>"2"</d:getetag><c:calendar-data>BEGIN:VCALENDAR
+BROKEN</c:calendar-data></d:prop></d:propstat></d:response></d:multistatus>`)
+	}))
+	defer server.Close()
+
+	settings := CalDAVSettings{CalendarURL: server.URL + "/cal/", ConflictPolicy: caldavConflictManual}
+	data, err := GetAllAnnualData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	location := locateTasks(data)["t1"]
+	now := time.Now()
+	if err := saveCalDAVState("t1", caldavState{href: "/cal/t1.ics", etag: `"1"`, localHash: vtodoHash(location), status: caldavStatusSynced}, now); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := syncCalDAV(context.Background(), settings, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.DeletedLocal != 0 || len(result.Errors) != 1 {
+		t.Fatalf("同步结果: %+v", result)
+	}
+	if _, ok := locateTasks(mustGetAllAnnualData(t))["t1"]; !ok {
+		t.Fatal("无法解析的服务器对象导致本地任务被删除")
+	}
+}
+
+// 更换日历地址后旧日历的同步记录应被清除
+func TestSaveCalDAVSettingsClearsStatesOnURLChange(t *testing.T) {
+	setupTestDB(t)
+
+	settings := CalDAVSettings{CalendarURL: "http://localhost/a/", IntervalMinutes: 15}
+	if err := SaveCalDAVSettings(settings); err != nil {
+		t.Fatal(err)
+	}
+	if err := saveCalDAVState("t1", caldavState{href: "/a/t1.ics", etag: `"1"`, status: caldavStatusSynced}, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	// 地址相同（仅末尾斜杠不同）时保留
+	settings.CalendarURL = "http://localhost/a"
+	if err := SaveCalDAVSettings(settings); err != nil {
+		t.Fatal(err)
+	}
+	if states, _ := getCalDAVStates(); len(states) != 1 {
+		t.Fatalf("地址未变化时同步记录被清除: %v", states)
+	}
+
+	settings.CalendarURL = "http://localhost/b/"
+	if err := SaveCalDAVSettings(settings); err != nil {
+		t.Fatal(err)
+	}
+	if states, _ := getCalDAVStates(); len(states) != 0 {
+		t.Fatalf("更换地址后同步记录未清除: %v", states)
+	}
+}
+
+// 辅助函数：读取所有年度数据，失败时结束测试
+func mustGetAllAnnualData(t *testing.T) SystemData {
+	t.Helper()
+	data, err := GetAllAnnualData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+// 密码保存在系统钥匙串中，配置文件中不保留
+func TestCalDAVPasswordInKeyring(t *testing.T) {
+	setupTestDB(t)
+	keyring.MockInit()
+
+	settings := CalDAVSettings{CalendarURL: "http://localhost/a/", Username: "user", Password: "secret", UseKeyring: true, IntervalMinutes: 15}
+	if err := SaveCalDAVSettings(settings); err != nil {
+		t.Fatal(err)
+	}
+	saved, err := GetCalDAVSettings()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.Password != "" {
+		t.Fatal("密码被写入配置文件")
+	}
+	client, err := newCalDAVClient(saved)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client.password != "secret" {
+		t.Fatalf("从钥匙串读取的密码 = %q", client.password)
+	}
+}
+
+// 得分无效的 VTODO 视为无法解析，不会写入任务
+func TestParseVTODOValidatesScore(t *testing.T) {
+	for _, tc := range []struct {
+		score string
+		valid bool
+	}{
+		{"80", true},
+		{"0", true},
+		{"100", true},
+		{"abc", false},
+		{"-1", false},
+		{"101", false},
+	} {
+		content := "BEGIN:VCALENDAR\r\nBEGIN:VTODO\r\nUID:t1\r\nSUMMARY:任务\r\n" + icalPropScore + ":" + tc.score + "\r\nEND:VTODO\r\nEND:VCALENDAR\r\n"
+		_, err := parseVTODO(content)
+		if (err == nil) != tc.valid {
+			t.Errorf("得分 %q 的解析结果: %v", tc.score, err)
+		}
+	}
+}
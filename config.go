@@ -2,44 +2,78 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sync"
 
 	"github.com/google/uuid"
 )
 
-// ConfigFilePath 配置文件路径
-const ConfigFilePath = ".manifest/config.json"
+// ConfigFileName 配置文件名，保存在数据目录中
+const ConfigFileName = "config.json"
+
+// 串行化配置文件的读写，修改配置应通过 UpdateConfig 完成，避免并发的读取-修改-保存互相覆盖
+var configMu sync.Mutex
 
 // GetConfigPath 获取完整的配置文件路径
 func GetConfigPath() (string, error) {
-	// 获取用户主目录
-	homeDir, err := os.UserHomeDir()
+	appDataDir, err := GetAppDataDir()
 	if err != nil {
-		return "", fmt.Errorf("获取用户主目录失败: %w", err)
+		return "", err
 	}
 
 	// 配置文件完整路径
-	configPath := filepath.Join(homeDir, ConfigFilePath)
+	configPath := filepath.Join(appDataDir, ConfigFileName)
 	return configPath, nil
 }
 
-// GetAppDataDir 获取应用数据目录
+// GetAppDataDir 获取应用数据目录，配置、数据库与头像都保存在该目录中
 func GetAppDataDir() (string, error) {
-	// 获取用户主目录
-	homeDir, err := os.UserHomeDir()
+	info, err := GetDataDirInfo()
 	if err != nil {
-		return "", fmt.Errorf("获取用户主目录失败: %w", err)
+		return "", err
 	}
-
-	// 应用数据目录
-	appDataDir := filepath.Join(homeDir, ".manifest")
-	return appDataDir, nil
+	return info.Path, nil
 }
 
 // LoadConfig 加载配置文件
 func LoadConfig() (*Config, error) {
+	configMu.Lock()
+	defer configMu.Unlock()
+	return loadConfigFile()
+}
+
+// SaveConfig 保存配置文件，会覆盖其他调用方同时做出的修改，修改部分设置时使用 UpdateConfig
+func SaveConfig(config *Config) error {
+	configMu.Lock()
+	defer configMu.Unlock()
+	return saveConfigFile(config)
+}
+
+// errConfigUnchanged 由 UpdateConfig 的 update 返回，表示配置无需保存
+var errConfigUnchanged = errors.New("配置未修改")
+
+// UpdateConfig 在持有配置锁的情况下读取、修改并保存配置，update 返回错误时不保存（errConfigUnchanged 视为成功）
+func UpdateConfig(update func(config *Config) error) error {
+	configMu.Lock()
+	defer configMu.Unlock()
+
+	config, err := loadConfigFile()
+	if err != nil {
+		return err
+	}
+	if err := update(config); err == errConfigUnchanged {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	return saveConfigFile(config)
+}
+
+// 辅助函数：读取配置文件，调用方需持有 configMu
+func loadConfigFile() (*Config, error) {
 	// 获取配置文件路径
 	configPath, err := GetConfigPath()
 	if err != nil {
@@ -67,8 +101,8 @@ func LoadConfig() (*Config, error) {
 	return &config, nil
 }
 
-// SaveConfig 保存配置文件
-func SaveConfig(config *Config) error {
+// 辅助函数：写入配置文件，调用方需持有 configMu
+func saveConfigFile(config *Config) error {
 	// 获取配置文件路径
 	configPath, err := GetConfigPath()
 	if err != nil {
@@ -87,92 +121,15 @@ func SaveConfig(config *Config) error {
 		return fmt.Errorf("序列化配置失败: %w", err)
 	}
 
-	// 写入配置文件
-	if err := os.WriteFile(configPath, configData, 0644); err != nil {
+	// 写入配置文件（先写临时文件再替换）
+	if err := writeFileAtomic(configPath, configData); err != nil {
 		return fmt.Errorf("写入配置文件失败: %w", err)
 	}
 
 	return nil
 }
 
-// GetAccounts 获取所有账号
-func GetAccounts() ([]Account, error) {
-	config, err := LoadConfig()
-	if err != nil {
-		return nil, err
-	}
-
-	return config.Accounts, nil
-}
-
-// SaveAccount 保存/更新账号
-func SaveAccount(account Account) error {
-	config, err := LoadConfig()
-	if err != nil {
-		return err
-	}
-
-	// 检查账号是否已存在
-	for i, acc := range config.Accounts {
-		if acc.ID == account.ID {
-			// 更新现有账号
-			config.Accounts[i] = account
-			return SaveConfig(config)
-		}
-	}
-
-	// 添加新账号
-	config.Accounts = append(config.Accounts, account)
-	return SaveConfig(config)
-}
-
-// SwitchAccount 切换当前活跃账号
-func SwitchAccount(accountID string) error {
-	config, err := LoadConfig()
-	if err != nil {
-		return err
-	}
-
-	// 检查账号是否存在
-	accountExists := false
-	for _, acc := range config.Accounts {
-		if acc.ID == accountID {
-			accountExists = true
-			break
-		}
-	}
-
-	if !accountExists {
-		return fmt.Errorf("账号不存在")
-	}
-
-	// 更新最后使用的账号ID
-	config.LastUsedID = accountID
-	return SaveConfig(config)
-}
-
-// GetLastUsedAccount 获取最后使用的账号
-func GetLastUsedAccount() (*Account, error) {
-	config, err := LoadConfig()
-	if err != nil {
-		return nil, err
-	}
-
-	if config.LastUsedID == "" || len(config.Accounts) == 0 {
-		return nil, nil
-	}
-
-	// 查找最后使用的账号
-	for _, acc := range config.Accounts {
-		if acc.ID == config.LastUsedID {
-			return &acc, nil
-		}
-	}
-
-	return nil, nil
-}
-
-// CopyAvatarToAppDir 复制头像到应用目录
+// CopyAvatarToAppDir 校验头像图片并复制到应用目录，同时生成缩略图
 func CopyAvatarToAppDir(sourcePath string) (string, error) {
 	// 获取应用数据目录
 	appDataDir, err := GetAppDataDir()
@@ -181,31 +138,47 @@ func CopyAvatarToAppDir(sourcePath string) (string, error) {
 	}
 
 	// 创建avatars目录
-	avatarsDir := filepath.Join(appDataDir, "avatars")
+	avatarsDir := filepath.Join(appDataDir, avatarsDirName)
 	if err := os.MkdirAll(avatarsDir, 0755); err != nil {
 		return "", fmt.Errorf("创建avatars目录失败: %w", err)
 	}
 
-	// 生成唯一的文件名
-	extension := filepath.Ext(sourcePath)
-	newFilename := fmt.Sprintf("%s%s", uuid.New().String(), extension)
-
-	// 目标路径
-	targetPath := filepath.Join(avatarsDir, newFilename)
-
 	// 读取源文件
+	sourceInfo, err := os.Stat(sourcePath)
+	if err != nil {
+		return "", fmt.Errorf("读取源文件失败: %w", err)
+	}
+	if sourceInfo.Size() > maxAvatarBytes {
+		return "", fmt.Errorf("头像文件不能超过%dMB", maxAvatarBytes>>20)
+	}
 	sourceData, err := os.ReadFile(sourcePath)
 	if err != nil {
 		return "", fmt.Errorf("读取源文件失败: %w", err)
 	}
 
+	// 校验图片格式与尺寸，扩展名以实际格式为准
+	extension, img, err := decodeAvatar(sourceData)
+	if err != nil {
+		return "", err
+	}
+
+	// 生成唯一的文件名
+	newFilename := fmt.Sprintf("%s%s", uuid.New().String(), extension)
+	relativePath := filepath.Join(avatarsDirName, newFilename)
+
 	// 写入目标文件
+	targetPath := filepath.Join(appDataDir, relativePath)
 	if err := os.WriteFile(targetPath, sourceData, 0644); err != nil {
 		return "", fmt.Errorf("写入目标文件失败: %w", err)
 	}
 
+	// 生成缩略图
+	if err := writeAvatarThumbnail(img, filepath.Join(appDataDir, avatarThumbnailPath(relativePath))); err != nil {
+		os.Remove(targetPath)
+		return "", err
+	}
+
 	// 返回相对于appDataDir的路径
-	relativePath := filepath.Join("avatars", newFilename)
 	return relativePath, nil
 }
 
@@ -226,34 +199,3 @@ func GetAvatarAbsolutePath(relativePath string) (string, error) {
 	absolutePath := filepath.Join(appDataDir, relativePath)
 	return absolutePath, nil
 }
-
-// NewAccount 创建新账号
-func NewAccount(username, avatarPath string) (Account, error) {
-	// 如果是绝对路径，复制到应用目录
-	if filepath.IsAbs(avatarPath) {
-		var err error
-		avatarPath, err = CopyAvatarToAppDir(avatarPath)
-		if err != nil {
-			return Account{}, err
-		}
-	}
-
-	// 创建新账号
-	account := Account{
-		ID:         uuid.New().String(),
-		Username:   username,
-		AvatarPath: avatarPath,
-	}
-
-	// 保存账号
-	if err := SaveAccount(account); err != nil {
-		return Account{}, err
-	}
-
-	// 设置为当前使用的账号
-	if err := SwitchAccount(account.ID); err != nil {
-		return Account{}, err
-	}
-
-	return account, nil
-}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// 并发修改不同设置时不应互相覆盖
+func TestUpdateConfigConcurrent(t *testing.T) {
+	setupTestDataDir(t)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			if err := SkipUpdateVersion("v9.9.9"); err != nil {
+				t.Error(err)
+			}
+		}()
+		go func(i int) {
+			defer wg.Done()
+			if err := SaveReminderSettings(ReminderSettings{Enabled: true, LeadDays: []int{i % 3}, CheckIntervalMinutes: 5}); err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+	if err := saveUpdateCheckedAt(time.Unix(0, 0)); err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Update == nil || config.Update.SkippedVersion != "v9.9.9" || config.Update.LastCheckedAt == "" {
+		t.Fatalf("更新设置丢失: %+v", config.Update)
+	}
+	if config.Reminder == nil || !config.Reminder.Enabled {
+		t.Fatalf("提醒设置丢失: %+v", config.Reminder)
+	}
+}
+
+func TestUpdateConfigUnchanged(t *testing.T) {
+	setupTestDataDir(t)
+
+	if err := UpdateConfig(func(config *Config) error { return errConfigUnchanged }); err != nil {
+		t.Fatalf("未修改时应返回 nil: %v", err)
+	}
+	if err := UpdateConfig(func(config *Config) error { return fmt.Errorf("失败") }); err == nil {
+		t.Fatal("update 返回的错误应传给调用方")
+	}
+}
@@ -6,34 +6,81 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
 	_ "modernc.org/sqlite"
 )
 
 var db *sql.DB
 
-// InitDatabase 初始化数据库连接并创建表结构
+// currentDBFile 当前账号的数据库文件，相对数据目录、以 / 分隔
+var currentDBFile = legacyDatabaseFile
+
+// InitDatabase 打开账号库与最后使用的账号的数据库，数据库已加密时返回 errDatabaseLocked，需调用 UnlockDatabase 解锁
 func InitDatabase() error {
-	// 获取用户配置目录
-	configDir, err := os.UserConfigDir()
+	appDataDir, err := GetAppDataDir()
 	if err != nil {
-		return fmt.Errorf("获取用户配置目录失败: %w", err)
+		return err
+	}
+	if err := migrateLegacyDatabase(appDataDir); err != nil {
+		return err
+	}
+
+	// 打开账号库
+	if accountsDB == nil {
+		if err := openAccountRegistry(appDataDir); err != nil {
+			return err
+		}
 	}
 
-	// 创建应用配置目录
-	appConfigDir := filepath.Join(configDir, "PerformanceWails")
-	if err := os.MkdirAll(appConfigDir, 0755); err != nil {
-		return fmt.Errorf("创建应用配置目录失败: %w", err)
+	// 打开最后使用的账号的数据库
+	lastUsedID, err := getLastUsedAccountID(accountsDB)
+	if err != nil {
+		return fmt.Errorf("读取当前账号失败: %w", err)
 	}
+	if lastUsedID != "" {
+		if _, err := findAccount(lastUsedID); err != nil {
+			// 账号已不存在
+			lastUsedID = ""
+		}
+	}
+	return useAccountDatabase(lastUsedID)
+}
+
+// 辅助函数：关闭当前的数据库并打开 dbFile，已打开时不做处理；已加密时返回 errDatabaseLocked
+func switchDatabase(dbFile string) error {
+	if dbFile == currentDBFile && db != nil {
+		return nil
+	}
+	if err := closeAccountDatabase(); err != nil {
+		return fmt.Errorf("关闭数据库失败: %w", err)
+	}
+	currentDBFile = dbFile
 
 	// 数据库文件路径
-	dbPath := filepath.Join(appConfigDir, "performance.db")
+	dbPath, err := databasePath()
+	if err != nil {
+		return err
+	}
+	return openDatabase(dbPath)
+}
+
+// 辅助函数：打开数据库并创建表结构，已加密时返回 errDatabaseLocked
+func openDatabase(dbPath string) error {
+	// 已加密的数据库需要密码解锁
+	if _, err := os.Stat(dbPath + encryptedDBSuffix); err == nil {
+		return errDatabaseLocked
+	}
+	if err := os.MkdirAll(filepath.Dir(dbPath), 0755); err != nil {
+		return fmt.Errorf("创建数据目录失败: %w", err)
+	}
 
 	// 连接数据库
-	db, err = sql.Open("sqlite", dbPath)
+	database, err := sql.Open("sqlite", dbPath)
 	if err != nil {
 		return fmt.Errorf("打开数据库失败: %w", err)
 	}
+	db = database
 
 	// 测试连接
 	if err = db.Ping(); err != nil {
@@ -45,9 +92,27 @@ func InitDatabase() error {
 		return fmt.Errorf("创建表结构失败: %w", err)
 	}
 
+	// 迁移旧版本保存在数据库中的账号
+	if err = migrateDatabaseAccounts(); err != nil {
+		return fmt.Errorf("迁移账号失败: %w", err)
+	}
+
 	return nil
 }
 
+// 辅助函数：获取当前账号的数据库文件路径，旧版本保存在系统配置目录中的数据库会先移动到数据目录
+func databasePath() (string, error) {
+	appDataDir, err := GetAppDataDir()
+	if err != nil {
+		return "", err
+	}
+	if err := migrateLegacyDatabase(appDataDir); err != nil {
+		return "", err
+	}
+
+	return filepath.Join(appDataDir, filepath.FromSlash(currentDBFile)), nil
+}
+
 // createTables 创建所有必要的表
 func createTables() error {
 	// 创建年度数据表
@@ -149,38 +214,74 @@ func createTables() error {
 		return fmt.Errorf("创建任务表失败: %w", err)
 	}
 
-	// 创建账号表
-	_, err = db.Exec(`
-		CREATE TABLE IF NOT EXISTS accounts (
-			id TEXT PRIMARY KEY,
-			username TEXT,
-			avatar_path TEXT
-		)
-	`)
+	// 创建任务查询所需的索引
+	_, err = db.Exec(`CREATE INDEX IF NOT EXISTS idx_monthly_tasks_task_id ON monthly_tasks(task_id)`)
 	if err != nil {
-		return fmt.Errorf("创建账号表失败: %w", err)
+		return fmt.Errorf("创建月度任务索引失败: %w", err)
 	}
 
-	// 创建配置表
-	_, err = db.Exec(`
-		CREATE TABLE IF NOT EXISTS config (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			last_used_id TEXT
-		)
-	`)
-	if err != nil {
-		return fmt.Errorf("创建配置表失败: %w", err)
+	// 创建标签相关表
+	if err = createTagTables(); err != nil {
+		return err
+	}
+
+	// 创建提醒相关表
+	if err = createReminderTables(); err != nil {
+		return err
+	}
+
+	// 创建摘要所需的历史记录表
+	if err = createDigestTables(); err != nil {
+		return err
+	}
+
+	// 创建Markdown同步状态表
+	if err = createMarkdownSyncTables(); err != nil {
+		return err
+	}
+
+	// 创建CalDAV同步状态表
+	if err = createCalDAVTables(); err != nil {
+		return err
+	}
+
+	// 创建工作区模板表
+	if err = createTemplateTables(); err != nil {
+		return err
+	}
+
+	// 创建局域网同步表
+	if err = createLANSyncTables(); err != nil {
+		return err
+	}
+
+	// 创建共享目录同步状态表
+	if err = createFolderSyncTables(); err != nil {
+		return err
 	}
 
 	return nil
 }
 
-// CloseDatabase 关闭数据库连接
+// CloseDatabase 关闭数据库与账号库连接，加密数据库会先写回磁盘
 func CloseDatabase() error {
-	if db != nil {
-		return db.Close()
+	if err := closeAccountDatabase(); err != nil {
+		return err
 	}
-	return nil
+	return closeAccountRegistry()
+}
+
+// 辅助函数：关闭当前账号的数据库，加密数据库会先写回磁盘
+func closeAccountDatabase() error {
+	if err := closeEncryptedDatabase(); err != nil {
+		return err
+	}
+	if db == nil {
+		return nil
+	}
+	err := db.Close()
+	db = nil
+	return err
 }
 
 // GetAllAnnualData 获取所有年度数据
@@ -354,6 +455,24 @@ func DeleteAnnualData(year string) error {
 		return err
 	}
 
+	// 删除相关的得分快照
+	_, err = tx.Exec(`DELETE FROM score_snapshots WHERE year = ?`, year)
+	if err != nil {
+		return err
+	}
+
+	// 删除相关的Markdown同步状态
+	_, err = tx.Exec(`DELETE FROM markdown_sync_state WHERE year = ?`, year)
+	if err != nil {
+		return err
+	}
+
+	// 删除年度与模板的关联
+	_, err = tx.Exec(`DELETE FROM year_templates WHERE year = ?`, year)
+	if err != nil {
+		return err
+	}
+
 	// 删除相关的维度配置
 	_, err = tx.Exec(`DELETE FROM dimension_configs WHERE year = ?`, year)
 	if err != nil {
@@ -573,6 +692,21 @@ func getTasksByIDs(ids []string) (map[string]Task, error) {
 
 		taskMap[task.ID] = task
 	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// 获取任务标签
+	tagMap, err := getTagNamesByTaskIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	for id, tags := range tagMap {
+		if task, ok := taskMap[id]; ok {
+			task.Tags = tags
+			taskMap[id] = task
+		}
+	}
 
 	return taskMap, nil
 }
@@ -593,6 +727,11 @@ func saveDimensionData(tx *sql.Tx, year, dimensionKey string, dimData DimensionD
 		return err
 	}
 
+	// 记录当天的得分快照
+	if err = recordScoreSnapshot(tx, year, dimensionKey, dimData.TotalScore, time.Now()); err != nil {
+		return err
+	}
+
 	// 保存季度目标
 	for quarter, goal := range dimData.QuarterlyGoals {
 		_, err = tx.Exec(
@@ -625,6 +764,11 @@ func saveDimensionData(tx *sql.Tx, year, dimensionKey string, dimData DimensionD
 				endDate = sql.NullString{String: *task.EndDate, Valid: true}
 			}
 
+			// 记录任务状态变化
+			if err = logTaskStatusChange(tx, task, time.Now()); err != nil {
+				return err
+			}
+
 			_, err = tx.Exec(
 				`INSERT OR REPLACE INTO tasks (id, title, description, status, score, priority, start_date, end_date) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
 				task.ID, task.Title, task.Description, task.Status, task.Score, task.Priority, startDate, endDate,
@@ -633,6 +777,13 @@ func saveDimensionData(tx *sql.Tx, year, dimensionKey string, dimData DimensionD
 				return err
 			}
 
+			// 保存任务标签
+			if task.Tags != nil {
+				if err = saveTaskTags(tx, task.ID, task.Tags); err != nil {
+					return err
+				}
+			}
+
 			// 保存月度任务关联
 			_, err = tx.Exec(
 				`INSERT OR REPLACE INTO monthly_tasks (year, dimension_key, month, task_id) VALUES (?, ?, ?, ?)`,
@@ -657,11 +808,24 @@ func AddTask(task Task) error {
 		endDate = sql.NullString{String: *task.EndDate, Valid: true}
 	}
 
+	// 记录任务状态变化
+	if err := logTaskStatusChange(db, task, time.Now()); err != nil {
+		return err
+	}
+
 	_, err := db.Exec(
 		`INSERT OR REPLACE INTO tasks (id, title, description, status, score, priority, start_date, end_date) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
 		task.ID, task.Title, task.Description, task.Status, task.Score, task.Priority, startDate, endDate,
 	)
-	return err
+	if err != nil {
+		return err
+	}
+
+	// 保存任务标签
+	if task.Tags != nil {
+		return SetTaskTags(task.ID, task.Tags)
+	}
+	return nil
 }
 
 // UpdateTask 更新任务
@@ -690,6 +854,24 @@ func DeleteTask(taskID string) error {
 		return err
 	}
 
+	// 删除任务标签关联
+	_, err = tx.Exec(`DELETE FROM task_tags WHERE task_id = ?`, taskID)
+	if err != nil {
+		return err
+	}
+
+	// 删除任务提醒记录
+	_, err = tx.Exec(`DELETE FROM task_reminders WHERE task_id = ?`, taskID)
+	if err != nil {
+		return err
+	}
+
+	// 删除任务状态变更记录
+	_, err = tx.Exec(`DELETE FROM task_status_log WHERE task_id = ?`, taskID)
+	if err != nil {
+		return err
+	}
+
 	// 删除任务
 	_, err = tx.Exec(`DELETE FROM tasks WHERE id = ?`, taskID)
 	if err != nil {
@@ -745,12 +927,50 @@ func ResetAllData() error {
 		return err
 	}
 
-	_, err = tx.Exec(`DELETE FROM accounts`)
+	_, err = tx.Exec(`DELETE FROM task_tags`)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`DELETE FROM task_reminders`)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`DELETE FROM task_status_log`)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`DELETE FROM score_snapshots`)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`DELETE FROM markdown_sync_state`)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`DELETE FROM task_caldav_sync`)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`DELETE FROM year_templates`)
 	if err != nil {
 		return err
 	}
 
-	_, err = tx.Exec(`DELETE FROM config`)
+	// 清空后重新开始记录修改，已配对设备需要重新获取全部数据
+	if err = resetLANSyncRows(tx); err != nil {
+		return err
+	}
+	if err = resetFolderSyncLogs(tx); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`DELETE FROM tags`)
 	if err != nil {
 		return err
 	}
@@ -780,8 +1000,9 @@ func ImportData(data SystemData) error {
 
 	// 导入年度数据
 	for year, annualData := range data {
-		// 保存年度基本信息
-		settingsJSON, err := json.Marshal(annualData.Settings)
+		// 保存年度基本信息，err 不能重新声明，否则出错时延迟函数仍会提交事务
+		var settingsJSON []byte
+		settingsJSON, err = json.Marshal(annualData.Settings)
 		if err != nil {
 			return err
 		}
@@ -797,7 +1018,7 @@ func ImportData(data SystemData) error {
 		// 保存维度配置
 		for _, dimConfig := range annualData.DimensionConfigs {
 			_, err = tx.Exec(
-				`INSERT INTO dimension_configs (year, dimension_key, title, icon, color, is_default) VALUES (?, ?, ?, ?, ?, ?)`,
+				`INSERT INTO dimension_configs (year, key, title, icon, color, is_default) VALUES (?, ?, ?, ?, ?, ?)`,
 				year, dimConfig.Key, dimConfig.Title, dimConfig.Icon, dimConfig.Color, dimConfig.IsDefault,
 			)
 			if err != nil {
@@ -849,7 +1070,50 @@ func resetAllData(tx *sql.Tx) error {
 		return err
 	}
 
+	_, err = tx.Exec(`DELETE FROM task_tags`)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`DELETE FROM task_reminders`)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`DELETE FROM task_status_log`)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`DELETE FROM score_snapshots`)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`DELETE FROM markdown_sync_state`)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`DELETE FROM task_caldav_sync`)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`DELETE FROM year_templates`)
+	if err != nil {
+		return err
+	}
+
+	// 清空后重新开始记录修改，已配对设备需要重新获取全部数据
+	if err = resetLANSyncRows(tx); err != nil {
+		return err
+	}
+	if err = resetFolderSyncLogs(tx); err != nil {
+		return err
+	}
+
 	return nil
 }
 
-// 账号相关数据库操作已在 config.go 中实现，这里只保留表结构定义
+// 账号相关数据库操作在 account.go 中实现，这里只保留表结构定义
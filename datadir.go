@@ -0,0 +1,380 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// 数据目录的来源
+const (
+	dataDirDefault   = "default"   // ~/.manifest
+	dataDirFlag      = "flag"      // 命令行 --data-dir
+	dataDirEnv       = "env"       // 环境变量 MANIFEST_DATA_DIR
+	dataDirPortable  = "portable"  // 可执行文件旁的 data 目录
+	dataDirRelocated = "relocated" // 通过 RelocateDataDir 迁移后的目录
+)
+
+const (
+	dataDirEnvName      = "MANIFEST_DATA_DIR"
+	dataDirFlagName     = "--data-dir"
+	portableFlagName    = "--portable"
+	portableMarkerFile  = "portable"      // 可执行文件旁存在该文件时使用便携模式
+	portableDataDirName = "data"          // 便携模式下的数据目录名
+	dataDirPointerFile  = "location.json" // 默认目录中记录迁移后位置的文件
+	defaultDataDirName  = ".manifest"
+)
+
+// DataDirInfo 数据目录信息
+type DataDirInfo struct {
+	Path        string `json:"path"`
+	Source      string `json:"source"`      // default, flag, env, portable, relocated
+	Relocatable bool   `json:"relocatable"` // 由命令行、环境变量或便携模式指定的目录不能迁移
+}
+
+// dataDirPointer 默认目录中的位置记录
+type dataDirPointer struct {
+	Path string `json:"path"`
+}
+
+var (
+	dataDirMu  sync.Mutex
+	dataDir    *DataDirInfo
+	legacyOnce sync.Once
+	legacyErr  error // 旧数据库迁移失败的原因，之后的调用都返回该错误，不会打开新的空数据库
+)
+
+// GetDataDirInfo 获取当前使用的数据目录
+func GetDataDirInfo() (DataDirInfo, error) {
+	dataDirMu.Lock()
+	defer dataDirMu.Unlock()
+
+	if dataDir == nil {
+		info, err := resolveDataDir(os.Args[1:])
+		if err != nil {
+			return DataDirInfo{}, err
+		}
+		if err := os.MkdirAll(info.Path, 0755); err != nil {
+			return DataDirInfo{}, fmt.Errorf("创建数据目录失败: %w", err)
+		}
+		dataDir = &info
+	}
+	return *dataDir, nil
+}
+
+// RelocateDataDir 将所有数据迁移到新目录，之后启动时自动使用新目录
+//
+// 先关闭数据库并完整复制所有文件，全部成功后才记录新位置并删除原文件；
+// 复制失败时删除已复制的文件，继续使用原目录。数据库已加密时迁移后需要重新解锁。
+func RelocateDataDir(target string) (err error) {
+	info, err := GetDataDirInfo()
+	if err != nil {
+		return err
+	}
+	if !info.Relocatable {
+		return fmt.Errorf("数据目录由命令行、环境变量或便携模式指定，无法迁移")
+	}
+
+	target, err = filepath.Abs(strings.TrimSpace(target))
+	if err != nil {
+		return fmt.Errorf("无效的目标目录: %w", err)
+	}
+	if err := checkRelocationTarget(info.Path, target); err != nil {
+		return err
+	}
+
+	if err := CloseDatabase(); err != nil {
+		return fmt.Errorf("关闭数据库失败: %w", err)
+	}
+	db = nil
+	defer func() {
+		// 无论迁移是否成功都重新打开数据库
+		if openErr := InitDatabase(); openErr != nil && !errors.Is(openErr, errDatabaseLocked) && err == nil {
+			err = openErr
+		}
+	}()
+
+	copied, err := copyDataDir(info.Path, target)
+	if err != nil {
+		for i := len(copied) - 1; i >= 0; i-- {
+			os.Remove(copied[i])
+		}
+		return err
+	}
+
+	// 记录新位置
+	defaultDir, err := defaultDataDir()
+	if err != nil {
+		return err
+	}
+	if err := writeDataDirPointer(defaultDir, target); err != nil {
+		return err
+	}
+
+	dataDirMu.Lock()
+	dataDir = &DataDirInfo{Path: target, Source: dataDirRelocated, Relocatable: true}
+	dataDirMu.Unlock()
+
+	// 删除原目录中的文件（默认目录中的位置记录除外）
+	return removeDataDir(info.Path, filepath.Join(defaultDir, dataDirPointerFile))
+}
+
+// 辅助函数：按命令行参数、环境变量、便携模式标记文件、迁移记录、默认目录的顺序确定数据目录
+func resolveDataDir(args []string) (DataDirInfo, error) {
+	portable := false
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == dataDirFlagName && i+1 < len(args):
+			return absDataDir(args[i+1], dataDirFlag)
+		case strings.HasPrefix(arg, dataDirFlagName+"="):
+			return absDataDir(strings.TrimPrefix(arg, dataDirFlagName+"="), dataDirFlag)
+		case arg == portableFlagName:
+			portable = true
+		}
+	}
+
+	if !portable {
+		if dir := os.Getenv(dataDirEnvName); dir != "" {
+			return absDataDir(dir, dataDirEnv)
+		}
+	}
+
+	executable, err := os.Executable()
+	if err == nil {
+		executableDir := filepath.Dir(executable)
+		if _, err := os.Stat(filepath.Join(executableDir, portableMarkerFile)); err == nil {
+			portable = true
+		}
+		if portable {
+			return DataDirInfo{Path: filepath.Join(executableDir, portableDataDirName), Source: dataDirPortable}, nil
+		}
+	}
+
+	defaultDir, err := defaultDataDir()
+	if err != nil {
+		return DataDirInfo{}, err
+	}
+	if pointer, err := readDataDirPointer(defaultDir); err != nil {
+		return DataDirInfo{}, err
+	} else if pointer != "" {
+		return DataDirInfo{Path: pointer, Source: dataDirRelocated, Relocatable: true}, nil
+	}
+	return DataDirInfo{Path: defaultDir, Source: dataDirDefault, Relocatable: true}, nil
+}
+
+// 辅助函数：转换为绝对路径
+func absDataDir(dir, source string) (DataDirInfo, error) {
+	path, err := filepath.Abs(dir)
+	if err != nil {
+		return DataDirInfo{}, fmt.Errorf("无效的数据目录: %w", err)
+	}
+	return DataDirInfo{Path: path, Source: source}, nil
+}
+
+// 辅助函数：默认数据目录 ~/.manifest
+func defaultDataDir() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("获取用户主目录失败: %w", err)
+	}
+	return filepath.Join(homeDir, defaultDataDirName), nil
+}
+
+// 辅助函数：读取默认目录中的位置记录，没有记录时返回空
+func readDataDirPointer(defaultDir string) (string, error) {
+	content, err := os.ReadFile(filepath.Join(defaultDir, dataDirPointerFile))
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("读取数据目录位置失败: %w", err)
+	}
+
+	var pointer dataDirPointer
+	if err := json.Unmarshal(content, &pointer); err != nil {
+		return "", fmt.Errorf("解析数据目录位置失败: %w", err)
+	}
+	return pointer.Path, nil
+}
+
+// 辅助函数：写入位置记录，迁回默认目录时删除记录
+func writeDataDirPointer(defaultDir, target string) error {
+	path := filepath.Join(defaultDir, dataDirPointerFile)
+	if filepath.Clean(target) == filepath.Clean(defaultDir) {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("删除数据目录位置失败: %w", err)
+		}
+		return nil
+	}
+
+	content, err := json.MarshalIndent(dataDirPointer{Path: target}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(defaultDir, 0755); err != nil {
+		return fmt.Errorf("创建配置目录失败: %w", err)
+	}
+	if err := writeFileAtomic(path, content); err != nil {
+		return fmt.Errorf("写入数据目录位置失败: %w", err)
+	}
+	return nil
+}
+
+// 辅助函数：目标目录必须为空（或不存在），且与当前目录互不包含
+func checkRelocationTarget(current, target string) error {
+	current, target = filepath.Clean(current), filepath.Clean(target)
+	if target == current {
+		return fmt.Errorf("目标目录与当前数据目录相同")
+	}
+	if isSubPath(current, target) || isSubPath(target, current) {
+		return fmt.Errorf("目标目录不能位于当前数据目录内，也不能包含当前数据目录")
+	}
+
+	entries, err := os.ReadDir(target)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("读取目标目录失败: %w", err)
+	}
+	for _, entry := range entries {
+		// 迁回默认目录时其中只有位置记录
+		if entry.Name() != dataDirPointerFile {
+			return fmt.Errorf("目标目录不为空")
+		}
+	}
+	return nil
+}
+
+// 辅助函数：path 是否位于 dir 内
+func isSubPath(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != "." && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// 辅助函数：复制目录中的所有文件（位置记录除外），返回已复制的文件与目录，便于失败时清理
+func copyDataDir(source, target string) ([]string, error) {
+	copied := []string{}
+	err := filepath.WalkDir(source, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(source, path)
+		if err != nil {
+			return err
+		}
+		if rel == dataDirPointerFile {
+			return nil
+		}
+
+		destination := filepath.Join(target, rel)
+		if entry.IsDir() {
+			if _, err := os.Stat(destination); os.IsNotExist(err) {
+				if err := os.MkdirAll(destination, 0755); err != nil {
+					return err
+				}
+				copied = append(copied, destination)
+			}
+			return nil
+		}
+		if err := copyDataFile(path, destination); err != nil {
+			return err
+		}
+		copied = append(copied, destination)
+		return nil
+	})
+	if err != nil {
+		return copied, fmt.Errorf("复制数据失败: %w", err)
+	}
+	return copied, nil
+}
+
+// 辅助函数：复制单个文件并校验大小
+func copyDataFile(source, target string) error {
+	in, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+	out, err := os.OpenFile(target, os.O_CREATE|os.O_EXCL|os.O_WRONLY, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	written, err := io.Copy(out, in)
+	if err == nil {
+		err = out.Sync()
+	}
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil && written != info.Size() {
+		err = fmt.Errorf("%s 复制不完整", filepath.Base(source))
+	}
+	return err
+}
+
+// 辅助函数：删除原数据目录中的文件，keep 指定的文件及其所在目录保留
+func removeDataDir(dir, keep string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("清理原数据目录失败: %w", err)
+	}
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		if path == keep {
+			continue
+		}
+		if err := os.RemoveAll(path); err != nil {
+			return fmt.Errorf("清理原数据目录失败: %w", err)
+		}
+	}
+
+	// 非默认目录清空后一并删除
+	if _, err := os.Stat(keep); filepath.Dir(keep) != dir || os.IsNotExist(err) {
+		os.Remove(dir)
+	}
+	return nil
+}
+
+// 辅助函数：将旧版本保存在系统配置目录（PerformanceWails）中的数据库移动到数据目录，只执行一次，失败后每次调用都返回该错误
+func migrateLegacyDatabase(dataDir string) error {
+	legacyOnce.Do(func() {
+		configDir, err := os.UserConfigDir()
+		if err != nil {
+			return
+		}
+		legacyDir := filepath.Join(configDir, "PerformanceWails")
+
+		for _, name := range []string{"performance.db", "performance.db" + encryptedDBSuffix} {
+			source, target := filepath.Join(legacyDir, name), filepath.Join(dataDir, name)
+			if _, err := os.Stat(source); err != nil {
+				continue
+			}
+			if _, err := os.Stat(target); err == nil {
+				// 数据目录中已有数据库，保留旧文件不做处理
+				continue
+			}
+			if err := copyDataFile(source, target); err != nil {
+				os.Remove(target)
+				legacyErr = fmt.Errorf("迁移旧数据库失败: %w", err)
+				return
+			}
+			os.Remove(source)
+			// 旧目录为空时一并删除
+			os.Remove(legacyDir)
+		}
+	})
+	return legacyErr
+}
@@ -0,0 +1,702 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	htmltemplate "html/template"
+	"log"
+	"os"
+	"path/filepath"
+	"text/template"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// 摘要生成事件名称
+const digestGeneratedEvent = "digestGenerated"
+
+// 摘要类型
+const (
+	digestKindDaily  = "daily"
+	digestKindWeekly = "weekly"
+)
+
+// 摘要输出格式
+const (
+	digestFormatMarkdown = "markdown"
+	digestFormatHTML     = "html"
+)
+
+// DigestSettings 每日/每周摘要设置
+type DigestSettings struct {
+	Enabled      bool     `json:"enabled"`      // 是否按计划写入文件
+	OutputDir    string   `json:"outputDir"`    // 输出目录
+	Daily        bool     `json:"daily"`        // 是否生成每日摘要
+	Weekly       bool     `json:"weekly"`       // 是否生成每周摘要
+	WeeklyDay    int      `json:"weeklyDay"`    // 每周摘要生成日，0 表示周日
+	Hour         int      `json:"hour"`         // 每天几点之后生成
+	Formats      []string `json:"formats"`      // markdown, html
+	UpcomingDays int      `json:"upcomingDays"` // 即将到期的统计天数
+}
+
+// DigestTask 摘要中的任务
+type DigestTask struct {
+	Task           Task   `json:"task"`
+	Year           string `json:"year"`
+	DimensionKey   string `json:"dimensionKey"`
+	DimensionTitle string `json:"dimensionTitle"`
+	Month          int    `json:"month"`
+}
+
+// DimensionScoreChange 维度得分变化
+type DimensionScoreChange struct {
+	Year           string  `json:"year"`
+	DimensionKey   string  `json:"dimensionKey"`
+	DimensionTitle string  `json:"dimensionTitle"`
+	PreviousScore  float64 `json:"previousScore"`
+	CurrentScore   float64 `json:"currentScore"`
+	Delta          float64 `json:"delta"`
+}
+
+// Digest 每日/每周摘要
+type Digest struct {
+	Kind              string                 `json:"kind"` // daily, weekly
+	From              string                 `json:"from"`
+	To                string                 `json:"to"`
+	GeneratedAt       string                 `json:"generatedAt"`
+	CompletedTasks    []DigestTask           `json:"completedTasks"`
+	NewlyOverdue      []DigestTask           `json:"newlyOverdue"`
+	UpcomingDeadlines []DigestTask           `json:"upcomingDeadlines"`
+	ScoreChanges      []DimensionScoreChange `json:"scoreChanges"`
+	Markdown          string                 `json:"markdown"`
+	HTML              string                 `json:"html"`
+}
+
+// DefaultDigestSettings 默认摘要设置
+func DefaultDigestSettings() DigestSettings {
+	return DigestSettings{
+		Enabled:      false,
+		Daily:        true,
+		Weekly:       true,
+		WeeklyDay:    int(time.Sunday),
+		Hour:         20,
+		Formats:      []string{digestFormatMarkdown, digestFormatHTML},
+		UpcomingDays: 7,
+	}
+}
+
+// GetDigestSettings 获取摘要设置，未配置时返回默认值
+func GetDigestSettings() (DigestSettings, error) {
+	config, err := LoadConfig()
+	if err != nil {
+		return DigestSettings{}, err
+	}
+
+	if config.Digest == nil {
+		return DefaultDigestSettings(), nil
+	}
+	return *config.Digest, nil
+}
+
+// SaveDigestSettings 保存摘要设置
+func SaveDigestSettings(settings DigestSettings) error {
+	if settings.Enabled && settings.OutputDir == "" {
+		return fmt.Errorf("请先设置摘要输出目录")
+	}
+	if settings.WeeklyDay < 0 || settings.WeeklyDay > 6 {
+		return fmt.Errorf("无效的每周生成日: %d", settings.WeeklyDay)
+	}
+	if settings.Hour < 0 || settings.Hour > 23 {
+		return fmt.Errorf("无效的生成时间: %d", settings.Hour)
+	}
+	for _, format := range settings.Formats {
+		if format != digestFormatMarkdown && format != digestFormatHTML {
+			return fmt.Errorf("不支持的摘要格式: %s", format)
+		}
+	}
+	if settings.UpcomingDays <= 0 {
+		settings.UpcomingDays = DefaultDigestSettings().UpcomingDays
+	}
+
+	return UpdateConfig(func(config *Config) error {
+		config.Digest = &settings
+		return nil
+	})
+}
+
+// 辅助函数：创建摘要所需的历史记录表
+func createDigestTables() error {
+	// 创建维度得分快照表，每个维度每天保留最后一次的得分
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS score_snapshots (
+			date TEXT,
+			year TEXT,
+			dimension_key TEXT,
+			total_score REAL,
+			PRIMARY KEY (date, year, dimension_key)
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("创建得分快照表失败: %w", err)
+	}
+
+	// 创建任务状态变更记录表
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS task_status_log (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			task_id TEXT,
+			status TEXT,
+			changed_at TEXT
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("创建任务状态记录表失败: %w", err)
+	}
+
+	_, err = db.Exec(`CREATE INDEX IF NOT EXISTS idx_task_status_log_changed_at ON task_status_log(changed_at)`)
+	if err != nil {
+		return fmt.Errorf("创建任务状态记录索引失败: %w", err)
+	}
+
+	return nil
+}
+
+// 辅助函数：记录任务状态变化，新建任务记录其初始状态
+func logTaskStatusChange(q queryer, task Task, now time.Time) error {
+	var previous string
+	err := q.QueryRow(`SELECT status FROM tasks WHERE id = ?`, task.ID).Scan(&previous)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+	if previous == task.Status {
+		return nil
+	}
+
+	_, err = q.Exec(
+		`INSERT INTO task_status_log (task_id, status, changed_at) VALUES (?, ?, ?)`,
+		task.ID, task.Status, now.Format(time.RFC3339),
+	)
+	return err
+}
+
+// 辅助函数：得分与最近的快照不同时记录当天的维度得分快照，查询时沿用之前最近的一次
+func recordScoreSnapshot(q queryer, year, dimensionKey string, totalScore float64, now time.Time) error {
+	date := now.Format(taskDateLayout)
+	_, err := q.Exec(
+		`INSERT OR REPLACE INTO score_snapshots (date, year, dimension_key, total_score) SELECT ?, ?, ?, ?
+		WHERE ? IS NOT (SELECT total_score FROM score_snapshots WHERE year = ? AND dimension_key = ? AND date <= ? ORDER BY date DESC LIMIT 1)`,
+		date, year, dimensionKey, totalScore, totalScore, year, dimensionKey, date,
+	)
+	return err
+}
+
+// 辅助函数：记录所有维度当天的得分快照，得分未变化的维度不记录
+func recordAllScoreSnapshots(now time.Time) error {
+	date := now.Format(taskDateLayout)
+	_, err := db.Exec(
+		`INSERT OR REPLACE INTO score_snapshots (date, year, dimension_key, total_score)
+		SELECT ?, d.year, d.dimension_key, d.total_score FROM dimension_data d
+		WHERE d.total_score IS NOT (SELECT s.total_score FROM score_snapshots s WHERE s.year = d.year AND s.dimension_key = d.dimension_key AND s.date <= ? ORDER BY s.date DESC LIMIT 1)`,
+		date, date,
+	)
+	return err
+}
+
+// GenerateDigest 生成摘要，date 为空时使用今天
+func GenerateDigest(kind, date string) (*Digest, error) {
+	settings, err := GetDigestSettings()
+	if err != nil {
+		return nil, err
+	}
+
+	day := time.Now()
+	if date != "" {
+		day, err = time.ParseInLocation(taskDateLayout, date, time.Local)
+		if err != nil {
+			return nil, fmt.Errorf("无效的日期: %s", date)
+		}
+	}
+
+	return buildDigest(kind, day, settings.UpcomingDays)
+}
+
+// 辅助函数：生成截止到指定日期的摘要
+func buildDigest(kind string, day time.Time, upcomingDays int) (*Digest, error) {
+	to := startOfDay(day)
+	var from time.Time
+	switch kind {
+	case digestKindDaily:
+		from = to
+	case digestKindWeekly:
+		from = to.AddDate(0, 0, -6)
+	default:
+		return nil, fmt.Errorf("不支持的摘要类型: %s", kind)
+	}
+	if upcomingDays <= 0 {
+		upcomingDays = DefaultDigestSettings().UpcomingDays
+	}
+
+	digest := &Digest{
+		Kind:        kind,
+		From:        from.Format(taskDateLayout),
+		To:          to.Format(taskDateLayout),
+		GeneratedAt: time.Now().Format("2006-01-02 15:04"),
+	}
+
+	titles := newDimensionTitleCache()
+	openStatuses := []string{"not-started", "in-progress"}
+
+	// 期间内完成的任务
+	completedIDs, err := getTasksCompletedBetween(digest.From, digest.To)
+	if err != nil {
+		return nil, err
+	}
+	digest.CompletedTasks = []DigestTask{}
+	if len(completedIDs) > 0 {
+		digest.CompletedTasks, err = queryDigestTasks(TaskQuery{TaskIDs: completedIDs, Statuses: []string{"completed"}}, to, titles)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// 期间内新增的逾期任务：截止日期的次日落在期间内
+	digest.NewlyOverdue, err = queryDigestTasks(TaskQuery{
+		Statuses: openStatuses,
+		DueFrom:  from.AddDate(0, 0, -1).Format(taskDateLayout),
+		DueTo:    to.AddDate(0, 0, -1).Format(taskDateLayout),
+	}, to, titles)
+	if err != nil {
+		return nil, err
+	}
+
+	// 即将到期的任务
+	digest.UpcomingDeadlines, err = queryDigestTasks(TaskQuery{
+		Statuses: openStatuses,
+		DueFrom:  to.AddDate(0, 0, 1).Format(taskDateLayout),
+		DueTo:    to.AddDate(0, 0, upcomingDays).Format(taskDateLayout),
+	}, to, titles)
+	if err != nil {
+		return nil, err
+	}
+
+	// 维度得分变化
+	digest.ScoreChanges, err = getScoreChanges(fmt.Sprint(to.Year()), digest.From, digest.To, titles)
+	if err != nil {
+		return nil, err
+	}
+
+	if digest.Markdown, err = renderDigestMarkdown(digest); err != nil {
+		return nil, err
+	}
+	if digest.HTML, err = renderDigestHTML(digest); err != nil {
+		return nil, err
+	}
+
+	return digest, nil
+}
+
+// WriteDigest 生成摘要并写入设置的输出目录，返回写入的文件路径
+func WriteDigest(kind, date string) ([]string, error) {
+	settings, err := GetDigestSettings()
+	if err != nil {
+		return nil, err
+	}
+	if settings.OutputDir == "" {
+		return nil, fmt.Errorf("请先设置摘要输出目录")
+	}
+
+	digest, err := GenerateDigest(kind, date)
+	if err != nil {
+		return nil, err
+	}
+
+	return writeDigestFiles(digest, settings)
+}
+
+// 辅助函数：将摘要按设置的格式写入文件
+func writeDigestFiles(digest *Digest, settings DigestSettings) ([]string, error) {
+	if err := os.MkdirAll(settings.OutputDir, 0755); err != nil {
+		return nil, fmt.Errorf("创建摘要输出目录失败: %w", err)
+	}
+
+	paths := []string{}
+	for _, format := range settings.Formats {
+		path := digestFilePath(settings.OutputDir, digest.Kind, digest.To, format)
+		content := digest.Markdown
+		if format == digestFormatHTML {
+			content = digest.HTML
+		}
+
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			return nil, fmt.Errorf("写入摘要文件失败: %w", err)
+		}
+		paths = append(paths, path)
+	}
+
+	return paths, nil
+}
+
+// 辅助函数：摘要文件路径
+func digestFilePath(dir, kind, date, format string) string {
+	extension := ".md"
+	if format == digestFormatHTML {
+		extension = ".html"
+	}
+	return filepath.Join(dir, fmt.Sprintf("digest-%s-%s%s", kind, date, extension))
+}
+
+// checkDigests 记录得分快照并按计划生成摘要，返回下一次检查的间隔
+func checkDigests(ctx context.Context) time.Duration {
+	const interval = 30 * time.Minute
+
+	if db == nil {
+		return interval
+	}
+
+	now := time.Now()
+	if err := recordAllScoreSnapshots(now); err != nil {
+		log.Printf("记录得分快照失败: %v", err)
+	}
+
+	settings, err := GetDigestSettings()
+	if err != nil {
+		log.Printf("读取摘要设置失败: %v", err)
+		return interval
+	}
+	if !settings.Enabled || settings.OutputDir == "" || now.Hour() < settings.Hour {
+		return interval
+	}
+
+	kinds := []string{}
+	if settings.Daily {
+		kinds = append(kinds, digestKindDaily)
+	}
+	if settings.Weekly && int(now.Weekday()) == settings.WeeklyDay {
+		kinds = append(kinds, digestKindWeekly)
+	}
+
+	today := now.Format(taskDateLayout)
+	for _, kind := range kinds {
+		// 已生成过的不再重复生成
+		written := true
+		for _, format := range settings.Formats {
+			if _, err := os.Stat(digestFilePath(settings.OutputDir, kind, today, format)); os.IsNotExist(err) {
+				written = false
+			}
+		}
+		if written {
+			continue
+		}
+
+		digest, err := buildDigest(kind, now, settings.UpcomingDays)
+		if err != nil {
+			log.Printf("生成摘要失败: %v", err)
+			continue
+		}
+		paths, err := writeDigestFiles(digest, settings)
+		if err != nil {
+			log.Printf("写入摘要失败: %v", err)
+			continue
+		}
+
+		runtime.EventsEmit(ctx, digestGeneratedEvent, paths)
+	}
+
+	return interval
+}
+
+// 辅助函数：获取期间内状态变为已完成的任务ID
+func getTasksCompletedBetween(from, to string) ([]string, error) {
+	rows, err := db.Query(
+		`SELECT DISTINCT task_id FROM task_status_log WHERE status = 'completed' AND substr(changed_at, 1, 10) BETWEEN ? AND ?`,
+		from, to,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []string{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// 辅助函数：查询摘要中的任务（不分页）
+func queryDigestTasks(query TaskQuery, now time.Time, titles *dimensionTitleCache) ([]DigestTask, error) {
+	query.SortBy = "endDate"
+	query.PageSize = maxTaskPageSize
+
+	tasks := []DigestTask{}
+	for page := 1; ; page++ {
+		query.Page = page
+		result, err := queryTasksAt(query, now)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, item := range result.Items {
+			title, err := titles.get(item.Year, item.DimensionKey)
+			if err != nil {
+				return nil, err
+			}
+			tasks = append(tasks, DigestTask{
+				Task:           item.Task,
+				Year:           item.Year,
+				DimensionKey:   item.DimensionKey,
+				DimensionTitle: title,
+				Month:          item.Month,
+			})
+		}
+
+		if page*result.PageSize >= result.Total {
+			break
+		}
+	}
+
+	return tasks, nil
+}
+
+// 辅助函数：计算期间内各维度的得分变化
+func getScoreChanges(year, from, to string, titles *dimensionTitleCache) ([]DimensionScoreChange, error) {
+	rows, err := db.Query(`SELECT dimension_key, total_score FROM dimension_data WHERE year = ? ORDER BY dimension_key`, year)
+	if err != nil {
+		return nil, err
+	}
+
+	type dimensionScore struct {
+		key   string
+		score float64
+	}
+	scores := []dimensionScore{}
+	for rows.Next() {
+		var s dimensionScore
+		if err := rows.Scan(&s.key, &s.score); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		scores = append(scores, s)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	changes := []DimensionScoreChange{}
+	for _, s := range scores {
+		current, ok, err := getScoreSnapshot(year, s.key, "<=", to)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			current = s.score
+		}
+
+		previous, ok, err := getScoreSnapshot(year, s.key, "<", from)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			previous = current
+		}
+
+		title, err := titles.get(year, s.key)
+		if err != nil {
+			return nil, err
+		}
+
+		changes = append(changes, DimensionScoreChange{
+			Year:           year,
+			DimensionKey:   s.key,
+			DimensionTitle: title,
+			PreviousScore:  previous,
+			CurrentScore:   current,
+			Delta:          current - previous,
+		})
+	}
+
+	return changes, nil
+}
+
+// 辅助函数：获取某日期之前（或当天）最近的得分快照
+func getScoreSnapshot(year, dimensionKey, op, date string) (float64, bool, error) {
+	var score float64
+	err := db.QueryRow(
+		`SELECT total_score FROM score_snapshots WHERE year = ? AND dimension_key = ? AND date `+op+` ? ORDER BY date DESC LIMIT 1`,
+		year, dimensionKey, date,
+	).Scan(&score)
+	if err == sql.ErrNoRows {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	return score, true, nil
+}
+
+// dimensionTitleCache 缓存维度标题，避免重复查询
+type dimensionTitleCache struct {
+	titles map[string]map[string]string
+}
+
+// 辅助函数：创建维度标题缓存
+func newDimensionTitleCache() *dimensionTitleCache {
+	return &dimensionTitleCache{titles: make(map[string]map[string]string)}
+}
+
+// 辅助函数：获取维度标题，未配置时返回key
+func (c *dimensionTitleCache) get(year, key string) (string, error) {
+	if _, ok := c.titles[year]; !ok {
+		configs, err := getDimensionConfigs(year)
+		if err != nil {
+			return "", err
+		}
+		c.titles[year] = make(map[string]string)
+		for _, config := range configs {
+			c.titles[year][config.Key] = config.Title
+		}
+	}
+
+	if title := c.titles[year][key]; title != "" {
+		return title, nil
+	}
+	return key, nil
+}
+
+// 辅助函数：摘要标题
+func digestTitle(d *Digest) string {
+	if d.Kind == digestKindWeekly {
+		return fmt.Sprintf("每周总结 %s ~ %s", d.From, d.To)
+	}
+	return fmt.Sprintf("每日总结 %s", d.To)
+}
+
+// 辅助函数：截止日期文本
+func digestDueDate(task Task) string {
+	if task.EndDate == nil {
+		return ""
+	}
+	if date, ok := taskDate(*task.EndDate); ok {
+		return date
+	}
+	return *task.EndDate
+}
+
+// 摘要模板使用的函数
+var digestTemplateFuncs = map[string]interface{}{
+	"title":    digestTitle,
+	"due":      digestDueDate,
+	"signed":   func(v float64) string { return fmt.Sprintf("%+.1f", v) },
+	"score":    func(v float64) string { return fmt.Sprintf("%.1f", v) },
+	"priority": priorityLabel,
+}
+
+var digestMarkdownTemplate = template.Must(template.New("digest.md").Funcs(digestTemplateFuncs).Parse(`# {{title .}}
+
+> 生成时间：{{.GeneratedAt}}
+
+## 已完成任务（{{len .CompletedTasks}}）
+{{range .CompletedTasks}}
+- [{{.DimensionTitle}}] {{.Task.Title}}（得分 {{score .Task.Score}}）
+{{- else}}
+- 无
+{{- end}}
+
+## 新增逾期任务（{{len .NewlyOverdue}}）
+{{range .NewlyOverdue}}
+- [{{.DimensionTitle}}] {{.Task.Title}}（截止 {{due .Task}}）
+{{- else}}
+- 无
+{{- end}}
+
+## 维度得分变化
+{{if .ScoreChanges}}
+| 维度 | 之前 | 当前 | 变化 |
+| --- | ---: | ---: | ---: |
+{{- range .ScoreChanges}}
+| {{.DimensionTitle}} | {{score .PreviousScore}} | {{score .CurrentScore}} | {{signed .Delta}} |
+{{- end}}
+{{else}}
+- 无
+{{end}}
+## 即将到期（{{len .UpcomingDeadlines}}）
+{{range .UpcomingDeadlines}}
+- [{{.DimensionTitle}}] {{.Task.Title}}（截止 {{due .Task}}，优先级 {{priority .Task.Priority}}）
+{{- else}}
+- 无
+{{- end}}
+`))
+
+var digestHTMLTemplate = htmltemplate.Must(htmltemplate.New("digest.html").Funcs(digestTemplateFuncs).Parse(`<!DOCTYPE html>
+<html lang="zh-CN">
+<head>
+<meta charset="utf-8">
+<title>{{title .}}</title>
+<style>
+body { font-family: -apple-system, "PingFang SC", "Microsoft YaHei", sans-serif; color: #1f2937; max-width: 760px; margin: 32px auto; padding: 0 16px; }
+h1 { font-size: 24px; margin-bottom: 4px; }
+h2 { font-size: 18px; margin-top: 28px; border-bottom: 1px solid #e5e7eb; padding-bottom: 6px; }
+.meta { color: #6b7280; font-size: 13px; }
+.dim { display: inline-block; background: #eef2ff; color: #4338ca; border-radius: 4px; padding: 0 6px; margin-right: 6px; font-size: 12px; }
+.empty { color: #9ca3af; }
+table { border-collapse: collapse; width: 100%; }
+th, td { border-bottom: 1px solid #e5e7eb; padding: 6px 8px; text-align: right; }
+th:first-child, td:first-child { text-align: left; }
+.up { color: #16a34a; }
+.down { color: #dc2626; }
+</style>
+</head>
+<body>
+<h1>{{title .}}</h1>
+<p class="meta">生成时间：{{.GeneratedAt}}</p>
+
+<h2>已完成任务（{{len .CompletedTasks}}）</h2>
+{{if .CompletedTasks}}<ul>{{range .CompletedTasks}}
+<li><span class="dim">{{.DimensionTitle}}</span>{{.Task.Title}}（得分 {{score .Task.Score}}）</li>{{end}}
+</ul>{{else}}<p class="empty">无</p>{{end}}
+
+<h2>新增逾期任务（{{len .NewlyOverdue}}）</h2>
+{{if .NewlyOverdue}}<ul>{{range .NewlyOverdue}}
+<li><span class="dim">{{.DimensionTitle}}</span>{{.Task.Title}}（截止 {{due .Task}}）</li>{{end}}
+</ul>{{else}}<p class="empty">无</p>{{end}}
+
+<h2>维度得分变化</h2>
+{{if .ScoreChanges}}<table>
+<tr><th>维度</th><th>之前</th><th>当前</th><th>变化</th></tr>{{range .ScoreChanges}}
+<tr><td>{{.DimensionTitle}}</td><td>{{score .PreviousScore}}</td><td>{{score .CurrentScore}}</td><td class="{{if gt .Delta 0.0}}up{{else if lt .Delta 0.0}}down{{end}}">{{signed .Delta}}</td></tr>{{end}}
+</table>{{else}}<p class="empty">无</p>{{end}}
+
+<h2>即将到期（{{len .UpcomingDeadlines}}）</h2>
+{{if .UpcomingDeadlines}}<ul>{{range .UpcomingDeadlines}}
+<li><span class="dim">{{.DimensionTitle}}</span>{{.Task.Title}}（截止 {{due .Task}}，优先级 {{priority .Task.Priority}}）</li>{{end}}
+</ul>{{else}}<p class="empty">无</p>{{end}}
+</body>
+</html>
+`))
+
+// 辅助函数：渲染 Markdown 摘要
+func renderDigestMarkdown(d *Digest) (string, error) {
+	var buf bytes.Buffer
+	if err := digestMarkdownTemplate.Execute(&buf, d); err != nil {
+		return "", fmt.Errorf("渲染Markdown摘要失败: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// 辅助函数：渲染 HTML 摘要
+func renderDigestHTML(d *Digest) (string, error) {
+	var buf bytes.Buffer
+	if err := digestHTMLTemplate.Execute(&buf, d); err != nil {
+		return "", fmt.Errorf("渲染HTML摘要失败: %w", err)
+	}
+	return buf.String(), nil
+}
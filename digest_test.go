@@ -0,0 +1,53 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// 新建时即为已完成的任务出现在摘要的已完成列表中
+func TestDigestIncludesTaskCreatedCompleted(t *testing.T) {
+	setupTestDB(t)
+	saveTestTask(t, "2026", "work", Task{ID: "t1", Title: "任务", Status: "completed", Priority: "medium", Score: 80})
+
+	digest, err := buildDigest(digestKindDaily, time.Now(), 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(digest.CompletedTasks) != 1 || digest.CompletedTasks[0].Task.ID != "t1" {
+		t.Fatalf("已完成任务 = %+v", digest.CompletedTasks)
+	}
+}
+
+// 得分未变化时不重复记录快照
+func TestScoreSnapshotsOnlyOnChange(t *testing.T) {
+	setupTestDB(t)
+	saveTestTask(t, "2026", "work", Task{ID: "t1", Title: "任务", Status: "completed", Priority: "medium", Score: 80})
+
+	day := time.Now()
+	for i := 1; i <= 3; i++ {
+		if err := recordAllScoreSnapshots(day.AddDate(0, 0, i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var count int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM score_snapshots`).Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Fatalf("得分未变化时快照数 = %d", count)
+	}
+
+	if _, err := db.Exec(`UPDATE dimension_data SET total_score = 90`); err != nil {
+		t.Fatal(err)
+	}
+	if err := recordAllScoreSnapshots(day.AddDate(0, 0, 4)); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.QueryRow(`SELECT COUNT(*) FROM score_snapshots`).Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	if count != 2 {
+		t.Fatalf("得分变化后快照数 = %d", count)
+	}
+}
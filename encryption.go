@@ -0,0 +1,578 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/argon2"
+	"modernc.org/sqlite"
+	"modernc.org/sqlite/vfs"
+)
+
+// 加密数据库文件后缀，与明文数据库位于同一目录
+const encryptedDBSuffix = ".enc"
+
+// 加密文件头：魔数、Argon2id 参数（迭代次数、内存KiB、并行度）、盐、随机数
+const (
+	encryptedDBMagic = "MNFSTDB1"
+	encryptionSalt   = 16
+	encryptionNonce  = 12
+	encryptionHeader = len(encryptedDBMagic) + 4 + 4 + 1 + encryptionSalt + encryptionNonce
+)
+
+// Argon2id 默认参数
+const (
+	argonTime    = 3
+	argonMemory  = 64 * 1024
+	argonThreads = 4
+	argonKeyLen  = 32
+)
+
+// 文件头中允许的 Argon2id 参数上限，防止篡改后的文件头耗尽内存或长时间占用 CPU
+const (
+	maxArgonTime    = 4 * argonTime
+	maxArgonMemory  = 4 * argonMemory
+	maxArgonThreads = 4 * argonThreads
+)
+
+// 密码最短长度
+const minPassphraseLength = 8
+
+// 解锁后数据只保存在内存数据库中，定时加密写回磁盘
+const (
+	encryptedMemoryURI     = "file:/manifest-performance.db?vfs=memdb&_pragma=busy_timeout(5000)"
+	encryptedFlushInterval = 5 * time.Second
+)
+
+var (
+	errDatabaseLocked  = errors.New("数据库已加密，请输入密码解锁")
+	errWrongPassphrase = errors.New("密码错误，无法解密数据库")
+)
+
+// DatabaseEncryptionStatus 数据库加密状态
+type DatabaseEncryptionStatus struct {
+	Encrypted bool `json:"encrypted"` // 数据库文件是否加密
+	Locked    bool `json:"locked"`    // 是否尚未输入密码解锁
+}
+
+// keyParams 密钥派生参数
+type keyParams struct {
+	time    uint32
+	memory  uint32
+	threads uint8
+	salt    []byte
+}
+
+// encryptedDatabase 已解锁的加密数据库
+type encryptedDatabase struct {
+	path    string    // 加密文件路径
+	params  keyParams // 当前密钥的派生参数
+	key     []byte
+	anchor  *sql.Conn // 保持内存数据库存活，并用于序列化
+	version int64     // 上次写回磁盘时的 data_version
+	flusher *Scheduler
+}
+
+var (
+	encryptionMu sync.Mutex
+	encrypted    *encryptedDatabase
+)
+
+// GetDatabaseEncryptionStatus 获取当前账号的数据库加密状态
+func GetDatabaseEncryptionStatus() (DatabaseEncryptionStatus, error) {
+	path, err := databasePath()
+	if err != nil {
+		return DatabaseEncryptionStatus{}, err
+	}
+
+	encryptionMu.Lock()
+	defer encryptionMu.Unlock()
+
+	if encrypted != nil {
+		return DatabaseEncryptionStatus{Encrypted: true}, nil
+	}
+	if _, err := os.Stat(path + encryptedDBSuffix); err == nil {
+		return DatabaseEncryptionStatus{Encrypted: true, Locked: true}, nil
+	}
+	return DatabaseEncryptionStatus{}, nil
+}
+
+// UnlockDatabase 使用密码解锁当前账号的加密数据库，密码错误时返回 errWrongPassphrase
+func UnlockDatabase(passphrase string) error {
+	path, err := databasePath()
+	if err != nil {
+		return err
+	}
+	path += encryptedDBSuffix
+
+	encryptionMu.Lock()
+	defer encryptionMu.Unlock()
+
+	if encrypted != nil {
+		return nil
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("读取加密数据库失败: %w", err)
+	}
+	params, err := parseKeyParams(content)
+	if err != nil {
+		return err
+	}
+	key := deriveKey(passphrase, params)
+	plain, err := decryptDatabase(content, key)
+	if err != nil {
+		return err
+	}
+
+	return openEncryptedDatabase(path, plain, params, key)
+}
+
+// EnableDatabaseEncryption 使用密码加密当前账号的数据库，加密后删除明文数据库文件
+//
+// 每个账号的数据保存在各自的数据库文件中，密码只作用于当前账号。
+func EnableDatabaseEncryption(passphrase string) error {
+	if len([]rune(passphrase)) < minPassphraseLength {
+		return fmt.Errorf("密码至少需要%d个字符", minPassphraseLength)
+	}
+	path, err := databasePath()
+	if err != nil {
+		return err
+	}
+
+	encryptionMu.Lock()
+	defer encryptionMu.Unlock()
+
+	if encrypted != nil {
+		return fmt.Errorf("数据库已加密")
+	}
+	if db == nil {
+		return fmt.Errorf("数据库未打开")
+	}
+
+	plain, err := serializeDatabase(context.Background(), db)
+	if err != nil {
+		return err
+	}
+	params, err := newKeyParams()
+	if err != nil {
+		return err
+	}
+	key := deriveKey(passphrase, params)
+	content, err := encryptDatabase(plain, params, key)
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(path+encryptedDBSuffix, content); err != nil {
+		return fmt.Errorf("写入加密数据库失败: %w", err)
+	}
+
+	if err := db.Close(); err != nil {
+		return fmt.Errorf("关闭数据库失败: %w", err)
+	}
+	db = nil
+	if err := openEncryptedDatabase(path+encryptedDBSuffix, plain, params, key); err != nil {
+		os.Remove(path + encryptedDBSuffix)
+		return err
+	}
+
+	// 删除明文数据库及日志文件
+	for _, suffix := range []string{"", "-journal", "-wal", "-shm"} {
+		if err := os.Remove(path + suffix); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("删除明文数据库失败: %w", err)
+		}
+	}
+	return nil
+}
+
+// DisableDatabaseEncryption 校验密码后将数据库恢复为明文
+func DisableDatabaseEncryption(passphrase string) error {
+	path, err := databasePath()
+	if err != nil {
+		return err
+	}
+
+	encryptionMu.Lock()
+	defer encryptionMu.Unlock()
+
+	if err := checkPassphrase(passphrase); err != nil {
+		return err
+	}
+
+	plain, err := serializeDatabase(context.Background(), db)
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(path, plain); err != nil {
+		return fmt.Errorf("写入数据库失败: %w", err)
+	}
+	if err := shutdownEncryptedDatabase(false); err != nil {
+		return err
+	}
+	if err := os.Remove(path + encryptedDBSuffix); err != nil {
+		return fmt.Errorf("删除加密数据库失败: %w", err)
+	}
+
+	return openDatabase(path)
+}
+
+// ChangeDatabasePassphrase 修改数据库密码
+func ChangeDatabasePassphrase(oldPassphrase, newPassphrase string) error {
+	if len([]rune(newPassphrase)) < minPassphraseLength {
+		return fmt.Errorf("密码至少需要%d个字符", minPassphraseLength)
+	}
+
+	encryptionMu.Lock()
+	defer encryptionMu.Unlock()
+
+	if err := checkPassphrase(oldPassphrase); err != nil {
+		return err
+	}
+
+	params, err := newKeyParams()
+	if err != nil {
+		return err
+	}
+	oldParams, oldKey := encrypted.params, encrypted.key
+	encrypted.params, encrypted.key = params, deriveKey(newPassphrase, params)
+	if err := flushEncryptedDatabase(true); err != nil {
+		encrypted.params, encrypted.key = oldParams, oldKey
+		return err
+	}
+	return nil
+}
+
+// 辅助函数：校验密码与当前密钥是否一致，调用方需持有 encryptionMu
+func checkPassphrase(passphrase string) error {
+	if encrypted == nil {
+		return fmt.Errorf("数据库未加密或尚未解锁")
+	}
+	key := deriveKey(passphrase, encrypted.params)
+	if subtle.ConstantTimeCompare(key, encrypted.key) != 1 {
+		return errWrongPassphrase
+	}
+	return nil
+}
+
+// 辅助函数：将解密后的数据库载入内存并开始定时写回，调用方需持有 encryptionMu
+func openEncryptedDatabase(path string, plain []byte, params keyParams, key []byte) error {
+	ctx := context.Background()
+	memory, err := sql.Open("sqlite", encryptedMemoryURI)
+	if err != nil {
+		return fmt.Errorf("打开数据库失败: %w", err)
+	}
+	anchor, err := memory.Conn(ctx)
+	if err != nil {
+		memory.Close()
+		return fmt.Errorf("数据库连接失败: %w", err)
+	}
+	if err := restoreDatabase(ctx, anchor, plain); err != nil {
+		anchor.Close()
+		memory.Close()
+		return err
+	}
+
+	state := &encryptedDatabase{path: path, params: params, key: key, anchor: anchor}
+	db, encrypted = memory, state
+	if err := createTables(); err != nil {
+		shutdownEncryptedDatabase(false)
+		return fmt.Errorf("创建表结构失败: %w", err)
+	}
+	if err := migrateDatabaseAccounts(); err != nil {
+		shutdownEncryptedDatabase(false)
+		return fmt.Errorf("迁移账号失败: %w", err)
+	}
+	if state.version, err = dataVersion(ctx, anchor); err != nil {
+		shutdownEncryptedDatabase(false)
+		return err
+	}
+
+	state.flusher = NewScheduler(func(ctx context.Context) time.Duration {
+		// 关闭数据库时会持有 encryptionMu 并等待写回任务结束，此时跳过本次写回
+		if !encryptionMu.TryLock() {
+			return encryptedFlushInterval
+		}
+		defer encryptionMu.Unlock()
+
+		if encrypted == state {
+			if err := flushEncryptedDatabase(false); err != nil {
+				log.Printf("写入加密数据库失败: %v", err)
+			}
+		}
+		return encryptedFlushInterval
+	})
+	state.flusher.Start(ctx)
+	return nil
+}
+
+// 辅助函数：关闭加密数据库，先将修改写回磁盘
+func closeEncryptedDatabase() error {
+	encryptionMu.Lock()
+	defer encryptionMu.Unlock()
+
+	if encrypted == nil {
+		return nil
+	}
+	return shutdownEncryptedDatabase(true)
+}
+
+// 辅助函数：停止写回并关闭内存数据库，调用方需持有 encryptionMu
+func shutdownEncryptedDatabase(flush bool) error {
+	if encrypted.flusher != nil {
+		encrypted.flusher.Stop()
+	}
+	var flushErr error
+	if flush {
+		flushErr = flushEncryptedDatabase(false)
+	}
+
+	encrypted.anchor.Close()
+	err := db.Close()
+	db, encrypted = nil, nil
+	if flushErr != nil {
+		return flushErr
+	}
+	return err
+}
+
+// 辅助函数：数据有修改（或 force 为 true）时加密写回磁盘，调用方需持有 encryptionMu
+func flushEncryptedDatabase(force bool) error {
+	ctx := context.Background()
+	version, err := dataVersion(ctx, encrypted.anchor)
+	if err != nil {
+		return err
+	}
+	if !force && version == encrypted.version {
+		return nil
+	}
+
+	plain, err := serializeDatabase(ctx, db)
+	if err != nil {
+		return err
+	}
+	content, err := encryptDatabase(plain, encrypted.params, encrypted.key)
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(encrypted.path, content); err != nil {
+		return fmt.Errorf("写入加密数据库失败: %w", err)
+	}
+	encrypted.version = version
+	return nil
+}
+
+// 辅助函数：读取 data_version，其他连接提交修改后该值会变化
+func dataVersion(ctx context.Context, conn *sql.Conn) (int64, error) {
+	var version int64
+	if err := conn.QueryRowContext(ctx, `PRAGMA data_version`).Scan(&version); err != nil {
+		return 0, fmt.Errorf("读取数据库版本失败: %w", err)
+	}
+	return version, nil
+}
+
+// 辅助函数：将数据库序列化为 SQLite 文件内容
+func serializeDatabase(ctx context.Context, database *sql.DB) ([]byte, error) {
+	conn, err := database.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	var content []byte
+	err = conn.Raw(func(driverConn any) error {
+		serializer, ok := driverConn.(interface{ Serialize() ([]byte, error) })
+		if !ok {
+			return fmt.Errorf("数据库驱动不支持序列化")
+		}
+		content, err = serializer.Serialize()
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("序列化数据库失败: %w", err)
+	}
+	return content, nil
+}
+
+// 辅助函数：将 SQLite 文件内容恢复到内存数据库，明文不落盘
+func restoreDatabase(ctx context.Context, conn *sql.Conn, plain []byte) error {
+	name, fsys, err := vfs.New(memoryFile{name: "performance.db", content: plain})
+	if err != nil {
+		return fmt.Errorf("载入数据库失败: %w", err)
+	}
+	defer fsys.Close()
+
+	err = conn.Raw(func(driverConn any) error {
+		restorer, ok := driverConn.(interface {
+			NewRestore(string) (*sqlite.Backup, error)
+		})
+		if !ok {
+			return fmt.Errorf("数据库驱动不支持恢复")
+		}
+		backup, err := restorer.NewRestore("file:performance.db?mode=ro&vfs=" + name)
+		if err != nil {
+			return err
+		}
+		if _, err := backup.Step(-1); err != nil {
+			backup.Finish()
+			return err
+		}
+		return backup.Finish()
+	})
+	if err != nil {
+		return fmt.Errorf("载入数据库失败: %w", err)
+	}
+	return nil
+}
+
+// memoryFile 只包含一个只读文件的 fs.FS，用于通过 vfs 从内存读取解密后的数据库
+type memoryFile struct {
+	name    string
+	content []byte
+}
+
+// Open 实现 fs.FS
+func (m memoryFile) Open(name string) (fs.File, error) {
+	if name != m.name {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return &openMemoryFile{Reader: bytes.NewReader(m.content), info: memoryFileInfo{m}}, nil
+}
+
+// openMemoryFile 已打开的内存文件，vfs 读取时需要 Seek
+type openMemoryFile struct {
+	*bytes.Reader
+	info memoryFileInfo
+}
+
+func (f *openMemoryFile) Stat() (fs.FileInfo, error) { return f.info, nil }
+func (f *openMemoryFile) Close() error               { return nil }
+
+// memoryFileInfo 内存文件的 fs.FileInfo
+type memoryFileInfo struct{ file memoryFile }
+
+func (i memoryFileInfo) Name() string       { return i.file.name }
+func (i memoryFileInfo) Size() int64        { return int64(len(i.file.content)) }
+func (i memoryFileInfo) Mode() fs.FileMode  { return 0444 }
+func (i memoryFileInfo) ModTime() time.Time { return time.Time{} }
+func (i memoryFileInfo) IsDir() bool        { return false }
+func (i memoryFileInfo) Sys() any           { return nil }
+
+// 辅助函数：生成新的密钥派生参数
+func newKeyParams() (keyParams, error) {
+	params := keyParams{time: argonTime, memory: argonMemory, threads: argonThreads, salt: make([]byte, encryptionSalt)}
+	if _, err := rand.Read(params.salt); err != nil {
+		return keyParams{}, fmt.Errorf("生成随机盐失败: %w", err)
+	}
+	return params, nil
+}
+
+// 辅助函数：使用 Argon2id 从密码派生密钥
+func deriveKey(passphrase string, params keyParams) []byte {
+	return argon2.IDKey([]byte(passphrase), params.salt, params.time, params.memory, params.threads, argonKeyLen)
+}
+
+// 辅助函数：解析加密文件头中的密钥派生参数
+func parseKeyParams(content []byte) (keyParams, error) {
+	if len(content) < encryptionHeader || !bytes.HasPrefix(content, []byte(encryptedDBMagic)) {
+		return keyParams{}, fmt.Errorf("无法识别的加密数据库文件")
+	}
+
+	offset := len(encryptedDBMagic)
+	params := keyParams{
+		time:    binary.BigEndian.Uint32(content[offset:]),
+		memory:  binary.BigEndian.Uint32(content[offset+4:]),
+		threads: content[offset+8],
+		salt:    content[offset+9 : offset+9+encryptionSalt],
+	}
+	if params.time == 0 || params.memory == 0 || params.threads == 0 {
+		return keyParams{}, fmt.Errorf("无法识别的加密数据库文件")
+	}
+	if params.time > maxArgonTime || params.memory > maxArgonMemory || params.threads > maxArgonThreads {
+		return keyParams{}, fmt.Errorf("加密数据库的密钥参数超出允许范围")
+	}
+	return params, nil
+}
+
+// 辅助函数：使用 AES-256-GCM 加密数据库，文件头作为附加数据参与认证
+func encryptDatabase(plain []byte, params keyParams, key []byte) ([]byte, error) {
+	gcm, err := newDatabaseCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	header := make([]byte, 0, encryptionHeader)
+	header = append(header, encryptedDBMagic...)
+	header = binary.BigEndian.AppendUint32(header, params.time)
+	header = binary.BigEndian.AppendUint32(header, params.memory)
+	header = append(header, params.threads)
+	header = append(header, params.salt...)
+	nonce := make([]byte, encryptionNonce)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("生成随机数失败: %w", err)
+	}
+	header = append(header, nonce...)
+
+	return gcm.Seal(header, nonce, plain, header), nil
+}
+
+// 辅助函数：解密数据库，认证失败说明密码错误或文件已损坏
+func decryptDatabase(content, key []byte) ([]byte, error) {
+	gcm, err := newDatabaseCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	header := content[:encryptionHeader]
+	nonce := header[encryptionHeader-encryptionNonce:]
+	plain, err := gcm.Open(nil, nonce, content[encryptionHeader:], header)
+	if err != nil {
+		return nil, errWrongPassphrase
+	}
+	return plain, nil
+}
+
+// 辅助函数：创建 AES-GCM 加密器
+func newDatabaseCipher(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("初始化加密失败: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// 辅助函数：先写入临时文件再替换，避免写入中断导致文件损坏
+func writeFileAtomic(path string, content []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
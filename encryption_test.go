@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/binary"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseKeyParamsBounds(t *testing.T) {
+	params, err := newKeyParams()
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err := encryptDatabase([]byte("data"), params, make([]byte, argonKeyLen))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := parseKeyParams(content); err != nil {
+		t.Fatalf("默认参数应通过: %v", err)
+	}
+
+	offset := len(encryptedDBMagic)
+	tampered := append([]byte{}, content...)
+	binary.BigEndian.PutUint32(tampered[offset+4:], maxArgonMemory+1)
+	if _, err := parseKeyParams(tampered); err == nil {
+		t.Fatal("内存参数超出上限时应返回错误")
+	}
+	tampered = append([]byte{}, content...)
+	binary.BigEndian.PutUint32(tampered[offset:], 1<<30)
+	if _, err := parseKeyParams(tampered); err == nil {
+		t.Fatal("迭代次数超出上限时应返回错误")
+	}
+}
+
+// 序列化后的数据库应能通过内存文件恢复，明文不落盘
+func TestRestoreDatabase(t *testing.T) {
+	ctx := context.Background()
+	source, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "source.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer source.Close()
+	if _, err := source.Exec(`CREATE TABLE items (name TEXT); INSERT INTO items VALUES ('a'), ('b')`); err != nil {
+		t.Fatal(err)
+	}
+	plain, err := serializeDatabase(ctx, source)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	target, err := sql.Open("sqlite", "file:/restore-test.db?vfs=memdb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer target.Close()
+	conn, err := target.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if err := restoreDatabase(ctx, conn, plain); err != nil {
+		t.Fatalf("恢复数据库失败: %v", err)
+	}
+
+	var count int
+	if err := target.QueryRow(`SELECT COUNT(*) FROM items`).Scan(&count); err != nil || count != 2 {
+		t.Fatalf("恢复后的行数 = %d, %v", count, err)
+	}
+}
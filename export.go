@@ -0,0 +1,355 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// 表格导出格式
+const (
+	spreadsheetFormatCSV  = "csv"
+	spreadsheetFormatXLSX = "xlsx"
+)
+
+// 工作表名称
+const (
+	taskSheetName    = "任务"
+	summarySheetName = "维度汇总"
+)
+
+// 任务表的列标题，导入时也按这些标题自动匹配列
+var taskSheetHeaders = []string{"年度", "维度", "维度Key", "月份", "任务ID", "任务", "描述", "状态", "优先级", "得分", "开始日期", "结束日期", "标签"}
+
+// 维度汇总表的列标题
+var summarySheetHeaders = []string{"年度", "维度", "维度Key", "权重", "得分", "加权得分", "完成任务", "任务总数", "完成率"}
+
+// TaskRow 扁平化后的任务行
+type TaskRow struct {
+	Year           string  `json:"year"`
+	DimensionTitle string  `json:"dimensionTitle"`
+	DimensionKey   string  `json:"dimensionKey"`
+	Month          int     `json:"month"` // 1-12
+	TaskID         string  `json:"taskId"`
+	Title          string  `json:"title"`
+	Description    string  `json:"description"`
+	Status         string  `json:"status"`
+	Priority       string  `json:"priority"`
+	Score          float64 `json:"score"`
+	StartDate      string  `json:"startDate"`
+	EndDate        string  `json:"endDate"`
+	Tags           string  `json:"tags"`
+}
+
+// DimensionSummaryRow 维度汇总行
+type DimensionSummaryRow struct {
+	Year           string  `json:"year"`
+	DimensionTitle string  `json:"dimensionTitle"`
+	DimensionKey   string  `json:"dimensionKey"`
+	Weight         float64 `json:"weight"`
+	TotalScore     float64 `json:"totalScore"`
+	WeightedScore  float64 `json:"weightedScore"`
+	CompletedTasks int     `json:"completedTasks"`
+	TotalTasks     int     `json:"totalTasks"`
+	Progress       int     `json:"progress"`
+}
+
+// 辅助函数：年度列表，year 为空时返回所有年度（升序）
+func selectYears(data SystemData, year string) ([]string, error) {
+	if year != "" {
+		if _, ok := data[year]; !ok {
+			return nil, fmt.Errorf("年度数据不存在: %s", year)
+		}
+		return []string{year}, nil
+	}
+
+	years := []string{}
+	for y := range data {
+		years = append(years, y)
+	}
+	sort.Strings(years)
+	return years, nil
+}
+
+// 辅助函数：将系统数据扁平化为任务行
+func flattenTasks(data SystemData, years []string) []TaskRow {
+	rows := []TaskRow{}
+	for _, year := range years {
+		annual := data[year]
+		for _, config := range orderedDimensionConfigs(annual) {
+			dimData, ok := annual.Dimensions[config.Key]
+			if !ok {
+				continue
+			}
+			for month, tasks := range dimData.MonthlyTasks {
+				for _, task := range tasks {
+					row := TaskRow{
+						Year:           year,
+						DimensionTitle: config.Title,
+						DimensionKey:   config.Key,
+						Month:          month + 1,
+						TaskID:         task.ID,
+						Title:          task.Title,
+						Description:    task.Description,
+						Status:         statusLabel(task.Status),
+						Priority:       priorityLabel(task.Priority),
+						Score:          task.Score,
+						Tags:           strings.Join(task.Tags, ", "),
+					}
+					if task.StartDate != nil {
+						row.StartDate = *task.StartDate
+					}
+					if task.EndDate != nil {
+						row.EndDate = *task.EndDate
+					}
+					rows = append(rows, row)
+				}
+			}
+		}
+	}
+	return rows
+}
+
+// 辅助函数：生成维度汇总行
+func summarizeDimensions(data SystemData, years []string) []DimensionSummaryRow {
+	rows := []DimensionSummaryRow{}
+	for _, year := range years {
+		annual := data[year]
+		for _, config := range orderedDimensionConfigs(annual) {
+			dimData, ok := annual.Dimensions[config.Key]
+			if !ok {
+				continue
+			}
+			weight := annual.Settings.Scoring.DimensionWeights[config.Key]
+			rows = append(rows, DimensionSummaryRow{
+				Year:           year,
+				DimensionTitle: config.Title,
+				DimensionKey:   config.Key,
+				Weight:         weight,
+				TotalScore:     dimData.TotalScore,
+				WeightedScore:  dimData.TotalScore * weight,
+				CompletedTasks: dimData.CompletedTasks,
+				TotalTasks:     dimData.TotalTasks,
+				Progress:       dimData.Progress,
+			})
+		}
+
+		// 年度合计
+		rows = append(rows, DimensionSummaryRow{
+			Year:           year,
+			DimensionTitle: "年度总分",
+			WeightedScore:  annual.TotalScore,
+		})
+	}
+	return rows
+}
+
+// 辅助函数：任务行转为单元格
+func (r TaskRow) cells() []interface{} {
+	return []interface{}{r.Year, r.DimensionTitle, r.DimensionKey, r.Month, r.TaskID, r.Title, r.Description, r.Status, r.Priority, r.Score, r.StartDate, r.EndDate, r.Tags}
+}
+
+// 辅助函数：汇总行转为单元格，年度合计行只保留总分
+func (r DimensionSummaryRow) cells() []interface{} {
+	if r.DimensionKey == "" {
+		return []interface{}{r.Year, r.DimensionTitle, "", "", "", r.WeightedScore, "", "", ""}
+	}
+	return []interface{}{r.Year, r.DimensionTitle, r.DimensionKey, r.Weight, r.TotalScore, r.WeightedScore, r.CompletedTasks, r.TotalTasks, float64(r.Progress) / 100}
+}
+
+// ExportSpreadsheet 导出任务与维度汇总表格，year 为空时导出所有年度，返回写入的文件路径
+//
+// CSV 格式会在 path 旁额外写入一个 “_summary” 后缀的汇总文件；XLSX 格式将两者写入同一文件的两个工作表。
+func ExportSpreadsheet(format, year, path string) ([]string, error) {
+	data, err := GetAllAnnualData()
+	if err != nil {
+		return nil, err
+	}
+
+	years, err := selectYears(data, year)
+	if err != nil {
+		return nil, err
+	}
+	tasks := flattenTasks(data, years)
+	summary := summarizeDimensions(data, years)
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("创建导出目录失败: %w", err)
+	}
+
+	switch format {
+	case spreadsheetFormatCSV:
+		summaryPath := strings.TrimSuffix(path, filepath.Ext(path)) + "_summary.csv"
+		if err := writeTaskCSV(path, tasks); err != nil {
+			return nil, err
+		}
+		if err := writeSummaryCSV(summaryPath, summary); err != nil {
+			return nil, err
+		}
+		return []string{path, summaryPath}, nil
+	case spreadsheetFormatXLSX:
+		if err := writeSpreadsheetXLSX(path, tasks, summary); err != nil {
+			return nil, err
+		}
+		return []string{path}, nil
+	default:
+		return nil, fmt.Errorf("不支持的导出格式: %s", format)
+	}
+}
+
+// 辅助函数：写入任务 CSV
+func writeTaskCSV(path string, rows []TaskRow) error {
+	records := [][]string{taskSheetHeaders}
+	for _, row := range rows {
+		records = append(records, cellStrings(row.cells()))
+	}
+	return writeCSVFile(path, records)
+}
+
+// 辅助函数：写入维度汇总 CSV
+func writeSummaryCSV(path string, rows []DimensionSummaryRow) error {
+	records := [][]string{summarySheetHeaders}
+	for _, row := range rows {
+		records = append(records, cellStrings(row.cells()))
+	}
+	return writeCSVFile(path, records)
+}
+
+// 辅助函数：写入 CSV 文件，带 UTF-8 BOM 以便 Excel 正确识别中文
+func writeCSVFile(path string, records [][]string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("创建CSV文件失败: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.WriteString("\ufeff"); err != nil {
+		return fmt.Errorf("写入CSV文件失败: %w", err)
+	}
+
+	writer := csv.NewWriter(file)
+	if err := writer.WriteAll(records); err != nil {
+		return fmt.Errorf("写入CSV文件失败: %w", err)
+	}
+	return file.Close()
+}
+
+// 辅助函数：单元格转为字符串
+func cellStrings(cells []interface{}) []string {
+	values := make([]string, len(cells))
+	for i, cell := range cells {
+		switch v := cell.(type) {
+		case float64:
+			values[i] = strings.TrimRight(strings.TrimRight(fmt.Sprintf("%.4f", v), "0"), ".")
+		default:
+			values[i] = fmt.Sprint(v)
+		}
+	}
+	return values
+}
+
+// 辅助函数：写入 XLSX 文件
+func writeSpreadsheetXLSX(path string, tasks []TaskRow, summary []DimensionSummaryRow) error {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	headerStyle, err := f.NewStyle(&excelize.Style{
+		Font: &excelize.Font{Bold: true},
+		Fill: excelize.Fill{Type: "pattern", Color: []string{"#EEF2FF"}, Pattern: 1},
+	})
+	if err != nil {
+		return err
+	}
+	percentStyle, err := f.NewStyle(&excelize.Style{NumFmt: 10}) // 0.00%
+	if err != nil {
+		return err
+	}
+
+	// 任务表
+	if err := f.SetSheetName("Sheet1", taskSheetName); err != nil {
+		return err
+	}
+	taskRows := [][]interface{}{}
+	for _, row := range tasks {
+		taskRows = append(taskRows, row.cells())
+	}
+	if err := writeXLSXSheet(f, taskSheetName, taskSheetHeaders, taskRows, headerStyle); err != nil {
+		return err
+	}
+	widths := map[string]float64{"A": 8, "B": 14, "C": 14, "D": 6, "E": 38, "F": 36, "G": 40, "H": 10, "I": 8, "J": 8, "K": 12, "L": 12, "M": 20}
+	for column, width := range widths {
+		if err := f.SetColWidth(taskSheetName, column, column, width); err != nil {
+			return err
+		}
+	}
+
+	// 维度汇总表
+	if _, err := f.NewSheet(summarySheetName); err != nil {
+		return err
+	}
+	summaryRows := [][]interface{}{}
+	for _, row := range summary {
+		summaryRows = append(summaryRows, row.cells())
+	}
+	if err := writeXLSXSheet(f, summarySheetName, summarySheetHeaders, summaryRows, headerStyle); err != nil {
+		return err
+	}
+	if err := f.SetColWidth(summarySheetName, "A", "I", 12); err != nil {
+		return err
+	}
+	if len(summaryRows) > 0 {
+		last := fmt.Sprintf("%d", len(summaryRows)+1)
+		if err := f.SetCellStyle(summarySheetName, "D2", "D"+last, percentStyle); err != nil {
+			return err
+		}
+		if err := f.SetCellStyle(summarySheetName, "I2", "I"+last, percentStyle); err != nil {
+			return err
+		}
+	}
+
+	if err := f.SaveAs(path); err != nil {
+		return fmt.Errorf("写入XLSX文件失败: %w", err)
+	}
+	return nil
+}
+
+// 辅助函数：写入工作表的标题与数据，并冻结首行
+func writeXLSXSheet(f *excelize.File, sheet string, headers []string, rows [][]interface{}, headerStyle int) error {
+	headerCells := make([]interface{}, len(headers))
+	for i, header := range headers {
+		headerCells[i] = header
+	}
+	if err := f.SetSheetRow(sheet, "A1", &headerCells); err != nil {
+		return err
+	}
+
+	lastColumn, err := excelize.ColumnNumberToName(len(headers))
+	if err != nil {
+		return err
+	}
+	if err := f.SetCellStyle(sheet, "A1", lastColumn+"1", headerStyle); err != nil {
+		return err
+	}
+
+	for i, row := range rows {
+		cell, err := excelize.CoordinatesToCellName(1, i+2)
+		if err != nil {
+			return err
+		}
+		if err := f.SetSheetRow(sheet, cell, &row); err != nil {
+			return err
+		}
+	}
+
+	return f.SetPanes(sheet, &excelize.Panes{
+		Freeze:      true,
+		YSplit:      1,
+		TopLeftCell: "A2",
+		ActivePane:  "bottomLeft",
+	})
+}
@@ -0,0 +1,375 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// 共享目录同步完成事件名称
+const folderSyncedEvent = "folderSynced"
+
+// 每台设备的操作日志文件后缀，文件名为设备ID
+const folderSyncLogSuffix = ".oplog.jsonl"
+
+// FolderSyncSettings 共享目录同步设置，用于 Syncthing、网络驱动器等同步目录
+type FolderSyncSettings struct {
+	Enabled         bool   `json:"enabled"`         // 是否定时自动同步
+	Folder          string `json:"folder"`          // 共享目录
+	IntervalMinutes int    `json:"intervalMinutes"` // 自动同步间隔（分钟）
+}
+
+// FolderSyncDevice 共享目录中的一台设备
+type FolderSyncDevice struct {
+	DeviceID  string `json:"deviceId"`
+	Own       bool   `json:"own"`       // 是否为本机
+	UpdatedAt string `json:"updatedAt"` // 日志文件最后修改时间
+}
+
+// FolderSyncResult 共享目录同步结果
+type FolderSyncResult struct {
+	SyncedAt string             `json:"syncedAt"`
+	Written  int                `json:"written"` // 写入本机日志的修改数
+	Applied  int                `json:"applied"` // 从日志中应用的修改数
+	Devices  []FolderSyncDevice `json:"devices"`
+	Errors   []string           `json:"errors"` // 无法读取的日志文件
+}
+
+// DefaultFolderSyncSettings 默认共享目录同步设置
+func DefaultFolderSyncSettings() FolderSyncSettings {
+	return FolderSyncSettings{
+		Enabled:         false,
+		IntervalMinutes: 5,
+	}
+}
+
+// GetFolderSyncSettings 获取共享目录同步设置，未配置时返回默认值
+func GetFolderSyncSettings() (FolderSyncSettings, error) {
+	config, err := LoadConfig()
+	if err != nil {
+		return FolderSyncSettings{}, err
+	}
+
+	if config.FolderSync == nil {
+		return DefaultFolderSyncSettings(), nil
+	}
+	return *config.FolderSync, nil
+}
+
+// SaveFolderSyncSettings 保存共享目录同步设置
+func SaveFolderSyncSettings(settings FolderSyncSettings) error {
+	if settings.Enabled && settings.Folder == "" {
+		return fmt.Errorf("请先设置共享目录")
+	}
+	if settings.IntervalMinutes <= 0 {
+		return fmt.Errorf("同步间隔必须大于0")
+	}
+	if settings.Folder != "" {
+		info, err := os.Stat(settings.Folder)
+		if err != nil || !info.IsDir() {
+			return fmt.Errorf("共享目录不存在: %s", settings.Folder)
+		}
+	}
+
+	return UpdateConfig(func(config *Config) error {
+		config.FolderSync = &settings
+		return nil
+	})
+}
+
+// SyncFolder 立即与共享目录同步
+func SyncFolder() (*FolderSyncResult, error) {
+	settings, err := GetFolderSyncSettings()
+	if err != nil {
+		return nil, err
+	}
+	if settings.Folder == "" {
+		return nil, fmt.Errorf("请先设置共享目录")
+	}
+	return syncFolder(settings.Folder, time.Now())
+}
+
+// 辅助函数：定时与共享目录同步，启动时会立即执行一次以回放其他设备的日志，供 Scheduler 调用
+func checkFolderSync(ctx context.Context) time.Duration {
+	settings, err := GetFolderSyncSettings()
+	if err != nil {
+		log.Printf("读取共享目录同步设置失败: %v", err)
+		return 10 * time.Minute
+	}
+	interval := time.Duration(settings.IntervalMinutes) * time.Minute
+	if interval <= 0 {
+		interval = 10 * time.Minute
+	}
+
+	if db == nil || !settings.Enabled || settings.Folder == "" {
+		return interval
+	}
+
+	result, err := syncFolder(settings.Folder, time.Now())
+	if err != nil {
+		log.Printf("共享目录同步失败: %v", err)
+		return interval
+	}
+	for _, message := range result.Errors {
+		log.Printf("共享目录同步: %s", message)
+	}
+	if result.Applied > 0 {
+		runtime.EventsEmit(ctx, folderSyncedEvent, result)
+	}
+
+	return interval
+}
+
+// 辅助函数：与共享目录同步
+//
+// 不直接复制数据库文件：每台设备只追加写入自己的操作日志，其他设备按记录的读取位置回放，
+// 同一记录以修改时间较新的为准（与局域网同步规则相同），各设备回放顺序不同结果也一致。
+func syncFolder(folder string, now time.Time) (*FolderSyncResult, error) {
+	if db == nil {
+		return nil, errDatabaseNotReady
+	}
+	folder, err := filepath.Abs(folder)
+	if err != nil {
+		return nil, err
+	}
+	if info, err := os.Stat(folder); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("共享目录不存在: %s", folder)
+	}
+	identity, err := loadLANIdentity()
+	if err != nil {
+		return nil, err
+	}
+
+	lanRowsMu.Lock()
+	defer lanRowsMu.Unlock()
+
+	result := &FolderSyncResult{SyncedAt: now.Format(time.RFC3339), Devices: []FolderSyncDevice{}, Errors: []string{}}
+
+	// 先记录本机的修改，再写入日志
+	if _, err := scanLANSyncRows(identity.deviceID, now); err != nil {
+		return nil, err
+	}
+	written, err := appendFolderSyncLog(folder, identity.deviceID)
+	if err != nil {
+		return nil, err
+	}
+	result.Written = written
+
+	// 回放所有设备的日志，本机日志在清空数据后用于恢复
+	files, err := filepath.Glob(filepath.Join(folder, "*"+folderSyncLogSuffix))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	for _, path := range files {
+		deviceID := strings.TrimSuffix(filepath.Base(path), folderSyncLogSuffix)
+		applied, err := replayFolderSyncLog(folder, deviceID, path)
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", filepath.Base(path), err))
+			continue
+		}
+		result.Applied += applied
+
+		device := FolderSyncDevice{DeviceID: deviceID, Own: deviceID == identity.deviceID}
+		if info, err := os.Stat(path); err == nil {
+			device.UpdatedAt = info.ModTime().Format(time.RFC3339)
+		}
+		result.Devices = append(result.Devices, device)
+	}
+
+	// 回放时应用的修改已在日志中，不再写入本机日志
+	seq, err := maxLANSyncSeq()
+	if err != nil {
+		return nil, err
+	}
+	if err := saveFolderSyncWrittenSeq(folder, identity.deviceID, seq); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// 辅助函数：将上次写入后的修改追加到本机日志，返回写入的记录数
+func appendFolderSyncLog(folder, deviceID string) (int, error) {
+	offset, writtenSeq, err := loadFolderSyncLog(folder, deviceID)
+	if err != nil {
+		return 0, err
+	}
+	rows, err := changedLANSyncRows(writtenSeq)
+	if err != nil {
+		return 0, err
+	}
+	if len(rows) == 0 {
+		return 0, nil
+	}
+
+	var buf bytes.Buffer
+	for _, row := range rows {
+		line, err := json.Marshal(row)
+		if err != nil {
+			return 0, err
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+
+	path := filepath.Join(folder, deviceID+folderSyncLogSuffix)
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return 0, fmt.Errorf("打开操作日志失败: %w", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return 0, err
+	}
+	if _, err := file.Write(buf.Bytes()); err != nil {
+		return 0, fmt.Errorf("写入操作日志失败: %w", err)
+	}
+	if err := file.Sync(); err != nil {
+		return 0, fmt.Errorf("写入操作日志失败: %w", err)
+	}
+
+	// 本机日志已全部读取过时，刚写入的内容无需再回放
+	if offset == info.Size() {
+		offset += int64(buf.Len())
+	}
+	if err := saveFolderSyncLog(folder, deviceID, offset); err != nil {
+		return 0, err
+	}
+	return len(rows), nil
+}
+
+// 辅助函数：从上次读取的位置回放日志，只处理完整的行，返回应用的记录数
+//
+// 同步工具可能只同步了文件的一部分，末尾不完整的一行留到下次读取。
+func replayFolderSyncLog(folder, deviceID, path string) (int, error) {
+	offset, _, err := loadFolderSyncLog(folder, deviceID)
+	if err != nil {
+		return 0, err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return 0, err
+	}
+	// 文件变短说明被替换过，从头读取
+	if info.Size() < offset {
+		offset = 0
+	}
+	if info.Size() == offset {
+		return 0, nil
+	}
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return 0, err
+	}
+
+	rows := []lanSyncRow{}
+	reader := bufio.NewReader(file)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 0, err
+		}
+		offset += int64(len(line))
+
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+		var row lanSyncRow
+		if err := json.Unmarshal(line, &row); err != nil {
+			log.Printf("跳过无法解析的日志行 %s: %v", filepath.Base(path), err)
+			continue
+		}
+		rows = append(rows, row)
+	}
+
+	applied, err := applyLANSyncRows(rows)
+	if err != nil {
+		return 0, err
+	}
+	if err := saveFolderSyncLog(folder, deviceID, offset); err != nil {
+		return 0, err
+	}
+	return applied, nil
+}
+
+// 辅助函数：创建共享目录同步状态表
+func createFolderSyncTables() error {
+	// 记录每个共享目录中各日志的读取位置，本机日志还记录已写入的最大序号
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS folder_sync_logs (
+			folder TEXT,
+			device_id TEXT,
+			read_offset INTEGER DEFAULT 0,
+			written_seq INTEGER DEFAULT 0,
+			PRIMARY KEY (folder, device_id)
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("创建共享目录同步状态表失败: %w", err)
+	}
+	return nil
+}
+
+// 辅助函数：读取日志的读取位置与已写入的序号
+func loadFolderSyncLog(folder, deviceID string) (int64, int64, error) {
+	var offset, seq int64
+	err := db.QueryRow(
+		`SELECT read_offset, written_seq FROM folder_sync_logs WHERE folder = ? AND device_id = ?`,
+		folder, deviceID,
+	).Scan(&offset, &seq)
+	if err == sql.ErrNoRows {
+		return 0, 0, nil
+	}
+	return offset, seq, err
+}
+
+// 辅助函数：保存日志的读取位置
+func saveFolderSyncLog(folder, deviceID string, offset int64) error {
+	_, err := db.Exec(
+		`INSERT INTO folder_sync_logs (folder, device_id, read_offset) VALUES (?, ?, ?)
+		ON CONFLICT(folder, device_id) DO UPDATE SET read_offset = excluded.read_offset`,
+		folder, deviceID, offset,
+	)
+	return err
+}
+
+// 辅助函数：保存本机日志已写入的序号
+func saveFolderSyncWrittenSeq(folder, deviceID string, seq int64) error {
+	_, err := db.Exec(
+		`INSERT INTO folder_sync_logs (folder, device_id, written_seq) VALUES (?, ?, ?)
+		ON CONFLICT(folder, device_id) DO UPDATE SET written_seq = excluded.written_seq`,
+		folder, deviceID, seq,
+	)
+	return err
+}
+
+// 辅助函数：清空数据后从头回放所有日志
+func resetFolderSyncLogs(tx *sql.Tx) error {
+	_, err := tx.Exec(`UPDATE folder_sync_logs SET read_offset = 0`)
+	return err
+}
@@ -4,36 +4,246 @@ import {main} from '../models';
 
 export function AddTask(arg1:main.Task):Promise<void>;
 
+export function ApplyWorkspaceTemplate(arg1:string,arg2:string):Promise<main.AnnualData>;
+
+export function BuildTeamReport(arg1:Array<string>,arg2:string):Promise<main.TeamReport>;
+
+export function CancelUpdate():Promise<void>;
+
+export function ChangeDatabasePassphrase(arg1:string,arg2:string):Promise<void>;
+
 export function CheckUpdate():Promise<main.CheckUpdateResult>;
 
+export function CommitGitHistory():Promise<main.GitHistoryCommitResult>;
+
+export function CreateBackup():Promise<main.BackupResult>;
+
+export function CreateTemplateFromYear(arg1:string,arg2:string):Promise<main.WorkspaceTemplate>;
+
+export function CreateYearFromTemplate(arg1:string,arg2:string):Promise<main.AnnualData>;
+
+export function DeleteAccount(arg1:string,arg2:string):Promise<void>;
+
 export function DeleteAnnualData(arg1:string):Promise<void>;
 
+export function DeleteTag(arg1:string):Promise<void>;
+
 export function DeleteTask(arg1:string):Promise<void>;
 
+export function DeleteWorkspaceTemplate(arg1:string):Promise<void>;
+
+export function DisableDatabaseEncryption(arg1:string):Promise<void>;
+
+export function DiscoverLANPeers():Promise<Array<main.LANPeer>>;
+
+export function DismissWhatsNew():Promise<void>;
+
+export function EnableDatabaseEncryption(arg1:string):Promise<void>;
+
+export function ExportReviewReport(arg1:string,arg2:string):Promise<string>;
+
+export function ExportSpreadsheet(arg1:string,arg2:string):Promise<Array<string>>;
+
+export function ExportTaskwarrior(arg1:string):Promise<string>;
+
+export function ExportTodoTxt(arg1:string):Promise<string>;
+
+export function ExportWorkspaceTemplate(arg1:string):Promise<string>;
+
+export function GenerateDigest(arg1:string,arg2:string):Promise<main.Digest>;
+
 export function GetAccounts():Promise<Array<main.Account>>;
 
 export function GetAllAnnualData():Promise<main.SystemData>;
 
 export function GetAnnualData(arg1:string):Promise<main.AnnualData>;
 
+export function GetAnnualGrade(arg1:string):Promise<main.GradeResult>;
+
+export function GetAutoLockSettings():Promise<main.AutoLockSettings>;
+
 export function GetAvatarAbsolutePath(arg1:string):Promise<string>;
 
+export function GetBackupSettings():Promise<main.BackupSettings>;
+
+export function GetBackupSnapshots():Promise<Array<main.BackupSnapshot>>;
+
+export function GetCalDAVSettings():Promise<main.CalDAVSettings>;
+
+export function GetCalDAVSyncStatuses():Promise<Array<main.CalDAVTaskStatus>>;
+
+export function GetDataDirInfo():Promise<main.DataDirInfo>;
+
+export function GetDatabaseEncryptionStatus():Promise<main.DatabaseEncryptionStatus>;
+
+export function GetDigestSettings():Promise<main.DigestSettings>;
+
+export function GetFolderSyncSettings():Promise<main.FolderSyncSettings>;
+
+export function GetGitHistory(arg1:string,arg2:string,arg3:number):Promise<Array<main.GitHistoryEntry>>;
+
+export function GetGitHistorySettings():Promise<main.GitHistorySettings>;
+
+export function GetLANDeviceInfo():Promise<main.LANDeviceInfo>;
+
+export function GetLANPeers():Promise<Array<main.LANPeer>>;
+
+export function GetLANSyncSettings():Promise<main.LANSyncSettings>;
+
 export function GetLastUsedAccount():Promise<main.Account>;
 
+export function GetMarkdownSyncSettings():Promise<main.MarkdownSyncSettings>;
+
+export function GetPendingReminders():Promise<Array<main.TaskReminder>>;
+
+export function GetReleaseNotesHistory(arg1:boolean):Promise<Array<main.ReleaseNote>>;
+
+export function GetReminderSettings():Promise<main.ReminderSettings>;
+
+export function GetReviewReport(arg1:string):Promise<main.ReviewReport>;
+
+export function GetReviewReportHTML(arg1:string):Promise<string>;
+
+export function GetSessionStatus():Promise<main.SessionStatus>;
+
+export function GetTagStats(arg1:string):Promise<Array<main.TagStats>>;
+
+export function GetTags():Promise<Array<main.Tag>>;
+
+export function GetUpdateInstallState():Promise<main.UpdateInstallState>;
+
+export function GetUpdateSettings():Promise<main.UpdateSettings>;
+
+export function GetWhatsNew():Promise<main.WhatsNew>;
+
+export function GetWorkspaceTemplates():Promise<Array<main.WorkspaceTemplate>>;
+
+export function GetYearTemplateStatuses():Promise<Array<main.YearTemplateStatus>>;
+
 export function Greet(arg1:string):Promise<string>;
 
 export function ImportData(arg1:main.SystemData):Promise<void>;
 
+export function ImportSpreadsheet(arg1:string,arg2:main.ImportOptions):Promise<main.ImportResult>;
+
+export function ImportTaskwarrior(arg1:string,arg2:main.TaskFileImportOptions):Promise<main.ImportResult>;
+
+export function ImportTodoTxt(arg1:string,arg2:main.TaskFileImportOptions):Promise<main.ImportResult>;
+
+export function ImportWorkspaceTemplate():Promise<main.TemplateImportResult>;
+
+export function InstallUpdate():Promise<main.UpdateInstallState>;
+
+export function LockSession():Promise<void>;
+
+export function MergeTags(arg1:Array<string>,arg2:string):Promise<void>;
+
 export function NewAccount(arg1:string,arg2:string):Promise<main.Account>;
 
 export function OpenDownloadURL(arg1:string):Promise<void>;
 
+export function PairLANPeer(arg1:string,arg2:string):Promise<main.LANPeer>;
+
+export function PreviewSpreadsheet(arg1:string,arg2:string):Promise<main.SpreadsheetPreview>;
+
+export function QueryTasks(arg1:main.TaskQuery):Promise<main.TaskQueryResult>;
+
+export function RelocateDataDir(arg1:string):Promise<void>;
+
+export function RemoveLANPeer(arg1:string):Promise<void>;
+
+export function RenameAccount(arg1:string,arg2:string):Promise<void>;
+
+export function RenameTag(arg1:string,arg2:string):Promise<void>;
+
+export function ReplaceAccountAvatar(arg1:string,arg2:string):Promise<main.Account>;
+
 export function ResetAllData():Promise<void>;
 
+export function ResolveCalDAVConflict(arg1:string,arg2:string):Promise<void>;
+
+export function ResolveMarkdownConflict(arg1:string,arg2:string,arg3:string):Promise<void>;
+
+export function RestartToUpdate():Promise<void>;
+
+export function RestoreBackup(arg1:string,arg2:string):Promise<void>;
+
+export function RestoreGitHistory(arg1:string):Promise<main.GitHistoryCommitResult>;
+
 export function SaveAccount(arg1:main.Account):Promise<void>;
 
 export function SaveAnnualData(arg1:main.AnnualData):Promise<void>;
 
-export function SwitchAccount(arg1:string):Promise<void>;
+export function SaveAutoLockSettings(arg1:main.AutoLockSettings):Promise<void>;
+
+export function SaveBackupSettings(arg1:main.BackupSettings):Promise<void>;
+
+export function SaveCalDAVSettings(arg1:main.CalDAVSettings):Promise<void>;
+
+export function SaveDigestSettings(arg1:main.DigestSettings):Promise<void>;
+
+export function SaveFolderSyncSettings(arg1:main.FolderSyncSettings):Promise<void>;
+
+export function SaveGitHistorySettings(arg1:main.GitHistorySettings):Promise<void>;
+
+export function SaveLANSyncSettings(arg1:main.LANSyncSettings):Promise<void>;
+
+export function SaveMarkdownSyncSettings(arg1:main.MarkdownSyncSettings):Promise<void>;
+
+export function SaveReminderSettings(arg1:main.ReminderSettings):Promise<void>;
+
+export function SaveTag(arg1:main.Tag):Promise<main.Tag>;
+
+export function SaveUpdateSettings(arg1:main.UpdateSettings):Promise<void>;
+
+export function SaveWorkspaceTemplate(arg1:main.WorkspaceTemplate):Promise<main.WorkspaceTemplate>;
+
+export function SelectAvatarFile():Promise<string>;
+
+export function SelectDataDir():Promise<string>;
+
+export function SelectGitHistoryDir():Promise<string>;
+
+export function SelectImportFile():Promise<string>;
+
+export function SelectMarkdownVault():Promise<string>;
+
+export function SelectSyncFolder():Promise<string>;
+
+export function SelectTaskFile():Promise<string>;
+
+export function SelectTeamFiles():Promise<Array<string>>;
+
+export function SetAccountLock(arg1:string,arg2:string,arg3:string,arg4:string):Promise<void>;
+
+export function SetTaskTags(arg1:string,arg2:Array<string>):Promise<void>;
+
+export function SkipUpdateVersion(arg1:string):Promise<void>;
+
+export function SnoozeReminder(arg1:string,arg2:number):Promise<void>;
+
+export function StartLANPairing():Promise<main.LANPairingCode>;
+
+export function SwitchAccount(arg1:string,arg2:string,arg3:string):Promise<void>;
+
+export function SyncCalDAV():Promise<main.CalDAVSyncResult>;
+
+export function SyncFolder():Promise<main.FolderSyncResult>;
+
+export function SyncLANPeers():Promise<Array<main.LANPeerSyncResult>>;
+
+export function SyncMarkdownVault():Promise<main.MarkdownSyncResult>;
+
+export function TestBackupConnection(arg1:main.BackupSettings):Promise<number>;
+
+export function TestCalDAVConnection(arg1:main.CalDAVSettings):Promise<string>;
+
+export function TouchSession():Promise<void>;
+
+export function UnlockDatabase(arg1:string):Promise<void>;
+
+export function UnlockSession(arg1:string):Promise<void>;
 
 export function UpdateTask(arg1:main.Task):Promise<void>;
+
+export function WriteDigest(arg1:string,arg2:string):Promise<Array<string>>;
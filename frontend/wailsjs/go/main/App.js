@@ -6,18 +6,102 @@ export function AddTask(arg1) {
   return window['go']['main']['App']['AddTask'](arg1);
 }
 
+export function ApplyWorkspaceTemplate(arg1, arg2) {
+  return window['go']['main']['App']['ApplyWorkspaceTemplate'](arg1, arg2);
+}
+
+export function BuildTeamReport(arg1, arg2) {
+  return window['go']['main']['App']['BuildTeamReport'](arg1, arg2);
+}
+
+export function CancelUpdate() {
+  return window['go']['main']['App']['CancelUpdate']();
+}
+
+export function ChangeDatabasePassphrase(arg1, arg2) {
+  return window['go']['main']['App']['ChangeDatabasePassphrase'](arg1, arg2);
+}
+
 export function CheckUpdate() {
   return window['go']['main']['App']['CheckUpdate']();
 }
 
+export function CommitGitHistory() {
+  return window['go']['main']['App']['CommitGitHistory']();
+}
+
+export function CreateBackup() {
+  return window['go']['main']['App']['CreateBackup']();
+}
+
+export function CreateTemplateFromYear(arg1, arg2) {
+  return window['go']['main']['App']['CreateTemplateFromYear'](arg1, arg2);
+}
+
+export function CreateYearFromTemplate(arg1, arg2) {
+  return window['go']['main']['App']['CreateYearFromTemplate'](arg1, arg2);
+}
+
+export function DeleteAccount(arg1, arg2) {
+  return window['go']['main']['App']['DeleteAccount'](arg1, arg2);
+}
+
 export function DeleteAnnualData(arg1) {
   return window['go']['main']['App']['DeleteAnnualData'](arg1);
 }
 
+export function DeleteTag(arg1) {
+  return window['go']['main']['App']['DeleteTag'](arg1);
+}
+
 export function DeleteTask(arg1) {
   return window['go']['main']['App']['DeleteTask'](arg1);
 }
 
+export function DeleteWorkspaceTemplate(arg1) {
+  return window['go']['main']['App']['DeleteWorkspaceTemplate'](arg1);
+}
+
+export function DisableDatabaseEncryption(arg1) {
+  return window['go']['main']['App']['DisableDatabaseEncryption'](arg1);
+}
+
+export function DiscoverLANPeers() {
+  return window['go']['main']['App']['DiscoverLANPeers']();
+}
+
+export function DismissWhatsNew() {
+  return window['go']['main']['App']['DismissWhatsNew']();
+}
+
+export function EnableDatabaseEncryption(arg1) {
+  return window['go']['main']['App']['EnableDatabaseEncryption'](arg1);
+}
+
+export function ExportReviewReport(arg1, arg2) {
+  return window['go']['main']['App']['ExportReviewReport'](arg1, arg2);
+}
+
+export function ExportSpreadsheet(arg1, arg2) {
+  return window['go']['main']['App']['ExportSpreadsheet'](arg1, arg2);
+}
+
+export function ExportTaskwarrior(arg1) {
+  return window['go']['main']['App']['ExportTaskwarrior'](arg1);
+}
+
+export function ExportTodoTxt(arg1) {
+  return window['go']['main']['App']['ExportTodoTxt'](arg1);
+}
+
+export function ExportWorkspaceTemplate(arg1) {
+  return window['go']['main']['App']['ExportWorkspaceTemplate'](arg1);
+}
+
+export function GenerateDigest(arg1, arg2) {
+  return window['go']['main']['App']['GenerateDigest'](arg1, arg2);
+}
+
 export function GetAccounts() {
   return window['go']['main']['App']['GetAccounts']();
 }
@@ -30,14 +114,130 @@ export function GetAnnualData(arg1) {
   return window['go']['main']['App']['GetAnnualData'](arg1);
 }
 
+export function GetAnnualGrade(arg1) {
+  return window['go']['main']['App']['GetAnnualGrade'](arg1);
+}
+
+export function GetAutoLockSettings() {
+  return window['go']['main']['App']['GetAutoLockSettings']();
+}
+
 export function GetAvatarAbsolutePath(arg1) {
   return window['go']['main']['App']['GetAvatarAbsolutePath'](arg1);
 }
 
+export function GetBackupSettings() {
+  return window['go']['main']['App']['GetBackupSettings']();
+}
+
+export function GetBackupSnapshots() {
+  return window['go']['main']['App']['GetBackupSnapshots']();
+}
+
+export function GetCalDAVSettings() {
+  return window['go']['main']['App']['GetCalDAVSettings']();
+}
+
+export function GetCalDAVSyncStatuses() {
+  return window['go']['main']['App']['GetCalDAVSyncStatuses']();
+}
+
+export function GetDataDirInfo() {
+  return window['go']['main']['App']['GetDataDirInfo']();
+}
+
+export function GetDatabaseEncryptionStatus() {
+  return window['go']['main']['App']['GetDatabaseEncryptionStatus']();
+}
+
+export function GetDigestSettings() {
+  return window['go']['main']['App']['GetDigestSettings']();
+}
+
+export function GetFolderSyncSettings() {
+  return window['go']['main']['App']['GetFolderSyncSettings']();
+}
+
+export function GetGitHistory(arg1, arg2, arg3) {
+  return window['go']['main']['App']['GetGitHistory'](arg1, arg2, arg3);
+}
+
+export function GetGitHistorySettings() {
+  return window['go']['main']['App']['GetGitHistorySettings']();
+}
+
+export function GetLANDeviceInfo() {
+  return window['go']['main']['App']['GetLANDeviceInfo']();
+}
+
+export function GetLANPeers() {
+  return window['go']['main']['App']['GetLANPeers']();
+}
+
+export function GetLANSyncSettings() {
+  return window['go']['main']['App']['GetLANSyncSettings']();
+}
+
 export function GetLastUsedAccount() {
   return window['go']['main']['App']['GetLastUsedAccount']();
 }
 
+export function GetMarkdownSyncSettings() {
+  return window['go']['main']['App']['GetMarkdownSyncSettings']();
+}
+
+export function GetPendingReminders() {
+  return window['go']['main']['App']['GetPendingReminders']();
+}
+
+export function GetReleaseNotesHistory(arg1) {
+  return window['go']['main']['App']['GetReleaseNotesHistory'](arg1);
+}
+
+export function GetReminderSettings() {
+  return window['go']['main']['App']['GetReminderSettings']();
+}
+
+export function GetReviewReport(arg1) {
+  return window['go']['main']['App']['GetReviewReport'](arg1);
+}
+
+export function GetReviewReportHTML(arg1) {
+  return window['go']['main']['App']['GetReviewReportHTML'](arg1);
+}
+
+export function GetSessionStatus() {
+  return window['go']['main']['App']['GetSessionStatus']();
+}
+
+export function GetTagStats(arg1) {
+  return window['go']['main']['App']['GetTagStats'](arg1);
+}
+
+export function GetTags() {
+  return window['go']['main']['App']['GetTags']();
+}
+
+export function GetUpdateInstallState() {
+  return window['go']['main']['App']['GetUpdateInstallState']();
+}
+
+export function GetUpdateSettings() {
+  return window['go']['main']['App']['GetUpdateSettings']();
+}
+
+export function GetWhatsNew() {
+  return window['go']['main']['App']['GetWhatsNew']();
+}
+
+export function GetWorkspaceTemplates() {
+  return window['go']['main']['App']['GetWorkspaceTemplates']();
+}
+
+export function GetYearTemplateStatuses() {
+  return window['go']['main']['App']['GetYearTemplateStatuses']();
+}
+
 export function Greet(arg1) {
   return window['go']['main']['App']['Greet'](arg1);
 }
@@ -46,6 +246,34 @@ export function ImportData(arg1) {
   return window['go']['main']['App']['ImportData'](arg1);
 }
 
+export function ImportSpreadsheet(arg1, arg2) {
+  return window['go']['main']['App']['ImportSpreadsheet'](arg1, arg2);
+}
+
+export function ImportTaskwarrior(arg1, arg2) {
+  return window['go']['main']['App']['ImportTaskwarrior'](arg1, arg2);
+}
+
+export function ImportTodoTxt(arg1, arg2) {
+  return window['go']['main']['App']['ImportTodoTxt'](arg1, arg2);
+}
+
+export function ImportWorkspaceTemplate() {
+  return window['go']['main']['App']['ImportWorkspaceTemplate']();
+}
+
+export function InstallUpdate() {
+  return window['go']['main']['App']['InstallUpdate']();
+}
+
+export function LockSession() {
+  return window['go']['main']['App']['LockSession']();
+}
+
+export function MergeTags(arg1, arg2) {
+  return window['go']['main']['App']['MergeTags'](arg1, arg2);
+}
+
 export function NewAccount(arg1, arg2) {
   return window['go']['main']['App']['NewAccount'](arg1, arg2);
 }
@@ -54,10 +282,62 @@ export function OpenDownloadURL(arg1) {
   return window['go']['main']['App']['OpenDownloadURL'](arg1);
 }
 
+export function PairLANPeer(arg1, arg2) {
+  return window['go']['main']['App']['PairLANPeer'](arg1, arg2);
+}
+
+export function PreviewSpreadsheet(arg1, arg2) {
+  return window['go']['main']['App']['PreviewSpreadsheet'](arg1, arg2);
+}
+
+export function QueryTasks(arg1) {
+  return window['go']['main']['App']['QueryTasks'](arg1);
+}
+
+export function RelocateDataDir(arg1) {
+  return window['go']['main']['App']['RelocateDataDir'](arg1);
+}
+
+export function RemoveLANPeer(arg1) {
+  return window['go']['main']['App']['RemoveLANPeer'](arg1);
+}
+
+export function RenameAccount(arg1, arg2) {
+  return window['go']['main']['App']['RenameAccount'](arg1, arg2);
+}
+
+export function RenameTag(arg1, arg2) {
+  return window['go']['main']['App']['RenameTag'](arg1, arg2);
+}
+
+export function ReplaceAccountAvatar(arg1, arg2) {
+  return window['go']['main']['App']['ReplaceAccountAvatar'](arg1, arg2);
+}
+
 export function ResetAllData() {
   return window['go']['main']['App']['ResetAllData']();
 }
 
+export function ResolveCalDAVConflict(arg1, arg2) {
+  return window['go']['main']['App']['ResolveCalDAVConflict'](arg1, arg2);
+}
+
+export function ResolveMarkdownConflict(arg1, arg2, arg3) {
+  return window['go']['main']['App']['ResolveMarkdownConflict'](arg1, arg2, arg3);
+}
+
+export function RestartToUpdate() {
+  return window['go']['main']['App']['RestartToUpdate']();
+}
+
+export function RestoreBackup(arg1, arg2) {
+  return window['go']['main']['App']['RestoreBackup'](arg1, arg2);
+}
+
+export function RestoreGitHistory(arg1) {
+  return window['go']['main']['App']['RestoreGitHistory'](arg1);
+}
+
 export function SaveAccount(arg1) {
   return window['go']['main']['App']['SaveAccount'](arg1);
 }
@@ -66,10 +346,150 @@ export function SaveAnnualData(arg1) {
   return window['go']['main']['App']['SaveAnnualData'](arg1);
 }
 
-export function SwitchAccount(arg1) {
-  return window['go']['main']['App']['SwitchAccount'](arg1);
+export function SaveAutoLockSettings(arg1) {
+  return window['go']['main']['App']['SaveAutoLockSettings'](arg1);
+}
+
+export function SaveBackupSettings(arg1) {
+  return window['go']['main']['App']['SaveBackupSettings'](arg1);
+}
+
+export function SaveCalDAVSettings(arg1) {
+  return window['go']['main']['App']['SaveCalDAVSettings'](arg1);
+}
+
+export function SaveDigestSettings(arg1) {
+  return window['go']['main']['App']['SaveDigestSettings'](arg1);
+}
+
+export function SaveFolderSyncSettings(arg1) {
+  return window['go']['main']['App']['SaveFolderSyncSettings'](arg1);
+}
+
+export function SaveGitHistorySettings(arg1) {
+  return window['go']['main']['App']['SaveGitHistorySettings'](arg1);
+}
+
+export function SaveLANSyncSettings(arg1) {
+  return window['go']['main']['App']['SaveLANSyncSettings'](arg1);
+}
+
+export function SaveMarkdownSyncSettings(arg1) {
+  return window['go']['main']['App']['SaveMarkdownSyncSettings'](arg1);
+}
+
+export function SaveReminderSettings(arg1) {
+  return window['go']['main']['App']['SaveReminderSettings'](arg1);
+}
+
+export function SaveTag(arg1) {
+  return window['go']['main']['App']['SaveTag'](arg1);
+}
+
+export function SaveUpdateSettings(arg1) {
+  return window['go']['main']['App']['SaveUpdateSettings'](arg1);
+}
+
+export function SaveWorkspaceTemplate(arg1) {
+  return window['go']['main']['App']['SaveWorkspaceTemplate'](arg1);
+}
+
+export function SelectAvatarFile() {
+  return window['go']['main']['App']['SelectAvatarFile']();
+}
+
+export function SelectDataDir() {
+  return window['go']['main']['App']['SelectDataDir']();
+}
+
+export function SelectGitHistoryDir() {
+  return window['go']['main']['App']['SelectGitHistoryDir']();
+}
+
+export function SelectImportFile() {
+  return window['go']['main']['App']['SelectImportFile']();
+}
+
+export function SelectMarkdownVault() {
+  return window['go']['main']['App']['SelectMarkdownVault']();
+}
+
+export function SelectSyncFolder() {
+  return window['go']['main']['App']['SelectSyncFolder']();
+}
+
+export function SelectTaskFile() {
+  return window['go']['main']['App']['SelectTaskFile']();
+}
+
+export function SelectTeamFiles() {
+  return window['go']['main']['App']['SelectTeamFiles']();
+}
+
+export function SetAccountLock(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['SetAccountLock'](arg1, arg2, arg3, arg4);
+}
+
+export function SetTaskTags(arg1, arg2) {
+  return window['go']['main']['App']['SetTaskTags'](arg1, arg2);
+}
+
+export function SkipUpdateVersion(arg1) {
+  return window['go']['main']['App']['SkipUpdateVersion'](arg1);
+}
+
+export function SnoozeReminder(arg1, arg2) {
+  return window['go']['main']['App']['SnoozeReminder'](arg1, arg2);
+}
+
+export function StartLANPairing() {
+  return window['go']['main']['App']['StartLANPairing']();
+}
+
+export function SwitchAccount(arg1, arg2, arg3) {
+  return window['go']['main']['App']['SwitchAccount'](arg1, arg2, arg3);
+}
+
+export function SyncCalDAV() {
+  return window['go']['main']['App']['SyncCalDAV']();
+}
+
+export function SyncFolder() {
+  return window['go']['main']['App']['SyncFolder']();
+}
+
+export function SyncLANPeers() {
+  return window['go']['main']['App']['SyncLANPeers']();
+}
+
+export function SyncMarkdownVault() {
+  return window['go']['main']['App']['SyncMarkdownVault']();
+}
+
+export function TestBackupConnection(arg1) {
+  return window['go']['main']['App']['TestBackupConnection'](arg1);
+}
+
+export function TestCalDAVConnection(arg1) {
+  return window['go']['main']['App']['TestCalDAVConnection'](arg1);
+}
+
+export function TouchSession() {
+  return window['go']['main']['App']['TouchSession']();
+}
+
+export function UnlockDatabase(arg1) {
+  return window['go']['main']['App']['UnlockDatabase'](arg1);
+}
+
+export function UnlockSession(arg1) {
+  return window['go']['main']['App']['UnlockSession'](arg1);
 }
 
 export function UpdateTask(arg1) {
   return window['go']['main']['App']['UpdateTask'](arg1);
 }
+
+export function WriteDigest(arg1, arg2) {
+  return window['go']['main']['App']['WriteDigest'](arg1, arg2);
+}
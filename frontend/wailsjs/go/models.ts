@@ -4,6 +4,9 @@ export namespace main {
 	    id: string;
 	    username: string;
 	    avatarPath: string;
+	    thumbnailPath?: string;
+	    lockType?: string;
+	    lockHash?: string;
 	
 	    static createFrom(source: any = {}) {
 	        return new Account(source);
@@ -14,6 +17,9 @@ export namespace main {
 	        this.id = source["id"];
 	        this.username = source["username"];
 	        this.avatarPath = source["avatarPath"];
+	        this.thumbnailPath = source["thumbnailPath"];
+	        this.lockType = source["lockType"];
+	        this.lockHash = source["lockHash"];
 	    }
 	}
 	export class DimensionSettings {
@@ -55,6 +61,7 @@ export namespace main {
 	    priority: string;
 	    startDate?: string;
 	    endDate?: string;
+	    tags?: string[];
 	
 	    static createFrom(source: any = {}) {
 	        return new Task(source);
@@ -70,6 +77,7 @@ export namespace main {
 	        this.priority = source["priority"];
 	        this.startDate = source["startDate"];
 	        this.endDate = source["endDate"];
+	        this.tags = source["tags"];
 	    }
 	}
 	export class DimensionData {
@@ -136,11 +144,28 @@ export namespace main {
 	        this.isDefault = source["isDefault"];
 	    }
 	}
+	export class GradeRule {
+	    grade: string;
+	    minScore: number;
+	    description: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new GradeRule(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.grade = source["grade"];
+	        this.minScore = source["minScore"];
+	        this.description = source["description"];
+	    }
+	}
 	export class ScoringSettings {
 	    completedScore: number;
 	    inProgressScore: number;
 	    notStartedScore: number;
 	    dimensionWeights?: Record<string, number>;
+	    gradeRules?: GradeRule[];
 	
 	    static createFrom(source: any = {}) {
 	        return new ScoringSettings(source);
@@ -152,7 +177,26 @@ export namespace main {
 	        this.inProgressScore = source["inProgressScore"];
 	        this.notStartedScore = source["notStartedScore"];
 	        this.dimensionWeights = source["dimensionWeights"];
+	        this.gradeRules = this.convertValues(source["gradeRules"], GradeRule);
 	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
 	}
 	export class AnnualSettings {
 	    scoring: ScoringSettings;
@@ -223,12 +267,270 @@ export namespace main {
 		}
 	}
 	
+	export class AutoLockSettings {
+	    enabled: boolean;
+	    idleMinutes: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new AutoLockSettings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.enabled = source["enabled"];
+	        this.idleMinutes = source["idleMinutes"];
+	    }
+	}
+	export class BackupSnapshot {
+	    name: string;
+	    size: number;
+	    createdAt: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new BackupSnapshot(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.size = source["size"];
+	        this.createdAt = source["createdAt"];
+	    }
+	}
+	export class BackupResult {
+	    snapshot: BackupSnapshot;
+	    removed: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new BackupResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.snapshot = this.convertValues(source["snapshot"], BackupSnapshot);
+	        this.removed = source["removed"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class S3Settings {
+	    endpoint: string;
+	    region: string;
+	    bucket: string;
+	    prefix: string;
+	    accessKeyId: string;
+	    secretAccessKey: string;
+	    useKeyring: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new S3Settings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.endpoint = source["endpoint"];
+	        this.region = source["region"];
+	        this.bucket = source["bucket"];
+	        this.prefix = source["prefix"];
+	        this.accessKeyId = source["accessKeyId"];
+	        this.secretAccessKey = source["secretAccessKey"];
+	        this.useKeyring = source["useKeyring"];
+	    }
+	}
+	export class WebDAVSettings {
+	    url: string;
+	    username: string;
+	    password: string;
+	    useKeyring: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new WebDAVSettings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.url = source["url"];
+	        this.username = source["username"];
+	        this.password = source["password"];
+	        this.useKeyring = source["useKeyring"];
+	    }
+	}
+	export class BackupSettings {
+	    enabled: boolean;
+	    target: string;
+	    webdav: WebDAVSettings;
+	    s3: S3Settings;
+	    passphrase: string;
+	    useKeyring: boolean;
+	    intervalHours: number;
+	    keepCount: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new BackupSettings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.enabled = source["enabled"];
+	        this.target = source["target"];
+	        this.webdav = this.convertValues(source["webdav"], WebDAVSettings);
+	        this.s3 = this.convertValues(source["s3"], S3Settings);
+	        this.passphrase = source["passphrase"];
+	        this.useKeyring = source["useKeyring"];
+	        this.intervalHours = source["intervalHours"];
+	        this.keepCount = source["keepCount"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	export class CalDAVSettings {
+	    enabled: boolean;
+	    calendarUrl: string;
+	    username: string;
+	    password: string;
+	    useKeyring: boolean;
+	    conflictPolicy: string;
+	    defaultDimension: string;
+	    intervalMinutes: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new CalDAVSettings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.enabled = source["enabled"];
+	        this.calendarUrl = source["calendarUrl"];
+	        this.username = source["username"];
+	        this.password = source["password"];
+	        this.useKeyring = source["useKeyring"];
+	        this.conflictPolicy = source["conflictPolicy"];
+	        this.defaultDimension = source["defaultDimension"];
+	        this.intervalMinutes = source["intervalMinutes"];
+	    }
+	}
+	export class CalDAVSyncError {
+	    taskId: string;
+	    message: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new CalDAVSyncError(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.taskId = source["taskId"];
+	        this.message = source["message"];
+	    }
+	}
+	export class CalDAVSyncResult {
+	    syncedAt: string;
+	    uploaded: number;
+	    downloaded: number;
+	    deletedLocal: number;
+	    deletedRemote: number;
+	    conflicts: string[];
+	    errors: CalDAVSyncError[];
+	
+	    static createFrom(source: any = {}) {
+	        return new CalDAVSyncResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.syncedAt = source["syncedAt"];
+	        this.uploaded = source["uploaded"];
+	        this.downloaded = source["downloaded"];
+	        this.deletedLocal = source["deletedLocal"];
+	        this.deletedRemote = source["deletedRemote"];
+	        this.conflicts = source["conflicts"];
+	        this.errors = this.convertValues(source["errors"], CalDAVSyncError);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class CalDAVTaskStatus {
+	    taskId: string;
+	    status: string;
+	    href: string;
+	    etag: string;
+	    message: string;
+	    syncedAt: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new CalDAVTaskStatus(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.taskId = source["taskId"];
+	        this.status = source["status"];
+	        this.href = source["href"];
+	        this.etag = source["etag"];
+	        this.message = source["message"];
+	        this.syncedAt = source["syncedAt"];
+	    }
+	}
 	export class CheckUpdateResult {
+	    status: string;
 	    updateAvailable: boolean;
 	    currentVersion: string;
 	    latestVersion: string;
+	    prerelease: boolean;
 	    releaseNotes: string;
 	    downloadURL: string;
+	    channel: string;
+	    checkedAt: string;
+	    error?: string;
 	
 	    static createFrom(source: any = {}) {
 	        return new CheckUpdateResult(source);
@@ -236,17 +538,1457 @@ export namespace main {
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.status = source["status"];
 	        this.updateAvailable = source["updateAvailable"];
 	        this.currentVersion = source["currentVersion"];
 	        this.latestVersion = source["latestVersion"];
+	        this.prerelease = source["prerelease"];
 	        this.releaseNotes = source["releaseNotes"];
 	        this.downloadURL = source["downloadURL"];
+	        this.channel = source["channel"];
+	        this.checkedAt = source["checkedAt"];
+	        this.error = source["error"];
+	    }
+	}
+	export class DataDirInfo {
+	    path: string;
+	    source: string;
+	    relocatable: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new DataDirInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.source = source["source"];
+	        this.relocatable = source["relocatable"];
+	    }
+	}
+	export class DatabaseEncryptionStatus {
+	    encrypted: boolean;
+	    locked: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new DatabaseEncryptionStatus(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.encrypted = source["encrypted"];
+	        this.locked = source["locked"];
+	    }
+	}
+	export class DimensionScoreChange {
+	    year: string;
+	    dimensionKey: string;
+	    dimensionTitle: string;
+	    previousScore: number;
+	    currentScore: number;
+	    delta: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new DimensionScoreChange(source);
 	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.year = source["year"];
+	        this.dimensionKey = source["dimensionKey"];
+	        this.dimensionTitle = source["dimensionTitle"];
+	        this.previousScore = source["previousScore"];
+	        this.currentScore = source["currentScore"];
+	        this.delta = source["delta"];
+	    }
+	}
+	export class DigestTask {
+	    task: Task;
+	    year: string;
+	    dimensionKey: string;
+	    dimensionTitle: string;
+	    month: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new DigestTask(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.task = this.convertValues(source["task"], Task);
+	        this.year = source["year"];
+	        this.dimensionKey = source["dimensionKey"];
+	        this.dimensionTitle = source["dimensionTitle"];
+	        this.month = source["month"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
 	}
+	export class Digest {
+	    kind: string;
+	    from: string;
+	    to: string;
+	    generatedAt: string;
+	    completedTasks: DigestTask[];
+	    newlyOverdue: DigestTask[];
+	    upcomingDeadlines: DigestTask[];
+	    scoreChanges: DimensionScoreChange[];
+	    markdown: string;
+	    html: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Digest(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.kind = source["kind"];
+	        this.from = source["from"];
+	        this.to = source["to"];
+	        this.generatedAt = source["generatedAt"];
+	        this.completedTasks = this.convertValues(source["completedTasks"], DigestTask);
+	        this.newlyOverdue = this.convertValues(source["newlyOverdue"], DigestTask);
+	        this.upcomingDeadlines = this.convertValues(source["upcomingDeadlines"], DigestTask);
+	        this.scoreChanges = this.convertValues(source["scoreChanges"], DimensionScoreChange);
+	        this.markdown = source["markdown"];
+	        this.html = source["html"];
+	    }
 	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class DigestSettings {
+	    enabled: boolean;
+	    outputDir: string;
+	    daily: boolean;
+	    weekly: boolean;
+	    weeklyDay: number;
+	    hour: number;
+	    formats: string[];
+	    upcomingDays: number;
 	
+	    static createFrom(source: any = {}) {
+	        return new DigestSettings(source);
+	    }
 	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.enabled = source["enabled"];
+	        this.outputDir = source["outputDir"];
+	        this.daily = source["daily"];
+	        this.weekly = source["weekly"];
+	        this.weeklyDay = source["weeklyDay"];
+	        this.hour = source["hour"];
+	        this.formats = source["formats"];
+	        this.upcomingDays = source["upcomingDays"];
+	    }
+	}
 	
+	
+	
+	
+	
+	export class FolderSyncDevice {
+	    deviceId: string;
+	    own: boolean;
+	    updatedAt: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new FolderSyncDevice(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.deviceId = source["deviceId"];
+	        this.own = source["own"];
+	        this.updatedAt = source["updatedAt"];
+	    }
+	}
+	export class FolderSyncResult {
+	    syncedAt: string;
+	    written: number;
+	    applied: number;
+	    devices: FolderSyncDevice[];
+	    errors: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new FolderSyncResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.syncedAt = source["syncedAt"];
+	        this.written = source["written"];
+	        this.applied = source["applied"];
+	        this.devices = this.convertValues(source["devices"], FolderSyncDevice);
+	        this.errors = source["errors"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class FolderSyncSettings {
+	    enabled: boolean;
+	    folder: string;
+	    intervalMinutes: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new FolderSyncSettings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.enabled = source["enabled"];
+	        this.folder = source["folder"];
+	        this.intervalMinutes = source["intervalMinutes"];
+	    }
+	}
+	export class GitHistoryCommitResult {
+	    commit: string;
+	    message: string;
+	    files: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new GitHistoryCommitResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.commit = source["commit"];
+	        this.message = source["message"];
+	        this.files = source["files"];
+	    }
+	}
+	export class GitHistoryEntry {
+	    commit: string;
+	    shortCommit: string;
+	    message: string;
+	    committedAt: string;
+	    files: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new GitHistoryEntry(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.commit = source["commit"];
+	        this.shortCommit = source["shortCommit"];
+	        this.message = source["message"];
+	        this.committedAt = source["committedAt"];
+	        this.files = source["files"];
+	    }
+	}
+	export class GitHistorySettings {
+	    enabled: boolean;
+	    repoDir: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new GitHistorySettings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.enabled = source["enabled"];
+	        this.repoDir = source["repoDir"];
+	    }
+	}
+	export class GradeResult {
+	    grade: string;
+	    score: number;
+	    description: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new GradeResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.grade = source["grade"];
+	        this.score = source["score"];
+	        this.description = source["description"];
+	    }
+	}
+	
+	export class ImportColumnMapping {
+	    year: string;
+	    dimension: string;
+	    month: string;
+	    id: string;
+	    title: string;
+	    description: string;
+	    status: string;
+	    priority: string;
+	    score: string;
+	    startDate: string;
+	    endDate: string;
+	    tags: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ImportColumnMapping(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.year = source["year"];
+	        this.dimension = source["dimension"];
+	        this.month = source["month"];
+	        this.id = source["id"];
+	        this.title = source["title"];
+	        this.description = source["description"];
+	        this.status = source["status"];
+	        this.priority = source["priority"];
+	        this.score = source["score"];
+	        this.startDate = source["startDate"];
+	        this.endDate = source["endDate"];
+	        this.tags = source["tags"];
+	    }
+	}
+	export class ImportOptions {
+	    sheet: string;
+	    mapping: ImportColumnMapping;
+	    defaultYear: string;
+	    dryRun: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new ImportOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.sheet = source["sheet"];
+	        this.mapping = this.convertValues(source["mapping"], ImportColumnMapping);
+	        this.defaultYear = source["defaultYear"];
+	        this.dryRun = source["dryRun"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class TaskRow {
+	    year: string;
+	    dimensionTitle: string;
+	    dimensionKey: string;
+	    month: number;
+	    taskId: string;
+	    title: string;
+	    description: string;
+	    status: string;
+	    priority: string;
+	    score: number;
+	    startDate: string;
+	    endDate: string;
+	    tags: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new TaskRow(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.year = source["year"];
+	        this.dimensionTitle = source["dimensionTitle"];
+	        this.dimensionKey = source["dimensionKey"];
+	        this.month = source["month"];
+	        this.taskId = source["taskId"];
+	        this.title = source["title"];
+	        this.description = source["description"];
+	        this.status = source["status"];
+	        this.priority = source["priority"];
+	        this.score = source["score"];
+	        this.startDate = source["startDate"];
+	        this.endDate = source["endDate"];
+	        this.tags = source["tags"];
+	    }
+	}
+	export class ImportRowError {
+	    row: number;
+	    column: string;
+	    value: string;
+	    message: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ImportRowError(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.row = source["row"];
+	        this.column = source["column"];
+	        this.value = source["value"];
+	        this.message = source["message"];
+	    }
+	}
+	export class ImportResult {
+	    totalRows: number;
+	    validRows: number;
+	    created: number;
+	    updated: number;
+	    imported: boolean;
+	    errors: ImportRowError[];
+	    tasks: TaskRow[];
+	
+	    static createFrom(source: any = {}) {
+	        return new ImportResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.totalRows = source["totalRows"];
+	        this.validRows = source["validRows"];
+	        this.created = source["created"];
+	        this.updated = source["updated"];
+	        this.imported = source["imported"];
+	        this.errors = this.convertValues(source["errors"], ImportRowError);
+	        this.tasks = this.convertValues(source["tasks"], TaskRow);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	export class LANDeviceInfo {
+	    deviceId: string;
+	    deviceName: string;
+	    fingerprint: string;
+	    port: number;
+	    running: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new LANDeviceInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.deviceId = source["deviceId"];
+	        this.deviceName = source["deviceName"];
+	        this.fingerprint = source["fingerprint"];
+	        this.port = source["port"];
+	        this.running = source["running"];
+	    }
+	}
+	export class LANPairingCode {
+	    code: string;
+	    expiresAt: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new LANPairingCode(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.code = source["code"];
+	        this.expiresAt = source["expiresAt"];
+	    }
+	}
+	export class LANPeer {
+	    deviceId: string;
+	    name: string;
+	    fingerprint: string;
+	    address: string;
+	    paired: boolean;
+	    lastSyncAt?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new LANPeer(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.deviceId = source["deviceId"];
+	        this.name = source["name"];
+	        this.fingerprint = source["fingerprint"];
+	        this.address = source["address"];
+	        this.paired = source["paired"];
+	        this.lastSyncAt = source["lastSyncAt"];
+	    }
+	}
+	export class LANPeerSyncResult {
+	    deviceId: string;
+	    name: string;
+	    sent: number;
+	    received: number;
+	    error?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new LANPeerSyncResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.deviceId = source["deviceId"];
+	        this.name = source["name"];
+	        this.sent = source["sent"];
+	        this.received = source["received"];
+	        this.error = source["error"];
+	    }
+	}
+	export class LANSyncSettings {
+	    enabled: boolean;
+	    deviceName: string;
+	    port: number;
+	    intervalMinutes: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new LANSyncSettings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.enabled = source["enabled"];
+	        this.deviceName = source["deviceName"];
+	        this.port = source["port"];
+	        this.intervalMinutes = source["intervalMinutes"];
+	    }
+	}
+	export class MarkdownConflict {
+	    year: string;
+	    dimensionKey: string;
+	    path: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new MarkdownConflict(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.year = source["year"];
+	        this.dimensionKey = source["dimensionKey"];
+	        this.path = source["path"];
+	    }
+	}
+	export class MarkdownSyncError {
+	    path: string;
+	    message: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new MarkdownSyncError(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.message = source["message"];
+	    }
+	}
+	export class MarkdownSyncResult {
+	    syncedAt: string;
+	    written: string[];
+	    imported: string[];
+	    unchanged: number;
+	    conflicts: MarkdownConflict[];
+	    errors: MarkdownSyncError[];
+	
+	    static createFrom(source: any = {}) {
+	        return new MarkdownSyncResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.syncedAt = source["syncedAt"];
+	        this.written = source["written"];
+	        this.imported = source["imported"];
+	        this.unchanged = source["unchanged"];
+	        this.conflicts = this.convertValues(source["conflicts"], MarkdownConflict);
+	        this.errors = this.convertValues(source["errors"], MarkdownSyncError);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class MarkdownSyncSettings {
+	    enabled: boolean;
+	    vaultDir: string;
+	    folder: string;
+	    intervalMinutes: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new MarkdownSyncSettings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.enabled = source["enabled"];
+	        this.vaultDir = source["vaultDir"];
+	        this.folder = source["folder"];
+	        this.intervalMinutes = source["intervalMinutes"];
+	    }
+	}
+	export class ReleaseNote {
+	    version: string;
+	    notes: string;
+	    url: string;
+	    publishedAt: string;
+	    prerelease: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new ReleaseNote(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.version = source["version"];
+	        this.notes = source["notes"];
+	        this.url = source["url"];
+	        this.publishedAt = source["publishedAt"];
+	        this.prerelease = source["prerelease"];
+	    }
+	}
+	export class ReminderSettings {
+	    enabled: boolean;
+	    leadDays: number[];
+	    notifyOverdue: boolean;
+	    nativeNotifications: boolean;
+	    checkIntervalMinutes: number;
+	    quietDimensions: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new ReminderSettings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.enabled = source["enabled"];
+	        this.leadDays = source["leadDays"];
+	        this.notifyOverdue = source["notifyOverdue"];
+	        this.nativeNotifications = source["nativeNotifications"];
+	        this.checkIntervalMinutes = source["checkIntervalMinutes"];
+	        this.quietDimensions = source["quietDimensions"];
+	    }
+	}
+	export class ReviewDimension {
+	    key: string;
+	    title: string;
+	    color: string;
+	    weight: number;
+	    score: number;
+	    completedTasks: number;
+	    totalTasks: number;
+	    completionRate: number;
+	    annualGoal: string;
+	    quarterlyGoals: string[];
+	    monthlyCompleted: number[];
+	    monthlyTotal: number[];
+	    completedByMonth: Task[][];
+	
+	    static createFrom(source: any = {}) {
+	        return new ReviewDimension(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.key = source["key"];
+	        this.title = source["title"];
+	        this.color = source["color"];
+	        this.weight = source["weight"];
+	        this.score = source["score"];
+	        this.completedTasks = source["completedTasks"];
+	        this.totalTasks = source["totalTasks"];
+	        this.completionRate = source["completionRate"];
+	        this.annualGoal = source["annualGoal"];
+	        this.quarterlyGoals = source["quarterlyGoals"];
+	        this.monthlyCompleted = source["monthlyCompleted"];
+	        this.monthlyTotal = source["monthlyTotal"];
+	        this.completedByMonth = this.convertValues(source["completedByMonth"], Task);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ReviewReport {
+	    year: string;
+	    generatedAt: string;
+	    totalScore: number;
+	    grade: GradeResult;
+	    completedTasks: number;
+	    totalTasks: number;
+	    dimensions: ReviewDimension[];
+	    monthlyCompleted: number[];
+	
+	    static createFrom(source: any = {}) {
+	        return new ReviewReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.year = source["year"];
+	        this.generatedAt = source["generatedAt"];
+	        this.totalScore = source["totalScore"];
+	        this.grade = this.convertValues(source["grade"], GradeResult);
+	        this.completedTasks = source["completedTasks"];
+	        this.totalTasks = source["totalTasks"];
+	        this.dimensions = this.convertValues(source["dimensions"], ReviewDimension);
+	        this.monthlyCompleted = source["monthlyCompleted"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	
+	export class SessionStatus {
+	    accountId: string;
+	    lockType: string;
+	    locked: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new SessionStatus(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.accountId = source["accountId"];
+	        this.lockType = source["lockType"];
+	        this.locked = source["locked"];
+	    }
+	}
+	export class SpreadsheetPreview {
+	    sheets: string[];
+	    headers: string[];
+	    sampleRows: string[][];
+	    totalRows: number;
+	    defaultMapping: ImportColumnMapping;
+	
+	    static createFrom(source: any = {}) {
+	        return new SpreadsheetPreview(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.sheets = source["sheets"];
+	        this.headers = source["headers"];
+	        this.sampleRows = source["sampleRows"];
+	        this.totalRows = source["totalRows"];
+	        this.defaultMapping = this.convertValues(source["defaultMapping"], ImportColumnMapping);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Tag {
+	    id: string;
+	    name: string;
+	    color: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Tag(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.color = source["color"];
+	    }
+	}
+	export class TagStats {
+	    tag: Tag;
+	    totalTasks: number;
+	    completedTasks: number;
+	    completionRate: number;
+	    totalScore: number;
+	    averageScore: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new TagStats(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.tag = this.convertValues(source["tag"], Tag);
+	        this.totalTasks = source["totalTasks"];
+	        this.completedTasks = source["completedTasks"];
+	        this.completionRate = source["completionRate"];
+	        this.totalScore = source["totalScore"];
+	        this.averageScore = source["averageScore"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	export class TaskFileImportOptions {
+	    defaultYear: string;
+	    dryRun: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new TaskFileImportOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.defaultYear = source["defaultYear"];
+	        this.dryRun = source["dryRun"];
+	    }
+	}
+	export class TaskQuery {
+	    taskIds?: string[];
+	    statuses?: string[];
+	    priorities?: string[];
+	    years?: string[];
+	    dimensionKeys?: string[];
+	    months?: number[];
+	    tags?: string[];
+	    keyword?: string;
+	    startFrom?: string;
+	    startTo?: string;
+	    dueFrom?: string;
+	    dueTo?: string;
+	    overdue?: boolean;
+	    sortBy?: string;
+	    sortDesc?: boolean;
+	    page?: number;
+	    pageSize?: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new TaskQuery(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.taskIds = source["taskIds"];
+	        this.statuses = source["statuses"];
+	        this.priorities = source["priorities"];
+	        this.years = source["years"];
+	        this.dimensionKeys = source["dimensionKeys"];
+	        this.months = source["months"];
+	        this.tags = source["tags"];
+	        this.keyword = source["keyword"];
+	        this.startFrom = source["startFrom"];
+	        this.startTo = source["startTo"];
+	        this.dueFrom = source["dueFrom"];
+	        this.dueTo = source["dueTo"];
+	        this.overdue = source["overdue"];
+	        this.sortBy = source["sortBy"];
+	        this.sortDesc = source["sortDesc"];
+	        this.page = source["page"];
+	        this.pageSize = source["pageSize"];
+	    }
+	}
+	export class TaskQueryItem {
+	    task: Task;
+	    year: string;
+	    dimensionKey: string;
+	    month: number;
+	    overdue: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new TaskQueryItem(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.task = this.convertValues(source["task"], Task);
+	        this.year = source["year"];
+	        this.dimensionKey = source["dimensionKey"];
+	        this.month = source["month"];
+	        this.overdue = source["overdue"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class TaskQueryResult {
+	    items: TaskQueryItem[];
+	    total: number;
+	    page: number;
+	    pageSize: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new TaskQueryResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.items = this.convertValues(source["items"], TaskQueryItem);
+	        this.total = source["total"];
+	        this.page = source["page"];
+	        this.pageSize = source["pageSize"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class TaskReminder {
+	    task: Task;
+	    year: string;
+	    dimensionKey: string;
+	    kind: string;
+	    daysLeft: number;
+	    message: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new TaskReminder(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.task = this.convertValues(source["task"], Task);
+	        this.year = source["year"];
+	        this.dimensionKey = source["dimensionKey"];
+	        this.kind = source["kind"];
+	        this.daysLeft = source["daysLeft"];
+	        this.message = source["message"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	export class TeamDimension {
+	    key: string;
+	    title: string;
+	    color: string;
+	    memberCount: number;
+	    averageScore: number;
+	    completedTasks: number;
+	    totalTasks: number;
+	    completionRate: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new TeamDimension(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.key = source["key"];
+	        this.title = source["title"];
+	        this.color = source["color"];
+	        this.memberCount = source["memberCount"];
+	        this.averageScore = source["averageScore"];
+	        this.completedTasks = source["completedTasks"];
+	        this.totalTasks = source["totalTasks"];
+	        this.completionRate = source["completionRate"];
+	    }
+	}
+	export class TeamGradeCount {
+	    grade: string;
+	    count: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new TeamGradeCount(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.grade = source["grade"];
+	        this.count = source["count"];
+	    }
+	}
+	export class TeamMemberDimension {
+	    key: string;
+	    score: number;
+	    completedTasks: number;
+	    totalTasks: number;
+	    completionRate: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new TeamMemberDimension(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.key = source["key"];
+	        this.score = source["score"];
+	        this.completedTasks = source["completedTasks"];
+	        this.totalTasks = source["totalTasks"];
+	        this.completionRate = source["completionRate"];
+	    }
+	}
+	export class TeamMember {
+	    name: string;
+	    file: string;
+	    totalScore: number;
+	    grade: GradeResult;
+	    completedTasks: number;
+	    totalTasks: number;
+	    completionRate: number;
+	    dimensions: TeamMemberDimension[];
+	
+	    static createFrom(source: any = {}) {
+	        return new TeamMember(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.file = source["file"];
+	        this.totalScore = source["totalScore"];
+	        this.grade = this.convertValues(source["grade"], GradeResult);
+	        this.completedTasks = source["completedTasks"];
+	        this.totalTasks = source["totalTasks"];
+	        this.completionRate = source["completionRate"];
+	        this.dimensions = this.convertValues(source["dimensions"], TeamMemberDimension);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	export class TeamSkippedFile {
+	    file: string;
+	    reason: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new TeamSkippedFile(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.file = source["file"];
+	        this.reason = source["reason"];
+	    }
+	}
+	export class TeamReport {
+	    year: string;
+	    years: string[];
+	    generatedAt: string;
+	    members: TeamMember[];
+	    dimensions: TeamDimension[];
+	    averageScore: number;
+	    completedTasks: number;
+	    totalTasks: number;
+	    completionRate: number;
+	    gradeDistribution: TeamGradeCount[];
+	    skipped: TeamSkippedFile[];
+	
+	    static createFrom(source: any = {}) {
+	        return new TeamReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.year = source["year"];
+	        this.years = source["years"];
+	        this.generatedAt = source["generatedAt"];
+	        this.members = this.convertValues(source["members"], TeamMember);
+	        this.dimensions = this.convertValues(source["dimensions"], TeamDimension);
+	        this.averageScore = source["averageScore"];
+	        this.completedTasks = source["completedTasks"];
+	        this.totalTasks = source["totalTasks"];
+	        this.completionRate = source["completionRate"];
+	        this.gradeDistribution = this.convertValues(source["gradeDistribution"], TeamGradeCount);
+	        this.skipped = this.convertValues(source["skipped"], TeamSkippedFile);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	export class WorkspaceTemplate {
+	    id: string;
+	    name: string;
+	    description: string;
+	    version: number;
+	    dimensionConfigs: DimensionConfig[];
+	    scoring: ScoringSettings;
+	    quarterlyGoals?: Record<string, Array<string>>;
+	    updatedAt: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new WorkspaceTemplate(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.description = source["description"];
+	        this.version = source["version"];
+	        this.dimensionConfigs = this.convertValues(source["dimensionConfigs"], DimensionConfig);
+	        this.scoring = this.convertValues(source["scoring"], ScoringSettings);
+	        this.quarterlyGoals = source["quarterlyGoals"];
+	        this.updatedAt = source["updatedAt"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class TemplateImportResult {
+	    template: WorkspaceTemplate;
+	    status: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new TemplateImportResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.template = this.convertValues(source["template"], WorkspaceTemplate);
+	        this.status = source["status"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class UpdateInstallState {
+	    status: string;
+	    version: string;
+	    stagedPath: string;
+	    executable?: string;
+	    backupPath?: string;
+	    updatedAt: string;
+	    error?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new UpdateInstallState(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.status = source["status"];
+	        this.version = source["version"];
+	        this.stagedPath = source["stagedPath"];
+	        this.executable = source["executable"];
+	        this.backupPath = source["backupPath"];
+	        this.updatedAt = source["updatedAt"];
+	        this.error = source["error"];
+	    }
+	}
+	export class UpdateSettings {
+	    enabled: boolean;
+	    sourceURL: string;
+	    channel: string;
+	    constraint: string;
+	    intervalHours: number;
+	    skippedVersion: string;
+	    lastCheckedAt: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new UpdateSettings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.enabled = source["enabled"];
+	        this.sourceURL = source["sourceURL"];
+	        this.channel = source["channel"];
+	        this.constraint = source["constraint"];
+	        this.intervalHours = source["intervalHours"];
+	        this.skippedVersion = source["skippedVersion"];
+	        this.lastCheckedAt = source["lastCheckedAt"];
+	    }
+	}
+	
+	export class WhatsNew {
+	    fromVersion: string;
+	    toVersion: string;
+	    upgradedAt: string;
+	    seen: boolean;
+	    releases: ReleaseNote[];
+	    missing: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new WhatsNew(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.fromVersion = source["fromVersion"];
+	        this.toVersion = source["toVersion"];
+	        this.upgradedAt = source["upgradedAt"];
+	        this.seen = source["seen"];
+	        this.releases = this.convertValues(source["releases"], ReleaseNote);
+	        this.missing = source["missing"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	export class YearTemplateStatus {
+	    year: string;
+	    templateId: string;
+	    templateName: string;
+	    appliedVersion: number;
+	    latestVersion: number;
+	    updateAvailable: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new YearTemplateStatus(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.year = source["year"];
+	        this.templateId = source["templateId"];
+	        this.templateName = source["templateName"];
+	        this.appliedVersion = source["appliedVersion"];
+	        this.latestVersion = source["latestVersion"];
+	        this.updateAvailable = source["updateAvailable"];
+	    }
+	}
 
 }
 
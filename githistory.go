@@ -0,0 +1,638 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 历史仓库中年度信息文件名，维度数据保存在同级的 dimensions 目录中
+const (
+	gitHistoryYearFile     = "year.json"
+	gitHistoryDimensionDir = "dimensions"
+)
+
+// 历史仓库根目录中的标记文件，只在空目录或带有该文件的目录中写入数据
+const gitHistoryMarkerFile = ".manifest-history"
+
+// 未保存时兜底检查数据变化的间隔（同步等后台修改不会触发保存提交）
+const gitHistoryCheckInterval = 30 * time.Minute
+
+// 提交时使用的作者，避免依赖用户的全局 git 配置
+const (
+	gitHistoryAuthorName  = "Manifest"
+	gitHistoryAuthorEmail = "manifest@localhost"
+)
+
+// 同一时间只允许一个提交或回滚
+var gitHistoryMu sync.Mutex
+
+// GitHistorySettings Git 历史记录设置
+type GitHistorySettings struct {
+	Enabled bool   `json:"enabled"` // 是否在保存后自动提交
+	RepoDir string `json:"repoDir"` // 本地 git 仓库目录，不存在时自动初始化
+}
+
+// GitHistoryEntry 一次历史提交
+type GitHistoryEntry struct {
+	Commit      string   `json:"commit"`
+	ShortCommit string   `json:"shortCommit"`
+	Message     string   `json:"message"`
+	CommittedAt string   `json:"committedAt"`
+	Files       []string `json:"files"` // 修改的文件，相对仓库目录
+}
+
+// GitHistoryCommitResult 提交结果，没有修改时 Commit 为空
+type GitHistoryCommitResult struct {
+	Commit  string   `json:"commit"`
+	Message string   `json:"message"`
+	Files   []string `json:"files"`
+}
+
+// gitHistoryYear 历史仓库中的年度信息，维度数据单独保存
+type gitHistoryYear struct {
+	Year             string            `json:"year"`
+	TotalScore       float64           `json:"totalScore"`
+	Settings         AnnualSettings    `json:"settings"`
+	DimensionConfigs []DimensionConfig `json:"dimensionConfigs"`
+}
+
+// DefaultGitHistorySettings 默认 Git 历史记录设置
+func DefaultGitHistorySettings() GitHistorySettings {
+	return GitHistorySettings{Enabled: false}
+}
+
+// GetGitHistorySettings 获取 Git 历史记录设置，未配置时返回默认值
+func GetGitHistorySettings() (GitHistorySettings, error) {
+	config, err := LoadConfig()
+	if err != nil {
+		return GitHistorySettings{}, err
+	}
+
+	if config.GitHistory == nil {
+		return DefaultGitHistorySettings(), nil
+	}
+	return *config.GitHistory, nil
+}
+
+// SaveGitHistorySettings 保存 Git 历史记录设置，启用时初始化仓库
+func SaveGitHistorySettings(settings GitHistorySettings) error {
+	if settings.Enabled && settings.RepoDir == "" {
+		return fmt.Errorf("请先设置历史仓库目录")
+	}
+	if settings.RepoDir != "" {
+		dir, err := filepath.Abs(settings.RepoDir)
+		if err != nil {
+			return err
+		}
+		settings.RepoDir = dir
+	}
+	if settings.Enabled {
+		if err := initGitHistoryRepo(settings.RepoDir); err != nil {
+			return err
+		}
+	}
+
+	return UpdateConfig(func(config *Config) error {
+		config.GitHistory = &settings
+		return nil
+	})
+}
+
+// CommitGitHistory 立即将当前数据提交到历史仓库
+func CommitGitHistory() (*GitHistoryCommitResult, error) {
+	settings, err := GetGitHistorySettings()
+	if err != nil {
+		return nil, err
+	}
+	if settings.RepoDir == "" {
+		return nil, fmt.Errorf("请先设置历史仓库目录")
+	}
+	return commitGitHistory(settings.RepoDir, "", time.Now())
+}
+
+// GetGitHistory 获取历史提交，year 与 dimensionKey 可为空，用于查看某一年度或维度的历史
+func GetGitHistory(year, dimensionKey string, limit int) ([]GitHistoryEntry, error) {
+	settings, err := GetGitHistorySettings()
+	if err != nil {
+		return nil, err
+	}
+	if settings.RepoDir == "" {
+		return nil, fmt.Errorf("请先设置历史仓库目录")
+	}
+	if dimensionKey != "" && year == "" {
+		return nil, fmt.Errorf("查看维度历史时必须指定年度")
+	}
+	return listGitHistory(settings.RepoDir, gitHistoryPathspec(year, dimensionKey), limit)
+}
+
+// RestoreGitHistory 将数据回滚到某次提交，回滚前后的数据都会提交，回滚本身也可以撤销
+func RestoreGitHistory(commit string) (*GitHistoryCommitResult, error) {
+	settings, err := GetGitHistorySettings()
+	if err != nil {
+		return nil, err
+	}
+	if settings.RepoDir == "" {
+		return nil, fmt.Errorf("请先设置历史仓库目录")
+	}
+	return restoreGitHistory(settings.RepoDir, commit, time.Now())
+}
+
+// 辅助函数：定时兜底提交，保存数据后通过 Refresh 立即执行，供 Scheduler 调用
+func checkGitHistory(ctx context.Context) time.Duration {
+	settings, err := GetGitHistorySettings()
+	if err != nil {
+		log.Printf("读取Git历史记录设置失败: %v", err)
+		return gitHistoryCheckInterval
+	}
+	if db == nil || !settings.Enabled || settings.RepoDir == "" {
+		return gitHistoryCheckInterval
+	}
+
+	if _, err := commitGitHistory(settings.RepoDir, "", time.Now()); err != nil {
+		log.Printf("提交Git历史记录失败: %v", err)
+	}
+	return gitHistoryCheckInterval
+}
+
+// 辅助函数：导出当前数据并提交，message 为空时根据修改的文件生成
+func commitGitHistory(dir, message string, now time.Time) (*GitHistoryCommitResult, error) {
+	if db == nil {
+		return nil, errDatabaseNotReady
+	}
+
+	gitHistoryMu.Lock()
+	defer gitHistoryMu.Unlock()
+
+	data, err := GetAllAnnualData()
+	if err != nil {
+		return nil, err
+	}
+	return commitGitHistorySnapshot(dir, data, message, now)
+}
+
+// 辅助函数：将数据写入仓库并提交，调用方需持有 gitHistoryMu
+func commitGitHistorySnapshot(dir string, data SystemData, message string, now time.Time) (*GitHistoryCommitResult, error) {
+	if err := initGitHistoryRepo(dir); err != nil {
+		return nil, err
+	}
+	// git add、git status 与提交都只作用于本功能的文件，仓库中的其他文件不受影响
+	paths, err := writeGitHistoryFiles(dir, data)
+	if err != nil {
+		return nil, err
+	}
+	if len(paths) == 0 {
+		return &GitHistoryCommitResult{Files: []string{}}, nil
+	}
+	pathspec := append([]string{"--"}, paths...)
+
+	if _, err := runGit(dir, append([]string{"add", "--all"}, pathspec...)...); err != nil {
+		return nil, err
+	}
+	status, err := runGit(dir, append([]string{"status", "--porcelain", "--no-renames", "-z"}, pathspec...)...)
+	if err != nil {
+		return nil, err
+	}
+	files, deleted := parseGitStatusFiles(status)
+	if len(files) == 0 {
+		return &GitHistoryCommitResult{Files: []string{}}, nil
+	}
+	if message == "" {
+		message = gitHistoryMessage(files, deleted)
+	}
+
+	stamp := now.Format(time.RFC3339)
+	_, err = runGitWithEnv(dir, []string{
+		"GIT_AUTHOR_NAME=" + gitHistoryAuthorName, "GIT_AUTHOR_EMAIL=" + gitHistoryAuthorEmail, "GIT_AUTHOR_DATE=" + stamp,
+		"GIT_COMMITTER_NAME=" + gitHistoryAuthorName, "GIT_COMMITTER_EMAIL=" + gitHistoryAuthorEmail, "GIT_COMMITTER_DATE=" + stamp,
+	}, append([]string{"commit", "--quiet", "--no-verify", "--no-gpg-sign", "-m", message}, pathspec...)...)
+	if err != nil {
+		return nil, err
+	}
+	commit, err := runGit(dir, "rev-parse", "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	return &GitHistoryCommitResult{Commit: strings.TrimSpace(commit), Message: message, Files: files}, nil
+}
+
+// 辅助函数：回滚到某次提交
+func restoreGitHistory(dir, commit string, now time.Time) (*GitHistoryCommitResult, error) {
+	if db == nil {
+		return nil, errDatabaseNotReady
+	}
+	if commit == "" || strings.HasPrefix(commit, "-") {
+		return nil, fmt.Errorf("无效的提交: %s", commit)
+	}
+
+	gitHistoryMu.Lock()
+	defer gitHistoryMu.Unlock()
+
+	hash, err := runGit(dir, "rev-parse", "--verify", "--quiet", commit+"^{commit}")
+	if err != nil {
+		return nil, fmt.Errorf("提交不存在: %s", commit)
+	}
+	hash = strings.TrimSpace(hash)
+
+	data, err := readGitHistorySnapshot(dir, hash)
+	if err != nil {
+		return nil, err
+	}
+
+	// 先提交当前数据，保证回滚前的状态也在历史中
+	current, err := GetAllAnnualData()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := commitGitHistorySnapshot(dir, current, "", now); err != nil {
+		return nil, err
+	}
+
+	if err := ImportData(data); err != nil {
+		return nil, fmt.Errorf("回滚数据失败: %w", err)
+	}
+
+	restored, err := GetAllAnnualData()
+	if err != nil {
+		return nil, err
+	}
+	return commitGitHistorySnapshot(dir, restored, fmt.Sprintf("回滚到 %s", shortGitCommit(hash)), now)
+}
+
+// 辅助函数：按维度拆分写入数据文件，并删除已不存在的年度或维度，返回写入与删除的文件
+//
+// 每个维度一个文件，可以用 git log 查看单个维度的历史；JSON 的 map 按 key 排序，
+// 任务顺序与应用中一致，相同数据导出的内容完全相同，差异只包含真正修改的部分。
+func writeGitHistoryFiles(dir string, data SystemData) ([]string, error) {
+	files := map[string][]byte{}
+	for year, annual := range data {
+		content, err := marshalGitHistoryFile(gitHistoryYear{
+			Year:             year,
+			TotalScore:       annual.TotalScore,
+			Settings:         annual.Settings,
+			DimensionConfigs: annual.DimensionConfigs,
+		})
+		if err != nil {
+			return nil, err
+		}
+		files[gitHistoryPathspec(year, "")+"/"+gitHistoryYearFile] = content
+
+		for key, dimension := range annual.Dimensions {
+			content, err := marshalGitHistoryFile(dimension)
+			if err != nil {
+				return nil, err
+			}
+			files[gitHistoryPathspec(year, key)] = content
+		}
+	}
+
+	for name, content := range files {
+		target := filepath.Join(dir, filepath.FromSlash(name))
+		if existing, err := os.ReadFile(target); err == nil && bytes.Equal(existing, content) {
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return nil, fmt.Errorf("创建目录失败: %w", err)
+		}
+		if err := os.WriteFile(target, content, 0644); err != nil {
+			return nil, fmt.Errorf("写入历史文件失败: %w", err)
+		}
+	}
+
+	// 删除已不存在的年度与维度，只处理本功能生成的文件
+	tracked, err := runGit(dir, "ls-files", "-z")
+	if err != nil {
+		return nil, err
+	}
+	paths := make([]string, 0, len(files))
+	for name := range files {
+		paths = append(paths, name)
+	}
+	for _, name := range strings.Split(tracked, "\x00") {
+		if !isGitHistoryFile(name) {
+			continue
+		}
+		if _, ok := files[name]; ok {
+			continue
+		}
+		if err := os.Remove(filepath.Join(dir, filepath.FromSlash(name))); err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("删除历史文件失败: %w", err)
+		}
+		paths = append(paths, name)
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// 辅助函数：从某次提交中读取全部数据，格式与 ImportData 的输入一致
+func readGitHistorySnapshot(dir, commit string) (SystemData, error) {
+	listing, err := runGit(dir, "ls-tree", "-r", "-z", "--name-only", commit)
+	if err != nil {
+		return nil, err
+	}
+
+	data := SystemData{}
+	dimensions := map[string]map[string]DimensionData{}
+	for _, name := range strings.Split(listing, "\x00") {
+		if !isGitHistoryFile(name) {
+			continue
+		}
+		parts := strings.Split(name, "/")
+		year, err := url.PathUnescape(parts[0])
+		if err != nil {
+			continue
+		}
+
+		content, err := runGit(dir, "show", commit+":"+name)
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case len(parts) == 2 && parts[1] == gitHistoryYearFile:
+			var info gitHistoryYear
+			if err := json.Unmarshal([]byte(content), &info); err != nil {
+				return nil, fmt.Errorf("解析历史文件失败 %s: %w", name, err)
+			}
+			data[year] = AnnualData{
+				Year:             year,
+				TotalScore:       info.TotalScore,
+				Settings:         info.Settings,
+				DimensionConfigs: info.DimensionConfigs,
+			}
+		case len(parts) == 3 && parts[1] == gitHistoryDimensionDir:
+			key, err := url.PathUnescape(strings.TrimSuffix(parts[2], ".json"))
+			if err != nil {
+				continue
+			}
+			var dimension DimensionData
+			if err := json.Unmarshal([]byte(content), &dimension); err != nil {
+				return nil, fmt.Errorf("解析历史文件失败 %s: %w", name, err)
+			}
+			if dimensions[year] == nil {
+				dimensions[year] = map[string]DimensionData{}
+			}
+			dimensions[year][key] = dimension
+		}
+	}
+
+	for year, annual := range data {
+		annual.Dimensions = dimensions[year]
+		if annual.Dimensions == nil {
+			annual.Dimensions = map[string]DimensionData{}
+		}
+		data[year] = annual
+	}
+	return data, nil
+}
+
+// 辅助函数：列出历史提交
+func listGitHistory(dir, pathspec string, limit int) ([]GitHistoryEntry, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".git")); err != nil {
+		return []GitHistoryEntry{}, nil
+	}
+	// 还没有任何提交时 git log 会失败
+	if _, err := runGit(dir, "rev-parse", "--verify", "--quiet", "HEAD"); err != nil {
+		return []GitHistoryEntry{}, nil
+	}
+
+	args := []string{"log", "-z", "--name-only", "--no-renames", "--format=%x1e%H%x1f%cI%x1f%s", fmt.Sprintf("-n%d", limit)}
+	if pathspec != "" {
+		args = append(args, "--", pathspec)
+	}
+	output, err := runGit(dir, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := []GitHistoryEntry{}
+	for _, record := range strings.Split(output, "\x1e") {
+		record = strings.Trim(record, "\x00\n")
+		if record == "" {
+			continue
+		}
+		header, names, _ := strings.Cut(record, "\x00")
+		fields := strings.SplitN(header, "\x1f", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		entry := GitHistoryEntry{
+			Commit:      fields[0],
+			ShortCommit: shortGitCommit(fields[0]),
+			CommittedAt: fields[1],
+			Message:     fields[2],
+			Files:       []string{},
+		}
+		for _, name := range strings.Split(names, "\x00") {
+			if name = strings.TrimSpace(name); name != "" {
+				entry.Files = append(entry.Files, name)
+			}
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// 辅助函数：检查仓库目录并在需要时执行 git init
+//
+// 只接受不存在或空的目录、带有标记文件的目录，以及只包含本功能文件的旧版本仓库，
+// 避免误选已有项目目录后删除或提交其中的文件。
+func initGitHistoryRepo(dir string) error {
+	if _, err := exec.LookPath("git"); err != nil {
+		return fmt.Errorf("未找到git命令，请先安装git")
+	}
+	marker := filepath.Join(dir, gitHistoryMarkerFile)
+	if _, err := os.Stat(marker); err != nil {
+		if err := checkGitHistoryDir(dir); err != nil {
+			return err
+		}
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("创建历史仓库目录失败: %w", err)
+		}
+		content := []byte("此目录由 Manifest 管理，用于保存数据的历史版本。\n")
+		if err := os.WriteFile(marker, content, 0644); err != nil {
+			return fmt.Errorf("创建历史仓库标记失败: %w", err)
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+		return nil
+	}
+	if _, err := runGit(dir, "init", "--quiet"); err != nil {
+		return fmt.Errorf("初始化历史仓库失败: %w", err)
+	}
+	return nil
+}
+
+// 辅助函数：检查没有标记文件的目录能否作为历史仓库
+func checkGitHistoryDir(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("读取历史仓库目录失败: %w", err)
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+
+	// 旧版本创建的仓库没有标记文件，所有文件都由本功能生成时继续使用
+	if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+		tracked, err := runGit(dir, "ls-files", "-z", "--others", "--cached")
+		if err != nil {
+			return err
+		}
+		owned := true
+		for _, name := range strings.Split(tracked, "\x00") {
+			if name != "" && !isGitHistoryFile(name) {
+				owned = false
+				break
+			}
+		}
+		if owned {
+			return nil
+		}
+	}
+	return fmt.Errorf("目录 %s 不为空，请选择空目录或已有的 Manifest 历史仓库", dir)
+}
+
+// 辅助函数：是否为本功能生成的文件，即 <年度>/year.json 或 <年度>/dimensions/<维度>.json
+func isGitHistoryFile(name string) bool {
+	parts := strings.Split(name, "/")
+	switch {
+	case len(parts) == 2:
+		return parts[0] != "" && parts[1] == gitHistoryYearFile
+	case len(parts) == 3:
+		return parts[0] != "" && parts[1] == gitHistoryDimensionDir && strings.HasSuffix(parts[2], ".json") && len(parts[2]) > len(".json")
+	}
+	return false
+}
+
+// 辅助函数：执行 git 命令，返回标准输出
+func runGit(dir string, args ...string) (string, error) {
+	return runGitWithEnv(dir, nil, args...)
+}
+
+// 辅助函数：使用额外的环境变量执行 git 命令
+func runGitWithEnv(dir string, env []string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	// 路径按字面匹配，维度 key 中的 * 等字符不会被当作通配符
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0", "GIT_LITERAL_PATHSPECS=1", "LC_ALL=C")
+	cmd.Env = append(cmd.Env, env...)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		message := strings.TrimSpace(stderr.String())
+		if message == "" {
+			message = err.Error()
+		}
+		return "", fmt.Errorf("git %s 失败: %s", args[0], message)
+	}
+	return stdout.String(), nil
+}
+
+// 辅助函数：年度或维度文件在仓库中的路径，年度与维度 key 都为空时返回空
+func gitHistoryPathspec(year, dimensionKey string) string {
+	if year == "" {
+		return ""
+	}
+	if dimensionKey == "" {
+		return url.PathEscape(year)
+	}
+	return path.Join(url.PathEscape(year), gitHistoryDimensionDir, url.PathEscape(dimensionKey)+".json")
+}
+
+// 辅助函数：序列化为缩进的 JSON，不转义 HTML 字符，以换行结尾
+func marshalGitHistoryFile(value interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(value); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// 辅助函数：解析 git status --porcelain -z 的输出，返回修改的文件与其中已删除的文件
+func parseGitStatusFiles(status string) ([]string, map[string]bool) {
+	files := []string{}
+	deleted := map[string]bool{}
+	for _, entry := range strings.Split(status, "\x00") {
+		if len(entry) <= 3 {
+			continue
+		}
+		name := entry[3:]
+		files = append(files, name)
+		if entry[0] == 'D' {
+			deleted[name] = true
+		}
+	}
+	sort.Strings(files)
+	return files, deleted
+}
+
+// 辅助函数：根据修改的文件生成提交说明，例如 “更新 2025: health, work; 删除 2024”
+func gitHistoryMessage(files []string, deleted map[string]bool) string {
+	years := []string{}
+	changes := map[string][]string{}
+	removed := map[string]bool{}
+	for _, name := range files {
+		parts := strings.Split(name, "/")
+		year, _ := url.PathUnescape(parts[0])
+		if _, ok := changes[year]; !ok {
+			years = append(years, year)
+			changes[year] = []string{}
+		}
+		switch {
+		case len(parts) == 2 && parts[1] == gitHistoryYearFile && deleted[name]:
+			removed[year] = true
+		case len(parts) == 3:
+			key, _ := url.PathUnescape(strings.TrimSuffix(parts[2], ".json"))
+			if deleted[name] {
+				key = "-" + key
+			}
+			changes[year] = append(changes[year], key)
+		}
+	}
+	sort.Strings(years)
+
+	parts := make([]string, 0, len(years))
+	for _, year := range years {
+		switch {
+		case removed[year]:
+			parts = append(parts, "删除 "+year)
+		case len(changes[year]) == 0:
+			parts = append(parts, "更新 "+year)
+		default:
+			parts = append(parts, "更新 "+year+": "+strings.Join(changes[year], ", "))
+		}
+	}
+	return strings.Join(parts, "; ")
+}
+
+// 辅助函数：提交哈希的短格式
+func shortGitCommit(commit string) string {
+	if len(commit) > 8 {
+		return commit[:8]
+	}
+	return commit
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// 辅助函数：没有 git 命令时跳过测试
+func requireGit(t *testing.T) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("未找到git命令")
+	}
+}
+
+func TestGitHistoryRejectsForeignDirectory(t *testing.T) {
+	requireGit(t)
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("keep"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := initGitHistoryRepo(dir); err == nil {
+		t.Fatal("非空目录应被拒绝")
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".git")); !os.IsNotExist(err) {
+		t.Fatal("被拒绝的目录不应初始化仓库")
+	}
+
+	// 不存在的目录会被创建并写入标记文件
+	fresh := filepath.Join(t.TempDir(), "history")
+	if err := initGitHistoryRepo(fresh); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(fresh, gitHistoryMarkerFile)); err != nil {
+		t.Fatalf("缺少标记文件: %v", err)
+	}
+}
+
+// 提交与删除只作用于本功能生成的文件，仓库中的其他文件保持不变
+func TestGitHistoryOnlyTouchesOwnFiles(t *testing.T) {
+	requireGit(t)
+	dir := t.TempDir()
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	// 只有年度没有维度时也能提交
+	data := SystemData{"2026": {Year: "2026", Dimensions: map[string]DimensionData{}}}
+	result, err := commitGitHistorySnapshot(dir, data, "", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Commit == "" || len(result.Files) != 1 {
+		t.Fatalf("首次提交: %+v", result)
+	}
+
+	// 用户放入仓库的其他文件（包括 JSON）不会被提交或删除
+	foreign := []string{"README.md", "other.json", filepath.Join("2026", "notes.json")}
+	for _, name := range foreign {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("{}"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	data["2026"].Dimensions["work"] = DimensionData{AnnualGoal: "ship"}
+	data["2025"] = AnnualData{Year: "2025", Dimensions: map[string]DimensionData{"health": {}}}
+	result, err = commitGitHistorySnapshot(dir, data, "", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(result.Files, ",") != "2025/dimensions/health.json,2025/year.json,2026/dimensions/work.json" {
+		t.Fatalf("提交的文件: %v", result.Files)
+	}
+
+	delete(data, "2025")
+	result, err = commitGitHistorySnapshot(dir, data, "", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Message != "删除 2025" {
+		t.Fatalf("提交说明: %q", result.Message)
+	}
+
+	for _, name := range foreign {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Fatalf("%s 被删除: %v", name, err)
+		}
+	}
+	tracked, err := runGit(dir, "ls-files")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.TrimSpace(tracked) != "2026/dimensions/work.json\n2026/year.json" {
+		t.Fatalf("仓库中的文件: %q", tracked)
+	}
+}
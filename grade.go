@@ -0,0 +1,124 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// GradeRule 绩效评级规则，综合得分不低于 MinScore 即获得该评级
+type GradeRule struct {
+	Grade       string  `json:"grade"`
+	MinScore    float64 `json:"minScore"` // 0-100
+	Description string  `json:"description"`
+}
+
+// GradeResult 绩效评级结果
+type GradeResult struct {
+	Grade       string  `json:"grade"`
+	Score       float64 `json:"score"` // 按维度权重加权的完成率，0-100
+	Description string  `json:"description"`
+}
+
+// DefaultGradeRules 默认评级规则
+func DefaultGradeRules() []GradeRule {
+	return []GradeRule{
+		{Grade: "S", MinScore: 90, Description: "卓越"},
+		{Grade: "A", MinScore: 80, Description: "优秀"},
+		{Grade: "B", MinScore: 70, Description: "良好"},
+		{Grade: "C", MinScore: 60, Description: "合格"},
+		{Grade: "D", MinScore: 0, Description: "待改进"},
+	}
+}
+
+// EvaluateGrade 根据年度数据计算绩效评级
+func EvaluateGrade(data AnnualData) GradeResult {
+	score := weightedCompletionRate(data)
+
+	rules := data.Settings.Scoring.GradeRules
+	if len(rules) == 0 {
+		rules = DefaultGradeRules()
+	}
+	rules = append([]GradeRule{}, rules...)
+	sort.SliceStable(rules, func(i, j int) bool {
+		return rules[i].MinScore > rules[j].MinScore
+	})
+
+	result := GradeResult{Score: score}
+	for _, rule := range rules {
+		if score >= rule.MinScore {
+			result.Grade = rule.Grade
+			result.Description = rule.Description
+			return result
+		}
+	}
+
+	// 低于所有规则的最低分时取最后一档
+	last := rules[len(rules)-1]
+	result.Grade = last.Grade
+	result.Description = last.Description
+	return result
+}
+
+// ValidateGradeRules 校验评级规则
+func ValidateGradeRules(rules []GradeRule) error {
+	seen := make(map[string]bool)
+	for _, rule := range rules {
+		if rule.Grade == "" {
+			return fmt.Errorf("评级名称不能为空")
+		}
+		if seen[rule.Grade] {
+			return fmt.Errorf("评级重复: %s", rule.Grade)
+		}
+		if rule.MinScore < 0 || rule.MinScore > 100 {
+			return fmt.Errorf("评级 %s 的最低分必须在0-100之间", rule.Grade)
+		}
+		seen[rule.Grade] = true
+	}
+	return nil
+}
+
+// 辅助函数：按维度权重计算加权完成率，未设置权重时取平均值
+func weightedCompletionRate(data AnnualData) float64 {
+	weights := data.Settings.Scoring.DimensionWeights
+
+	var weighted, totalWeight float64
+	for key, dimData := range data.Dimensions {
+		weight := weights[key]
+		if weight <= 0 {
+			continue
+		}
+		weighted += dimensionCompletionRate(dimData) * weight
+		totalWeight += weight
+	}
+
+	if totalWeight == 0 {
+		if len(data.Dimensions) == 0 {
+			return 0
+		}
+		for _, dimData := range data.Dimensions {
+			weighted += dimensionCompletionRate(dimData)
+		}
+		totalWeight = float64(len(data.Dimensions))
+	}
+
+	return math.Round(weighted/totalWeight*10) / 10
+}
+
+// 辅助函数：按任务实际状态计算维度完成率
+func dimensionCompletionRate(dimData DimensionData) float64 {
+	total, completed := 0, 0
+	for _, tasks := range dimData.MonthlyTasks {
+		for _, task := range tasks {
+			total++
+			if task.Status == "completed" {
+				completed++
+			}
+		}
+	}
+
+	if total == 0 {
+		return 0
+	}
+	return float64(completed) * 100 / float64(total)
+}
@@ -0,0 +1,351 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// iCalendar 中的日期与时间格式
+const (
+	icalDateLayout     = "20060102"
+	icalDateTimeLayout = "20060102T150405Z"
+)
+
+// 任务位置等应用专有字段使用的扩展属性
+const (
+	icalPropYear      = "X-MANIFEST-YEAR"
+	icalPropDimension = "X-MANIFEST-DIMENSION"
+	icalPropMonth     = "X-MANIFEST-MONTH"
+	icalPropScore     = "X-MANIFEST-SCORE"
+)
+
+// icalTodo 解析后的 VTODO
+type icalTodo struct {
+	UID         string
+	Summary     string
+	Description string
+	Status      string // NEEDS-ACTION, IN-PROCESS, COMPLETED, CANCELLED
+	Priority    int    // 0 表示未设置，1 最高，9 最低
+	Start       string // YYYY-MM-DD
+	Due         string // YYYY-MM-DD
+	Categories  []string
+	Extra       map[string]string // X- 扩展属性
+}
+
+// 辅助函数：将任务渲染为包含单个 VTODO 的 iCalendar 文本，stamp 为零值时省略时间戳
+func renderVTODO(location taskLocation, stamp time.Time) string {
+	task := location.task
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//Manifest//Tasks//ZH",
+		"BEGIN:VTODO",
+		"UID:" + icalEscape(task.ID),
+	}
+	if !stamp.IsZero() {
+		lines = append(lines, "DTSTAMP:"+stamp.UTC().Format(icalDateTimeLayout))
+	}
+	lines = append(lines, "SUMMARY:"+icalEscape(task.Title))
+	if task.Description != "" {
+		lines = append(lines, "DESCRIPTION:"+icalEscape(task.Description))
+	}
+	lines = append(lines, "STATUS:"+icalStatus(task.Status))
+	if priority := icalPriority(task.Priority); priority > 0 {
+		lines = append(lines, fmt.Sprintf("PRIORITY:%d", priority))
+	}
+	if task.StartDate != nil {
+		if date, ok := taskDate(*task.StartDate); ok {
+			lines = append(lines, "DTSTART;VALUE=DATE:"+strings.ReplaceAll(date, "-", ""))
+		}
+	}
+	if task.EndDate != nil {
+		if date, ok := taskDate(*task.EndDate); ok {
+			lines = append(lines, "DUE;VALUE=DATE:"+strings.ReplaceAll(date, "-", ""))
+		}
+	}
+	if len(task.Tags) > 0 {
+		tags := append([]string{}, task.Tags...)
+		sort.Strings(tags)
+		for i, tag := range tags {
+			tags[i] = icalEscape(tag)
+		}
+		lines = append(lines, "CATEGORIES:"+strings.Join(tags, ","))
+	}
+	lines = append(lines,
+		icalPropYear+":"+icalEscape(location.year),
+		icalPropDimension+":"+icalEscape(location.dimensionKey),
+		fmt.Sprintf("%s:%d", icalPropMonth, location.month+1),
+	)
+	if task.Score != 0 {
+		lines = append(lines, icalPropScore+":"+strconv.FormatFloat(task.Score, 'f', -1, 64))
+	}
+	lines = append(lines, "END:VTODO", "END:VCALENDAR")
+
+	var b strings.Builder
+	for _, line := range lines {
+		b.WriteString(icalFold(line))
+		b.WriteString("\r\n")
+	}
+	return b.String()
+}
+
+// 辅助函数：解析 iCalendar 文本中的第一个 VTODO
+func parseVTODO(content string) (*icalTodo, error) {
+	todo := &icalTodo{Extra: make(map[string]string)}
+	inTodo, found := false, false
+	nested := 0 // VTODO 内嵌套组件（如 VALARM）的层级
+
+	for _, line := range icalUnfold(content) {
+		name, _, value := icalSplitLine(line)
+		if found {
+			break
+		}
+		if !inTodo {
+			if name == "BEGIN" && strings.EqualFold(value, "VTODO") {
+				inTodo = true
+			}
+			continue
+		}
+
+		switch {
+		case name == "BEGIN":
+			nested++
+			continue
+		case name == "END" && nested > 0:
+			nested--
+			continue
+		case name == "END":
+			inTodo, found = false, true
+			continue
+		case nested > 0:
+			continue
+		}
+
+		switch name {
+		case "UID":
+			todo.UID = icalUnescape(value)
+		case "SUMMARY":
+			todo.Summary = icalUnescape(value)
+		case "DESCRIPTION":
+			todo.Description = icalUnescape(value)
+		case "STATUS":
+			todo.Status = strings.ToUpper(value)
+		case "PRIORITY":
+			todo.Priority, _ = strconv.Atoi(value)
+		case "DTSTART":
+			todo.Start = icalDate(value)
+		case "DUE":
+			todo.Due = icalDate(value)
+		case "CATEGORIES":
+			for _, category := range icalSplitList(value) {
+				if category = strings.TrimSpace(category); category != "" {
+					todo.Categories = append(todo.Categories, category)
+				}
+			}
+		default:
+			if strings.HasPrefix(name, "X-") {
+				todo.Extra[name] = icalUnescape(value)
+			}
+		}
+	}
+
+	if !found {
+		return nil, fmt.Errorf("未找到VTODO")
+	}
+	if todo.UID == "" {
+		return nil, fmt.Errorf("VTODO缺少UID")
+	}
+	if value, ok := todo.Extra[icalPropScore]; ok {
+		if score, err := strconv.ParseFloat(value, 64); err != nil || score < 0 || score > 100 {
+			return nil, fmt.Errorf("无效的得分: %s，得分必须为0-100之间的数字", value)
+		}
+	}
+	return todo, nil
+}
+
+// 辅助函数：展开折行，统一换行符
+func icalUnfold(content string) []string {
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	lines := []string{}
+	for _, line := range strings.Split(content, "\n") {
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// 辅助函数：按 75 字节折行，不拆分 UTF-8 字符
+func icalFold(line string) string {
+	const limit = 75
+	if len(line) <= limit {
+		return line
+	}
+
+	var b strings.Builder
+	width := 0
+	for _, r := range line {
+		size := len(string(r))
+		if width+size > limit {
+			b.WriteString("\r\n ")
+			width = 1
+		}
+		b.WriteRune(r)
+		width += size
+	}
+	return b.String()
+}
+
+// 辅助函数：拆分属性行为名称、参数与值
+func icalSplitLine(line string) (string, map[string]string, string) {
+	// 参数值可能带引号并包含冒号，需跳过引号内的内容
+	quoted := false
+	colon := -1
+	for i, r := range line {
+		if r == '"' {
+			quoted = !quoted
+		}
+		if r == ':' && !quoted {
+			colon = i
+			break
+		}
+	}
+	if colon < 0 {
+		return strings.ToUpper(line), nil, ""
+	}
+
+	head, value := line[:colon], line[colon+1:]
+	parts := strings.Split(head, ";")
+	params := make(map[string]string)
+	for _, param := range parts[1:] {
+		if key, val, ok := strings.Cut(param, "="); ok {
+			params[strings.ToUpper(key)] = strings.Trim(val, `"`)
+		}
+	}
+	return strings.ToUpper(parts[0]), params, value
+}
+
+// 辅助函数：解析日期或日期时间，返回 YYYY-MM-DD
+func icalDate(value string) string {
+	if t, err := time.Parse(icalDateLayout, value); err == nil {
+		return t.Format(taskDateLayout)
+	}
+	if t, err := time.Parse(icalDateTimeLayout, value); err == nil {
+		return t.Local().Format(taskDateLayout)
+	}
+	// 带 TZID 的本地时间只取日期部分
+	if len(value) >= len(icalDateLayout) {
+		if t, err := time.Parse(icalDateLayout, value[:len(icalDateLayout)]); err == nil {
+			return t.Format(taskDateLayout)
+		}
+	}
+	return ""
+}
+
+// 辅助函数：转义文本值
+func icalEscape(value string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+	return replacer.Replace(value)
+}
+
+// 辅助函数：反转义文本值
+func icalUnescape(value string) string {
+	var b strings.Builder
+	escaped := false
+	for _, r := range value {
+		if escaped {
+			switch r {
+			case 'n', 'N':
+				b.WriteRune('\n')
+			default:
+				b.WriteRune(r)
+			}
+			escaped = false
+			continue
+		}
+		if r == '\\' {
+			escaped = true
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// 辅助函数：拆分以逗号分隔的列表值（忽略转义的逗号）
+func icalSplitList(value string) []string {
+	items := []string{}
+	current := strings.Builder{}
+	escaped := false
+	for _, r := range value {
+		switch {
+		case escaped:
+			current.WriteRune('\\')
+			current.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == ',':
+			items = append(items, icalUnescape(current.String()))
+			current.Reset()
+		default:
+			current.WriteRune(r)
+		}
+	}
+	return append(items, icalUnescape(current.String()))
+}
+
+// 辅助函数：任务状态转为 VTODO 状态
+func icalStatus(status string) string {
+	switch status {
+	case "completed":
+		return "COMPLETED"
+	case "in-progress":
+		return "IN-PROCESS"
+	}
+	return "NEEDS-ACTION"
+}
+
+// 辅助函数：VTODO 状态转为任务状态
+func taskStatusFromICal(status string) string {
+	switch status {
+	case "COMPLETED":
+		return "completed"
+	case "IN-PROCESS":
+		return "in-progress"
+	}
+	return "not-started"
+}
+
+// 辅助函数：任务优先级转为 VTODO 优先级（1 高、5 中、9 低）
+func icalPriority(priority string) int {
+	switch priority {
+	case "high":
+		return 1
+	case "medium":
+		return 5
+	case "low":
+		return 9
+	}
+	return 0
+}
+
+// 辅助函数：VTODO 优先级转为任务优先级，0 表示未设置
+func taskPriorityFromICal(priority int) string {
+	switch {
+	case priority >= 1 && priority <= 4:
+		return "high"
+	case priority == 5:
+		return "medium"
+	case priority >= 6 && priority <= 9:
+		return "low"
+	}
+	return ""
+}
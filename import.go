@@ -0,0 +1,649 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/xuri/excelize/v2"
+)
+
+// 预览时返回的样例行数
+const importSampleRows = 5
+
+// 导入时可识别的日期格式
+var importDateLayouts = []string{taskDateLayout, "2006/01/02", "2006/1/2", "2006-1-2", "2006.1.2", "2006年1月2日", "01-02-06", "1/2/06", "1/2/2006"}
+
+// ImportColumnMapping 导入列映射，值为表格中的列标题，为空表示不导入该字段
+type ImportColumnMapping struct {
+	Year        string `json:"year"`
+	Dimension   string `json:"dimension"` // 可匹配维度Key或维度名称
+	Month       string `json:"month"`     // 1-12
+	ID          string `json:"id"`        // 任务ID已存在时更新该任务，否则新建
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Status      string `json:"status"`
+	Priority    string `json:"priority"`
+	Score       string `json:"score"`
+	StartDate   string `json:"startDate"`
+	EndDate     string `json:"endDate"`
+	Tags        string `json:"tags"` // 多个标签以逗号分隔
+}
+
+// ImportOptions 表格导入选项
+type ImportOptions struct {
+	Sheet       string              `json:"sheet"` // XLSX工作表名称，为空时使用第一个工作表
+	Mapping     ImportColumnMapping `json:"mapping"`
+	DefaultYear string              `json:"defaultYear"` // 未映射年度列或单元格为空时使用
+	DryRun      bool                `json:"dryRun"`      // 只校验不写入
+}
+
+// ImportRowError 行级导入错误
+type ImportRowError struct {
+	Row     int    `json:"row"` // 表格中的行号，标题行为第1行
+	Column  string `json:"column"`
+	Value   string `json:"value"`
+	Message string `json:"message"`
+}
+
+// ImportResult 导入结果
+type ImportResult struct {
+	TotalRows int              `json:"totalRows"`
+	ValidRows int              `json:"validRows"`
+	Created   int              `json:"created"`
+	Updated   int              `json:"updated"`
+	Imported  bool             `json:"imported"` // 存在错误或为预演时不写入
+	Errors    []ImportRowError `json:"errors"`
+	Tasks     []TaskRow        `json:"tasks"`
+}
+
+// SpreadsheetPreview 表格预览，用于前端设置列映射
+type SpreadsheetPreview struct {
+	Sheets         []string            `json:"sheets"`
+	Headers        []string            `json:"headers"`
+	SampleRows     [][]string          `json:"sampleRows"`
+	TotalRows      int                 `json:"totalRows"`
+	DefaultMapping ImportColumnMapping `json:"defaultMapping"`
+}
+
+// importRow 解析后的导入行
+type importRow struct {
+	line         int
+	year         string
+	dimensionKey string
+	month        int // 0-11
+	task         Task
+	hasTags      bool
+}
+
+// PreviewSpreadsheet 读取表格标题与样例行，并按导出的列标题给出默认映射
+func PreviewSpreadsheet(path, sheet string) (*SpreadsheetPreview, error) {
+	sheets, records, err := readSpreadsheet(path, sheet)
+	if err != nil {
+		return nil, err
+	}
+
+	preview := &SpreadsheetPreview{
+		Sheets:     sheets,
+		Headers:    []string{},
+		SampleRows: [][]string{},
+	}
+	if len(records) == 0 {
+		return preview, nil
+	}
+
+	preview.Headers = records[0]
+	preview.TotalRows = len(records) - 1
+	for _, record := range records[1:] {
+		if len(preview.SampleRows) >= importSampleRows {
+			break
+		}
+		preview.SampleRows = append(preview.SampleRows, record)
+	}
+	preview.DefaultMapping = defaultImportMapping(preview.Headers)
+
+	return preview, nil
+}
+
+// ImportSpreadsheet 按列映射从CSV或XLSX导入任务
+//
+// 所有行校验通过后才会写入，任一行出错时只返回错误报告。
+// 任务按年度合并到已有数据后通过 SaveAnnualData 保存，维度得分随之重新计算。
+func ImportSpreadsheet(path string, options ImportOptions) (*ImportResult, error) {
+	if options.Mapping.Title == "" {
+		return nil, fmt.Errorf("必须指定任务名称列")
+	}
+	if options.Mapping.Dimension == "" {
+		return nil, fmt.Errorf("必须指定维度列")
+	}
+	if options.Mapping.Month == "" {
+		return nil, fmt.Errorf("必须指定月份列")
+	}
+	if options.Mapping.Year == "" && options.DefaultYear == "" {
+		return nil, fmt.Errorf("必须指定年度列或默认年度")
+	}
+
+	_, records, err := readSpreadsheet(path, options.Sheet)
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("表格为空")
+	}
+
+	columns, err := resolveImportColumns(records[0], options.Mapping)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := GetAllAnnualData()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ImportResult{Errors: []ImportRowError{}, Tasks: []TaskRow{}}
+	rows := []importRow{}
+	for i, record := range records[1:] {
+		if isBlankRecord(record) {
+			continue
+		}
+		result.TotalRows++
+
+		row, rowErrors := parseImportRow(i+2, record, columns, options.DefaultYear, data)
+		if len(rowErrors) > 0 {
+			result.Errors = append(result.Errors, rowErrors...)
+			continue
+		}
+		rows = append(rows, row)
+	}
+
+	return saveImportRows(result, rows, data, options.DryRun)
+}
+
+// 辅助函数：所有行校验通过后合并到年度数据并保存
+func saveImportRows(result *ImportResult, rows []importRow, data SystemData, dryRun bool) (*ImportResult, error) {
+	result.ValidRows = len(rows)
+	for _, row := range rows {
+		result.Tasks = append(result.Tasks, importTaskRow(row, data))
+	}
+
+	if len(result.Errors) > 0 || dryRun || len(rows) == 0 {
+		return result, nil
+	}
+
+	created, updated, years := mergeImportRows(data, rows)
+	for _, year := range years {
+		if err := SaveAnnualData(data[year]); err != nil {
+			return nil, fmt.Errorf("保存年度数据失败: %w", err)
+		}
+	}
+
+	result.Created = created
+	result.Updated = updated
+	result.Imported = true
+	return result, nil
+}
+
+// 辅助函数：读取表格内容，返回工作表列表与所有行
+func readSpreadsheet(path, sheet string) ([]string, [][]string, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case "." + spreadsheetFormatCSV:
+		records, err := readCSVFile(path)
+		return []string{}, records, err
+	case "." + spreadsheetFormatXLSX:
+		f, err := excelize.OpenFile(path)
+		if err != nil {
+			return nil, nil, fmt.Errorf("打开XLSX文件失败: %w", err)
+		}
+		defer f.Close()
+
+		sheets := f.GetSheetList()
+		if sheet == "" {
+			if len(sheets) == 0 {
+				return sheets, [][]string{}, nil
+			}
+			sheet = sheets[0]
+		}
+		records, err := f.GetRows(sheet)
+		if err != nil {
+			return nil, nil, fmt.Errorf("读取工作表失败: %w", err)
+		}
+		return sheets, records, nil
+	default:
+		return nil, nil, fmt.Errorf("不支持的导入格式: %s", filepath.Ext(path))
+	}
+}
+
+// 辅助函数：读取 CSV 文件，忽略 UTF-8 BOM
+func readCSVFile(path string) ([][]string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取CSV文件失败: %w", err)
+	}
+
+	reader := csv.NewReader(strings.NewReader(strings.TrimPrefix(string(content), "\ufeff")))
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("解析CSV文件失败: %w", err)
+	}
+	return records, nil
+}
+
+// 辅助函数：按导出表格的列标题生成默认映射
+func defaultImportMapping(headers []string) ImportColumnMapping {
+	has := make(map[string]bool)
+	for _, header := range headers {
+		has[strings.TrimSpace(header)] = true
+	}
+	pick := func(names ...string) string {
+		for _, name := range names {
+			if has[name] {
+				return name
+			}
+		}
+		return ""
+	}
+
+	return ImportColumnMapping{
+		Year:        pick("年度"),
+		Dimension:   pick("维度Key", "维度"),
+		Month:       pick("月份"),
+		ID:          pick("任务ID"),
+		Title:       pick("任务"),
+		Description: pick("描述"),
+		Status:      pick("状态"),
+		Priority:    pick("优先级"),
+		Score:       pick("得分"),
+		StartDate:   pick("开始日期"),
+		EndDate:     pick("结束日期"),
+		Tags:        pick("标签"),
+	}
+}
+
+// 辅助函数：将列映射解析为列序号，未映射的字段为 -1
+func resolveImportColumns(headers []string, mapping ImportColumnMapping) (map[string]int, error) {
+	index := make(map[string]int)
+	for i, header := range headers {
+		header = strings.TrimSpace(header)
+		if _, ok := index[header]; !ok {
+			index[header] = i
+		}
+	}
+
+	fields := map[string]string{
+		"年度": mapping.Year, "维度": mapping.Dimension, "月份": mapping.Month, "任务ID": mapping.ID,
+		"任务": mapping.Title, "描述": mapping.Description, "状态": mapping.Status, "优先级": mapping.Priority,
+		"得分": mapping.Score, "开始日期": mapping.StartDate, "结束日期": mapping.EndDate, "标签": mapping.Tags,
+	}
+
+	columns := make(map[string]int)
+	for field, header := range fields {
+		if header == "" {
+			columns[field] = -1
+			continue
+		}
+		i, ok := index[strings.TrimSpace(header)]
+		if !ok {
+			return nil, fmt.Errorf("表格中不存在列: %s", header)
+		}
+		columns[field] = i
+	}
+	return columns, nil
+}
+
+// 辅助函数：判断是否为空行
+func isBlankRecord(record []string) bool {
+	for _, value := range record {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// 辅助函数：解析并校验一行数据
+func parseImportRow(line int, record []string, columns map[string]int, defaultYear string, data SystemData) (importRow, []ImportRowError) {
+	row := importRow{line: line}
+	errors := []ImportRowError{}
+	cell := func(field string) string {
+		i := columns[field]
+		if i < 0 || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+	fail := func(field, value, message string) {
+		errors = append(errors, ImportRowError{Row: line, Column: field, Value: value, Message: message})
+	}
+
+	// 年度与维度
+	row.year = cell("年度")
+	if row.year == "" {
+		row.year = defaultYear
+	}
+	annual, ok := data[row.year]
+	if !ok {
+		fail("年度", row.year, "年度数据不存在")
+	} else if dimension := cell("维度"); dimension == "" {
+		fail("维度", dimension, "维度不能为空")
+	} else if key, ok := findImportDimension(annual, dimension); !ok {
+		fail("维度", dimension, "维度不存在")
+	} else {
+		row.dimensionKey = key
+	}
+
+	// 月份
+	month := cell("月份")
+	if value, ok := parseImportMonth(month); ok {
+		row.month = value - 1
+	} else {
+		fail("月份", month, "月份必须为1-12")
+	}
+
+	// 任务字段
+	row.task.ID = cell("任务ID")
+	row.task.Title = cell("任务")
+	if row.task.Title == "" {
+		fail("任务", "", "任务名称不能为空")
+	}
+	row.task.Description = cell("描述")
+
+	status := cell("状态")
+	if value, ok := parseImportStatus(status); ok {
+		row.task.Status = value
+	} else {
+		fail("状态", status, "状态必须为 not-started/in-progress/completed 或 未开始/进行中/已完成")
+	}
+
+	priority := cell("优先级")
+	if value, ok := parseImportPriority(priority); ok {
+		row.task.Priority = value
+	} else {
+		fail("优先级", priority, "优先级必须为 low/medium/high 或 低/中/高")
+	}
+
+	if score := cell("得分"); score != "" {
+		value, err := strconv.ParseFloat(score, 64)
+		if err != nil || value < 0 || value > 100 {
+			fail("得分", score, "得分必须为0-100之间的数字")
+		} else {
+			row.task.Score = value
+		}
+	}
+
+	for _, field := range []string{"开始日期", "结束日期"} {
+		value := cell(field)
+		if value == "" {
+			continue
+		}
+		date, ok := parseImportDate(value)
+		if !ok {
+			fail(field, value, "日期格式无法识别，请使用YYYY-MM-DD")
+			continue
+		}
+		if field == "开始日期" {
+			row.task.StartDate = &date
+		} else {
+			row.task.EndDate = &date
+		}
+	}
+	if row.task.StartDate != nil && row.task.EndDate != nil && *row.task.EndDate < *row.task.StartDate {
+		fail("结束日期", *row.task.EndDate, "结束日期不能早于开始日期")
+	}
+
+	if columns["标签"] >= 0 {
+		row.hasTags = true
+		row.task.Tags = splitImportTags(cell("标签"))
+	}
+
+	return row, errors
+}
+
+// 辅助函数：按维度Key或名称查找维度
+func findImportDimension(annual AnnualData, value string) (string, bool) {
+	for _, config := range orderedDimensionConfigs(annual) {
+		if config.Key == value {
+			return config.Key, true
+		}
+	}
+	for _, config := range orderedDimensionConfigs(annual) {
+		if strings.EqualFold(config.Title, value) {
+			return config.Key, true
+		}
+	}
+	return "", false
+}
+
+// 辅助函数：解析月份，支持 “3” 与 “3月”
+func parseImportMonth(value string) (int, bool) {
+	month, err := strconv.Atoi(strings.TrimSuffix(value, "月"))
+	if err != nil || month < 1 || month > 12 {
+		return 0, false
+	}
+	return month, true
+}
+
+// 辅助函数：解析任务状态，支持英文值与中文名称，为空时视为未开始
+func parseImportStatus(value string) (string, bool) {
+	if value == "" {
+		return "not-started", true
+	}
+	for _, status := range []string{"not-started", "in-progress", "completed"} {
+		if strings.EqualFold(value, status) || value == statusLabel(status) {
+			return status, true
+		}
+	}
+	return "", false
+}
+
+// 辅助函数：解析优先级，支持英文值与中文名称，为空时视为中
+func parseImportPriority(value string) (string, bool) {
+	if value == "" {
+		return "medium", true
+	}
+	for _, priority := range []string{"low", "medium", "high"} {
+		if strings.EqualFold(value, priority) || value == priorityLabel(priority) {
+			return priority, true
+		}
+	}
+	return "", false
+}
+
+// 辅助函数：解析日期并统一为 YYYY-MM-DD
+func parseImportDate(value string) (string, bool) {
+	if date, ok := taskDate(value); ok {
+		return date, true
+	}
+	for _, layout := range importDateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.Format(taskDateLayout), true
+		}
+	}
+	return "", false
+}
+
+// 辅助函数：拆分标签，支持中英文逗号
+func splitImportTags(value string) []string {
+	tags := []string{}
+	for _, tag := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == '，' }) {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// 辅助函数：导入行转为预览用的任务行
+func importTaskRow(row importRow, data SystemData) TaskRow {
+	title := row.dimensionKey
+	for _, config := range orderedDimensionConfigs(data[row.year]) {
+		if config.Key == row.dimensionKey {
+			title = config.Title
+		}
+	}
+
+	taskRow := TaskRow{
+		Year:           row.year,
+		DimensionTitle: title,
+		DimensionKey:   row.dimensionKey,
+		Month:          row.month + 1,
+		TaskID:         row.task.ID,
+		Title:          row.task.Title,
+		Description:    row.task.Description,
+		Status:         statusLabel(row.task.Status),
+		Priority:       priorityLabel(row.task.Priority),
+		Score:          row.task.Score,
+		Tags:           strings.Join(row.task.Tags, ", "),
+	}
+	if row.task.StartDate != nil {
+		taskRow.StartDate = *row.task.StartDate
+	}
+	if row.task.EndDate != nil {
+		taskRow.EndDate = *row.task.EndDate
+	}
+	return taskRow
+}
+
+// 辅助函数：将导入行合并到年度数据，返回新建数、更新数与受影响的年度
+//
+// 已有任务从原来的位置移除后再按导入行放置，原位置可能在其他年度（例如文件中修改了年度）。
+func mergeImportRows(data SystemData, rows []importRow) (int, int, []string) {
+	created, updated := 0, 0
+	touched := make(map[string]map[string]bool)
+	years := []string{}
+	touch := func(year string) {
+		if touched[year] == nil {
+			touched[year] = make(map[string]bool)
+			years = append(years, year)
+		}
+	}
+	locations := locateTasks(data)
+
+	for _, row := range rows {
+		task := row.task
+		if location, ok := locations[task.ID]; task.ID != "" && ok {
+			touch(location.year)
+			previous := data[location.year]
+			removeImportedTask(&previous, task.ID, touched[location.year])
+			data[location.year] = previous
+			updated++
+		} else {
+			if task.ID == "" {
+				task.ID = uuid.New().String()
+			}
+			created++
+		}
+		if !row.hasTags {
+			task.Tags = nil
+		}
+
+		touch(row.year)
+		annual := data[row.year]
+		if annual.Dimensions == nil {
+			annual.Dimensions = make(map[string]DimensionData)
+		}
+		dimData, ok := annual.Dimensions[row.dimensionKey]
+		if !ok {
+			dimData = newDimensionData()
+		}
+		for len(dimData.MonthlyTasks) < 12 {
+			dimData.MonthlyTasks = append(dimData.MonthlyTasks, []Task{})
+		}
+		dimData.MonthlyTasks[row.month] = append(dimData.MonthlyTasks[row.month], task)
+		annual.Dimensions[row.dimensionKey] = dimData
+		touched[row.year][row.dimensionKey] = true
+
+		data[row.year] = annual
+		locations[task.ID] = taskLocation{year: row.year, dimensionKey: row.dimensionKey, month: row.month, task: task}
+	}
+
+	for _, year := range years {
+		annual := data[year]
+		for key := range touched[year] {
+			dimData := annual.Dimensions[key]
+			updateDimensionStats(&dimData)
+			annual.Dimensions[key] = dimData
+		}
+		updateAnnualTotalScore(&annual)
+		data[year] = annual
+	}
+
+	return created, updated, years
+}
+
+// 辅助函数：创建空的维度数据，默认计分规则与前端一致
+func newDimensionData() DimensionData {
+	return DimensionData{
+		QuarterlyGoals: []string{"", "", "", ""},
+		MonthlyTasks:   make([][]Task, 12),
+		Settings: DimensionSettings{Scoring: ScoringSettings{
+			CompletedScore:  100,
+			InProgressScore: 50,
+			NotStartedScore: 0,
+		}},
+	}
+}
+
+// 辅助函数：从年度数据中移除指定任务，用于导入时更新已有任务
+func removeImportedTask(annual *AnnualData, taskID string, touched map[string]bool) bool {
+	for key, dimData := range annual.Dimensions {
+		for month, tasks := range dimData.MonthlyTasks {
+			for i, task := range tasks {
+				if task.ID != taskID {
+					continue
+				}
+				dimData.MonthlyTasks[month] = append(tasks[:i:i], tasks[i+1:]...)
+				annual.Dimensions[key] = dimData
+				touched[key] = true
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// 辅助函数：按维度计分规则重新计算维度统计，与前端的计算方式一致
+func updateDimensionStats(dimData *DimensionData) {
+	scoring := dimData.Settings.Scoring
+	totalScore := 0.0
+	completed, total := 0, 0
+	for _, tasks := range dimData.MonthlyTasks {
+		for _, task := range tasks {
+			total++
+			switch task.Status {
+			case "completed":
+				completed++
+				totalScore += scoring.CompletedScore
+			case "in-progress":
+				totalScore += scoring.InProgressScore
+			default:
+				totalScore += scoring.NotStartedScore
+			}
+		}
+	}
+
+	dimData.TotalScore = totalScore
+	dimData.CompletedTasks = completed
+	dimData.TotalTasks = total
+	dimData.Progress = 0
+	if total > 0 {
+		dimData.Progress = int(float64(completed)*100/float64(total) + 0.5)
+	}
+}
+
+// 辅助函数：按维度权重重新计算年度总分
+func updateAnnualTotalScore(annual *AnnualData) {
+	totalScore := 0.0
+	for key, weight := range annual.Settings.Scoring.DimensionWeights {
+		if dimData, ok := annual.Dimensions[key]; ok {
+			totalScore += dimData.TotalScore * weight
+		}
+	}
+	annual.TotalScore = totalScore
+}
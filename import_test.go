@@ -0,0 +1,37 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+// 导入的已有任务修改了年度时移到新年度，不在原年度中保留
+func TestMergeImportRowsMovesTaskAcrossYears(t *testing.T) {
+	monthly := make([][]Task, 12)
+	monthly[2] = []Task{{ID: "t1", Title: "旧标题", Status: "completed"}}
+	data := SystemData{"2025": {
+		Year:       "2025",
+		Dimensions: map[string]DimensionData{"work": {MonthlyTasks: monthly, Settings: DimensionSettings{Scoring: ScoringSettings{CompletedScore: 100}}}},
+	}}
+
+	rows := []importRow{
+		{year: "2026", dimensionKey: "work", month: 0, task: Task{ID: "t1", Title: "新标题", Status: "not-started"}},
+		{year: "2026", dimensionKey: "work", month: 1, task: Task{Title: "新任务", Status: "not-started"}},
+	}
+	created, updated, years := mergeImportRows(data, rows)
+	if created != 1 || updated != 1 {
+		t.Fatalf("新建 %d，更新 %d", created, updated)
+	}
+	if !slices.Contains(years, "2025") || !slices.Contains(years, "2026") {
+		t.Fatalf("受影响的年度: %v", years)
+	}
+
+	location, ok := locateTasks(data)["t1"]
+	if !ok || location.year != "2026" || location.task.Title != "新标题" {
+		t.Fatalf("任务位置: %+v", location)
+	}
+	old := data["2025"].Dimensions["work"]
+	if len(old.MonthlyTasks[2]) != 0 || old.TotalTasks != 0 || old.TotalScore != 0 {
+		t.Fatalf("原年度仍保留任务: %+v", old)
+	}
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// TaskFileImportOptions todo.txt / Taskwarrior 导入选项
+type TaskFileImportOptions struct {
+	DefaultYear string `json:"defaultYear"` // 任务未注明年度且没有日期可推断时使用
+	DryRun      bool   `json:"dryRun"`      // 只校验不写入
+}
+
+// taskLocation 任务在年度数据中的位置
+type taskLocation struct {
+	year         string
+	dimensionKey string
+	month        int // 0-11
+	task         Task
+}
+
+// taskPlacement 导入文件中注明的任务位置，为空表示未注明
+type taskPlacement struct {
+	year      string
+	dimension string
+	month     string
+}
+
+// 辅助函数：按任务ID索引所有任务的位置
+func locateTasks(data SystemData) map[string]taskLocation {
+	locations := make(map[string]taskLocation)
+	for year, annual := range data {
+		for key, dimData := range annual.Dimensions {
+			for month, tasks := range dimData.MonthlyTasks {
+				for _, task := range tasks {
+					locations[task.ID] = taskLocation{year: year, dimensionKey: key, month: month, task: task}
+				}
+			}
+		}
+	}
+	return locations
+}
+
+// 辅助函数：确定导入任务的年度、维度与月份
+//
+// 优先使用文件中注明的位置，其次沿用已有任务的位置，最后按结束日期或开始日期推断。
+func resolveTaskPlacement(row *importRow, placement taskPlacement, existing *taskLocation, defaultYear string, data SystemData, fail func(field, value, message string)) {
+	dates := []string{}
+	for _, date := range []*string{row.task.EndDate, row.task.StartDate} {
+		if date != nil && *date != "" {
+			dates = append(dates, *date)
+		}
+	}
+
+	// 年度
+	row.year = placement.year
+	if row.year == "" && existing != nil {
+		row.year = existing.year
+	}
+	if row.year == "" && len(dates) > 0 {
+		row.year = dates[0][:4]
+	}
+	if row.year == "" {
+		row.year = defaultYear
+	}
+	annual, ok := data[row.year]
+	if !ok {
+		fail("年度", row.year, "年度数据不存在")
+		return
+	}
+	sameYear := existing != nil && existing.year == row.year
+
+	// 维度
+	switch {
+	case placement.dimension != "":
+		key, ok := findImportDimension(annual, placement.dimension)
+		if !ok {
+			fail("维度", placement.dimension, "维度不存在")
+		}
+		row.dimensionKey = key
+	case sameYear:
+		row.dimensionKey = existing.dimensionKey
+	default:
+		fail("维度", "", "未注明维度")
+	}
+
+	// 月份
+	switch {
+	case placement.month != "":
+		month, ok := parseImportMonth(placement.month)
+		if !ok {
+			fail("月份", placement.month, "月份必须为1-12")
+		}
+		row.month = month - 1
+	case sameYear:
+		row.month = existing.month
+	default:
+		for _, date := range dates {
+			if strings.HasPrefix(date, row.year+"-") {
+				month, _ := strconv.Atoi(date[5:7])
+				row.month = month - 1
+				return
+			}
+		}
+		fail("月份", "", fmt.Sprintf("未注明月份，且没有%s年的日期可推断", row.year))
+	}
+}
+
+// 辅助函数：标签中的空白替换为下划线，以便作为单个词写入
+func taskFileTag(tag string) string {
+	return strings.Join(strings.Fields(tag), "_")
+}
+
+// 辅助函数：将文件中的标签还原为标签名，与已有标签写法一致时沿用原标签名
+func restoreTaskFileTags(fileTags, existing []string) []string {
+	names := make(map[string]string)
+	for _, tag := range existing {
+		names[taskFileTag(tag)] = tag
+	}
+
+	tags := []string{}
+	for _, tag := range fileTags {
+		if name, ok := names[tag]; ok {
+			tags = append(tags, name)
+		} else {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
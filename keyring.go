@@ -0,0 +1,31 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/zalando/go-keyring"
+)
+
+// 辅助函数：将密码写入系统钥匙串并从设置中清除，密码为空时保留钥匙串中已有的密码
+func storeKeyringSecret(service, user string, secret *string) error {
+	if *secret == "" {
+		return nil
+	}
+	if err := keyring.Set(service, user, *secret); err != nil {
+		return fmt.Errorf("写入系统钥匙串失败: %w", err)
+	}
+	*secret = ""
+	return nil
+}
+
+// 辅助函数：设置中没有密码时从系统钥匙串读取
+func loadKeyringSecret(service, user, secret string) (string, error) {
+	if secret != "" {
+		return secret, nil
+	}
+	secret, err := keyring.Get(service, user)
+	if err != nil {
+		return "", fmt.Errorf("读取系统钥匙串失败: %w", err)
+	}
+	return secret, nil
+}
@@ -0,0 +1,1107 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// 局域网同步事件名称
+const (
+	lanSyncedEvent     = "lanSynced"
+	lanPeerPairedEvent = "lanPeerPaired"
+)
+
+// 局域网同步参数
+const (
+	defaultLANSyncPort    = 47600
+	lanPairingCodeTTL     = 5 * time.Minute
+	maxLANPairingAttempts = 5
+	lanDiscoveryTimeout   = 2 * time.Second
+	lanRequestTimeout     = 30 * time.Second
+	maxLANRequestBytes    = 64 << 20 // 64MB
+	maxLANDeviceName      = 64
+)
+
+var (
+	errLANSyncDisabled  = errors.New("局域网同步未开启")
+	errLANPeerNotPaired = errors.New("设备未配对")
+)
+
+// LANSyncSettings 局域网同步设置
+type LANSyncSettings struct {
+	Enabled         bool   `json:"enabled"`
+	DeviceName      string `json:"deviceName"`      // 在其他设备上显示的名称
+	Port            int    `json:"port"`            // 监听端口
+	IntervalMinutes int    `json:"intervalMinutes"` // 自动同步间隔（分钟）
+}
+
+// LANDeviceInfo 本机的同步身份
+type LANDeviceInfo struct {
+	DeviceID    string `json:"deviceId"`
+	DeviceName  string `json:"deviceName"`
+	Fingerprint string `json:"fingerprint"` // 证书 SHA-256 指纹，配对时核对
+	Port        int    `json:"port"`
+	Running     bool   `json:"running"`
+}
+
+// LANPairingCode 配对码，在另一台设备上输入
+type LANPairingCode struct {
+	Code      string `json:"code"`
+	ExpiresAt string `json:"expiresAt"`
+}
+
+// LANPeer 局域网中的设备
+type LANPeer struct {
+	DeviceID    string `json:"deviceId"`
+	Name        string `json:"name"`
+	Fingerprint string `json:"fingerprint"`
+	Address     string `json:"address"` // IP:端口
+	Paired      bool   `json:"paired"`
+	LastSyncAt  string `json:"lastSyncAt,omitempty"`
+}
+
+// LANPeerSyncResult 与单个设备同步的结果
+type LANPeerSyncResult struct {
+	DeviceID string `json:"deviceId"`
+	Name     string `json:"name"`
+	Sent     int    `json:"sent"`     // 发送的修改数
+	Received int    `json:"received"` // 应用的对端修改数
+	Error    string `json:"error,omitempty"`
+}
+
+// lanPeerState 已配对设备及同步进度
+type lanPeerState struct {
+	LANPeer
+	sentSeq     int64 // 对端已确认收到的本机序号
+	receivedSeq int64 // 本机已应用的对端序号
+}
+
+// lanIdentity 本机的设备ID与TLS证书
+type lanIdentity struct {
+	deviceID    string
+	certificate tls.Certificate
+	fingerprint string
+}
+
+// lanPairStartRequest 配对第一步：发起方的设备信息与 SPAKE2 消息
+type lanPairStartRequest struct {
+	DeviceID string `json:"deviceId"`
+	Name     string `json:"name"`
+	Port     int    `json:"port"`
+	Share    string `json:"share"` // hex 编码的 T
+}
+
+// lanPairStartResponse 配对第一步应答：SPAKE2 消息及应答方的确认值
+type lanPairStartResponse struct {
+	Share   string `json:"share"` // hex 编码的 S
+	Confirm string `json:"confirm"`
+}
+
+// lanPairFinishRequest 配对第二步：发起方的确认值
+type lanPairFinishRequest struct {
+	Confirm string `json:"confirm"`
+}
+
+// lanPairFinishResponse 配对完成，返回应答方的设备信息
+type lanPairFinishResponse struct {
+	DeviceID string `json:"deviceId"`
+	Name     string `json:"name"`
+}
+
+// lanPairPending 已完成第一步、等待发起方确认的配对
+type lanPairPending struct {
+	fingerprint string // 发起方的证书指纹
+	request     lanPairStartRequest
+	address     string
+	confirm     []byte // 期望的发起方确认值
+}
+
+// lanSyncRequest 同步请求：发送本机的修改，并获取对端 since 之后的修改
+type lanSyncRequest struct {
+	DeviceID string       `json:"deviceId"`
+	Port     int          `json:"port"`
+	Since    int64        `json:"since"`
+	Seq      int64        `json:"seq"` // 本次发送的修改截止的序号
+	Changes  []lanSyncRow `json:"changes"`
+}
+
+// lanSyncResponse 同步应答
+type lanSyncResponse struct {
+	Seq     int64        `json:"seq"`
+	Changes []lanSyncRow `json:"changes"`
+}
+
+// 正在运行的同步服务
+var lanServer struct {
+	mu      sync.Mutex
+	ctx     context.Context // 用于向前端发送事件
+	cancel  context.CancelFunc
+	done    chan struct{} // 服务关闭且正在处理的请求全部结束后关闭
+	port    int
+	name    string
+	pairing struct {
+		code     string
+		expires  time.Time
+		attempts int
+		pending  *lanPairPending
+	}
+}
+
+// DefaultLANSyncSettings 默认局域网同步设置
+func DefaultLANSyncSettings() LANSyncSettings {
+	name, _ := os.Hostname()
+	return LANSyncSettings{
+		Enabled:         false,
+		DeviceName:      name,
+		Port:            defaultLANSyncPort,
+		IntervalMinutes: 10,
+	}
+}
+
+// GetLANSyncSettings 获取局域网同步设置，未配置时返回默认值
+func GetLANSyncSettings() (LANSyncSettings, error) {
+	config, err := LoadConfig()
+	if err != nil {
+		return LANSyncSettings{}, err
+	}
+
+	if config.LANSync == nil {
+		return DefaultLANSyncSettings(), nil
+	}
+	return *config.LANSync, nil
+}
+
+// SaveLANSyncSettings 保存局域网同步设置
+func SaveLANSyncSettings(settings LANSyncSettings) error {
+	settings.DeviceName = strings.TrimSpace(settings.DeviceName)
+	if settings.DeviceName == "" {
+		return fmt.Errorf("设备名称不能为空")
+	}
+	if len([]rune(settings.DeviceName)) > maxLANDeviceName {
+		return fmt.Errorf("设备名称不能超过%d个字符", maxLANDeviceName)
+	}
+	if settings.Port < 1024 || settings.Port > 65535 {
+		return fmt.Errorf("端口必须在1024-65535之间")
+	}
+	if settings.IntervalMinutes <= 0 {
+		return fmt.Errorf("同步间隔必须大于0")
+	}
+
+	return UpdateConfig(func(config *Config) error {
+		config.LANSync = &settings
+		return nil
+	})
+}
+
+// GetLANDeviceInfo 获取本机的同步身份，首次调用时生成设备ID与证书
+func GetLANDeviceInfo() (LANDeviceInfo, error) {
+	identity, err := loadLANIdentity()
+	if err != nil {
+		return LANDeviceInfo{}, err
+	}
+	settings, err := GetLANSyncSettings()
+	if err != nil {
+		return LANDeviceInfo{}, err
+	}
+
+	lanServer.mu.Lock()
+	defer lanServer.mu.Unlock()
+
+	return LANDeviceInfo{
+		DeviceID:    identity.deviceID,
+		DeviceName:  settings.DeviceName,
+		Fingerprint: identity.fingerprint,
+		Port:        settings.Port,
+		Running:     lanServerRunning(),
+	}, nil
+}
+
+// StartLANPairing 生成一次性配对码，在另一台设备上输入后完成配对
+func StartLANPairing() (LANPairingCode, error) {
+	lanServer.mu.Lock()
+	defer lanServer.mu.Unlock()
+
+	if !lanServerRunning() {
+		return LANPairingCode{}, errLANSyncDisabled
+	}
+
+	n, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	if err != nil {
+		return LANPairingCode{}, fmt.Errorf("生成配对码失败: %w", err)
+	}
+	lanServer.pairing.code = fmt.Sprintf("%06d", n.Int64())
+	lanServer.pairing.expires = time.Now().Add(lanPairingCodeTTL)
+	lanServer.pairing.attempts = 0
+	lanServer.pairing.pending = nil
+
+	return LANPairingCode{
+		Code:      lanServer.pairing.code,
+		ExpiresAt: lanServer.pairing.expires.Format(time.RFC3339),
+	}, nil
+}
+
+// DiscoverLANPeers 通过 mDNS 查找局域网中开启了同步的设备
+func DiscoverLANPeers() ([]LANPeer, error) {
+	identity, err := loadLANIdentity()
+	if err != nil {
+		return nil, err
+	}
+	services, err := browseMDNS(lanDiscoveryTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("查找局域网设备失败: %w", err)
+	}
+	peers, err := loadLANPeers()
+	if err != nil {
+		return nil, err
+	}
+
+	found := []LANPeer{}
+	for _, service := range services {
+		if service.DeviceID == identity.deviceID {
+			continue
+		}
+		peer := LANPeer{
+			DeviceID:    service.DeviceID,
+			Name:        service.Name,
+			Fingerprint: service.Fingerprint,
+			Address:     service.Address,
+		}
+		if paired, ok := peers[service.DeviceID]; ok {
+			peer.Paired = true
+			peer.LastSyncAt = paired.LastSyncAt
+		}
+		found = append(found, peer)
+	}
+	return found, nil
+}
+
+// PairLANPeer 使用对方设备上显示的配对码与其配对，address 为 IP:端口
+//
+// 配对时双方以配对码进行 SPAKE2 协商，并将双方的证书指纹写入协商记录，之后只接受指纹相同的连接。
+func PairLANPeer(address, code string) (LANPeer, error) {
+	settings, err := GetLANSyncSettings()
+	if err != nil {
+		return LANPeer{}, err
+	}
+	if !settings.Enabled {
+		return LANPeer{}, errLANSyncDisabled
+	}
+	identity, err := loadLANIdentity()
+	if err != nil {
+		return LANPeer{}, err
+	}
+	address = strings.TrimSpace(address)
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		return LANPeer{}, fmt.Errorf("无效的设备地址: %s", address)
+	}
+	code = strings.TrimSpace(code)
+
+	// 配对前不知道对方的证书，先握手读取指纹，之后的请求只接受该证书，并用配对码校验
+	serverFingerprint, err := fetchLANFingerprint(identity, address)
+	if err != nil {
+		return LANPeer{}, err
+	}
+	client := newLANClient(identity, serverFingerprint)
+
+	pake, err := newLANPake(code, true)
+	if err != nil {
+		return LANPeer{}, err
+	}
+	request := lanPairStartRequest{
+		DeviceID: identity.deviceID,
+		Name:     settings.DeviceName,
+		Port:     settings.Port,
+		Share:    hex.EncodeToString(pake.share),
+	}
+	var started lanPairStartResponse
+	if err := postLANRequest(client, address, "/pair/start", request, &started); err != nil {
+		return LANPeer{}, err
+	}
+
+	// 对方的确认值正确说明对方知道配对码，且双方看到的证书相同
+	share, err := hex.DecodeString(started.Share)
+	if err != nil {
+		return LANPeer{}, errLANPakeInvalidShare
+	}
+	clientConfirm, serverConfirm, err := pake.finish(share, identity.fingerprint, serverFingerprint)
+	if err != nil {
+		return LANPeer{}, err
+	}
+	if confirm, err := hex.DecodeString(started.Confirm); err != nil || !hmac.Equal(confirm, serverConfirm) {
+		return LANPeer{}, fmt.Errorf("配对码错误，或对方设备的证书校验失败，请确认连接的是正确的设备")
+	}
+
+	var response lanPairFinishResponse
+	if err := postLANRequest(client, address, "/pair/finish", lanPairFinishRequest{Confirm: hex.EncodeToString(clientConfirm)}, &response); err != nil {
+		return LANPeer{}, err
+	}
+	if response.DeviceID == "" {
+		return LANPeer{}, fmt.Errorf("解析设备应答失败")
+	}
+
+	peer := LANPeer{
+		DeviceID:    response.DeviceID,
+		Name:        response.Name,
+		Fingerprint: serverFingerprint,
+		Address:     address,
+		Paired:      true,
+	}
+	if err := saveLANPeer(peer); err != nil {
+		return LANPeer{}, err
+	}
+	return peer, nil
+}
+
+// GetLANPeers 获取已配对的设备
+func GetLANPeers() ([]LANPeer, error) {
+	peers, err := loadLANPeers()
+	if err != nil {
+		return nil, err
+	}
+
+	list := []LANPeer{}
+	for _, peer := range peers {
+		list = append(list, peer.LANPeer)
+	}
+	return list, nil
+}
+
+// RemoveLANPeer 取消与设备的配对，对方设备上的配对需单独取消
+func RemoveLANPeer(deviceID string) error {
+	_, err := db.Exec(`DELETE FROM lan_sync_peers WHERE device_id = ?`, deviceID)
+	return err
+}
+
+// SyncLANPeers 立即与所有已配对的设备同步
+func SyncLANPeers(ctx context.Context) ([]LANPeerSyncResult, error) {
+	settings, err := GetLANSyncSettings()
+	if err != nil {
+		return nil, err
+	}
+	if !settings.Enabled {
+		return nil, errLANSyncDisabled
+	}
+	if db == nil {
+		return nil, errDatabaseNotReady
+	}
+	identity, err := loadLANIdentity()
+	if err != nil {
+		return nil, err
+	}
+	peers, err := loadLANPeers()
+	if err != nil {
+		return nil, err
+	}
+
+	results := []LANPeerSyncResult{}
+	for _, peer := range peers {
+		result := LANPeerSyncResult{DeviceID: peer.DeviceID, Name: peer.Name}
+		if err := syncLANPeer(ctx, identity, settings, peer, &result); err != nil {
+			result.Error = err.Error()
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// 辅助函数：后台启动/停止同步服务并定时与已配对设备同步，供 Scheduler 调用
+func checkLANSync(ctx context.Context) time.Duration {
+	settings, err := GetLANSyncSettings()
+	if err != nil {
+		log.Printf("读取局域网同步设置失败: %v", err)
+		return 10 * time.Minute
+	}
+	interval := time.Duration(settings.IntervalMinutes) * time.Minute
+	if interval <= 0 {
+		interval = 10 * time.Minute
+	}
+
+	if db == nil || !settings.Enabled {
+		stopLANServer()
+		return interval
+	}
+	if err := startLANServer(ctx, settings); err != nil {
+		log.Printf("启动局域网同步服务失败: %v", err)
+		return interval
+	}
+
+	results, err := SyncLANPeers(ctx)
+	if err != nil {
+		log.Printf("局域网同步失败: %v", err)
+		return interval
+	}
+	received := 0
+	for _, result := range results {
+		if result.Error != "" {
+			log.Printf("与设备 %s 同步失败: %s", result.Name, result.Error)
+		}
+		received += result.Received
+	}
+	if received > 0 {
+		runtime.EventsEmit(ctx, lanSyncedEvent, results)
+	}
+	return interval
+}
+
+// 辅助函数：启动同步服务与 mDNS 应答，已按相同设置运行时不做处理；ctx 取消时停止
+func startLANServer(ctx context.Context, settings LANSyncSettings) error {
+	lanServer.mu.Lock()
+	running := lanServerRunning() && lanServer.port == settings.Port && lanServer.name == settings.DeviceName
+	lanServer.mu.Unlock()
+	if running {
+		return nil
+	}
+	shutdownLANServer()
+
+	lanServer.mu.Lock()
+	defer lanServer.mu.Unlock()
+
+	identity, err := loadLANIdentity()
+	if err != nil {
+		return err
+	}
+	listener, err := net.Listen("tcp", ":"+strconv.Itoa(settings.Port))
+	if err != nil {
+		return fmt.Errorf("监听端口失败: %w", err)
+	}
+
+	server := &http.Server{
+		Handler:      newLANServeMux(),
+		ReadTimeout:  lanRequestTimeout,
+		WriteTimeout: lanRequestTimeout,
+		TLSConfig: &tls.Config{
+			Certificates: []tls.Certificate{identity.certificate},
+			ClientAuth:   tls.RequireAnyClientCert,
+			MinVersion:   tls.VersionTLS13,
+		},
+	}
+
+	serverCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		<-serverCtx.Done()
+		// 等待正在处理的请求结束，之后数据库才能被关闭或替换
+		if err := server.Shutdown(context.Background()); err != nil {
+			log.Printf("关闭局域网同步服务失败: %v", err)
+		}
+		close(done)
+	}()
+	go func() {
+		if err := server.ServeTLS(listener, "", ""); err != nil && err != http.ErrServerClosed {
+			log.Printf("局域网同步服务已停止: %v", err)
+		}
+	}()
+
+	// mDNS 只用于发现设备，失败时仍可手动输入地址配对
+	name := settings.DeviceName
+	err = serveMDNS(serverCtx, func() mdnsService {
+		return mdnsService{DeviceID: identity.deviceID, Name: truncateRunes(name, maxLANDeviceName), Fingerprint: identity.fingerprint}
+	}, settings.Port)
+	if err != nil {
+		log.Printf("启动mDNS失败: %v", err)
+	}
+
+	lanServer.ctx, lanServer.cancel, lanServer.done = serverCtx, cancel, done
+	lanServer.port, lanServer.name = settings.Port, settings.DeviceName
+	return nil
+}
+
+// 辅助函数：同步服务的请求路由
+func newLANServeMux() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/pair/start", handleLANPairStart)
+	mux.HandleFunc("/pair/finish", handleLANPairFinish)
+	mux.HandleFunc("/sync", handleLANSync)
+	return mux
+}
+
+// 辅助函数：停止同步服务并作废配对码，等待正在处理的请求结束后返回
+func stopLANServer() {
+	lanServer.mu.Lock()
+	lanServer.pairing.code = ""
+	lanServer.pairing.pending = nil
+	lanServer.mu.Unlock()
+
+	shutdownLANServer()
+}
+
+// 辅助函数：关闭同步服务并等待正在处理的请求结束
+//
+// 等待时不持有 lanServer.mu，配对请求处理中需要获取该锁。
+func shutdownLANServer() {
+	lanServer.mu.Lock()
+	done := lanServer.done
+	if lanServer.cancel != nil {
+		lanServer.cancel()
+		lanServer.cancel = nil
+	}
+	lanServer.done = nil
+	lanServer.mu.Unlock()
+
+	if done != nil {
+		<-done
+	}
+}
+
+// 辅助函数：同步服务是否在运行，调用方需持有 lanServer.mu
+func lanServerRunning() bool {
+	return lanServer.cancel != nil && lanServer.ctx.Err() == nil
+}
+
+// 辅助函数：处理配对第一步，每次请求消耗一次尝试机会，连续失败后配对码作废
+func handleLANPairStart(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	clientFingerprint, ok := lanClientFingerprint(r)
+	if !ok || db == nil {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	identity, err := loadLANIdentity()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var request lanPairStartRequest
+	if err := json.NewDecoder(io.LimitReader(r.Body, maxLANRequestBytes)).Decode(&request); err != nil || request.DeviceID == "" {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	share, err := hex.DecodeString(request.Share)
+	if err != nil {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+
+	// 应答中的确认值可用于验证一个猜测的配对码，因此在这一步计数
+	lanServer.mu.Lock()
+	code := lanServer.pairing.code
+	valid := code != "" && time.Now().Before(lanServer.pairing.expires)
+	if valid {
+		lanServer.pairing.attempts++
+		if lanServer.pairing.attempts >= maxLANPairingAttempts {
+			lanServer.pairing.code = ""
+		}
+	}
+	lanServer.pairing.pending = nil
+	lanServer.mu.Unlock()
+	if !valid {
+		http.Error(w, "配对码已失效，请重新生成", http.StatusForbidden)
+		return
+	}
+
+	pake, err := newLANPake(code, false)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	clientConfirm, serverConfirm, err := pake.finish(share, clientFingerprint, identity.fingerprint)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	lanServer.mu.Lock()
+	lanServer.pairing.pending = &lanPairPending{
+		fingerprint: clientFingerprint,
+		request:     request,
+		address:     lanRemoteAddress(r, request.Port),
+		confirm:     clientConfirm,
+	}
+	lanServer.mu.Unlock()
+
+	writeLANResponse(w, lanPairStartResponse{
+		Share:   hex.EncodeToString(pake.share),
+		Confirm: hex.EncodeToString(serverConfirm),
+	})
+}
+
+// 辅助函数：处理配对第二步，发起方的确认值正确时保存设备并作废配对码
+func handleLANPairFinish(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	clientFingerprint, ok := lanClientFingerprint(r)
+	if !ok || db == nil {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	identity, err := loadLANIdentity()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	settings, err := GetLANSyncSettings()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var request lanPairFinishRequest
+	if err := json.NewDecoder(io.LimitReader(r.Body, maxLANRequestBytes)).Decode(&request); err != nil {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	confirm, err := hex.DecodeString(request.Confirm)
+	if err != nil {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+
+	lanServer.mu.Lock()
+	pending := lanServer.pairing.pending
+	valid := pending != nil && pending.fingerprint == clientFingerprint && lanServer.pairing.code != "" &&
+		time.Now().Before(lanServer.pairing.expires) && hmac.Equal(confirm, pending.confirm)
+	lanServer.pairing.pending = nil
+	if valid {
+		lanServer.pairing.code = ""
+	}
+	ctx := lanServer.ctx
+	lanServer.mu.Unlock()
+	if !valid {
+		http.Error(w, "配对码错误或已过期", http.StatusForbidden)
+		return
+	}
+
+	peer := LANPeer{
+		DeviceID:    pending.request.DeviceID,
+		Name:        truncateRunes(pending.request.Name, maxLANDeviceName),
+		Fingerprint: clientFingerprint,
+		Address:     pending.address,
+		Paired:      true,
+	}
+	if err := saveLANPeer(peer); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeLANResponse(w, lanPairFinishResponse{
+		DeviceID: identity.deviceID,
+		Name:     settings.DeviceName,
+	})
+	if ctx != nil {
+		runtime.EventsEmit(ctx, lanPeerPairedEvent, peer)
+	}
+}
+
+// 辅助函数：处理同步请求，只接受已配对设备的证书
+func handleLANSync(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	clientFingerprint, ok := lanClientFingerprint(r)
+	if !ok || db == nil {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	var request lanSyncRequest
+	if err := json.NewDecoder(io.LimitReader(r.Body, maxLANRequestBytes)).Decode(&request); err != nil {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	peers, err := loadLANPeers()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	peer, ok := peers[request.DeviceID]
+	if !ok || peer.Fingerprint != clientFingerprint {
+		http.Error(w, errLANPeerNotPaired.Error(), http.StatusForbidden)
+		return
+	}
+	identity, err := loadLANIdentity()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	lanRowsMu.Lock()
+	response, applied, err := func() (lanSyncResponse, int, error) {
+		defer lanRowsMu.Unlock()
+
+		if _, err := scanLANSyncRows(identity.deviceID, time.Now()); err != nil {
+			return lanSyncResponse{}, 0, err
+		}
+		applied, err := applyLANSyncRows(request.Changes)
+		if err != nil {
+			return lanSyncResponse{}, 0, err
+		}
+		seq, err := maxLANSyncSeq()
+		if err != nil {
+			return lanSyncResponse{}, 0, err
+		}
+		changes, err := changedLANSyncRows(request.Since)
+		if err != nil {
+			return lanSyncResponse{}, 0, err
+		}
+		return lanSyncResponse{Seq: seq, Changes: changes}, applied, nil
+	}()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	_, err = db.Exec(
+		`UPDATE lan_sync_peers SET received_seq = ?, address = ?, last_sync_at = ? WHERE device_id = ?`,
+		request.Seq, lanRemoteAddress(r, request.Port), time.Now().Format(time.RFC3339), peer.DeviceID,
+	)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeLANResponse(w, response)
+	lanServer.mu.Lock()
+	ctx := lanServer.ctx
+	lanServer.mu.Unlock()
+	if applied > 0 && ctx != nil {
+		runtime.EventsEmit(ctx, lanSyncedEvent, []LANPeerSyncResult{{DeviceID: peer.DeviceID, Name: peer.Name, Received: applied}})
+	}
+}
+
+// 辅助函数：与单个设备同步，先发送本机修改并获取对端修改，再在本机应用
+//
+// 网络请求期间不持有锁，两台设备同时发起同步时不会互相等待。
+func syncLANPeer(ctx context.Context, identity lanIdentity, settings LANSyncSettings, peer lanPeerState, result *LANPeerSyncResult) error {
+	lanRowsMu.Lock()
+	request, err := func() (lanSyncRequest, error) {
+		defer lanRowsMu.Unlock()
+
+		seq, err := scanLANSyncRows(identity.deviceID, time.Now())
+		if err != nil {
+			return lanSyncRequest{}, err
+		}
+		changes, err := changedLANSyncRows(peer.sentSeq)
+		if err != nil {
+			return lanSyncRequest{}, err
+		}
+		return lanSyncRequest{DeviceID: identity.deviceID, Port: settings.Port, Since: peer.receivedSeq, Seq: seq, Changes: changes}, nil
+	}()
+	if err != nil {
+		return err
+	}
+
+	client := newLANClient(identity, peer.Fingerprint)
+
+	var response lanSyncResponse
+	address := peer.Address
+	err = postLANRequest(client, address, "/sync", request, &response)
+	if err != nil && ctx.Err() == nil {
+		// 对方地址可能已变化，通过 mDNS 重新查找
+		if found := findLANPeerAddress(peer.DeviceID); found != "" && found != address {
+			address = found
+			err = postLANRequest(client, address, "/sync", request, &response)
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	lanRowsMu.Lock()
+	applied, err := applyLANSyncRows(response.Changes)
+	lanRowsMu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(
+		`UPDATE lan_sync_peers SET sent_seq = ?, received_seq = ?, address = ?, last_sync_at = ? WHERE device_id = ?`,
+		request.Seq, response.Seq, address, time.Now().Format(time.RFC3339), peer.DeviceID,
+	)
+	if err != nil {
+		return err
+	}
+
+	result.Sent = len(request.Changes)
+	result.Received = applied
+	return nil
+}
+
+// 辅助函数：通过 mDNS 查找设备的当前地址
+func findLANPeerAddress(deviceID string) string {
+	services, err := browseMDNS(lanDiscoveryTimeout)
+	if err != nil {
+		return ""
+	}
+	for _, service := range services {
+		if service.DeviceID == deviceID {
+			return service.Address
+		}
+	}
+	return ""
+}
+
+// 辅助函数：握手读取对方证书的指纹，用于首次配对
+func fetchLANFingerprint(identity lanIdentity, address string) (string, error) {
+	dialer := &net.Dialer{Timeout: lanRequestTimeout}
+	conn, err := tls.DialWithDialer(dialer, "tcp", address, &tls.Config{
+		Certificates:       []tls.Certificate{identity.certificate},
+		InsecureSkipVerify: true,
+		MinVersion:         tls.VersionTLS13,
+	})
+	if err != nil {
+		return "", fmt.Errorf("连接设备失败: %w", err)
+	}
+	defer conn.Close()
+
+	certificates := conn.ConnectionState().PeerCertificates
+	if len(certificates) == 0 {
+		return "", fmt.Errorf("对方设备未提供证书")
+	}
+	return certificateFingerprint(certificates[0]), nil
+}
+
+// 辅助函数：创建使用本机证书的客户端，只接受指纹为 fingerprint 的对方证书
+//
+// 设备证书是自签名的，不使用 CA 校验，只按配对时记录的指纹校验。
+func newLANClient(identity lanIdentity, fingerprint string) *http.Client {
+	return &http.Client{
+		Timeout: lanRequestTimeout,
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{
+				Certificates:       []tls.Certificate{identity.certificate},
+				InsecureSkipVerify: true,
+				MinVersion:         tls.VersionTLS13,
+				VerifyConnection: func(state tls.ConnectionState) error {
+					if len(state.PeerCertificates) == 0 || certificateFingerprint(state.PeerCertificates[0]) != fingerprint {
+						return fmt.Errorf("设备证书与配对时不一致")
+					}
+					return nil
+				},
+			},
+		},
+	}
+}
+
+// 辅助函数：发送JSON请求并解析应答
+func postLANRequest(client *http.Client, address, path string, payload, response any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.Post("https://"+address+path, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("连接设备失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("设备返回错误: %s", strings.TrimSpace(string(message)))
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxLANRequestBytes)).Decode(response); err != nil {
+		return fmt.Errorf("解析设备应答失败: %w", err)
+	}
+	return nil
+}
+
+// 辅助函数：写入JSON应答
+func writeLANResponse(w http.ResponseWriter, response any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// 辅助函数：请求方证书的指纹
+func lanClientFingerprint(r *http.Request) (string, bool) {
+	if r.TLS == nil || len(r.TLS.PeerCertificates) == 0 {
+		return "", false
+	}
+	return certificateFingerprint(r.TLS.PeerCertificates[0]), true
+}
+
+// 辅助函数：请求方的同步地址（来源IP + 其声明的端口）
+func lanRemoteAddress(r *http.Request, port int) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil || port <= 0 {
+		return ""
+	}
+	return net.JoinHostPort(host, strconv.Itoa(port))
+}
+
+// 辅助函数：证书的 SHA-256 指纹
+func certificateFingerprint(certificate *x509.Certificate) string {
+	sum := sha256.Sum256(certificate.Raw)
+	return hex.EncodeToString(sum[:])
+}
+
+// 辅助函数：按字符截断
+func truncateRunes(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) > n {
+		return string(runes[:n])
+	}
+	return s
+}
+
+// 辅助函数：创建局域网同步相关的表
+func createLANSyncTables() error {
+	// 创建本机身份表，只保存一行
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS lan_sync_identity (
+			id INTEGER PRIMARY KEY CHECK (id = 1),
+			device_id TEXT,
+			cert_pem TEXT,
+			key_pem TEXT
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("创建同步身份表失败: %w", err)
+	}
+
+	// 创建已配对设备表
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS lan_sync_peers (
+			device_id TEXT PRIMARY KEY,
+			name TEXT,
+			fingerprint TEXT,
+			address TEXT,
+			sent_seq INTEGER DEFAULT 0,
+			received_seq INTEGER DEFAULT 0,
+			last_sync_at TEXT
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("创建已配对设备表失败: %w", err)
+	}
+
+	return createLANSyncRowTables()
+}
+
+// 辅助函数：读取本机身份，不存在时生成设备ID与自签名证书
+func loadLANIdentity() (lanIdentity, error) {
+	if db == nil {
+		return lanIdentity{}, errDatabaseNotReady
+	}
+
+	var deviceID, certPEM, keyPEM string
+	err := db.QueryRow(`SELECT device_id, cert_pem, key_pem FROM lan_sync_identity WHERE id = 1`).Scan(&deviceID, &certPEM, &keyPEM)
+	if err == sql.ErrNoRows {
+		deviceID = uuid.New().String()
+		if certPEM, keyPEM, err = generateLANCertificate(deviceID); err != nil {
+			return lanIdentity{}, err
+		}
+		// 并发生成时以先写入的为准
+		if _, err = db.Exec(`INSERT OR IGNORE INTO lan_sync_identity (id, device_id, cert_pem, key_pem) VALUES (1, ?, ?, ?)`, deviceID, certPEM, keyPEM); err != nil {
+			return lanIdentity{}, fmt.Errorf("保存同步身份失败: %w", err)
+		}
+		return loadLANIdentity()
+	}
+	if err != nil {
+		return lanIdentity{}, err
+	}
+
+	certificate, err := tls.X509KeyPair([]byte(certPEM), []byte(keyPEM))
+	if err != nil {
+		return lanIdentity{}, fmt.Errorf("读取同步证书失败: %w", err)
+	}
+	leaf, err := x509.ParseCertificate(certificate.Certificate[0])
+	if err != nil {
+		return lanIdentity{}, fmt.Errorf("读取同步证书失败: %w", err)
+	}
+	return lanIdentity{deviceID: deviceID, certificate: certificate, fingerprint: certificateFingerprint(leaf)}, nil
+}
+
+// 辅助函数：生成 ECDSA P-256 自签名证书
+func generateLANCertificate(deviceID string) (string, string, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return "", "", fmt.Errorf("生成同步密钥失败: %w", err)
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return "", "", err
+	}
+
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: "Manifest " + deviceID},
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.AddDate(20, 0, 0),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return "", "", fmt.Errorf("生成同步证书失败: %w", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return "", "", err
+	}
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	return string(certPEM), string(keyPEM), nil
+}
+
+// 辅助函数：读取已配对设备，key 为设备ID
+func loadLANPeers() (map[string]lanPeerState, error) {
+	if db == nil {
+		return nil, errDatabaseNotReady
+	}
+
+	rows, err := db.Query(`SELECT device_id, name, fingerprint, address, sent_seq, received_seq, last_sync_at FROM lan_sync_peers ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	peers := make(map[string]lanPeerState)
+	for rows.Next() {
+		var peer lanPeerState
+		var address, lastSyncAt sql.NullString
+		if err := rows.Scan(&peer.DeviceID, &peer.Name, &peer.Fingerprint, &address, &peer.sentSeq, &peer.receivedSeq, &lastSyncAt); err != nil {
+			return nil, err
+		}
+		peer.Address, peer.LastSyncAt, peer.Paired = address.String, lastSyncAt.String, true
+		peers[peer.DeviceID] = peer
+	}
+	return peers, rows.Err()
+}
+
+// 辅助函数：保存配对的设备，重新配对时重置同步进度
+func saveLANPeer(peer LANPeer) error {
+	_, err := db.Exec(
+		`INSERT OR REPLACE INTO lan_sync_peers (device_id, name, fingerprint, address, sent_seq, received_seq, last_sync_at) VALUES (?, ?, ?, ?, 0, 0, '')`,
+		peer.DeviceID, peer.Name, peer.Fingerprint, peer.Address,
+	)
+	if err != nil {
+		return fmt.Errorf("保存配对设备失败: %w", err)
+	}
+	return nil
+}
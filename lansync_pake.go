@@ -0,0 +1,194 @@
+package main
+
+import (
+	"crypto/hkdf"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"filippo.io/bigmod"
+	"filippo.io/nistec"
+)
+
+// 配对使用 SPAKE2（RFC 9382）over P-256：配对码只用于派生共享密钥，
+// 截获或冒充任一方的攻击者每次连接只能猜测一个配对码，无法离线穷举。
+//
+// 双方的证书指纹写入协商记录，中间人替换证书时双方得到的密钥不同，确认值校验失败。
+// 点运算与标量运算都使用常数时间的实现，耗时不随配对码或私钥变化。
+
+// SPAKE2 的 M、N 点，由固定标签哈希到曲线上得到，没有人知道它们的离散对数
+var (
+	lanPakeM = hashToP256("Manifest SPAKE2 P-256 M")
+	lanPakeN = hashToP256("Manifest SPAKE2 P-256 N")
+)
+
+// P-256 的阶 n
+var lanPakeOrder = mustModulus("ffffffff00000000ffffffffffffffffbce6faada7179e84f3b9cac2fc632551")
+
+var errLANPakeInvalidShare = errors.New("无效的配对消息")
+
+// lanPake 一方的 SPAKE2 状态，client 为发起配对的一方（RFC 中的 A）
+type lanPake struct {
+	client  bool
+	w       *bigmod.Nat
+	private []byte // 32 字节大端序标量
+	share   []byte // 发送给对方的消息：client 为 T = X + w·M，server 为 S = Y + w·N
+}
+
+// 辅助函数：以配对码开始一次 SPAKE2 协商
+func newLANPake(code string, client bool) (*lanPake, error) {
+	private, err := lanPakePrivateKey()
+	if err != nil {
+		return nil, err
+	}
+
+	w := lanPakePassword(code)
+	blind := lanPakeM
+	if !client {
+		blind = lanPakeN
+	}
+
+	x, err := nistec.NewP256Point().ScalarBaseMult(private)
+	if err != nil {
+		return nil, err
+	}
+	masked, err := nistec.NewP256Point().ScalarMult(blind, w.Bytes(lanPakeOrder))
+	if err != nil {
+		return nil, err
+	}
+	return &lanPake{
+		client:  client,
+		w:       w,
+		private: private,
+		share:   masked.Add(masked, x).Bytes(),
+	}, nil
+}
+
+// 辅助函数：根据对方的消息计算双方的确认值
+//
+// 返回 client 与 server 的确认值；本方发送自己的确认值，并校验对方发来的确认值。
+func (p *lanPake) finish(peerShare []byte, clientFingerprint, serverFingerprint string) (clientConfirm, serverConfirm []byte, err error) {
+	// 只接受非压缩格式的点，SetBytes 会校验点在曲线上
+	if len(peerShare) != 65 || peerShare[0] != 4 {
+		return nil, nil, errLANPakeInvalidShare
+	}
+	peer, err := nistec.NewP256Point().SetBytes(peerShare)
+	if err != nil {
+		return nil, nil, errLANPakeInvalidShare
+	}
+
+	// 去掉对方消息中的 w·N（或 w·M）：K = private·(peer − w·blind)
+	blind := lanPakeN
+	if !p.client {
+		blind = lanPakeM
+	}
+	negW := bigmod.NewNat().ExpandFor(lanPakeOrder).Sub(p.w, lanPakeOrder)
+	unmask, err := nistec.NewP256Point().ScalarMult(blind, negW.Bytes(lanPakeOrder))
+	if err != nil {
+		return nil, nil, err
+	}
+	k, err := nistec.NewP256Point().ScalarMult(unmask.Add(unmask, peer), p.private)
+	if err != nil {
+		return nil, nil, err
+	}
+	sharedKey := k.Bytes()
+	if len(sharedKey) == 1 {
+		// 无穷远点
+		return nil, nil, errLANPakeInvalidShare
+	}
+
+	t, s := p.share, peerShare
+	if !p.client {
+		t, s = peerShare, p.share
+	}
+	transcript := lanPakeTranscript(
+		[]byte(clientFingerprint),
+		[]byte(serverFingerprint),
+		t,
+		s,
+		sharedKey,
+		p.w.Bytes(lanPakeOrder),
+	)
+
+	// Ke || Ka = Hash(TT)，KcA || KcB = KDF(Ka, "ConfirmationKeys")
+	hash := sha256.Sum256(transcript)
+	keys, err := hkdf.Key(sha256.New, hash[sha256.Size/2:], nil, "ConfirmationKeys", 2*sha256.Size)
+	if err != nil {
+		return nil, nil, err
+	}
+	return lanPakeMAC(keys[:sha256.Size], transcript), lanPakeMAC(keys[sha256.Size:], transcript), nil
+}
+
+// 辅助函数：生成范围 [1, n-1] 内的随机私钥
+func lanPakePrivateKey() ([]byte, error) {
+	private := make([]byte, 32)
+	for {
+		if _, err := rand.Read(private); err != nil {
+			return nil, fmt.Errorf("生成配对密钥失败: %w", err)
+		}
+		scalar, err := bigmod.NewNat().SetBytes(private, lanPakeOrder)
+		if err == nil && scalar.IsZero() == 0 {
+			return private, nil
+		}
+	}
+}
+
+// 辅助函数：配对码对应的标量 w = H(code) mod n
+func lanPakePassword(code string) *bigmod.Nat {
+	sum := sha256.Sum256([]byte("Manifest SPAKE2 pairing code\x00" + code))
+	// 256 位的哈希值小于 2n，SetOverflowingBytes 最多减去一次 n 即完成取模
+	w, err := bigmod.NewNat().SetOverflowingBytes(sum[:], lanPakeOrder)
+	if err != nil {
+		panic(err)
+	}
+	return w
+}
+
+// 辅助函数：按 RFC 9382 的格式拼接协商记录，每项前加 8 字节小端长度
+func lanPakeTranscript(parts ...[]byte) []byte {
+	transcript := []byte{}
+	for _, part := range parts {
+		transcript = binary.LittleEndian.AppendUint64(transcript, uint64(len(part)))
+		transcript = append(transcript, part...)
+	}
+	return transcript
+}
+
+// 辅助函数：HMAC-SHA256
+func lanPakeMAC(key, data []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+	return mac.Sum(nil)
+}
+
+// 辅助函数：将标签哈希到 P-256 上的点（try-and-increment），取 y 为偶数的点
+//
+// 以哈希值作为压缩格式（0x02 前缀）的 x 坐标解码，不在曲线上时递增计数器重试。
+func hashToP256(label string) *nistec.P256Point {
+	for counter := uint32(0); ; counter++ {
+		var suffix [4]byte
+		binary.BigEndian.PutUint32(suffix[:], counter)
+		sum := sha256.Sum256(append([]byte(label), suffix[:]...))
+		point, err := nistec.NewP256Point().SetBytes(append([]byte{2}, sum[:]...))
+		if err == nil {
+			return point
+		}
+	}
+}
+
+// 辅助函数：由十六进制常量创建模数
+func mustModulus(n string) *bigmod.Modulus {
+	b, err := hex.DecodeString(n)
+	if err != nil {
+		panic(err)
+	}
+	m, err := bigmod.NewModulus(b)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
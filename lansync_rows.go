@@ -0,0 +1,661 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 局域网同步的数据单元
+const (
+	lanEntityYear      = "year"
+	lanEntityDimension = "dimension"
+	lanEntityTask      = "task"
+	lanEntityTag       = "tag"
+)
+
+// lanSyncRow 一条同步记录：一个年度、维度、任务或标签的当前内容及最后修改时间
+type lanSyncRow struct {
+	Entity    string          `json:"entity"`
+	Key       string          `json:"key"`
+	Content   json.RawMessage `json:"content,omitempty"` // 已删除时为空
+	Deleted   bool            `json:"deleted"`
+	UpdatedAt int64           `json:"updatedAt"` // 毫秒时间戳
+	Origin    string          `json:"origin"`    // 最后修改的设备ID
+	hash      string
+	seq       int64
+}
+
+// lanYearContent 年度记录内容，不包含由任务计算出的总分
+type lanYearContent struct {
+	Settings AnnualSettings `json:"settings"`
+}
+
+// lanDimensionContent 维度记录内容，不包含由任务计算出的统计数据
+type lanDimensionContent struct {
+	Year           string            `json:"year"`
+	Config         DimensionConfig   `json:"config"`
+	AnnualGoal     string            `json:"annualGoal"`
+	QuarterlyGoals []string          `json:"quarterlyGoals"`
+	Settings       DimensionSettings `json:"settings"`
+}
+
+// lanTaskContent 任务记录内容，包含任务所在的位置
+type lanTaskContent struct {
+	Year      string `json:"year"`
+	Dimension string `json:"dimension"`
+	Month     int    `json:"month"`
+	Task      Task   `json:"task"`
+}
+
+// lanTagContent 标签记录内容，不同设备上的标签ID不同，按名称对应
+type lanTagContent struct {
+	Name  string `json:"name"`
+	Color string `json:"color"`
+}
+
+// 扫描与应用同步记录时加锁，避免与对端的请求同时修改
+var lanRowsMu sync.Mutex
+
+// 定时记录本机修改的间隔，未通过 Refresh 通知的修改最多延迟这么久记录
+const lanChangeScanInterval = time.Minute
+
+// 辅助函数：创建同步记录表
+func createLANSyncRowTables() error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS lan_sync_rows (
+			entity TEXT,
+			row_key TEXT,
+			content TEXT,
+			hash TEXT,
+			deleted INTEGER,
+			updated_at INTEGER,
+			origin TEXT,
+			seq INTEGER,
+			PRIMARY KEY (entity, row_key)
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("创建同步记录表失败: %w", err)
+	}
+
+	_, err = db.Exec(`CREATE INDEX IF NOT EXISTS idx_lan_sync_rows_seq ON lan_sync_rows(seq)`)
+	if err != nil {
+		return fmt.Errorf("创建同步记录索引失败: %w", err)
+	}
+	return nil
+}
+
+// 辅助函数：对比当前数据与上次记录，为有变化的记录更新修改时间与序号，返回当前最大序号
+//
+// 前端每次保存都会重写整个年度，因此按内容哈希判断每条记录是否真正修改。
+func scanLANSyncRows(deviceID string, now time.Time) (int64, error) {
+	snapshot, err := snapshotLANSyncRows()
+	if err != nil {
+		return 0, err
+	}
+	existing, err := loadLANSyncRows()
+	if err != nil {
+		return 0, err
+	}
+	seq, err := maxLANSyncSeq()
+	if err != nil {
+		return 0, err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	stamp := now.UnixMilli()
+	for id, row := range snapshot {
+		if old, ok := existing[id]; ok && !old.Deleted && old.hash == row.hash {
+			continue
+		}
+		seq++
+		row.UpdatedAt, row.Origin, row.seq = stamp, deviceID, seq
+		if err := putLANSyncRow(tx, row); err != nil {
+			return 0, err
+		}
+	}
+
+	// 当前数据中已不存在的记录标记为删除
+	for id, old := range existing {
+		if _, ok := snapshot[id]; ok || old.Deleted || old.Entity == "" {
+			continue
+		}
+		seq++
+		tombstone := lanSyncRow{Entity: old.Entity, Key: old.Key, Deleted: true, UpdatedAt: stamp, Origin: deviceID, seq: seq}
+		if err := putLANSyncRow(tx, tombstone); err != nil {
+			return 0, err
+		}
+	}
+
+	return seq, tx.Commit()
+}
+
+// 辅助函数：记录本机修改的时间，供 Scheduler 调用，保存数据后通过 Refresh 立即执行
+//
+// 修改时间以发现修改的时间为准；只在同步时扫描会把离线期间的修改都记为同步时间，
+// 从而覆盖对端在此之后的修改。未启用同步且从未同步过时不记录。
+func checkLANSyncChanges(ctx context.Context) time.Duration {
+	if db == nil {
+		return lanChangeScanInterval
+	}
+	tracking, err := lanChangeTrackingEnabled()
+	if err != nil {
+		log.Printf("读取同步设置失败: %v", err)
+		return lanChangeScanInterval
+	}
+	if !tracking {
+		return lanChangeScanInterval
+	}
+	if err := recordLANSyncChanges(time.Now()); err != nil {
+		log.Printf("记录本机修改失败: %v", err)
+	}
+	return lanChangeScanInterval
+}
+
+// 辅助函数：启用了局域网同步或共享目录同步，或已有同步记录时需要记录修改时间
+func lanChangeTrackingEnabled() (bool, error) {
+	lanSettings, err := GetLANSyncSettings()
+	if err != nil {
+		return false, err
+	}
+	folderSettings, err := GetFolderSyncSettings()
+	if err != nil {
+		return false, err
+	}
+	if lanSettings.Enabled || folderSettings.Enabled {
+		return true, nil
+	}
+	var exists bool
+	err = db.QueryRow(`SELECT EXISTS (SELECT 1 FROM lan_sync_rows)`).Scan(&exists)
+	return exists, err
+}
+
+// 辅助函数：以 now 作为修改时间记录本机自上次扫描以来的修改
+func recordLANSyncChanges(now time.Time) error {
+	identity, err := loadLANIdentity()
+	if err != nil {
+		return err
+	}
+	lanRowsMu.Lock()
+	defer lanRowsMu.Unlock()
+
+	_, err = scanLANSyncRows(identity.deviceID, now)
+	return err
+}
+
+// 辅助函数：获取序号大于 since 的记录
+func changedLANSyncRows(since int64) ([]lanSyncRow, error) {
+	rows, err := db.Query(
+		`SELECT entity, row_key, content, hash, deleted, updated_at, origin, seq FROM lan_sync_rows WHERE seq > ? AND entity != '' ORDER BY seq`,
+		since,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	changes := []lanSyncRow{}
+	for rows.Next() {
+		row, err := scanLANSyncRow(rows)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, row)
+	}
+	return changes, rows.Err()
+}
+
+// 辅助函数：合并对端的修改，同一记录以修改时间较新的为准，返回实际应用的记录数
+func applyLANSyncRows(remote []lanSyncRow) (int, error) {
+	existing, err := loadLANSyncRows()
+	if err != nil {
+		return 0, err
+	}
+
+	winners := []lanSyncRow{}
+	for _, row := range remote {
+		if row.Entity == "" || row.Key == "" {
+			continue
+		}
+		if !row.Deleted {
+			row.hash = contentHash(string(row.Content))
+		}
+		local, ok := existing[lanRowID(row.Entity, row.Key)]
+		if ok && !lanRowNewer(row, local) {
+			continue
+		}
+		if ok && local.Deleted == row.Deleted && local.hash == row.hash {
+			continue
+		}
+		winners = append(winners, row)
+	}
+	if len(winners) == 0 {
+		return 0, nil
+	}
+
+	data, err := GetAllAnnualData()
+	if err != nil {
+		return 0, err
+	}
+	if err := applyLANSyncChanges(data, winners); err != nil {
+		return 0, err
+	}
+
+	// 按应用后的本地内容记录哈希，保留对端的修改时间，避免下次扫描时被当作本地修改
+	snapshot, err := snapshotLANSyncRows()
+	if err != nil {
+		return 0, err
+	}
+	seq, err := maxLANSyncSeq()
+	if err != nil {
+		return 0, err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	for _, row := range winners {
+		seq++
+		record := lanSyncRow{Entity: row.Entity, Key: row.Key, Deleted: true, UpdatedAt: row.UpdatedAt, Origin: row.Origin, seq: seq}
+		if current, ok := snapshot[lanRowID(row.Entity, row.Key)]; ok {
+			record.Content, record.hash, record.Deleted = current.Content, current.hash, false
+		}
+		if err := putLANSyncRow(tx, record); err != nil {
+			return 0, err
+		}
+	}
+	return len(winners), tx.Commit()
+}
+
+// 辅助函数：按记录修改本地数据
+func applyLANSyncChanges(data SystemData, rows []lanSyncRow) error {
+	// 依次处理标签、年度、维度、任务，删除按相反顺序处理
+	order := map[string]int{lanEntityTag: 0, lanEntityYear: 1, lanEntityDimension: 2, lanEntityTask: 3}
+	sort.SliceStable(rows, func(i, j int) bool {
+		if rows[i].Deleted != rows[j].Deleted {
+			return !rows[i].Deleted
+		}
+		if rows[i].Deleted {
+			return order[rows[i].Entity] > order[rows[j].Entity]
+		}
+		return order[rows[i].Entity] < order[rows[j].Entity]
+	})
+
+	touched := make(map[string]bool)
+	deletedYears := []string{}
+	deletedDimensions := [][2]string{}
+	deletedTasks := []string{}
+	deletedTags := []string{}
+
+	for _, row := range rows {
+		switch row.Entity {
+		case lanEntityTag:
+			if row.Deleted {
+				deletedTags = append(deletedTags, row.Key)
+				continue
+			}
+			var content lanTagContent
+			if err := json.Unmarshal(row.Content, &content); err != nil {
+				return fmt.Errorf("解析同步标签失败: %w", err)
+			}
+			if err := putLANSyncTag(content); err != nil {
+				return err
+			}
+
+		case lanEntityYear:
+			if row.Deleted {
+				delete(data, row.Key)
+				deletedYears = append(deletedYears, row.Key)
+				continue
+			}
+			var content lanYearContent
+			if err := json.Unmarshal(row.Content, &content); err != nil {
+				return fmt.Errorf("解析同步年度失败: %w", err)
+			}
+			annual := ensureLANSyncYear(data, row.Key)
+			annual.Settings = content.Settings
+			data[row.Key] = annual
+			touched[row.Key] = true
+
+		case lanEntityDimension:
+			year, key, _ := strings.Cut(row.Key, "/")
+			if row.Deleted {
+				if annual, ok := data[year]; ok {
+					delete(annual.Dimensions, key)
+					configs := annual.DimensionConfigs[:0]
+					for _, config := range annual.DimensionConfigs {
+						if config.Key != key {
+							configs = append(configs, config)
+						}
+					}
+					annual.DimensionConfigs = configs
+					data[year] = annual
+					touched[year] = true
+				}
+				deletedDimensions = append(deletedDimensions, [2]string{year, key})
+				continue
+			}
+			var content lanDimensionContent
+			if err := json.Unmarshal(row.Content, &content); err != nil {
+				return fmt.Errorf("解析同步维度失败: %w", err)
+			}
+			annual := ensureLANSyncYear(data, year)
+			replaced := false
+			for i, config := range annual.DimensionConfigs {
+				if config.Key == key {
+					annual.DimensionConfigs[i], replaced = content.Config, true
+				}
+			}
+			if !replaced {
+				annual.DimensionConfigs = append(annual.DimensionConfigs, content.Config)
+			}
+			dimData, ok := annual.Dimensions[key]
+			if !ok {
+				dimData = newDimensionData()
+			}
+			dimData.AnnualGoal = content.AnnualGoal
+			dimData.QuarterlyGoals = content.QuarterlyGoals
+			dimData.Settings = content.Settings
+			annual.Dimensions[key] = dimData
+			data[year] = annual
+			touched[year] = true
+
+		case lanEntityTask:
+			// 先从原位置移除
+			for year, annual := range data {
+				keys := make(map[string]bool)
+				if removeImportedTask(&annual, row.Key, keys) {
+					data[year] = annual
+					touched[year] = true
+				}
+			}
+			if row.Deleted {
+				deletedTasks = append(deletedTasks, row.Key)
+				continue
+			}
+			var content lanTaskContent
+			if err := json.Unmarshal(row.Content, &content); err != nil {
+				return fmt.Errorf("解析同步任务失败: %w", err)
+			}
+			if content.Month < 0 || content.Month >= 12 {
+				return fmt.Errorf("同步任务的月份无效: %s", row.Key)
+			}
+			annual := ensureLANSyncYear(data, content.Year)
+			dimData, ok := annual.Dimensions[content.Dimension]
+			if !ok {
+				dimData = newDimensionData()
+			}
+			for len(dimData.MonthlyTasks) < 12 {
+				dimData.MonthlyTasks = append(dimData.MonthlyTasks, nil)
+			}
+			content.Task.ID = row.Key
+			if content.Task.Tags == nil {
+				content.Task.Tags = []string{}
+			}
+			dimData.MonthlyTasks[content.Month] = append(dimData.MonthlyTasks[content.Month], content.Task)
+			annual.Dimensions[content.Dimension] = dimData
+			data[content.Year] = annual
+			touched[content.Year] = true
+		}
+	}
+
+	// 重新计算得分后保存
+	for year := range touched {
+		annual, ok := data[year]
+		if !ok {
+			continue
+		}
+		for key, dimData := range annual.Dimensions {
+			updateDimensionStats(&dimData)
+			annual.Dimensions[key] = dimData
+		}
+		updateAnnualTotalScore(&annual)
+		if err := SaveAnnualData(annual); err != nil {
+			return fmt.Errorf("保存年度数据失败: %w", err)
+		}
+	}
+
+	for _, id := range deletedTasks {
+		if err := DeleteTask(id); err != nil {
+			return err
+		}
+	}
+	for _, dimension := range deletedDimensions {
+		if err := deleteLANSyncDimension(dimension[0], dimension[1]); err != nil {
+			return err
+		}
+	}
+	for _, year := range deletedYears {
+		if err := DeleteAnnualData(year); err != nil {
+			return err
+		}
+	}
+	for _, name := range deletedTags {
+		tag, err := getTagByName(db, name)
+		if err != nil {
+			return err
+		}
+		if tag != nil {
+			if err := DeleteTag(tag.ID); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// 辅助函数：生成当前数据的所有同步记录
+func snapshotLANSyncRows() (map[string]lanSyncRow, error) {
+	data, err := GetAllAnnualData()
+	if err != nil {
+		return nil, err
+	}
+	tags, err := GetTags()
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make(map[string]lanSyncRow)
+	add := func(entity, key string, content any) error {
+		encoded, err := json.Marshal(content)
+		if err != nil {
+			return err
+		}
+		rows[lanRowID(entity, key)] = lanSyncRow{Entity: entity, Key: key, Content: encoded, hash: contentHash(string(encoded))}
+		return nil
+	}
+
+	for year, annual := range data {
+		if err := add(lanEntityYear, year, lanYearContent{Settings: annual.Settings}); err != nil {
+			return nil, err
+		}
+		for _, config := range orderedDimensionConfigs(annual) {
+			dimData := annual.Dimensions[config.Key]
+			goals := make([]string, 4)
+			copy(goals, dimData.QuarterlyGoals)
+			content := lanDimensionContent{
+				Year:           year,
+				Config:         config,
+				AnnualGoal:     dimData.AnnualGoal,
+				QuarterlyGoals: goals,
+				Settings:       dimData.Settings,
+			}
+			if err := add(lanEntityDimension, year+"/"+config.Key, content); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for id, location := range locateTasks(data) {
+		task := location.task
+		task.Tags = append([]string{}, task.Tags...)
+		sort.Strings(task.Tags)
+		content := lanTaskContent{Year: location.year, Dimension: location.dimensionKey, Month: location.month, Task: task}
+		if err := add(lanEntityTask, id, content); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, tag := range tags {
+		if err := add(lanEntityTag, strings.ToLower(tag.Name), lanTagContent{Name: tag.Name, Color: tag.Color}); err != nil {
+			return nil, err
+		}
+	}
+	return rows, nil
+}
+
+// 辅助函数：读取所有同步记录
+func loadLANSyncRows() (map[string]lanSyncRow, error) {
+	rows, err := db.Query(`SELECT entity, row_key, content, hash, deleted, updated_at, origin, seq FROM lan_sync_rows`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	records := make(map[string]lanSyncRow)
+	for rows.Next() {
+		row, err := scanLANSyncRow(rows)
+		if err != nil {
+			return nil, err
+		}
+		records[lanRowID(row.Entity, row.Key)] = row
+	}
+	return records, rows.Err()
+}
+
+// 辅助函数：扫描一行同步记录
+func scanLANSyncRow(row interface{ Scan(...any) error }) (lanSyncRow, error) {
+	var record lanSyncRow
+	var content, hash sql.NullString
+	if err := row.Scan(&record.Entity, &record.Key, &content, &hash, &record.Deleted, &record.UpdatedAt, &record.Origin, &record.seq); err != nil {
+		return lanSyncRow{}, err
+	}
+	if content.String != "" {
+		record.Content = json.RawMessage(content.String)
+	}
+	record.hash = hash.String
+	return record, nil
+}
+
+// 辅助函数：保存同步记录
+func putLANSyncRow(q queryer, row lanSyncRow) error {
+	_, err := q.Exec(
+		`INSERT OR REPLACE INTO lan_sync_rows (entity, row_key, content, hash, deleted, updated_at, origin, seq) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		row.Entity, row.Key, string(row.Content), row.hash, row.Deleted, row.UpdatedAt, row.Origin, row.seq,
+	)
+	if err != nil {
+		return fmt.Errorf("保存同步记录失败: %w", err)
+	}
+	return nil
+}
+
+// 辅助函数：当前最大序号
+func maxLANSyncSeq() (int64, error) {
+	var seq sql.NullInt64
+	if err := db.QueryRow(`SELECT MAX(seq) FROM lan_sync_rows`).Scan(&seq); err != nil {
+		return 0, err
+	}
+	return seq.Int64, nil
+}
+
+// 辅助函数：对端记录是否比本地记录新，修改时间相同时按设备ID决定，保证两端结果一致
+func lanRowNewer(remote, local lanSyncRow) bool {
+	if remote.UpdatedAt != local.UpdatedAt {
+		return remote.UpdatedAt > local.UpdatedAt
+	}
+	return remote.Origin > local.Origin
+}
+
+// 辅助函数：同步记录的唯一标识
+func lanRowID(entity, key string) string {
+	return entity + ":" + key
+}
+
+// 辅助函数：获取年度数据，不存在时创建
+func ensureLANSyncYear(data SystemData, year string) AnnualData {
+	annual, ok := data[year]
+	if !ok {
+		annual = AnnualData{Year: year, DimensionConfigs: []DimensionConfig{}}
+	}
+	if annual.Dimensions == nil {
+		annual.Dimensions = map[string]DimensionData{}
+	}
+	return annual
+}
+
+// 辅助函数：按名称创建或更新标签颜色
+func putLANSyncTag(content lanTagContent) error {
+	tag, err := getTagByName(db, content.Name)
+	if err != nil {
+		return err
+	}
+	if tag == nil {
+		tag = &Tag{}
+	}
+	tag.Name, tag.Color = content.Name, content.Color
+	_, err = SaveTag(*tag)
+	return err
+}
+
+// 辅助函数：删除年度中的一个维度，SaveAnnualData 不会删除已移除的维度
+func deleteLANSyncDimension(year, key string) (err error) {
+	// 开始事务
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	for _, query := range []string{
+		`DELETE FROM monthly_tasks WHERE year = ? AND dimension_key = ?`,
+		`DELETE FROM quarterly_goals WHERE year = ? AND dimension_key = ?`,
+		`DELETE FROM dimension_data WHERE year = ? AND dimension_key = ?`,
+		`DELETE FROM dimension_configs WHERE year = ? AND key = ?`,
+	} {
+		if _, err = tx.Exec(query, year, key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// 辅助函数：清空数据后重置同步记录，之后与对端同步时重新获取全部数据
+//
+// 保留一条已删除的占位记录以延续序号，对端按序号获取修改，序号不能回退。
+func resetLANSyncRows(tx *sql.Tx) error {
+	var seq sql.NullInt64
+	if err := tx.QueryRow(`SELECT MAX(seq) FROM lan_sync_rows`).Scan(&seq); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM lan_sync_rows`); err != nil {
+		return err
+	}
+	if seq.Int64 > 0 {
+		if err := putLANSyncRow(tx, lanSyncRow{Deleted: true, seq: seq.Int64}); err != nil {
+			return err
+		}
+	}
+	_, err := tx.Exec(`UPDATE lan_sync_peers SET sent_seq = 0, received_seq = 0`)
+	return err
+}
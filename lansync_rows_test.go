@@ -0,0 +1,47 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// 修改时间是保存后记录的时间，而不是之后同步时扫描的时间
+func TestLANSyncRowsKeepModificationTime(t *testing.T) {
+	setupTestDB(t)
+
+	// 未启用同步且没有同步记录时不记录
+	checkLANSyncChanges(context.Background())
+	if rows, err := loadLANSyncRows(); err != nil || len(rows) != 0 {
+		t.Fatalf("未启用同步时的记录: %v %v", rows, err)
+	}
+
+	settings := DefaultLANSyncSettings()
+	settings.Enabled = true
+	if err := SaveLANSyncSettings(settings); err != nil {
+		t.Fatal(err)
+	}
+	saveTestTask(t, "2026", "work", Task{ID: "t1", Title: "任务", Status: "not-started", Priority: "medium"})
+	before := time.Now()
+	checkLANSyncChanges(context.Background())
+
+	// 之后的同步扫描不改变未修改记录的时间
+	identity, err := loadLANIdentity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := scanLANSyncRows(identity.deviceID, before.Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := loadLANSyncRows()
+	if err != nil {
+		t.Fatal(err)
+	}
+	row, ok := rows[lanRowID(lanEntityTask, "t1")]
+	if !ok {
+		t.Fatalf("缺少任务记录: %v", rows)
+	}
+	if row.UpdatedAt < before.Add(-time.Second).UnixMilli() || row.UpdatedAt > time.Now().UnixMilli() {
+		t.Fatalf("修改时间 %d 不是保存后记录的时间 %d", row.UpdatedAt, before.UnixMilli())
+	}
+}
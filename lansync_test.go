@@ -0,0 +1,163 @@
+package main
+
+import (
+	"bytes"
+	"crypto/tls"
+	"net"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestLANPake(t *testing.T) {
+	// M、N 由 SetBytes 解码，一定在曲线上
+	if bytes.Equal(lanPakeM.Bytes(), lanPakeN.Bytes()) || len(lanPakeM.Bytes()) != 65 {
+		t.Fatal("M、N 必须是曲线上不同的点")
+	}
+
+	exchange := func(clientCode, serverCode, clientView, serverView string) (bool, bool) {
+		client, err := newLANPake(clientCode, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		server, err := newLANPake(serverCode, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// clientView/serverView 为各自看到的对方证书指纹
+		clientA, clientB, err := client.finish(server.share, "client", clientView)
+		if err != nil {
+			t.Fatal(err)
+		}
+		serverA, serverB, err := server.finish(client.share, serverView, "server")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return bytes.Equal(clientA, serverA), bytes.Equal(clientB, serverB)
+	}
+
+	if a, b := exchange("123456", "123456", "server", "client"); !a || !b {
+		t.Fatal("配对码相同时双方的确认值应一致")
+	}
+	if a, b := exchange("123456", "123457", "server", "client"); a || b {
+		t.Fatal("配对码不同时确认值不应一致")
+	}
+	if a, b := exchange("123456", "123456", "attacker", "client"); a || b {
+		t.Fatal("证书指纹不同时确认值不应一致")
+	}
+
+	client, _ := newLANPake("123456", true)
+	for _, share := range [][]byte{[]byte("not a point"), {0}, lanPakeM.BytesCompressed()} {
+		if _, _, err := client.finish(share, "client", "server"); err == nil {
+			t.Fatalf("无效的消息 %x 应返回错误", share)
+		}
+	}
+}
+
+// 辅助函数：启用局域网同步并以本机身份启动同步服务，返回服务地址
+func startTestLANServer(t *testing.T) (lanIdentity, string) {
+	t.Helper()
+	setupTestDB(t)
+	settings := DefaultLANSyncSettings()
+	settings.Enabled = true
+	if err := SaveLANSyncSettings(settings); err != nil {
+		t.Fatal(err)
+	}
+	identity, err := loadLANIdentity()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewUnstartedServer(newLANServeMux())
+	server.TLS = &tls.Config{
+		Certificates: []tls.Certificate{identity.certificate},
+		ClientAuth:   tls.RequireAnyClientCert,
+		MinVersion:   tls.VersionTLS13,
+	}
+	server.StartTLS()
+	t.Cleanup(server.Close)
+	t.Cleanup(stopLANServer)
+	return identity, server.Listener.Addr().String()
+}
+
+// 辅助函数：设置当前的配对码，不启动 mDNS
+func setTestPairingCode(code string) {
+	lanServer.mu.Lock()
+	defer lanServer.mu.Unlock()
+	lanServer.pairing.code, lanServer.pairing.expires, lanServer.pairing.attempts = code, time.Now().Add(time.Minute), 0
+}
+
+// 配对与同步的完整握手；同一进程中两端使用相同的数据库与身份
+func TestLANPairingAndSync(t *testing.T) {
+	identity, address := startTestLANServer(t)
+
+	setTestPairingCode("123456")
+	if _, err := PairLANPeer(address, "654321"); err == nil {
+		t.Fatal("配对码错误时应配对失败")
+	}
+	if peers, _ := loadLANPeers(); len(peers) != 0 {
+		t.Fatalf("配对失败时保存了设备: %v", peers)
+	}
+
+	peer, err := PairLANPeer(address, "123456")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if peer.DeviceID != identity.deviceID || peer.Fingerprint != identity.fingerprint {
+		t.Fatalf("配对的设备: %+v", peer)
+	}
+	if _, err := PairLANPeer(address, "123456"); err == nil {
+		t.Fatal("配对码只能使用一次")
+	}
+
+	results, err := SyncLANPeers(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].Error != "" {
+		t.Fatalf("同步结果: %+v", results)
+	}
+}
+
+// 连续输错配对码后配对码作废
+func TestLANPairingAttempts(t *testing.T) {
+	_, address := startTestLANServer(t)
+
+	setTestPairingCode("123456")
+	for i := 0; i < maxLANPairingAttempts; i++ {
+		PairLANPeer(address, "000000")
+	}
+	if _, err := PairLANPeer(address, "123456"); err == nil {
+		t.Fatal("连续输错后正确的配对码也应失效")
+	}
+}
+
+// 停止同步服务后才返回，之后不再接受连接
+func TestStopLANServerWaitsForShutdown(t *testing.T) {
+	setupTestDB(t)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+
+	settings := DefaultLANSyncSettings()
+	settings.Port = port
+	if err := startLANServer(t.Context(), settings); err != nil {
+		t.Fatal(err)
+	}
+	address := net.JoinHostPort("127.0.0.1", strconv.Itoa(port))
+	conn, err := net.Dial("tcp", address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.Close()
+
+	stopLANServer()
+	if conn, err := net.Dial("tcp", address); err == nil {
+		conn.Close()
+		t.Fatal("停止后仍接受连接")
+	}
+}
@@ -13,6 +13,11 @@ import (
 var assets embed.FS
 
 func main() {
+	// 已下载新版本时先替换并启动新版本，启动成功后当前进程退出
+	if RunStagedUpdate() {
+		return
+	}
+
 	app := NewApp()
 
 	err := wails.Run(&options.App{
@@ -27,6 +32,7 @@ func main() {
 		},
 		BackgroundColour: &options.RGBA{R: 248, G: 249, B: 250, A: 255},
 		OnStartup:        app.startup,
+		OnShutdown:       app.shutdown,
 		Bind: []interface{}{
 			app,
 		},
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// mDNS 组播地址与局域网同步的服务类型
+const (
+	mdnsAddress      = "224.0.0.251:5353"
+	mdnsServiceType  = "_manifest-sync._tcp.local."
+	mdnsTTL          = 120
+	mdnsUnicastClass = dnsmessage.ClassINET | 1<<15 // 请求单播应答（QU）
+)
+
+// mdnsService 局域网中发现的同步服务
+type mdnsService struct {
+	DeviceID    string
+	Name        string
+	Fingerprint string
+	Address     string // IP:端口
+}
+
+// 辅助函数：响应局域网中的服务查询，直到 ctx 取消
+//
+// 只应答本服务类型的 PTR 查询，应答直接发回查询方，同一台电脑上的多个实例可同时运行。
+func serveMDNS(ctx context.Context, service func() mdnsService, port int) error {
+	group, err := net.ResolveUDPAddr("udp4", mdnsAddress)
+	if err != nil {
+		return err
+	}
+	conn, err := net.ListenMulticastUDP("udp4", nil, group)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+	}()
+
+	go func() {
+		buf := make([]byte, 9000)
+		for {
+			n, from, err := conn.ReadFromUDP(buf)
+			if err != nil {
+				if ctx.Err() == nil {
+					log.Printf("mDNS读取失败: %v", err)
+				}
+				return
+			}
+			if !isMDNSServiceQuery(buf[:n]) {
+				continue
+			}
+
+			response, err := packMDNSResponse(service(), port)
+			if err != nil {
+				log.Printf("mDNS应答失败: %v", err)
+				continue
+			}
+			conn.WriteToUDP(response, from)
+		}
+	}()
+	return nil
+}
+
+// 辅助函数：查询局域网中的同步服务，在 timeout 内收集应答
+func browseMDNS(timeout time.Duration) ([]mdnsService, error) {
+	group, err := net.ResolveUDPAddr("udp4", mdnsAddress)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := net.ListenUDP("udp4", &net.UDPAddr{})
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	query := dnsmessage.Message{
+		Questions: []dnsmessage.Question{{
+			Name:  dnsmessage.MustNewName(mdnsServiceType),
+			Type:  dnsmessage.TypePTR,
+			Class: mdnsUnicastClass,
+		}},
+	}
+	packet, err := query.Pack()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := conn.WriteToUDP(packet, group); err != nil {
+		return nil, err
+	}
+
+	services := []mdnsService{}
+	seen := make(map[string]bool)
+	conn.SetReadDeadline(time.Now().Add(timeout))
+	buf := make([]byte, 9000)
+	for {
+		n, from, err := conn.ReadFromUDP(buf)
+		if err != nil {
+			// 超时结束
+			break
+		}
+		service, ok := parseMDNSResponse(buf[:n], from.IP)
+		if !ok || seen[service.DeviceID] {
+			continue
+		}
+		seen[service.DeviceID] = true
+		services = append(services, service)
+	}
+	return services, nil
+}
+
+// 辅助函数：是否为本服务类型的查询
+func isMDNSServiceQuery(packet []byte) bool {
+	var message dnsmessage.Message
+	if err := message.Unpack(packet); err != nil || message.Header.Response {
+		return false
+	}
+	for _, question := range message.Questions {
+		if (question.Type == dnsmessage.TypePTR || question.Type == dnsmessage.TypeALL) &&
+			strings.EqualFold(question.Name.String(), mdnsServiceType) {
+			return true
+		}
+	}
+	return false
+}
+
+// 辅助函数：生成 PTR/SRV/TXT 应答
+func packMDNSResponse(service mdnsService, port int) ([]byte, error) {
+	instance, err := dnsmessage.NewName(service.DeviceID + "." + mdnsServiceType)
+	if err != nil {
+		return nil, err
+	}
+	target, err := dnsmessage.NewName(service.DeviceID + ".local.")
+	if err != nil {
+		return nil, err
+	}
+
+	header := func(name dnsmessage.Name, kind dnsmessage.Type) dnsmessage.ResourceHeader {
+		return dnsmessage.ResourceHeader{Name: name, Type: kind, Class: dnsmessage.ClassINET, TTL: mdnsTTL}
+	}
+	message := dnsmessage.Message{
+		Header: dnsmessage.Header{Response: true, Authoritative: true},
+		Answers: []dnsmessage.Resource{
+			{Header: header(dnsmessage.MustNewName(mdnsServiceType), dnsmessage.TypePTR), Body: &dnsmessage.PTRResource{PTR: instance}},
+			{Header: header(instance, dnsmessage.TypeSRV), Body: &dnsmessage.SRVResource{Target: target, Port: uint16(port)}},
+			{Header: header(instance, dnsmessage.TypeTXT), Body: &dnsmessage.TXTResource{TXT: []string{
+				"id=" + service.DeviceID,
+				"name=" + service.Name,
+				"fp=" + service.Fingerprint,
+			}}},
+		},
+	}
+	return message.Pack()
+}
+
+// 辅助函数：解析应答，地址取应答来源的IP与SRV记录中的端口
+func parseMDNSResponse(packet []byte, ip net.IP) (mdnsService, bool) {
+	var message dnsmessage.Message
+	if err := message.Unpack(packet); err != nil || !message.Header.Response {
+		return mdnsService{}, false
+	}
+
+	var service mdnsService
+	port := 0
+	for _, answer := range append(message.Answers, message.Additionals...) {
+		if !strings.HasSuffix(strings.ToLower(answer.Header.Name.String()), mdnsServiceType) {
+			continue
+		}
+		switch body := answer.Body.(type) {
+		case *dnsmessage.SRVResource:
+			port = int(body.Port)
+		case *dnsmessage.TXTResource:
+			for _, entry := range body.TXT {
+				key, value, _ := strings.Cut(entry, "=")
+				switch key {
+				case "id":
+					service.DeviceID = value
+				case "name":
+					service.Name = value
+				case "fp":
+					service.Fingerprint = value
+				}
+			}
+		}
+	}
+	if service.DeviceID == "" || port == 0 {
+		return mdnsService{}, false
+	}
+	service.Address = net.JoinHostPort(ip.String(), strconv.Itoa(port))
+	return service, true
+}
@@ -1,25 +1,41 @@
 package main
 
 type Account struct {
-	ID         string `json:"id"`
-	Username   string `json:"username"`
-	AvatarPath string `json:"avatarPath"`
+	ID            string `json:"id"`
+	Username      string `json:"username"`
+	AvatarPath    string `json:"avatarPath"`
+	ThumbnailPath string `json:"thumbnailPath,omitempty"` // 头像缩略图，由头像路径推导，不单独保存
+	LockType      string `json:"lockType,omitempty"`      // password, pin，为空表示未设置密码
+	LockHash      string `json:"lockHash,omitempty"`      // 只保存在数据库中，不返回给前端
 }
 
 type Config struct {
-	LastUsedID string    `json:"lastUsedID"`
-	Accounts   []Account `json:"accounts"`
+	LastUsedID     string                `json:"lastUsedID,omitempty"` // 旧版本保存在配置文件中的账号，启动时迁移到数据库
+	Accounts       []Account             `json:"accounts,omitempty"`
+	Reminder       *ReminderSettings     `json:"reminder,omitempty"`
+	Digest         *DigestSettings       `json:"digest,omitempty"`
+	MarkdownSync   *MarkdownSyncSettings `json:"markdownSync,omitempty"`
+	CalDAV         *CalDAVSettings       `json:"caldav,omitempty"`
+	AutoLock       *AutoLockSettings     `json:"autoLock,omitempty"`
+	LANSync        *LANSyncSettings      `json:"lanSync,omitempty"`
+	FolderSync     *FolderSyncSettings   `json:"folderSync,omitempty"`
+	Backup         *BackupSettings       `json:"backup,omitempty"`
+	GitHistory     *GitHistorySettings   `json:"gitHistory,omitempty"`
+	Update         *UpdateSettings       `json:"update,omitempty"`
+	LastRunVersion string                `json:"lastRunVersion,omitempty"` // 上次运行的版本，用于检测升级
+	WhatsNew       *WhatsNewState        `json:"whatsNew,omitempty"`
 }
 
 type Task struct {
-	ID          string  `json:"id"`
-	Title       string  `json:"title"`
-	Description string  `json:"description"`
-	Status      string  `json:"status"` // not-started, in-progress, completed
-	Score       float64 `json:"score"`
-	Priority    string  `json:"priority"` // low, medium, high
-	StartDate   *string `json:"startDate,omitempty"`
-	EndDate     *string `json:"endDate,omitempty"`
+	ID          string   `json:"id"`
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	Status      string   `json:"status"` // not-started, in-progress, completed
+	Score       float64  `json:"score"`
+	Priority    string   `json:"priority"` // low, medium, high
+	StartDate   *string  `json:"startDate,omitempty"`
+	EndDate     *string  `json:"endDate,omitempty"`
+	Tags        []string `json:"tags,omitempty"` // 标签名称，为nil时保存不修改已有标签
 }
 
 type DimensionConfig struct {
@@ -31,11 +47,12 @@ type DimensionConfig struct {
 }
 
 type ScoringSettings struct {
-	CompletedScore   float64            `json:"completedScore"`
-	InProgressScore  float64            `json:"inProgressScore"`
-	NotStartedScore  float64            `json:"notStartedScore"`
-	DimensionWeights map[string]float64 `json:"dimensionWeights,omitempty"`
-	ExtraFields      map[string]interface{} `json:"-,omitempty"` // For any additional fields
+	CompletedScore   float64                `json:"completedScore"`
+	InProgressScore  float64                `json:"inProgressScore"`
+	NotStartedScore  float64                `json:"notStartedScore"`
+	DimensionWeights map[string]float64     `json:"dimensionWeights,omitempty"`
+	GradeRules       []GradeRule            `json:"gradeRules,omitempty"` // 评级规则，为空时使用默认规则
+	ExtraFields      map[string]interface{} `json:"-,omitempty"`          // For any additional fields
 }
 
 type DimensionSettings struct {
@@ -58,11 +75,11 @@ type AnnualSettings struct {
 }
 
 type AnnualData struct {
-	Year              string                 `json:"year"`
-	TotalScore        float64                `json:"totalScore"`
-	Settings          AnnualSettings         `json:"settings"`
-	DimensionConfigs  []DimensionConfig      `json:"dimensionConfigs"`
-	Dimensions        map[string]DimensionData `json:"dimensions"`
+	Year             string                   `json:"year"`
+	TotalScore       float64                  `json:"totalScore"`
+	Settings         AnnualSettings           `json:"settings"`
+	DimensionConfigs []DimensionConfig        `json:"dimensionConfigs"`
+	Dimensions       map[string]DimensionData `json:"dimensions"`
 }
 
 type SystemData map[string]AnnualData
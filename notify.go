@@ -0,0 +1,16 @@
+package main
+
+import "strings"
+
+// 系统通知正文的最大长度
+const maxNotificationLength = 200
+
+// 辅助函数：规范化通知文本，去掉换行并限制长度
+func notificationText(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	runes := []rune(s)
+	if len(runes) > maxNotificationLength {
+		s = string(runes[:maxNotificationLength-1]) + "…"
+	}
+	return s
+}
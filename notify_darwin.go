@@ -0,0 +1,24 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+)
+
+// sendNativeNotification 发送系统通知（macOS 通过 osascript）
+func sendNativeNotification(title, message string) error {
+	script := fmt.Sprintf("display notification %s with title %s", appleScriptString(message), appleScriptString(title))
+	return exec.Command("osascript", "-e", script).Run()
+}
+
+// 辅助函数：转义为 AppleScript 字符串字面量
+func appleScriptString(s string) string {
+	escaped := []rune{'"'}
+	for _, r := range notificationText(s) {
+		if r == '"' || r == '\\' {
+			escaped = append(escaped, '\\')
+		}
+		escaped = append(escaped, r)
+	}
+	return string(append(escaped, '"'))
+}
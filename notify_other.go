@@ -0,0 +1,10 @@
+//go:build !darwin && !windows
+
+package main
+
+import "os/exec"
+
+// sendNativeNotification 发送系统通知（Linux 等平台通过 notify-send）
+func sendNativeNotification(title, message string) error {
+	return exec.Command("notify-send", "--app-name=Manifest", notificationText(title), notificationText(message)).Run()
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+	"syscall"
+)
+
+// Windows 通知使用的 PowerShell 脚本
+const windowsToastScript = `
+[Windows.UI.Notifications.ToastNotificationManager, Windows.UI.Notifications, ContentType = WindowsRuntime] | Out-Null
+[Windows.Data.Xml.Dom.XmlDocument, Windows.Data.Xml.Dom.XmlDocument, ContentType = WindowsRuntime] | Out-Null
+$xml = New-Object Windows.Data.Xml.Dom.XmlDocument
+$xml.LoadXml('<toast><visual><binding template="ToastGeneric"><text>%s</text><text>%s</text></binding></visual></toast>')
+$toast = New-Object Windows.UI.Notifications.ToastNotification $xml
+[Windows.UI.Notifications.ToastNotificationManager]::CreateToastNotifier('Manifest').Show($toast)
+`
+
+// sendNativeNotification 发送系统通知（Windows 通过 PowerShell 调用 Toast）
+func sendNativeNotification(title, message string) error {
+	script := fmt.Sprintf(windowsToastScript, powerShellXMLText(title), powerShellXMLText(message))
+	cmd := exec.Command("powershell", "-NoProfile", "-NonInteractive", "-Command", script)
+	cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
+	return cmd.Run()
+}
+
+// 辅助函数：转义为可放入单引号 PowerShell 字符串的 XML 文本
+func powerShellXMLText(s string) string {
+	s = strings.NewReplacer(
+		"&", "&amp;",
+		"<", "&lt;",
+		">", "&gt;",
+		`"`, "&quot;",
+		"'", "''",
+	).Replace(notificationText(s))
+	return s
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// A4 页面尺寸（单位：pt）
+const (
+	pdfPageWidth  = 595.28
+	pdfPageHeight = 841.89
+)
+
+// pdfColor RGB 颜色，取值 0-1
+type pdfColor struct {
+	R, G, B float64
+}
+
+// 常用颜色
+var (
+	pdfBlack = pdfColor{0.12, 0.16, 0.22}
+	pdfGray  = pdfColor{0.42, 0.45, 0.50}
+	pdfLight = pdfColor{0.90, 0.91, 0.92}
+)
+
+// pdfDocument 极简 PDF 生成器
+//
+// 使用 Adobe 预置的 STSong-Light 中文字体（UniGB-UCS2-H 编码），
+// 阅读器自带该字体，因此无需嵌入字体文件即可显示中文。
+// 坐标以页面左上角为原点，y 轴向下。
+type pdfDocument struct {
+	pages []*pdfPage
+}
+
+// pdfPage PDF 页面
+type pdfPage struct {
+	content bytes.Buffer
+}
+
+// 辅助函数：创建 PDF 文档
+func newPDFDocument() *pdfDocument {
+	return &pdfDocument{}
+}
+
+// AddPage 添加新页面
+func (d *pdfDocument) AddPage() *pdfPage {
+	page := &pdfPage{}
+	d.pages = append(d.pages, page)
+	return page
+}
+
+// Text 在指定位置绘制单行文本，y 为文本基线位置
+func (p *pdfPage) Text(x, y, size float64, color pdfColor, text string) {
+	fmt.Fprintf(&p.content, "BT /F1 %.2f Tf %.3f %.3f %.3f rg %.2f %.2f Td <%s> Tj ET\n",
+		size, color.R, color.G, color.B, x, pdfPageHeight-y, pdfEncodeText(text))
+}
+
+// Rect 绘制填充矩形
+func (p *pdfPage) Rect(x, y, w, h float64, color pdfColor) {
+	fmt.Fprintf(&p.content, "%.3f %.3f %.3f rg %.2f %.2f %.2f %.2f re f\n",
+		color.R, color.G, color.B, x, pdfPageHeight-y-h, w, h)
+}
+
+// Line 绘制直线
+func (p *pdfPage) Line(x1, y1, x2, y2, width float64, color pdfColor) {
+	fmt.Fprintf(&p.content, "%.3f %.3f %.3f RG %.2f w %.2f %.2f m %.2f %.2f l S\n",
+		color.R, color.G, color.B, width, x1, pdfPageHeight-y1, x2, pdfPageHeight-y2)
+}
+
+// Bytes 输出完整的 PDF 文件内容
+func (d *pdfDocument) Bytes() []byte {
+	if len(d.pages) == 0 {
+		d.AddPage()
+	}
+
+	var buf bytes.Buffer
+	offsets := []int{}
+	writeObject := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// 1: Catalog, 2: Pages, 3-5: 字体，之后每页占两个对象（页面与内容流）
+	kids := []string{}
+	for i := range d.pages {
+		kids = append(kids, fmt.Sprintf("%d 0 R", 6+i*2))
+	}
+	writeObject("<< /Type /Catalog /Pages 2 0 R >>")
+	writeObject(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	writeObject("<< /Type /Font /Subtype /Type0 /BaseFont /STSong-Light /Encoding /UniGB-UCS2-H /DescendantFonts [4 0 R] >>")
+	writeObject("<< /Type /Font /Subtype /CIDFontType0 /BaseFont /STSong-Light " +
+		"/CIDSystemInfo << /Registry (Adobe) /Ordering (GB1) /Supplement 2 >> " +
+		"/FontDescriptor 5 0 R /DW 1000 /W [1 95 500 814 939 500] >>")
+	writeObject("<< /Type /FontDescriptor /FontName /STSong-Light /Flags 6 /FontBBox [-25 -254 1000 880] " +
+		"/ItalicAngle 0 /Ascent 880 /Descent -120 /CapHeight 880 /StemV 93 >>")
+
+	for i, page := range d.pages {
+		writeObject(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>",
+			pdfPageWidth, pdfPageHeight, 7+i*2))
+		writeObject(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", page.content.Len(), page.content.String()))
+	}
+
+	// 交叉引用表
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	return buf.Bytes()
+}
+
+// 辅助函数：将文本编码为 UCS-2 大端十六进制串，BMP 以外的字符替换为问号
+func pdfEncodeText(text string) string {
+	var b strings.Builder
+	for _, r := range text {
+		if r > 0xFFFF || r < 0x20 {
+			r = '?'
+		}
+		fmt.Fprintf(&b, "%04X", r)
+	}
+	return b.String()
+}
+
+// 辅助函数：估算文本宽度，半角字符按半个字宽计算
+func pdfTextWidth(text string, size float64) float64 {
+	width := 0.0
+	for _, r := range text {
+		if r < 0x80 {
+			width += 0.5
+		} else {
+			width += 1
+		}
+	}
+	return width * size
+}
+
+// 辅助函数：按最大宽度将文本折行
+func pdfWrapText(text string, size, maxWidth float64) []string {
+	lines := []string{}
+	for _, paragraph := range strings.Split(text, "\n") {
+		line := []rune{}
+		for _, r := range paragraph {
+			if pdfTextWidth(string(append(line, r)), size) > maxWidth && len(line) > 0 {
+				lines = append(lines, string(line))
+				line = line[:0]
+			}
+			line = append(line, r)
+		}
+		lines = append(lines, string(line))
+	}
+	return lines
+}
+
+// 辅助函数：解析 #RRGGBB 颜色，失败时返回默认颜色
+func pdfParseColor(hex string, fallback pdfColor) pdfColor {
+	var r, g, b int
+	if len(hex) != 7 || hex[0] != '#' {
+		return fallback
+	}
+	if _, err := fmt.Sscanf(hex[1:], "%02x%02x%02x", &r, &g, &b); err != nil {
+		return fallback
+	}
+	return pdfColor{float64(r) / 255, float64(g) / 255, float64(b) / 255}
+}
@@ -0,0 +1,362 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// 任务日期统一使用的格式
+const taskDateLayout = "2006-01-02"
+
+// 查询分页默认值与上限
+const (
+	defaultTaskPageSize = 50
+	maxTaskPageSize     = 500
+)
+
+// TaskQuery 任务查询条件，所有条件之间为“与”关系，空值表示不限制
+type TaskQuery struct {
+	TaskIDs       []string `json:"taskIds,omitempty"`       // 任务ID
+	Statuses      []string `json:"statuses,omitempty"`      // not-started, in-progress, completed
+	Priorities    []string `json:"priorities,omitempty"`    // low, medium, high
+	Years         []string `json:"years,omitempty"`         // 年度
+	DimensionKeys []string `json:"dimensionKeys,omitempty"` // 维度key
+	Months        []int    `json:"months,omitempty"`        // 月份，0-11
+	Tags          []string `json:"tags,omitempty"`          // 标签名称，包含任一标签即匹配
+	Keyword       string   `json:"keyword,omitempty"`       // 标题/描述关键字
+	StartFrom     string   `json:"startFrom,omitempty"`     // 开始日期下限（含），YYYY-MM-DD
+	StartTo       string   `json:"startTo,omitempty"`       // 开始日期上限（含），YYYY-MM-DD
+	DueFrom       string   `json:"dueFrom,omitempty"`       // 截止日期下限（含），YYYY-MM-DD
+	DueTo         string   `json:"dueTo,omitempty"`         // 截止日期上限（含），YYYY-MM-DD
+	Overdue       *bool    `json:"overdue,omitempty"`       // true 仅逾期任务，false 仅未逾期任务
+	SortBy        string   `json:"sortBy,omitempty"`        // 排序字段，见 taskSortColumns
+	SortDesc      bool     `json:"sortDesc,omitempty"`      // 是否倒序
+	Page          int      `json:"page,omitempty"`          // 页码，从1开始
+	PageSize      int      `json:"pageSize,omitempty"`      // 每页条数
+}
+
+// TaskQueryItem 查询结果中的单个任务及其归属
+type TaskQueryItem struct {
+	Task         Task   `json:"task"`
+	Year         string `json:"year"`
+	DimensionKey string `json:"dimensionKey"`
+	Month        int    `json:"month"`
+	Overdue      bool   `json:"overdue"`
+}
+
+// TaskQueryResult 任务查询结果
+type TaskQueryResult struct {
+	Items    []TaskQueryItem `json:"items"`
+	Total    int             `json:"total"`
+	Page     int             `json:"page"`
+	PageSize int             `json:"pageSize"`
+}
+
+// taskSortColumns 允许排序的字段与对应的SQL表达式
+var taskSortColumns = map[string]string{
+	"title":     "t.title",
+	"status":    "CASE t.status WHEN 'not-started' THEN 0 WHEN 'in-progress' THEN 1 WHEN 'completed' THEN 2 ELSE 3 END",
+	"priority":  "CASE t.priority WHEN 'high' THEN 0 WHEN 'medium' THEN 1 WHEN 'low' THEN 2 ELSE 3 END",
+	"score":     "t.score",
+	"startDate": "COALESCE(substr(t.start_date, 1, 10), '9999-12-31')",
+	"endDate":   "COALESCE(substr(t.end_date, 1, 10), '9999-12-31')",
+	"year":      "mt.year",
+	"dimension": "mt.dimension_key",
+	"month":     "mt.month",
+}
+
+// QueryTasks 按条件查询任务，过滤、排序和分页均在SQL中完成
+func QueryTasks(query TaskQuery) (*TaskQueryResult, error) {
+	return queryTasksAt(query, time.Now())
+}
+
+// 辅助函数：以指定时间为“今天”查询任务
+func queryTasksAt(query TaskQuery, now time.Time) (*TaskQueryResult, error) {
+	today := now.Format(taskDateLayout)
+
+	where, args, err := buildTaskQueryWhere(query, today)
+	if err != nil {
+		return nil, err
+	}
+
+	from := ` FROM monthly_tasks mt JOIN tasks t ON t.id = mt.task_id`
+
+	// 统计总数
+	var total int
+	if err := db.QueryRow(`SELECT COUNT(*)`+from+where, args...).Scan(&total); err != nil {
+		return nil, err
+	}
+
+	// 排序
+	orderBy := " ORDER BY mt.year, mt.month, mt.dimension_key, t.id"
+	if query.SortBy != "" {
+		column, ok := taskSortColumns[query.SortBy]
+		if !ok {
+			return nil, fmt.Errorf("不支持的排序字段: %s", query.SortBy)
+		}
+		direction := "ASC"
+		if query.SortDesc {
+			direction = "DESC"
+		}
+		orderBy = fmt.Sprintf(" ORDER BY %s %s, t.id", column, direction)
+	}
+
+	// 分页
+	page := query.Page
+	if page < 1 {
+		page = 1
+	}
+	pageSize := query.PageSize
+	if pageSize <= 0 {
+		pageSize = defaultTaskPageSize
+	}
+	if pageSize > maxTaskPageSize {
+		pageSize = maxTaskPageSize
+	}
+
+	selectSQL := `SELECT t.id, t.title, t.description, t.status, t.score, t.priority, t.start_date, t.end_date, mt.year, mt.dimension_key, mt.month` +
+		from + where + orderBy + ` LIMIT ? OFFSET ?`
+	pageArgs := append(append([]interface{}{}, args...), pageSize, (page-1)*pageSize)
+
+	rows, err := db.Query(selectSQL, pageArgs...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []TaskQueryItem{}
+	for rows.Next() {
+		var item TaskQueryItem
+		var startDate sql.NullString
+		var endDate sql.NullString
+
+		if err := rows.Scan(&item.Task.ID, &item.Task.Title, &item.Task.Description, &item.Task.Status, &item.Task.Score, &item.Task.Priority,
+			&startDate, &endDate, &item.Year, &item.DimensionKey, &item.Month); err != nil {
+			return nil, err
+		}
+
+		if startDate.Valid {
+			item.Task.StartDate = &startDate.String
+		}
+		if endDate.Valid {
+			item.Task.EndDate = &endDate.String
+		}
+		item.Overdue = isTaskOverdue(item.Task, today)
+
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// 获取任务标签
+	ids := []string{}
+	for _, item := range items {
+		ids = append(ids, item.Task.ID)
+	}
+	tagMap, err := getTagNamesByTaskIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	for i := range items {
+		items[i].Task.Tags = tagMap[items[i].Task.ID]
+	}
+
+	return &TaskQueryResult{
+		Items:    items,
+		Total:    total,
+		Page:     page,
+		PageSize: pageSize,
+	}, nil
+}
+
+// 辅助函数：根据查询条件构建WHERE子句
+func buildTaskQueryWhere(query TaskQuery, today string) (string, []interface{}, error) {
+	conditions := []string{}
+	args := []interface{}{}
+
+	addIn := func(column string, values []interface{}) {
+		if len(values) == 0 {
+			return
+		}
+		conditions = append(conditions, fmt.Sprintf("%s IN (%s)", column, placeholders(len(values))))
+		args = append(args, values...)
+	}
+
+	taskIDs := []interface{}{}
+	for _, id := range query.TaskIDs {
+		taskIDs = append(taskIDs, id)
+	}
+	addIn("t.id", taskIDs)
+
+	statuses := []interface{}{}
+	for _, status := range query.Statuses {
+		if !isValidTaskStatus(status) {
+			return "", nil, fmt.Errorf("无效的任务状态: %s", status)
+		}
+		statuses = append(statuses, status)
+	}
+	addIn("t.status", statuses)
+
+	priorities := []interface{}{}
+	for _, priority := range query.Priorities {
+		if !isValidTaskPriority(priority) {
+			return "", nil, fmt.Errorf("无效的任务优先级: %s", priority)
+		}
+		priorities = append(priorities, priority)
+	}
+	addIn("t.priority", priorities)
+
+	years := []interface{}{}
+	for _, year := range query.Years {
+		years = append(years, year)
+	}
+	addIn("mt.year", years)
+
+	dimensionKeys := []interface{}{}
+	for _, key := range query.DimensionKeys {
+		dimensionKeys = append(dimensionKeys, key)
+	}
+	addIn("mt.dimension_key", dimensionKeys)
+
+	months := []interface{}{}
+	for _, month := range query.Months {
+		if month < 0 || month > 11 {
+			return "", nil, fmt.Errorf("无效的月份: %d", month)
+		}
+		months = append(months, month)
+	}
+	addIn("mt.month", months)
+
+	tags := []interface{}{}
+	for _, tag := range query.Tags {
+		tags = append(tags, tag)
+	}
+	if len(tags) > 0 {
+		conditions = append(conditions, fmt.Sprintf("t.id IN (SELECT tt.task_id FROM task_tags tt JOIN tags g ON g.id = tt.tag_id WHERE g.name IN (%s))", placeholders(len(tags))))
+		args = append(args, tags...)
+	}
+
+	if keyword := strings.TrimSpace(query.Keyword); keyword != "" {
+		// 转义关键词中的通配符，按字面匹配
+		conditions = append(conditions, `(t.title LIKE ? ESCAPE '\' OR t.description LIKE ? ESCAPE '\')`)
+		pattern := "%" + likeEscaper.Replace(keyword) + "%"
+		args = append(args, pattern, pattern)
+	}
+
+	// 日期范围
+	dateRanges := []struct {
+		column string
+		op     string
+		value  string
+	}{
+		{"t.start_date", ">=", query.StartFrom},
+		{"t.start_date", "<=", query.StartTo},
+		{"t.end_date", ">=", query.DueFrom},
+		{"t.end_date", "<=", query.DueTo},
+	}
+	for _, r := range dateRanges {
+		if r.value == "" {
+			continue
+		}
+		if _, err := time.Parse(taskDateLayout, r.value); err != nil {
+			return "", nil, fmt.Errorf("无效的日期: %s", r.value)
+		}
+		conditions = append(conditions, fmt.Sprintf("substr(%s, 1, 10) %s ?", r.column, r.op))
+		args = append(args, r.value)
+	}
+
+	// 逾期：截止日期早于今天且未完成
+	if query.Overdue != nil {
+		overdueCondition := "(t.end_date IS NOT NULL AND t.end_date != '' AND substr(t.end_date, 1, 10) < ? AND t.status != 'completed')"
+		if *query.Overdue {
+			conditions = append(conditions, overdueCondition)
+		} else {
+			conditions = append(conditions, "NOT "+overdueCondition)
+		}
+		args = append(args, today)
+	}
+
+	if len(conditions) == 0 {
+		return "", args, nil
+	}
+	return " WHERE " + strings.Join(conditions, " AND "), args, nil
+}
+
+// likeEscaper 转义 LIKE 模式中的通配符与转义符
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// 辅助函数：生成n个以逗号分隔的占位符
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+// 辅助函数：判断任务是否逾期，today 为 YYYY-MM-DD
+func isTaskOverdue(task Task, today string) bool {
+	if task.Status == "completed" || task.EndDate == nil {
+		return false
+	}
+	endDate, ok := taskDate(*task.EndDate)
+	if !ok {
+		return false
+	}
+	return endDate < today
+}
+
+// 辅助函数：取日期字符串中的 YYYY-MM-DD 部分
+func taskDate(value string) (string, bool) {
+	if len(value) < len(taskDateLayout) {
+		return "", false
+	}
+	date := value[:len(taskDateLayout)]
+	if _, err := time.Parse(taskDateLayout, date); err != nil {
+		return "", false
+	}
+	return date, true
+}
+
+// 辅助函数：判断任务状态是否合法
+func isValidTaskStatus(status string) bool {
+	switch status {
+	case "not-started", "in-progress", "completed":
+		return true
+	}
+	return false
+}
+
+// 辅助函数：判断任务优先级是否合法
+func isValidTaskPriority(priority string) bool {
+	switch priority {
+	case "low", "medium", "high":
+		return true
+	}
+	return false
+}
+
+// 辅助函数：任务状态显示文本
+func statusLabel(status string) string {
+	switch status {
+	case "not-started":
+		return "未开始"
+	case "in-progress":
+		return "进行中"
+	case "completed":
+		return "已完成"
+	}
+	return status
+}
+
+// 辅助函数：优先级显示文本
+func priorityLabel(priority string) string {
+	switch priority {
+	case "high":
+		return "高"
+	case "medium":
+		return "中"
+	case "low":
+		return "低"
+	}
+	return priority
+}
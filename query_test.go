@@ -0,0 +1,67 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// 过滤、排序与分页
+func TestQueryTasksAt(t *testing.T) {
+	setupTestDB(t)
+	date := func(value string) *string { return &value }
+	work := make([][]Task, 12)
+	work[0] = []Task{{ID: "a", Title: "100% 完成", Status: "completed", Priority: "high", Score: 90, EndDate: date("2026-01-10")}}
+	work[1] = []Task{{ID: "b", Title: "普通任务", Status: "not-started", Priority: "low", Score: 10, EndDate: date("2026-01-05")}}
+	life := make([][]Task, 12)
+	life[0] = []Task{{ID: "c", Title: "snake_case 任务", Status: "in-progress", Priority: "medium", Score: 50}}
+	life[2] = []Task{{ID: "d", Title: "snakeXcase", Status: "not-started", Priority: "medium", Score: 30, EndDate: date("2026-03-01")}}
+	err := SaveAnnualData(AnnualData{
+		Year:             "2026",
+		DimensionConfigs: []DimensionConfig{{Key: "work", Title: "工作"}, {Key: "life", Title: "生活"}},
+		Dimensions: map[string]DimensionData{
+			"work": {QuarterlyGoals: make([]string, 4), MonthlyTasks: work},
+			"life": {QuarterlyGoals: make([]string, 4), MonthlyTasks: life},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	overdue := true
+	now := time.Date(2026, 2, 1, 12, 0, 0, 0, time.Local)
+	for _, tc := range []struct {
+		name  string
+		query TaskQuery
+		want  []string
+		total int
+	}{
+		{"全部", TaskQuery{}, []string{"c", "a", "b", "d"}, 4},
+		{"状态", TaskQuery{Statuses: []string{"not-started"}}, []string{"b", "d"}, 2},
+		{"维度与月份", TaskQuery{DimensionKeys: []string{"life"}, Months: []int{0}}, []string{"c"}, 1},
+		{"关键词中的百分号按字面匹配", TaskQuery{Keyword: "%"}, []string{"a"}, 1},
+		{"关键词中的下划线按字面匹配", TaskQuery{Keyword: "snake_case"}, []string{"c"}, 1},
+		{"截止日期范围", TaskQuery{DueFrom: "2026-01-06", DueTo: "2026-03-01"}, []string{"a", "d"}, 2},
+		{"逾期", TaskQuery{Overdue: &overdue}, []string{"b"}, 1},
+		{"按得分倒序", TaskQuery{SortBy: "score", SortDesc: true}, []string{"a", "c", "d", "b"}, 4},
+		{"分页", TaskQuery{SortBy: "score", Page: 2, PageSize: 3}, []string{"a"}, 4},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := queryTasksAt(tc.query, now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ids := []string{}
+			for _, item := range result.Items {
+				ids = append(ids, item.Task.ID)
+			}
+			if !reflect.DeepEqual(ids, tc.want) || result.Total != tc.total {
+				t.Fatalf("结果 = %v（共 %d 条），期望 %v（共 %d 条）", ids, result.Total, tc.want, tc.total)
+			}
+		})
+	}
+
+	if _, err := queryTasksAt(TaskQuery{SortBy: "unknown"}, now); err == nil {
+		t.Fatal("不支持的排序字段应返回错误")
+	}
+}
@@ -0,0 +1,327 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-version"
+)
+
+// 升级后有新内容时发送的事件名称
+const whatsNewEvent = "whatsNew"
+
+// 发布说明缓存文件名，保存在数据目录中，离线时也能查看
+const releaseNotesFileName = "release-notes.json"
+
+// 同一时间只允许一个读写缓存
+var releaseNotesMu sync.Mutex
+
+// ReleaseNote 一个版本的发布说明
+type ReleaseNote struct {
+	Version     string `json:"version"`
+	Notes       string `json:"notes"`
+	URL         string `json:"url"`
+	PublishedAt string `json:"publishedAt"`
+	Prerelease  bool   `json:"prerelease"`
+}
+
+// WhatsNewState 最近一次升级，保存在配置文件中
+type WhatsNewState struct {
+	FromVersion string `json:"fromVersion"`
+	ToVersion   string `json:"toVersion"`
+	UpgradedAt  string `json:"upgradedAt"`
+	Seen        bool   `json:"seen"` // 用户是否已看过
+}
+
+// WhatsNew 升级后的新内容
+type WhatsNew struct {
+	FromVersion string        `json:"fromVersion"`
+	ToVersion   string        `json:"toVersion"`
+	UpgradedAt  string        `json:"upgradedAt"`
+	Seen        bool          `json:"seen"`
+	Releases    []ReleaseNote `json:"releases"` // 两个版本之间（不含旧版本）的发布说明，新版本在前
+	Missing     []string      `json:"missing"`  // 无法获取发布说明的版本（例如离线且未缓存）
+}
+
+// DetectUpgrade 启动时比较上次运行的版本，升级后记录待查看的新内容，返回是否检测到升级
+func DetectUpgrade() (bool, error) {
+	upgraded := false
+	err := UpdateConfig(func(config *Config) error {
+		var changed bool
+		upgraded, changed = detectUpgrade(config, currentVersion, time.Now())
+		if !changed {
+			return errConfigUnchanged
+		}
+		return nil
+	})
+	return upgraded, err
+}
+
+// GetWhatsNew 获取最近一次升级的新内容，没有升级记录时返回 nil；发布说明优先使用缓存，缺少时从更新源获取
+func GetWhatsNew(ctx context.Context) (*WhatsNew, error) {
+	config, err := LoadConfig()
+	if err != nil {
+		return nil, err
+	}
+	if config.WhatsNew == nil {
+		return nil, nil
+	}
+	state := *config.WhatsNew
+
+	notes, err := loadReleaseNotes()
+	if err != nil {
+		return nil, err
+	}
+	whatsNew := buildWhatsNew(state, notes)
+	if len(whatsNew.Missing) == 0 && len(whatsNew.Releases) > 0 {
+		return whatsNew, nil
+	}
+
+	// 缓存不完整时从更新源获取，离线时返回已缓存的部分
+	if err := refreshReleaseNotes(ctx); err != nil {
+		log.Printf("获取发布说明失败: %v", err)
+		return whatsNew, nil
+	}
+	if notes, err = loadReleaseNotes(); err != nil {
+		return nil, err
+	}
+	return buildWhatsNew(state, notes), nil
+}
+
+// DismissWhatsNew 标记升级后的新内容已查看
+func DismissWhatsNew() error {
+	return UpdateConfig(func(config *Config) error {
+		if config.WhatsNew == nil || config.WhatsNew.Seen {
+			return errConfigUnchanged
+		}
+		config.WhatsNew.Seen = true
+		return nil
+	})
+}
+
+// GetReleaseNotesHistory 获取所有已知版本的发布说明，新版本在前；refresh 为 true 时先从更新源获取
+func GetReleaseNotesHistory(ctx context.Context, refresh bool) ([]ReleaseNote, error) {
+	if refresh {
+		if err := refreshReleaseNotes(ctx); err != nil {
+			log.Printf("获取发布说明失败: %v", err)
+		}
+	}
+	notes, err := loadReleaseNotes()
+	if err != nil {
+		return nil, err
+	}
+	return sortReleaseNotes(notes), nil
+}
+
+// 辅助函数：比较上次运行的版本并更新配置，返回是否升级以及配置是否需要保存
+//
+// 首次运行只记录版本；降级时清除未查看的新内容，避免显示比当前版本更新的说明。
+func detectUpgrade(config *Config, current string, now time.Time) (upgraded, changed bool) {
+	last := config.LastRunVersion
+	if last == current {
+		return false, false
+	}
+	config.LastRunVersion = current
+	if last == "" {
+		return false, true
+	}
+
+	lastParsed, err := version.NewVersion(last)
+	if err != nil {
+		return false, true
+	}
+	currentParsed, err := version.NewVersion(current)
+	if err != nil {
+		return false, true
+	}
+	if !currentParsed.GreaterThan(lastParsed) {
+		config.WhatsNew = nil
+		return false, true
+	}
+
+	// 多次升级未查看时从最早的版本开始累计
+	from := last
+	if state := config.WhatsNew; state != nil && !state.Seen && state.ToVersion == last {
+		from = state.FromVersion
+	}
+	config.WhatsNew = &WhatsNewState{
+		FromVersion: from,
+		ToVersion:   current,
+		UpgradedAt:  now.Format(time.RFC3339),
+	}
+	return true, true
+}
+
+// 辅助函数：选出升级范围内的发布说明，并列出缺少说明的版本
+func buildWhatsNew(state WhatsNewState, notes map[string]ReleaseNote) *WhatsNew {
+	whatsNew := &WhatsNew{
+		FromVersion: state.FromVersion,
+		ToVersion:   state.ToVersion,
+		UpgradedAt:  state.UpgradedAt,
+		Seen:        state.Seen,
+		Releases:    []ReleaseNote{},
+		Missing:     []string{},
+	}
+	from, errFrom := version.NewVersion(state.FromVersion)
+	to, errTo := version.NewVersion(state.ToVersion)
+	if errFrom != nil || errTo != nil {
+		return whatsNew
+	}
+
+	found := false
+	for _, note := range sortReleaseNotes(notes) {
+		parsed, err := version.NewVersion(note.Version)
+		if err != nil || !parsed.GreaterThan(from) || parsed.GreaterThan(to) {
+			continue
+		}
+		// 升级到正式版本时不显示中间的预发布版本，其内容已包含在正式版本中
+		if note.Prerelease && to.Prerelease() == "" {
+			continue
+		}
+		whatsNew.Releases = append(whatsNew.Releases, note)
+		if parsed.Equal(to) {
+			found = true
+		}
+	}
+	// 中间版本可能从未发布过，只能确定新版本本身是否缺少说明
+	if !found {
+		whatsNew.Missing = append(whatsNew.Missing, state.ToVersion)
+	}
+	return whatsNew
+}
+
+// 辅助函数：从更新源获取所有版本并写入缓存
+func refreshReleaseNotes(ctx context.Context) error {
+	settings, err := GetUpdateSettings()
+	if err != nil {
+		return err
+	}
+	client := &http.Client{Timeout: 10 * time.Second}
+	releases, err := fetchReleases(ctx, client, settings.SourceURL)
+	if err != nil {
+		return err
+	}
+	cacheReleaseNotes(releases)
+	return nil
+}
+
+// 辅助函数：将获取到的版本写入缓存，跳过草稿与无法解析的标签，失败时只记录日志
+func cacheReleaseNotes(releases []GitHubRelease) {
+	if len(releases) == 0 {
+		return
+	}
+	if err := mergeReleaseNotes(releases); err != nil {
+		log.Printf("缓存发布说明失败: %v", err)
+	}
+}
+
+// 辅助函数：合并到缓存文件，同一版本以新获取的为准
+func mergeReleaseNotes(releases []GitHubRelease) error {
+	releaseNotesMu.Lock()
+	defer releaseNotesMu.Unlock()
+
+	notes, err := readReleaseNotesFile()
+	if err != nil {
+		return err
+	}
+	for _, release := range releases {
+		if release.Draft {
+			continue
+		}
+		parsed, err := version.NewVersion(release.TagName)
+		if err != nil {
+			continue
+		}
+		notes[parsed.String()] = ReleaseNote{
+			Version:     release.TagName,
+			Notes:       release.Body,
+			URL:         release.HTMLURL,
+			PublishedAt: release.PublishedAt,
+			Prerelease:  release.Prerelease || parsed.Prerelease() != "",
+		}
+	}
+
+	path, err := releaseNotesPath()
+	if err != nil {
+		return err
+	}
+	content, err := json.MarshalIndent(notes, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path+".tmp", content, 0644); err != nil {
+		return fmt.Errorf("保存发布说明失败: %w", err)
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+// 辅助函数：读取缓存的发布说明，key 为规范化的版本号
+func loadReleaseNotes() (map[string]ReleaseNote, error) {
+	releaseNotesMu.Lock()
+	defer releaseNotesMu.Unlock()
+	return readReleaseNotesFile()
+}
+
+// 辅助函数：读取缓存文件，调用方需持有 releaseNotesMu
+func readReleaseNotesFile() (map[string]ReleaseNote, error) {
+	path, err := releaseNotesPath()
+	if err != nil {
+		return nil, err
+	}
+	notes := map[string]ReleaseNote{}
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return notes, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取发布说明失败: %w", err)
+	}
+	if err := json.Unmarshal(content, &notes); err != nil {
+		// 缓存损坏时重新获取
+		log.Printf("解析发布说明缓存失败: %v", err)
+		return map[string]ReleaseNote{}, nil
+	}
+	return notes, nil
+}
+
+// 辅助函数：按版本从新到旧排序
+func sortReleaseNotes(notes map[string]ReleaseNote) []ReleaseNote {
+	type entry struct {
+		note    ReleaseNote
+		version *version.Version
+	}
+	entries := make([]entry, 0, len(notes))
+	for _, note := range notes {
+		parsed, err := version.NewVersion(note.Version)
+		if err != nil {
+			continue
+		}
+		entries = append(entries, entry{note: note, version: parsed})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].version.GreaterThan(entries[j].version)
+	})
+
+	sorted := make([]ReleaseNote, len(entries))
+	for i, entry := range entries {
+		sorted[i] = entry.note
+	}
+	return sorted
+}
+
+// 辅助函数：发布说明缓存文件路径
+func releaseNotesPath() (string, error) {
+	appDataDir, err := GetAppDataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(appDataDir, releaseNotesFileName), nil
+}
@@ -0,0 +1,338 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// 提醒事件名称，前端通过 EventsOn 监听
+const taskReminderEvent = "taskReminder"
+
+// 提醒类型
+const (
+	reminderKindDue     = "due"     // 即将到期
+	reminderKindOverdue = "overdue" // 已逾期
+)
+
+// ReminderSettings 截止日期提醒设置
+type ReminderSettings struct {
+	Enabled              bool     `json:"enabled"`              // 是否启用提醒
+	LeadDays             []int    `json:"leadDays"`             // 提前提醒的天数，0 表示当天到期
+	NotifyOverdue        bool     `json:"notifyOverdue"`        // 是否提醒逾期任务
+	NativeNotifications  bool     `json:"nativeNotifications"`  // 是否发送系统通知
+	CheckIntervalMinutes int      `json:"checkIntervalMinutes"` // 检查间隔（分钟）
+	QuietDimensions      []string `json:"quietDimensions"`      // 不提醒的维度key
+}
+
+// TaskReminder 单条任务提醒
+type TaskReminder struct {
+	Task         Task   `json:"task"`
+	Year         string `json:"year"`
+	DimensionKey string `json:"dimensionKey"`
+	Kind         string `json:"kind"`     // due, overdue
+	DaysLeft     int    `json:"daysLeft"` // 距离截止日期的天数，逾期为负数
+	Message      string `json:"message"`
+}
+
+// DefaultReminderSettings 默认提醒设置
+func DefaultReminderSettings() ReminderSettings {
+	return ReminderSettings{
+		Enabled:              true,
+		LeadDays:             []int{0, 1, 3},
+		NotifyOverdue:        true,
+		NativeNotifications:  true,
+		CheckIntervalMinutes: 30,
+		QuietDimensions:      []string{},
+	}
+}
+
+// GetReminderSettings 获取提醒设置，未配置时返回默认值
+func GetReminderSettings() (ReminderSettings, error) {
+	config, err := LoadConfig()
+	if err != nil {
+		return ReminderSettings{}, err
+	}
+
+	if config.Reminder == nil {
+		return DefaultReminderSettings(), nil
+	}
+	return *config.Reminder, nil
+}
+
+// SaveReminderSettings 保存提醒设置
+func SaveReminderSettings(settings ReminderSettings) error {
+	if settings.CheckIntervalMinutes <= 0 {
+		return fmt.Errorf("检查间隔必须大于0")
+	}
+	for _, days := range settings.LeadDays {
+		if days < 0 {
+			return fmt.Errorf("提前天数不能为负数: %d", days)
+		}
+	}
+
+	return UpdateConfig(func(config *Config) error {
+		config.Reminder = &settings
+		return nil
+	})
+}
+
+// 辅助函数：创建提醒记录表
+func createReminderTables() error {
+	// 记录每个任务的贪睡时间以及最近一次提醒，避免重复提醒
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS task_reminders (
+			task_id TEXT PRIMARY KEY,
+			snoozed_until TEXT,
+			last_kind TEXT,
+			last_notified_date TEXT
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("创建任务提醒表失败: %w", err)
+	}
+
+	return nil
+}
+
+// SnoozeReminder 将任务提醒推迟指定分钟数，到期后即使当天已提醒过也会再次提醒
+func SnoozeReminder(taskID string, minutes int) error {
+	if minutes <= 0 {
+		return fmt.Errorf("推迟时间必须大于0")
+	}
+
+	until := time.Now().Add(time.Duration(minutes) * time.Minute).Format(time.RFC3339)
+	_, err := db.Exec(
+		`INSERT INTO task_reminders (task_id, snoozed_until) VALUES (?, ?)
+		ON CONFLICT(task_id) DO UPDATE SET snoozed_until = excluded.snoozed_until, last_kind = NULL, last_notified_date = NULL`,
+		taskID, until,
+	)
+	return err
+}
+
+// GetPendingReminders 获取当前所有需要提醒的任务（已推迟的除外）
+func GetPendingReminders() ([]TaskReminder, error) {
+	settings, err := GetReminderSettings()
+	if err != nil {
+		return nil, err
+	}
+	return evaluateReminders(settings, time.Now())
+}
+
+// 辅助函数：计算指定时间点需要提醒的任务
+func evaluateReminders(settings ReminderSettings, now time.Time) ([]TaskReminder, error) {
+	reminders := []TaskReminder{}
+	if !settings.Enabled {
+		return reminders, nil
+	}
+
+	maxLead := -1
+	leadDays := make(map[int]bool)
+	for _, days := range settings.LeadDays {
+		leadDays[days] = true
+		if days > maxLead {
+			maxLead = days
+		}
+	}
+	if maxLead < 0 && !settings.NotifyOverdue {
+		return reminders, nil
+	}
+
+	quiet := make(map[string]bool)
+	for _, key := range settings.QuietDimensions {
+		quiet[key] = true
+	}
+
+	snoozed, err := getSnoozedTasks(now)
+	if err != nil {
+		return nil, err
+	}
+
+	today := startOfDay(now)
+
+	// 查询所有未完成且有截止日期的任务
+	query := TaskQuery{
+		Statuses: []string{"not-started", "in-progress"},
+		DueTo:    today.AddDate(0, 0, maxLead).Format(taskDateLayout),
+		SortBy:   "endDate",
+		PageSize: maxTaskPageSize,
+	}
+	if maxLead < 0 {
+		query.DueTo = today.Format(taskDateLayout)
+	}
+
+	seen := make(map[string]bool)
+	for page := 1; ; page++ {
+		query.Page = page
+		result, err := queryTasksAt(query, now)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, item := range result.Items {
+			if seen[item.Task.ID] || quiet[item.DimensionKey] || snoozed[item.Task.ID] {
+				continue
+			}
+			seen[item.Task.ID] = true
+
+			date, ok := taskDate(*item.Task.EndDate)
+			if !ok {
+				continue
+			}
+			dueDate, err := time.ParseInLocation(taskDateLayout, date, now.Location())
+			if err != nil {
+				continue
+			}
+			daysLeft := int(math.Round(dueDate.Sub(today).Hours() / 24))
+
+			reminder := TaskReminder{
+				Task:         item.Task,
+				Year:         item.Year,
+				DimensionKey: item.DimensionKey,
+				DaysLeft:     daysLeft,
+			}
+			switch {
+			case daysLeft < 0 && settings.NotifyOverdue:
+				reminder.Kind = reminderKindOverdue
+				reminder.Message = fmt.Sprintf("「%s」已逾期 %d 天", item.Task.Title, -daysLeft)
+			case daysLeft == 0 && leadDays[0]:
+				reminder.Kind = reminderKindDue
+				reminder.Message = fmt.Sprintf("「%s」今天到期", item.Task.Title)
+			case daysLeft > 0 && leadDays[daysLeft]:
+				reminder.Kind = reminderKindDue
+				reminder.Message = fmt.Sprintf("「%s」还有 %d 天到期", item.Task.Title, daysLeft)
+			default:
+				continue
+			}
+			reminders = append(reminders, reminder)
+		}
+
+		if page*result.PageSize >= result.Total {
+			break
+		}
+	}
+
+	sort.SliceStable(reminders, func(i, j int) bool {
+		return reminders[i].DaysLeft < reminders[j].DaysLeft
+	})
+	return reminders, nil
+}
+
+// 辅助函数：获取当前处于推迟状态的任务
+func getSnoozedTasks(now time.Time) (map[string]bool, error) {
+	rows, err := db.Query(`SELECT task_id, snoozed_until FROM task_reminders WHERE snoozed_until IS NOT NULL AND snoozed_until != ''`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	snoozed := make(map[string]bool)
+	for rows.Next() {
+		var taskID, until string
+		if err := rows.Scan(&taskID, &until); err != nil {
+			return nil, err
+		}
+		if t, err := time.Parse(time.RFC3339, until); err == nil && t.After(now) {
+			snoozed[taskID] = true
+		}
+	}
+
+	return snoozed, rows.Err()
+}
+
+// 辅助函数：过滤出今天尚未提醒过的任务并记录提醒
+func markRemindersNotified(reminders []TaskReminder, now time.Time) ([]TaskReminder, error) {
+	today := now.Format(taskDateLayout)
+	fresh := []TaskReminder{}
+
+	for _, reminder := range reminders {
+		kind := fmt.Sprintf("%s:%d", reminder.Kind, reminder.DaysLeft)
+		if reminder.Kind == reminderKindOverdue {
+			// 逾期每天只提醒一次
+			kind = reminderKindOverdue
+		}
+
+		var lastKind, lastDate sql.NullString
+		err := db.QueryRow(`SELECT last_kind, last_notified_date FROM task_reminders WHERE task_id = ?`, reminder.Task.ID).Scan(&lastKind, &lastDate)
+		if err != nil && err != sql.ErrNoRows {
+			return nil, err
+		}
+		if lastKind.String == kind && lastDate.String == today {
+			continue
+		}
+
+		_, err = db.Exec(
+			`INSERT INTO task_reminders (task_id, last_kind, last_notified_date) VALUES (?, ?, ?) ON CONFLICT(task_id) DO UPDATE SET last_kind = excluded.last_kind, last_notified_date = excluded.last_notified_date`,
+			reminder.Task.ID, kind, today,
+		)
+		if err != nil {
+			return nil, err
+		}
+		fresh = append(fresh, reminder)
+	}
+
+	return fresh, nil
+}
+
+// 辅助函数：取某个时间点当天的零点
+func startOfDay(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
+
+// checkReminders 执行一次提醒检查，返回下一次检查的间隔
+func checkReminders(ctx context.Context) time.Duration {
+	settings, err := GetReminderSettings()
+	if err != nil {
+		log.Printf("读取提醒设置失败: %v", err)
+		settings = DefaultReminderSettings()
+	}
+
+	interval := time.Duration(settings.CheckIntervalMinutes) * time.Minute
+	if interval <= 0 {
+		interval = time.Duration(DefaultReminderSettings().CheckIntervalMinutes) * time.Minute
+	}
+
+	if db == nil || !settings.Enabled {
+		return interval
+	}
+
+	now := time.Now()
+	reminders, err := evaluateReminders(settings, now)
+	if err != nil {
+		log.Printf("检查任务提醒失败: %v", err)
+		return interval
+	}
+
+	reminders, err = markRemindersNotified(reminders, now)
+	if err != nil {
+		log.Printf("记录任务提醒失败: %v", err)
+		return interval
+	}
+	if len(reminders) == 0 {
+		return interval
+	}
+
+	// 向前端发送事件通知
+	runtime.EventsEmit(ctx, taskReminderEvent, reminders)
+
+	// 发送系统通知
+	if settings.NativeNotifications {
+		title := "Manifest 任务提醒"
+		message := reminders[0].Message
+		if len(reminders) > 1 {
+			message = fmt.Sprintf("%s 等 %d 个任务需要关注", message, len(reminders))
+		}
+		if err := sendNativeNotification(title, message); err != nil {
+			log.Printf("发送系统通知失败: %v", err)
+		}
+	}
+
+	return interval
+}
@@ -0,0 +1,575 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// 年度报告导出格式
+const (
+	reportFormatHTML = "html"
+	reportFormatPDF  = "pdf"
+)
+
+// 维度未设置颜色时使用的默认颜色
+const defaultDimensionColor = "#3498db"
+
+// 月份名称
+var reportMonthNames = []string{"1月", "2月", "3月", "4月", "5月", "6月", "7月", "8月", "9月", "10月", "11月", "12月"}
+
+// ReviewDimension 年度报告中的单个维度
+type ReviewDimension struct {
+	Key              string   `json:"key"`
+	Title            string   `json:"title"`
+	Color            string   `json:"color"`
+	Weight           float64  `json:"weight"`
+	Score            float64  `json:"score"`
+	CompletedTasks   int      `json:"completedTasks"`
+	TotalTasks       int      `json:"totalTasks"`
+	CompletionRate   float64  `json:"completionRate"` // 0-100
+	AnnualGoal       string   `json:"annualGoal"`
+	QuarterlyGoals   []string `json:"quarterlyGoals"`
+	MonthlyCompleted []int    `json:"monthlyCompleted"` // 每月完成的任务数
+	MonthlyTotal     []int    `json:"monthlyTotal"`     // 每月的任务总数
+	CompletedByMonth [][]Task `json:"completedByMonth"` // 每月完成的任务
+}
+
+// ReviewReport 年度绩效报告
+type ReviewReport struct {
+	Year             string            `json:"year"`
+	GeneratedAt      string            `json:"generatedAt"`
+	TotalScore       float64           `json:"totalScore"`
+	Grade            GradeResult       `json:"grade"`
+	CompletedTasks   int               `json:"completedTasks"`
+	TotalTasks       int               `json:"totalTasks"`
+	Dimensions       []ReviewDimension `json:"dimensions"`
+	MonthlyCompleted []int             `json:"monthlyCompleted"`
+}
+
+// BuildReviewReport 生成指定年度的绩效报告数据
+func BuildReviewReport(year string) (*ReviewReport, error) {
+	data, err := GetAnnualData(year)
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		return nil, fmt.Errorf("年度数据不存在: %s", year)
+	}
+
+	return buildReviewReport(*data, time.Now()), nil
+}
+
+// 辅助函数：根据年度数据生成报告
+func buildReviewReport(data AnnualData, now time.Time) *ReviewReport {
+	report := &ReviewReport{
+		Year:             data.Year,
+		GeneratedAt:      now.Format("2006-01-02 15:04"),
+		TotalScore:       data.TotalScore,
+		Grade:            EvaluateGrade(data),
+		Dimensions:       []ReviewDimension{},
+		MonthlyCompleted: make([]int, 12),
+	}
+
+	for _, config := range orderedDimensionConfigs(data) {
+		dimData, ok := data.Dimensions[config.Key]
+		if !ok {
+			continue
+		}
+
+		dimension := ReviewDimension{
+			Key:              config.Key,
+			Title:            config.Title,
+			Color:            config.Color,
+			Weight:           data.Settings.Scoring.DimensionWeights[config.Key],
+			Score:            dimData.TotalScore,
+			AnnualGoal:       dimData.AnnualGoal,
+			QuarterlyGoals:   make([]string, 4),
+			MonthlyCompleted: make([]int, 12),
+			MonthlyTotal:     make([]int, 12),
+			CompletedByMonth: make([][]Task, 12),
+		}
+		if dimension.Color == "" {
+			dimension.Color = defaultDimensionColor
+		}
+		copy(dimension.QuarterlyGoals, dimData.QuarterlyGoals)
+
+		for month, tasks := range dimData.MonthlyTasks {
+			if month >= 12 {
+				break
+			}
+			dimension.CompletedByMonth[month] = []Task{}
+			for _, task := range tasks {
+				dimension.TotalTasks++
+				dimension.MonthlyTotal[month]++
+				if task.Status == "completed" {
+					dimension.CompletedTasks++
+					dimension.MonthlyCompleted[month]++
+					dimension.CompletedByMonth[month] = append(dimension.CompletedByMonth[month], task)
+					report.MonthlyCompleted[month]++
+				}
+			}
+		}
+		if dimension.TotalTasks > 0 {
+			dimension.CompletionRate = float64(dimension.CompletedTasks) * 100 / float64(dimension.TotalTasks)
+		}
+
+		report.CompletedTasks += dimension.CompletedTasks
+		report.TotalTasks += dimension.TotalTasks
+		report.Dimensions = append(report.Dimensions, dimension)
+	}
+
+	return report
+}
+
+// 辅助函数：按配置顺序返回维度，未配置的维度按key排序追加在后
+func orderedDimensionConfigs(data AnnualData) []DimensionConfig {
+	configs := []DimensionConfig{}
+	seen := make(map[string]bool)
+	for _, config := range data.DimensionConfigs {
+		if seen[config.Key] {
+			continue
+		}
+		seen[config.Key] = true
+		if config.Title == "" {
+			config.Title = config.Key
+		}
+		configs = append(configs, config)
+	}
+
+	extra := []string{}
+	for key := range data.Dimensions {
+		if !seen[key] {
+			extra = append(extra, key)
+		}
+	}
+	sort.Strings(extra)
+	for _, key := range extra {
+		configs = append(configs, DimensionConfig{Key: key, Title: key})
+	}
+
+	return configs
+}
+
+// ExportReviewReport 将年度报告导出到指定路径
+func ExportReviewReport(year, format, path string) error {
+	report, err := BuildReviewReport(year)
+	if err != nil {
+		return err
+	}
+
+	var content []byte
+	switch format {
+	case reportFormatHTML:
+		html, err := RenderReviewReportHTML(report)
+		if err != nil {
+			return err
+		}
+		content = []byte(html)
+	case reportFormatPDF:
+		content = RenderReviewReportPDF(report)
+	default:
+		return fmt.Errorf("不支持的报告格式: %s", format)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("创建报告目录失败: %w", err)
+	}
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		return fmt.Errorf("写入报告失败: %w", err)
+	}
+	return nil
+}
+
+// chart 与输出格式无关的简单图表，可渲染为 SVG 或绘制到 PDF
+type chart struct {
+	Width  float64
+	Height float64
+	Rects  []chartRect
+	Lines  []chartLine
+	Labels []chartLabel
+}
+
+type chartRect struct {
+	X, Y, W, H float64
+	Color      string
+}
+
+type chartLine struct {
+	X1, Y1, X2, Y2 float64
+	Width          float64
+	Color          string
+}
+
+type chartLabel struct {
+	X, Y   float64
+	Size   float64
+	Anchor string // start, middle, end
+	Text   string
+}
+
+// 图表边距
+const (
+	chartMarginLeft   = 36.0
+	chartMarginRight  = 12.0
+	chartMarginTop    = 12.0
+	chartMarginBottom = 24.0
+)
+
+// 辅助函数：绘制坐标轴与纵轴刻度
+func (c *chart) drawAxes(maxValue float64, steps int, format string) {
+	plotHeight := c.Height - chartMarginTop - chartMarginBottom
+	for i := 0; i <= steps; i++ {
+		y := chartMarginTop + plotHeight*(1-float64(i)/float64(steps))
+		c.Lines = append(c.Lines, chartLine{X1: chartMarginLeft, Y1: y, X2: c.Width - chartMarginRight, Y2: y, Width: 0.5, Color: "#e5e7eb"})
+		c.Labels = append(c.Labels, chartLabel{X: chartMarginLeft - 4, Y: y + 3, Size: 8, Anchor: "end", Text: fmt.Sprintf(format, maxValue*float64(i)/float64(steps))})
+	}
+
+	plotWidth := c.Width - chartMarginLeft - chartMarginRight
+	for month, name := range reportMonthNames {
+		x := chartMarginLeft + plotWidth*(float64(month)+0.5)/12
+		c.Labels = append(c.Labels, chartLabel{X: x, Y: c.Height - 8, Size: 8, Anchor: "middle", Text: name})
+	}
+}
+
+// 辅助函数：每月完成任务数的堆叠柱状图
+func monthlyCompletedChart(report *ReviewReport, width, height float64) *chart {
+	c := &chart{Width: width, Height: height}
+
+	maxValue := 0
+	for _, count := range report.MonthlyCompleted {
+		if count > maxValue {
+			maxValue = count
+		}
+	}
+	steps := 4
+	if maxValue < steps {
+		maxValue = steps
+	}
+	if maxValue%steps != 0 {
+		maxValue += steps - maxValue%steps
+	}
+	c.drawAxes(float64(maxValue), steps, "%.0f")
+
+	plotWidth := width - chartMarginLeft - chartMarginRight
+	plotHeight := height - chartMarginTop - chartMarginBottom
+	barWidth := plotWidth / 12 * 0.6
+
+	for month := 0; month < 12; month++ {
+		x := chartMarginLeft + plotWidth*(float64(month)+0.2)/12
+		bottom := chartMarginTop + plotHeight
+		for _, dimension := range report.Dimensions {
+			count := dimension.MonthlyCompleted[month]
+			if count == 0 {
+				continue
+			}
+			h := plotHeight * float64(count) / float64(maxValue)
+			bottom -= h
+			c.Rects = append(c.Rects, chartRect{X: x, Y: bottom, W: barWidth, H: h, Color: dimension.Color})
+		}
+	}
+
+	return c
+}
+
+// 辅助函数：各维度累计完成率的折线图
+func completionTrendChart(report *ReviewReport, width, height float64) *chart {
+	c := &chart{Width: width, Height: height}
+	c.drawAxes(100, 4, "%.0f%%")
+
+	plotWidth := width - chartMarginLeft - chartMarginRight
+	plotHeight := height - chartMarginTop - chartMarginBottom
+
+	for _, dimension := range report.Dimensions {
+		if dimension.TotalTasks == 0 {
+			continue
+		}
+
+		completed := 0
+		var prevX, prevY float64
+		for month := 0; month < 12; month++ {
+			completed += dimension.MonthlyCompleted[month]
+			rate := float64(completed) / float64(dimension.TotalTasks)
+			x := chartMarginLeft + plotWidth*(float64(month)+0.5)/12
+			y := chartMarginTop + plotHeight*(1-rate)
+			if month > 0 {
+				c.Lines = append(c.Lines, chartLine{X1: prevX, Y1: prevY, X2: x, Y2: y, Width: 1.5, Color: dimension.Color})
+			}
+			prevX, prevY = x, y
+		}
+	}
+
+	return c
+}
+
+// 辅助函数：将图表渲染为 SVG
+func (c *chart) SVG() htmltemplate.HTML {
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %.0f %.0f" width="100%%">`, c.Width, c.Height)
+	for _, r := range c.Rects {
+		fmt.Fprintf(&b, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="%s"/>`, r.X, r.Y, r.W, r.H, htmltemplate.HTMLEscapeString(r.Color))
+	}
+	for _, l := range c.Lines {
+		fmt.Fprintf(&b, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="%s" stroke-width="%.1f" stroke-linecap="round"/>`,
+			l.X1, l.Y1, l.X2, l.Y2, htmltemplate.HTMLEscapeString(l.Color), l.Width)
+	}
+	for _, t := range c.Labels {
+		fmt.Fprintf(&b, `<text x="%.1f" y="%.1f" font-size="%.0f" text-anchor="%s" fill="#6b7280">%s</text>`,
+			t.X, t.Y, t.Size, t.Anchor, htmltemplate.HTMLEscapeString(t.Text))
+	}
+	b.WriteString(`</svg>`)
+	return htmltemplate.HTML(b.String())
+}
+
+// 辅助函数：将图表绘制到 PDF 页面的指定位置
+func (c *chart) drawPDF(page *pdfPage, left, top float64) {
+	for _, l := range c.Lines {
+		page.Line(left+l.X1, top+l.Y1, left+l.X2, top+l.Y2, l.Width, pdfParseColor(l.Color, pdfLight))
+	}
+	for _, r := range c.Rects {
+		page.Rect(left+r.X, top+r.Y, r.W, r.H, pdfParseColor(r.Color, pdfGray))
+	}
+	for _, t := range c.Labels {
+		x := left + t.X
+		switch t.Anchor {
+		case "middle":
+			x -= pdfTextWidth(t.Text, t.Size) / 2
+		case "end":
+			x -= pdfTextWidth(t.Text, t.Size)
+		}
+		page.Text(x, top+t.Y, t.Size, pdfGray, t.Text)
+	}
+}
+
+// 报告模板使用的函数
+var reportTemplateFuncs = htmltemplate.FuncMap{
+	"month":   func(i int) string { return reportMonthNames[i] },
+	"quarter": func(i int) string { return fmt.Sprintf("第%d季度", i+1) },
+	"percent": func(v float64) string { return fmt.Sprintf("%.1f%%", v) },
+	"weight":  func(v float64) string { return fmt.Sprintf("%.0f%%", v*100) },
+	"score":   func(v float64) string { return fmt.Sprintf("%.1f", v) },
+	"monthlyChart": func(r *ReviewReport) htmltemplate.HTML {
+		return monthlyCompletedChart(r, 720, 220).SVG()
+	},
+	"trendChart": func(r *ReviewReport) htmltemplate.HTML {
+		return completionTrendChart(r, 720, 220).SVG()
+	},
+}
+
+var reviewReportTemplate = htmltemplate.Must(htmltemplate.New("report.html").Funcs(reportTemplateFuncs).Parse(`<!DOCTYPE html>
+<html lang="zh-CN">
+<head>
+<meta charset="utf-8">
+<title>{{.Year}} 年度绩效报告</title>
+<style>
+body { font-family: -apple-system, "PingFang SC", "Microsoft YaHei", sans-serif; color: #1f2937; max-width: 820px; margin: 32px auto; padding: 0 16px; }
+h1 { font-size: 26px; margin-bottom: 4px; }
+h2 { font-size: 19px; margin-top: 32px; border-bottom: 1px solid #e5e7eb; padding-bottom: 6px; }
+h3 { font-size: 16px; margin: 24px 0 8px; }
+.meta { color: #6b7280; font-size: 13px; }
+.summary { display: flex; gap: 12px; margin-top: 20px; }
+.card { flex: 1; border: 1px solid #e5e7eb; border-radius: 8px; padding: 12px 16px; }
+.card .label { color: #6b7280; font-size: 12px; }
+.card .value { font-size: 24px; font-weight: 700; margin-top: 4px; }
+.grade { color: #4338ca; }
+table { border-collapse: collapse; width: 100%; }
+th, td { border-bottom: 1px solid #e5e7eb; padding: 6px 8px; text-align: right; font-size: 14px; }
+th:first-child, td:first-child { text-align: left; }
+.swatch { display: inline-block; width: 10px; height: 10px; border-radius: 2px; margin-right: 6px; }
+.legend span { margin-right: 14px; font-size: 12px; color: #4b5563; }
+.goal { color: #374151; font-size: 14px; margin: 4px 0; }
+.month { font-size: 13px; color: #6b7280; margin-top: 8px; }
+ul { margin: 4px 0; padding-left: 20px; font-size: 14px; }
+@media print { body { margin: 0 auto; } h2 { page-break-after: avoid; } }
+</style>
+</head>
+<body>
+<h1>{{.Year}} 年度绩效报告</h1>
+<p class="meta">生成时间：{{.GeneratedAt}}</p>
+
+<div class="summary">
+<div class="card"><div class="label">年度总分</div><div class="value">{{score .TotalScore}}</div></div>
+<div class="card"><div class="label">绩效评级</div><div class="value grade">{{.Grade.Grade}}</div><div class="label">{{.Grade.Description}}</div></div>
+<div class="card"><div class="label">加权完成率</div><div class="value">{{percent .Grade.Score}}</div></div>
+<div class="card"><div class="label">完成任务</div><div class="value">{{.CompletedTasks}} / {{.TotalTasks}}</div></div>
+</div>
+
+<h2>维度得分</h2>
+<table>
+<tr><th>维度</th><th>权重</th><th>得分</th><th>完成 / 总数</th><th>完成率</th></tr>
+{{range .Dimensions}}<tr><td><span class="swatch" style="background: {{.Color}}"></span>{{.Title}}</td><td>{{weight .Weight}}</td><td>{{score .Score}}</td><td>{{.CompletedTasks}} / {{.TotalTasks}}</td><td>{{percent .CompletionRate}}</td></tr>
+{{end}}</table>
+
+<h2>趋势</h2>
+<p class="legend">{{range .Dimensions}}<span><span class="swatch" style="background: {{.Color}}"></span>{{.Title}}</span>{{end}}</p>
+<h3>每月完成任务数</h3>
+{{monthlyChart .}}
+<h3>累计完成率</h3>
+{{trendChart .}}
+
+<h2>目标与完成情况</h2>
+{{range .Dimensions}}
+<h3><span class="swatch" style="background: {{.Color}}"></span>{{.Title}}</h3>
+{{if .AnnualGoal}}<p class="goal"><strong>年度目标：</strong>{{.AnnualGoal}}</p>{{end}}
+{{range $i, $goal := .QuarterlyGoals}}{{if $goal}}<p class="goal"><strong>{{quarter $i}}：</strong>{{$goal}}</p>{{end}}{{end}}
+{{range $i, $tasks := .CompletedByMonth}}{{if $tasks}}<div class="month">{{month $i}}</div>
+<ul>{{range $tasks}}<li>{{.Title}}</li>{{end}}</ul>{{end}}{{end}}
+{{end}}
+</body>
+</html>
+`))
+
+// RenderReviewReportHTML 将年度报告渲染为自包含的 HTML
+func RenderReviewReportHTML(report *ReviewReport) (string, error) {
+	var buf bytes.Buffer
+	if err := reviewReportTemplate.Execute(&buf, report); err != nil {
+		return "", fmt.Errorf("渲染年度报告失败: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// PDF 版面参数
+const (
+	pdfMargin     = 48.0
+	pdfLineHeight = 1.6
+)
+
+// pdfReportWriter 带自动分页的 PDF 排版辅助
+type pdfReportWriter struct {
+	doc  *pdfDocument
+	page *pdfPage
+	y    float64
+}
+
+// 辅助函数：剩余空间不足时换页
+func (w *pdfReportWriter) ensure(height float64) {
+	if w.page == nil || w.y+height > pdfPageHeight-pdfMargin {
+		w.page = w.doc.AddPage()
+		w.y = pdfMargin
+	}
+}
+
+// 辅助函数：输出一段可折行的文本
+func (w *pdfReportWriter) paragraph(text string, size float64, color pdfColor, indent float64) {
+	for _, line := range pdfWrapText(text, size, pdfPageWidth-2*pdfMargin-indent) {
+		w.ensure(size * pdfLineHeight)
+		w.y += size * pdfLineHeight
+		w.page.Text(pdfMargin+indent, w.y-size*0.3, size, color, line)
+	}
+}
+
+// 辅助函数：输出章节标题
+func (w *pdfReportWriter) heading(text string, size float64) {
+	w.ensure(size*pdfLineHeight + 40)
+	w.y += size * 0.8
+	w.paragraph(text, size, pdfBlack, 0)
+	w.page.Line(pdfMargin, w.y+4, pdfPageWidth-pdfMargin, w.y+4, 0.5, pdfLight)
+	w.y += 10
+}
+
+// RenderReviewReportPDF 将年度报告渲染为 PDF
+func RenderReviewReportPDF(report *ReviewReport) []byte {
+	w := &pdfReportWriter{doc: newPDFDocument()}
+	contentWidth := pdfPageWidth - 2*pdfMargin
+
+	// 标题与概览
+	w.paragraph(fmt.Sprintf("%s 年度绩效报告", report.Year), 22, pdfBlack, 0)
+	w.paragraph("生成时间："+report.GeneratedAt, 9, pdfGray, 0)
+	w.y += 8
+	w.paragraph(fmt.Sprintf("年度总分：%.1f    绩效评级：%s（%s）", report.TotalScore, report.Grade.Grade, report.Grade.Description), 12, pdfBlack, 0)
+	w.paragraph(fmt.Sprintf("加权完成率：%.1f%%    完成任务：%d / %d", report.Grade.Score, report.CompletedTasks, report.TotalTasks), 12, pdfBlack, 0)
+
+	// 维度得分表
+	w.heading("维度得分", 15)
+	columns := []struct {
+		title string
+		x     float64
+	}{
+		{"维度", 0}, {"权重", 200}, {"得分", 260}, {"完成 / 总数", 320}, {"完成率", 420},
+	}
+	w.ensure(18)
+	w.y += 14
+	for _, column := range columns {
+		w.page.Text(pdfMargin+column.x, w.y, 10, pdfGray, column.title)
+	}
+	for _, dimension := range report.Dimensions {
+		w.ensure(18)
+		w.y += 18
+		w.page.Rect(pdfMargin, w.y-8, 8, 8, pdfParseColor(dimension.Color, pdfGray))
+		values := []string{
+			dimension.Title,
+			fmt.Sprintf("%.0f%%", dimension.Weight*100),
+			fmt.Sprintf("%.1f", dimension.Score),
+			fmt.Sprintf("%d / %d", dimension.CompletedTasks, dimension.TotalTasks),
+			fmt.Sprintf("%.1f%%", dimension.CompletionRate),
+		}
+		for i, value := range values {
+			x := pdfMargin + columns[i].x
+			if i == 0 {
+				x += 12
+			}
+			w.page.Text(x, w.y, 10, pdfBlack, value)
+		}
+		w.page.Line(pdfMargin, w.y+5, pdfPageWidth-pdfMargin, w.y+5, 0.5, pdfLight)
+	}
+
+	// 趋势图
+	charts := []struct {
+		title string
+		chart *chart
+	}{
+		{"每月完成任务数", monthlyCompletedChart(report, contentWidth, 180)},
+		{"累计完成率", completionTrendChart(report, contentWidth, 180)},
+	}
+	w.heading("趋势", 15)
+	w.y += 4
+	x := pdfMargin
+	for _, dimension := range report.Dimensions {
+		if x+24+pdfTextWidth(dimension.Title, 9) > pdfPageWidth-pdfMargin {
+			x = pdfMargin
+			w.y += 14
+		}
+		w.page.Rect(x, w.y, 8, 8, pdfParseColor(dimension.Color, pdfGray))
+		w.page.Text(x+11, w.y+8, 9, pdfGray, dimension.Title)
+		x += 24 + pdfTextWidth(dimension.Title, 9)
+	}
+	w.y += 12
+	for _, item := range charts {
+		w.ensure(item.chart.Height + 24)
+		w.paragraph(item.title, 11, pdfBlack, 0)
+		item.chart.drawPDF(w.page, pdfMargin, w.y)
+		w.y += item.chart.Height
+	}
+
+	// 目标与完成情况
+	w.heading("目标与完成情况", 15)
+	for _, dimension := range report.Dimensions {
+		w.ensure(40)
+		w.y += 6
+		w.paragraph(dimension.Title, 13, pdfParseColor(dimension.Color, pdfBlack), 0)
+		if dimension.AnnualGoal != "" {
+			w.paragraph("年度目标："+dimension.AnnualGoal, 10, pdfBlack, 0)
+		}
+		for i, goal := range dimension.QuarterlyGoals {
+			if goal != "" {
+				w.paragraph(fmt.Sprintf("第%d季度：%s", i+1, goal), 10, pdfBlack, 0)
+			}
+		}
+		for month, tasks := range dimension.CompletedByMonth {
+			if len(tasks) == 0 {
+				continue
+			}
+			w.paragraph(reportMonthNames[month], 10, pdfGray, 0)
+			for _, task := range tasks {
+				w.paragraph("• "+task.Title, 10, pdfBlack, 12)
+			}
+		}
+	}
+
+	return w.doc.Bytes()
+}
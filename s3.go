@@ -0,0 +1,381 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// 系统钥匙串中保存 S3 密钥的服务名称
+const s3KeyringService = "Manifest S3 Backup"
+
+// 上传时记录在对象元数据中的 SHA-256，下载后用于校验
+const s3ChecksumHeader = "X-Amz-Meta-Sha256"
+
+// 未签名的请求内容哈希（空内容）
+const s3EmptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+// S3Settings S3 兼容对象存储的备份位置（MinIO、AWS S3 等）
+type S3Settings struct {
+	Endpoint        string `json:"endpoint"` // 例如 http://localhost:9000 或 https://s3.amazonaws.com
+	Region          string `json:"region"`
+	Bucket          string `json:"bucket"`
+	Prefix          string `json:"prefix"` // 存放备份的目录，例如 manifest/
+	AccessKeyID     string `json:"accessKeyId"`
+	SecretAccessKey string `json:"secretAccessKey"` // 保存在系统钥匙串时为空
+	UseKeyring      bool   `json:"useKeyring"`      // 密钥保存在系统钥匙串中，不写入配置文件
+}
+
+// s3Client 极简 S3 客户端，使用路径形式的地址（兼容 MinIO）与 AWS Signature V4 签名
+type s3Client struct {
+	endpoint  *url.URL
+	region    string
+	bucket    string
+	prefix    string
+	accessKey string
+	secretKey string
+	http      *http.Client
+}
+
+// S3 ListObjectsV2 响应
+type s3ListResult struct {
+	Contents []struct {
+		Key  string `xml:"Key"`
+		Size int64  `xml:"Size"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+// S3 错误响应
+type s3Error struct {
+	Code    string `xml:"Code"`
+	Message string `xml:"Message"`
+}
+
+// 辅助函数：校验 S3 设置
+func validateS3Settings(settings S3Settings) error {
+	if _, err := parseS3Endpoint(settings.Endpoint); err != nil {
+		return err
+	}
+	if settings.Bucket == "" {
+		return fmt.Errorf("请设置S3存储桶")
+	}
+	if settings.AccessKeyID == "" {
+		return fmt.Errorf("请设置S3访问密钥ID")
+	}
+	if strings.Contains(settings.Prefix, "..") {
+		return fmt.Errorf("无效的S3目录: %s", settings.Prefix)
+	}
+	return nil
+}
+
+// 辅助函数：将 S3 密钥保存到系统钥匙串，并从设置中清除，调用方随后保存设置
+func storeS3Secret(settings *S3Settings) error {
+	if !settings.UseKeyring {
+		return nil
+	}
+	return storeKeyringSecret(s3KeyringService, s3KeyringUser(*settings), &settings.SecretAccessKey)
+}
+
+// 辅助函数：钥匙串中的账号名称，按服务地址与访问密钥ID区分
+func s3KeyringUser(settings S3Settings) string {
+	return settings.AccessKeyID + "@" + strings.TrimRight(settings.Endpoint, "/")
+}
+
+// 辅助函数：校验服务地址
+func parseS3Endpoint(raw string) (*url.URL, error) {
+	endpoint, err := url.Parse(strings.TrimRight(raw, "/"))
+	if err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+		return nil, fmt.Errorf("无效的S3服务地址: %s", raw)
+	}
+	return endpoint, nil
+}
+
+// 辅助函数：创建 S3 客户端，设置中没有密钥时从系统钥匙串读取
+func newS3Client(settings S3Settings) (*s3Client, error) {
+	if err := validateS3Settings(settings); err != nil {
+		return nil, err
+	}
+	endpoint, _ := parseS3Endpoint(settings.Endpoint)
+
+	secret := settings.SecretAccessKey
+	if settings.UseKeyring {
+		var err error
+		if secret, err = loadKeyringSecret(s3KeyringService, s3KeyringUser(settings), secret); err != nil {
+			return nil, err
+		}
+	}
+	if secret == "" {
+		return nil, fmt.Errorf("请设置S3访问密钥")
+	}
+
+	region := settings.Region
+	if region == "" {
+		region = "us-east-1"
+	}
+	prefix := strings.Trim(settings.Prefix, "/")
+	if prefix != "" {
+		prefix += "/"
+	}
+
+	return &s3Client{
+		endpoint:  endpoint,
+		region:    region,
+		bucket:    settings.Bucket,
+		prefix:    prefix,
+		accessKey: settings.AccessKeyID,
+		secretKey: secret,
+		http:      &http.Client{Timeout: backupRequestTimeout},
+	}, nil
+}
+
+// 辅助函数：列出目录中的快照
+func (c *s3Client) list(ctx context.Context) ([]BackupSnapshot, error) {
+	snapshots := []BackupSnapshot{}
+	token := ""
+	for {
+		query := url.Values{"list-type": {"2"}, "prefix": {c.prefix}}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+		response, err := c.do(ctx, http.MethodGet, "", query, nil, nil, 0)
+		if err != nil {
+			return nil, err
+		}
+		content, err := io.ReadAll(io.LimitReader(response.Body, maxBackupResponseSize))
+		response.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		if response.StatusCode != http.StatusOK {
+			return nil, s3ResponseError("列出备份失败", response, content)
+		}
+
+		var result s3ListResult
+		if err := xml.Unmarshal(content, &result); err != nil {
+			return nil, fmt.Errorf("解析S3响应失败: %w", err)
+		}
+		for _, object := range result.Contents {
+			name := strings.TrimPrefix(object.Key, c.prefix)
+			created, ok := parseBackupName(name)
+			if !ok {
+				continue
+			}
+			snapshots = append(snapshots, BackupSnapshot{Name: name, Size: object.Size, CreatedAt: created.Format(backupDisplayLayout)})
+		}
+
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return snapshots, nil
+		}
+		token = result.NextContinuationToken
+	}
+}
+
+// 辅助函数：上传快照，服务器按 Content-MD5 与签名中的 SHA-256 校验内容，SHA-256 同时保存在元数据中
+func (c *s3Client) upload(ctx context.Context, name string, content []byte, progress func(done int64)) error {
+	sum := sha256.Sum256(content)
+	md5Sum := md5.Sum(content)
+	headers := map[string]string{
+		"Content-Type":         "application/octet-stream",
+		"Content-Md5":          base64.StdEncoding.EncodeToString(md5Sum[:]),
+		"X-Amz-Content-Sha256": hex.EncodeToString(sum[:]),
+		s3ChecksumHeader:       hex.EncodeToString(sum[:]),
+	}
+
+	body := &progressReader{reader: bytes.NewReader(content), report: progress}
+	response, err := c.do(ctx, http.MethodPut, c.prefix+name, nil, headers, body, int64(len(content)))
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	message, _ := io.ReadAll(io.LimitReader(response.Body, maxBackupResponseSize))
+
+	if response.StatusCode != http.StatusOK {
+		return s3ResponseError("上传备份失败", response, message)
+	}
+	// 单次上传的 ETag 为内容的 MD5
+	if etag := strings.Trim(response.Header.Get("ETag"), `"`); len(etag) == 32 && etag != hex.EncodeToString(md5Sum[:]) {
+		return fmt.Errorf("上传备份失败: 服务器校验值不一致")
+	}
+	return nil
+}
+
+// 辅助函数：下载快照，按上传时保存的 SHA-256 校验内容
+func (c *s3Client) download(ctx context.Context, name string, progress func(done, total int64)) ([]byte, error) {
+	response, err := c.do(ctx, http.MethodGet, c.prefix+name, nil, nil, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("备份不存在: %s", name)
+	}
+	if response.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(response.Body, maxBackupResponseSize))
+		return nil, s3ResponseError("下载备份失败", response, message)
+	}
+
+	content, err := readBackupDownload(response, progress)
+	if err != nil {
+		return nil, err
+	}
+
+	if expected := response.Header.Get(s3ChecksumHeader); expected != "" {
+		sum := sha256.Sum256(content)
+		if !strings.EqualFold(expected, hex.EncodeToString(sum[:])) {
+			return nil, fmt.Errorf("备份校验失败，文件可能已损坏: %s", name)
+		}
+	}
+	return content, nil
+}
+
+// 辅助函数：删除快照
+func (c *s3Client) remove(ctx context.Context, name string) error {
+	response, err := c.do(ctx, http.MethodDelete, c.prefix+name, nil, nil, nil, 0)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	message, _ := io.ReadAll(io.LimitReader(response.Body, maxBackupResponseSize))
+
+	if response.StatusCode != http.StatusNoContent && response.StatusCode != http.StatusOK && response.StatusCode != http.StatusNotFound {
+		return s3ResponseError("删除备份失败", response, message)
+	}
+	return nil
+}
+
+// 辅助函数：发送签名请求，key 为空时请求存储桶本身
+func (c *s3Client) do(ctx context.Context, method, key string, query url.Values, headers map[string]string, body io.Reader, length int64) (*http.Response, error) {
+	target := *c.endpoint
+	target.Path = strings.TrimRight(target.Path, "/") + "/" + c.bucket + "/" + key
+	target.RawPath = ""
+	target.RawQuery = strings.ReplaceAll(query.Encode(), "+", "%20")
+
+	request, err := http.NewRequestWithContext(ctx, method, target.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		request.ContentLength = length
+	}
+	for name, value := range headers {
+		request.Header.Set(name, value)
+	}
+	if request.Header.Get("X-Amz-Content-Sha256") == "" {
+		request.Header.Set("X-Amz-Content-Sha256", s3EmptyPayloadHash)
+	}
+	signS3Request(request, c.accessKey, c.secretKey, c.region, time.Now())
+
+	response, err := c.http.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("连接S3服务器失败: %w", err)
+	}
+	return response, nil
+}
+
+// 辅助函数：使用 AWS Signature V4 签名请求，签名 Host 与所有 x-amz-*、Content-MD5、Content-Type、Range 头
+func signS3Request(request *http.Request, accessKey, secretKey, region string, now time.Time) {
+	stamp := now.UTC().Format("20060102T150405Z")
+	day := stamp[:8]
+	request.Header.Set("X-Amz-Date", stamp)
+
+	headers := map[string]string{"host": request.URL.Host}
+	for name, values := range request.Header {
+		lower := strings.ToLower(name)
+		if strings.HasPrefix(lower, "x-amz-") || lower == "content-md5" || lower == "content-type" || lower == "range" {
+			headers[lower] = strings.TrimSpace(strings.Join(values, ","))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		request.Method,
+		request.URL.EscapedPath(),
+		s3CanonicalQuery(request.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		request.Header.Get("X-Amz-Content-Sha256"),
+	}, "\n")
+
+	scope := day + "/" + region + "/s3/aws4_request"
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + stamp + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := s3HMAC([]byte("AWS4"+secretKey), day)
+	key = s3HMAC(key, region)
+	key = s3HMAC(key, "s3")
+	key = s3HMAC(key, "aws4_request")
+	signature := hex.EncodeToString(s3HMAC(key, stringToSign))
+
+	request.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		accessKey, scope, signedHeaders, signature,
+	))
+}
+
+// 辅助函数：按参数名排序并编码查询字符串
+func s3CanonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	parts := []string{}
+	for _, key := range keys {
+		values := query[key]
+		sort.Strings(values)
+		for _, value := range values {
+			parts = append(parts, s3Escape(key)+"="+s3Escape(value))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// 辅助函数：按 AWS 规则编码，只保留字母、数字与 -_.~
+func s3Escape(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
+
+// 辅助函数：HMAC-SHA256
+func s3HMAC(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// 辅助函数：生成包含服务器错误信息的错误
+func s3ResponseError(action string, response *http.Response, content []byte) error {
+	var s3Err s3Error
+	if xml.Unmarshal(content, &s3Err) == nil && s3Err.Code != "" {
+		if s3Err.Code == "SignatureDoesNotMatch" || s3Err.Code == "InvalidAccessKeyId" {
+			return fmt.Errorf("S3认证失败，请检查访问密钥")
+		}
+		return fmt.Errorf("%s: %s %s", action, s3Err.Code, s3Err.Message)
+	}
+	return fmt.Errorf("%s: %s", action, response.Status)
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Scheduler 后台定时任务，job 返回下一次执行前的等待时间
+type Scheduler struct {
+	job     func(ctx context.Context) time.Duration
+	mu      sync.Mutex
+	cancel  context.CancelFunc
+	done    chan struct{} // 调度循环退出后关闭
+	refresh chan struct{}
+}
+
+// NewScheduler 创建后台定时任务
+func NewScheduler(job func(ctx context.Context) time.Duration) *Scheduler {
+	return &Scheduler{
+		job:     job,
+		refresh: make(chan struct{}, 1),
+	}
+}
+
+// Start 启动后台定时任务，重复调用无效
+func (s *Scheduler) Start(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cancel != nil {
+		return
+	}
+	ctx, s.cancel = context.WithCancel(ctx)
+	s.done = make(chan struct{})
+	go s.run(ctx, s.done)
+}
+
+// Stop 停止后台定时任务，正在执行的 job 结束后才返回
+//
+// job 中不能等待调用 Stop 时持有的锁，否则会互相等待。
+func (s *Scheduler) Stop() {
+	s.mu.Lock()
+	cancel, done := s.cancel, s.done
+	s.cancel, s.done = nil, nil
+	s.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+}
+
+// Refresh 立即重新执行一次（例如设置变更后）
+func (s *Scheduler) Refresh() {
+	select {
+	case s.refresh <- struct{}{}:
+	default:
+	}
+}
+
+// 辅助函数：调度循环
+func (s *Scheduler) run(ctx context.Context, done chan struct{}) {
+	defer close(done)
+
+	for {
+		interval := s.job(ctx)
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-s.refresh:
+			timer.Stop()
+		case <-timer.C:
+		}
+	}
+}
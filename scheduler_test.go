@@ -0,0 +1,38 @@
+package main
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Stop 应等待正在执行的 job 结束后才返回
+func TestSchedulerStopWaitsForJob(t *testing.T) {
+	started := make(chan struct{})
+	var finished atomic.Bool
+	scheduler := NewScheduler(func(ctx context.Context) time.Duration {
+		close(started)
+		time.Sleep(50 * time.Millisecond)
+		finished.Store(true)
+		return time.Hour
+	})
+
+	scheduler.Start(context.Background())
+	<-started
+	scheduler.Stop()
+	if !finished.Load() {
+		t.Fatal("Stop 在 job 结束前返回")
+	}
+
+	// 停止后可以重新启动，重复停止无效
+	restarted := make(chan struct{}, 1)
+	scheduler.job = func(ctx context.Context) time.Duration {
+		restarted <- struct{}{}
+		return time.Hour
+	}
+	scheduler.Start(context.Background())
+	<-restarted
+	scheduler.Stop()
+	scheduler.Stop()
+}
@@ -0,0 +1,612 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	goruntime "runtime"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-version"
+)
+
+// 更新进度事件名称
+const updateProgressEvent = "updateProgress"
+
+// 发布资源中的校验和文件与其签名
+const (
+	updateChecksumAsset  = "SHA256SUMS"
+	updateSignatureAsset = "SHA256SUMS.sig"
+)
+
+// 校验和文件中记录发布版本号的行，格式为 "version v1.2.3"，与校验和一起签名
+const updateVersionLine = "version"
+
+// 试运行新版本时传给新进程的环境变量，值为新版本号
+const updateTrialEnv = "MANIFEST_UPDATE_TRIAL"
+
+// 等待新版本启动成功的最长时间，超时视为启动失败并回滚
+const updateTrialTimeout = 2 * time.Minute
+
+// 下载的程序文件最大长度
+const maxUpdateAssetSize = 512 << 20
+
+// 更新状态
+const (
+	UpdateInstallStaged    = "staged"    // 已下载并校验，等待重启
+	UpdateInstallTrial     = "trial"     // 已替换程序，等待新版本启动成功
+	UpdateInstallConfirmed = "confirmed" // 新版本已启动成功
+	UpdateInstallFailed    = "failed"    // 新版本启动失败，已回滚
+)
+
+// updatePublicKey 校验发布签名的 Ed25519 公钥（base64），发布构建时通过
+// -ldflags "-X main.updatePublicKey=..." 写入；为空时不支持应用内更新
+var updatePublicKey = ""
+
+// GitHubAsset 发布版本中的文件
+type GitHubAsset struct {
+	Name               string `json:"name"`
+	Size               int64  `json:"size"`
+	BrowserDownloadURL string `json:"browser_download_url"`
+}
+
+// UpdateProgress 下载更新的进度，通过 updateProgress 事件发送给前端
+type UpdateProgress struct {
+	Stage string `json:"stage"` // download, verify
+	Done  int64  `json:"done"`
+	Total int64  `json:"total"`
+}
+
+// UpdateInstallState 应用内更新状态，保存在数据目录的 updates/state.json 中
+type UpdateInstallState struct {
+	Status     string `json:"status"`               // staged, trial, confirmed, failed
+	Version    string `json:"version"`              // 新版本号
+	StagedPath string `json:"stagedPath"`           // 已下载的新程序
+	Executable string `json:"executable,omitempty"` // 被替换的程序路径
+	BackupPath string `json:"backupPath,omitempty"` // 旧程序备份，回滚时使用
+	UpdatedAt  string `json:"updatedAt"`
+	Error      string `json:"error,omitempty"` // 启动失败的原因
+}
+
+// InstallUpdate 下载当前渠道的最新版本，校验签名与 SHA-256 后暂存，重启时替换
+func InstallUpdate(ctx context.Context, progress func(UpdateProgress)) (*UpdateInstallState, error) {
+	settings, err := GetUpdateSettings()
+	if err != nil {
+		return nil, err
+	}
+	dir, err := updatesDir()
+	if err != nil {
+		return nil, err
+	}
+	client := &http.Client{Timeout: 30 * time.Minute}
+	return installUpdate(ctx, client, settings, currentVersion, updatePublicKey, dir, time.Now(), progress)
+}
+
+// GetUpdateInstallState 获取应用内更新状态，没有进行中的更新时返回 nil
+func GetUpdateInstallState() (*UpdateInstallState, error) {
+	dir, err := updatesDir()
+	if err != nil {
+		return nil, err
+	}
+	return loadUpdateState(dir)
+}
+
+// CancelUpdate 放弃已暂存的更新，或清除上次更新失败的记录
+func CancelUpdate() error {
+	dir, err := updatesDir()
+	if err != nil {
+		return err
+	}
+	state, err := loadUpdateState(dir)
+	if err != nil || state == nil {
+		return err
+	}
+	if state.Status != UpdateInstallStaged && state.Status != UpdateInstallFailed {
+		return fmt.Errorf("没有等待安装的更新")
+	}
+	// 下载失败时没有暂存文件，filepath.Dir("") 会指向当前目录
+	if state.StagedPath != "" {
+		os.RemoveAll(filepath.Dir(state.StagedPath))
+	}
+	return os.Remove(updateStatePath(dir))
+}
+
+// ConfirmUpdate 新版本启动成功后调用，通知等待中的旧进程退出
+func ConfirmUpdate() {
+	trial := os.Getenv(updateTrialEnv)
+	if trial == "" {
+		return
+	}
+	dir, err := updatesDir()
+	if err != nil {
+		log.Printf("确认更新失败: %v", err)
+		return
+	}
+	state, err := loadUpdateState(dir)
+	if err != nil || state == nil || state.Status != UpdateInstallTrial || state.Version != trial {
+		return
+	}
+	state.Status = UpdateInstallConfirmed
+	state.UpdatedAt = time.Now().Format(time.RFC3339)
+	if err := saveUpdateState(dir, state); err != nil {
+		log.Printf("确认更新失败: %v", err)
+		return
+	}
+
+	// 旧进程退出后才能删除旧程序（Windows 下运行中的文件无法删除）
+	go func() {
+		for i := 0; i < 30; i++ {
+			time.Sleep(2 * time.Second)
+			if finishUpdate(dir) {
+				return
+			}
+		}
+	}()
+}
+
+// RunStagedUpdate 启动时替换已暂存的新版本并等待其启动，返回 true 表示新版本已接管，当前进程应退出
+//
+// 新版本在 updateTrialTimeout 内没有调用 ConfirmUpdate 或提前退出时恢复旧程序，继续以旧版本启动。
+func RunStagedUpdate() bool {
+	if os.Getenv(updateTrialEnv) != "" {
+		return false
+	}
+	dir, err := updatesDir()
+	if err != nil {
+		log.Printf("读取更新状态失败: %v", err)
+		return false
+	}
+	state, err := loadUpdateState(dir)
+	if err != nil || state == nil {
+		return false
+	}
+
+	switch state.Status {
+	case UpdateInstallConfirmed:
+		finishUpdate(dir)
+		return false
+	case UpdateInstallTrial:
+		// 上次试运行时进程被中断，恢复旧程序
+		rollbackUpdate(dir, state, fmt.Errorf("更新过程被中断"))
+		return false
+	case UpdateInstallStaged:
+	default:
+		return false
+	}
+
+	executable, err := currentExecutable()
+	if err != nil {
+		log.Printf("安装更新失败: %v", err)
+		return false
+	}
+	if err := swapExecutable(dir, state, executable); err != nil {
+		log.Printf("安装更新失败: %v", err)
+		failUpdate(dir, state, err)
+		return false
+	}
+
+	if err := superviseUpdate(dir, state, executable); err != nil {
+		log.Printf("新版本启动失败，已恢复旧版本: %v", err)
+		rollbackUpdate(dir, state, err)
+		return false
+	}
+	return true
+}
+
+// 辅助函数：下载、校验并暂存新版本
+func installUpdate(ctx context.Context, client *http.Client, settings UpdateSettings, current, publicKey, dir string, now time.Time, progress func(UpdateProgress)) (*UpdateInstallState, error) {
+	key, err := parseUpdatePublicKey(publicKey)
+	if err != nil {
+		return nil, err
+	}
+	if state, err := loadUpdateState(dir); err == nil && state != nil && state.Status == UpdateInstallTrial {
+		return nil, fmt.Errorf("正在安装更新，请稍后再试")
+	}
+
+	currentParsed, err := version.NewVersion(current)
+	if err != nil {
+		return nil, fmt.Errorf("解析当前版本失败: %w", err)
+	}
+	var constraint version.Constraints
+	if settings.Constraint != "" {
+		if constraint, err = version.NewConstraint(settings.Constraint); err != nil {
+			return nil, fmt.Errorf("无效的版本约束: %w", err)
+		}
+	}
+	releases, err := fetchReleases(ctx, client, settings.SourceURL)
+	if err != nil {
+		return nil, err
+	}
+	// 重启后离线也能显示新版本的发布说明
+	cacheReleaseNotes(releases)
+	release, latest := selectRelease(releases, settings.Channel, constraint)
+	if release == nil || !latest.GreaterThan(currentParsed) {
+		return nil, fmt.Errorf("已是最新版本")
+	}
+
+	binary, checksums, signature := findUpdateAssets(release.Assets, goruntime.GOOS, goruntime.GOARCH)
+	if binary == nil {
+		return nil, fmt.Errorf("版本 %s 没有适用于 %s/%s 的安装文件，请手动下载", release.TagName, goruntime.GOOS, goruntime.GOARCH)
+	}
+	if checksums == nil || signature == nil {
+		return nil, fmt.Errorf("版本 %s 缺少校验文件，无法安全更新", release.TagName)
+	}
+
+	// 先校验签名，再信任其中的校验和
+	sums, err := downloadUpdateAsset(ctx, client, checksums, nil)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := downloadUpdateAsset(ctx, client, signature, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := verifyUpdateSignature(key, sums, sig); err != nil {
+		return nil, err
+	}
+	// 版本号在签名内容中，防止把旧版本已签名的文件当作新版本提供
+	signedVersion, files := parseUpdateChecksums(sums)
+	if err := checkSignedVersion(signedVersion, release.TagName); err != nil {
+		return nil, err
+	}
+	expected, ok := files[binary.Name]
+	if !ok {
+		return nil, fmt.Errorf("校验文件中没有 %s", binary.Name)
+	}
+
+	// 与备份进度相同，每 1%（大小未知时每 1 MB）报告一次
+	lastPercent := int64(-1)
+	content, err := downloadUpdateAsset(ctx, client, binary, func(done, total int64) {
+		percent := done >> 20
+		if total > 0 {
+			percent = done * 100 / total
+		}
+		if progress == nil || percent == lastPercent {
+			return
+		}
+		lastPercent = percent
+		progress(UpdateProgress{Stage: "download", Done: done, Total: total})
+	})
+	if err != nil {
+		return nil, err
+	}
+	if progress != nil {
+		progress(UpdateProgress{Stage: "verify", Done: int64(len(content)), Total: int64(len(content))})
+	}
+	sum := sha256.Sum256(content)
+	if hex.EncodeToString(sum[:]) != expected {
+		return nil, fmt.Errorf("安装文件校验失败，可能已损坏或被篡改")
+	}
+
+	// 暂存到新目录，覆盖之前暂存的版本
+	if state, err := loadUpdateState(dir); err == nil && state != nil && state.StagedPath != "" {
+		os.RemoveAll(filepath.Dir(state.StagedPath))
+	}
+	stagedDir := filepath.Join(dir, strings.TrimPrefix(release.TagName, "v"))
+	if err := os.MkdirAll(stagedDir, 0755); err != nil {
+		return nil, fmt.Errorf("创建更新目录失败: %w", err)
+	}
+	stagedPath := filepath.Join(stagedDir, filepath.Base(binary.Name))
+	if err := os.WriteFile(stagedPath, content, 0755); err != nil {
+		return nil, fmt.Errorf("保存安装文件失败: %w", err)
+	}
+
+	state := &UpdateInstallState{
+		Status:     UpdateInstallStaged,
+		Version:    release.TagName,
+		StagedPath: stagedPath,
+		UpdatedAt:  now.Format(time.RFC3339),
+	}
+	if err := saveUpdateState(dir, state); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// 辅助函数：用新程序替换当前程序，旧程序改名保留用于回滚
+//
+// 运行中的程序可以改名（Windows 也允许），但不能覆盖，所以先改名再放入新程序。
+func swapExecutable(dir string, state *UpdateInstallState, executable string) error {
+	if _, err := os.Stat(state.StagedPath); err != nil {
+		return fmt.Errorf("找不到已下载的新版本: %w", err)
+	}
+	backup := executable + ".old"
+	os.Remove(backup)
+	if err := os.Rename(executable, backup); err != nil {
+		return fmt.Errorf("无法替换程序文件，请手动安装: %w", err)
+	}
+	if err := copyDataFile(state.StagedPath, executable); err != nil {
+		os.Remove(executable)
+		os.Rename(backup, executable)
+		return fmt.Errorf("无法替换程序文件，请手动安装: %w", err)
+	}
+	if err := os.Chmod(executable, 0755); err != nil {
+		os.Remove(executable)
+		os.Rename(backup, executable)
+		return err
+	}
+
+	state.Status = UpdateInstallTrial
+	state.Executable = executable
+	state.BackupPath = backup
+	state.UpdatedAt = time.Now().Format(time.RFC3339)
+	return saveUpdateState(dir, state)
+}
+
+// 辅助函数：启动新版本并等待其确认启动成功，新版本提前退出或超时都视为失败
+func superviseUpdate(dir string, state *UpdateInstallState, executable string) error {
+	cmd := exec.Command(executable, os.Args[1:]...)
+	cmd.Env = append(os.Environ(), updateTrialEnv+"="+state.Version)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("无法启动新版本: %w", err)
+	}
+
+	exited := make(chan error, 1)
+	go func() { exited <- cmd.Wait() }()
+
+	ticker := time.NewTicker(200 * time.Millisecond)
+	defer ticker.Stop()
+	deadline := time.After(updateTrialTimeout)
+	for {
+		select {
+		case err := <-exited:
+			// 确认后很快退出（例如用户立即关闭）也算成功
+			if current, _ := loadUpdateState(dir); current != nil && current.Status == UpdateInstallConfirmed {
+				return nil
+			}
+			if err == nil {
+				err = fmt.Errorf("新版本启动后立即退出")
+			}
+			return fmt.Errorf("新版本启动失败: %w", err)
+		case <-deadline:
+			cmd.Process.Kill()
+			<-exited
+			return fmt.Errorf("新版本启动超时")
+		case <-ticker.C:
+			if current, _ := loadUpdateState(dir); current != nil && current.Status == UpdateInstallConfirmed {
+				return nil
+			}
+		}
+	}
+}
+
+// 辅助函数：恢复旧程序并记录失败原因
+func rollbackUpdate(dir string, state *UpdateInstallState, cause error) {
+	if state.BackupPath != "" && state.Executable != "" {
+		if _, err := os.Stat(state.BackupPath); err == nil {
+			os.Remove(state.Executable)
+			if err := os.Rename(state.BackupPath, state.Executable); err != nil {
+				log.Printf("恢复旧版本失败: %v", err)
+			}
+		}
+	}
+	failUpdate(dir, state, cause)
+}
+
+// 辅助函数：记录更新失败，删除已下载的新版本
+func failUpdate(dir string, state *UpdateInstallState, cause error) {
+	if state.StagedPath != "" {
+		os.RemoveAll(filepath.Dir(state.StagedPath))
+	}
+	state.Status = UpdateInstallFailed
+	state.Error = cause.Error()
+	state.UpdatedAt = time.Now().Format(time.RFC3339)
+	if err := saveUpdateState(dir, state); err != nil {
+		log.Printf("保存更新状态失败: %v", err)
+	}
+}
+
+// 辅助函数：更新成功后删除旧程序与安装文件，旧程序仍在运行无法删除时返回 false
+func finishUpdate(dir string) bool {
+	state, err := loadUpdateState(dir)
+	if err != nil || state == nil || state.Status != UpdateInstallConfirmed {
+		return true
+	}
+	if state.BackupPath != "" {
+		if err := os.Remove(state.BackupPath); err != nil && !os.IsNotExist(err) {
+			return false
+		}
+	}
+	if state.StagedPath != "" {
+		os.RemoveAll(filepath.Dir(state.StagedPath))
+	}
+	os.Remove(updateStatePath(dir))
+	return true
+}
+
+// 辅助函数：按文件名查找当前平台的程序文件与校验文件
+//
+// 程序文件命名为 Manifest-<系统>-<架构>，Windows 为 .exe，例如 Manifest-windows-amd64.exe。
+func findUpdateAssets(assets []GitHubAsset, goos, goarch string) (binary, checksums, signature *GitHubAsset) {
+	prefix := strings.ToLower(fmt.Sprintf("manifest-%s-%s", goos, goarch))
+	for i := range assets {
+		asset := &assets[i]
+		name := strings.ToLower(asset.Name)
+		switch {
+		case asset.Name == updateChecksumAsset:
+			checksums = asset
+		case asset.Name == updateSignatureAsset:
+			signature = asset
+		case name == prefix || (goos == "windows" && name == prefix+".exe"):
+			binary = asset
+		}
+	}
+	return binary, checksums, signature
+}
+
+// 辅助函数：下载发布文件
+func downloadUpdateAsset(ctx context.Context, client *http.Client, asset *GitHubAsset, progress func(done, total int64)) ([]byte, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, asset.BrowserDownloadURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("无效的下载地址: %w", err)
+	}
+	request.Header.Set("Accept", "application/octet-stream")
+	request.Header.Set("User-Agent", "Manifest/"+currentVersion)
+
+	response, err := client.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("下载 %s 失败: %w", asset.Name, err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("下载 %s 失败: %s", asset.Name, response.Status)
+	}
+
+	total := response.ContentLength
+	if total <= 0 {
+		total = asset.Size
+	}
+	var reader io.Reader = io.LimitReader(response.Body, maxUpdateAssetSize+1)
+	if progress != nil {
+		reader = &progressReader{reader: reader, report: func(done int64) { progress(done, total) }}
+	}
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("下载 %s 失败: %w", asset.Name, err)
+	}
+	if len(content) > maxUpdateAssetSize {
+		return nil, fmt.Errorf("%s 超过大小限制", asset.Name)
+	}
+	return content, nil
+}
+
+// 辅助函数：解析内置的签名公钥
+func parseUpdatePublicKey(encoded string) (ed25519.PublicKey, error) {
+	if encoded == "" {
+		return nil, fmt.Errorf("当前版本不支持应用内更新，请手动下载")
+	}
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("更新签名公钥无效")
+	}
+	return ed25519.PublicKey(key), nil
+}
+
+// 辅助函数：校验校验和文件的 Ed25519 签名，签名文件可以是原始字节或 base64
+func verifyUpdateSignature(key ed25519.PublicKey, content, signature []byte) error {
+	if len(signature) != ed25519.SignatureSize {
+		decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(signature)))
+		if err != nil {
+			return fmt.Errorf("签名格式无效")
+		}
+		signature = decoded
+	}
+	if len(signature) != ed25519.SignatureSize || !ed25519.Verify(key, content, signature) {
+		return fmt.Errorf("签名校验失败，更新文件可能被篡改")
+	}
+	return nil
+}
+
+// 辅助函数：解析 sha256sum 格式的校验和文件，返回 version 行中的版本号与文件名到哈希的映射
+func parseUpdateChecksums(content []byte) (string, map[string]string) {
+	signedVersion := ""
+	sums := map[string]string{}
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		if fields[0] == updateVersionLine {
+			signedVersion = fields[1]
+			continue
+		}
+		sums[strings.TrimPrefix(fields[1], "*")] = strings.ToLower(fields[0])
+	}
+	return signedVersion, sums
+}
+
+// 辅助函数：校验签名内容中的版本号与发布版本一致
+func checkSignedVersion(signedVersion, tag string) error {
+	if signedVersion == "" {
+		return fmt.Errorf("版本 %s 的校验文件中没有版本号，无法安全更新", tag)
+	}
+	signed, err := version.NewVersion(signedVersion)
+	if err != nil {
+		return fmt.Errorf("校验文件中的版本号无效: %s", signedVersion)
+	}
+	released, err := version.NewVersion(tag)
+	if err != nil || !signed.Equal(released) {
+		return fmt.Errorf("校验文件的版本 %s 与发布版本 %s 不一致，更新文件可能被篡改", signedVersion, tag)
+	}
+	return nil
+}
+
+// 辅助函数：以相同参数重新启动程序
+func restartApp() error {
+	executable, err := currentExecutable()
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command(executable, os.Args[1:]...)
+	return cmd.Start()
+}
+
+// 辅助函数：当前程序文件的真实路径
+func currentExecutable() (string, error) {
+	executable, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	return filepath.EvalSymlinks(executable)
+}
+
+// 辅助函数：更新文件目录
+func updatesDir() (string, error) {
+	appDataDir, err := GetAppDataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(appDataDir, "updates"), nil
+}
+
+// 辅助函数：更新状态文件路径
+func updateStatePath(dir string) string {
+	return filepath.Join(dir, "state.json")
+}
+
+// 辅助函数：读取更新状态，不存在时返回 nil
+func loadUpdateState(dir string) (*UpdateInstallState, error) {
+	content, err := os.ReadFile(updateStatePath(dir))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var state UpdateInstallState
+	if err := json.Unmarshal(content, &state); err != nil {
+		return nil, fmt.Errorf("解析更新状态失败: %w", err)
+	}
+	return &state, nil
+}
+
+// 辅助函数：保存更新状态，先写临时文件再改名，避免另一个进程读到一半
+func saveUpdateState(dir string, state *UpdateInstallState) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("创建更新目录失败: %w", err)
+	}
+	content, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := updateStatePath(dir)
+	if err := os.WriteFile(path+".tmp", content, 0644); err != nil {
+		return fmt.Errorf("保存更新状态失败: %w", err)
+	}
+	return os.Rename(path+".tmp", path)
+}
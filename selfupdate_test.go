@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	goruntime "runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestVerifyUpdateSignature(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := []byte("version v1.2.0\nabc  Manifest-linux-amd64\n")
+	signature := ed25519.Sign(private, content)
+
+	if err := verifyUpdateSignature(public, content, signature); err != nil {
+		t.Fatalf("原始签名校验失败: %v", err)
+	}
+	encoded := []byte(base64.StdEncoding.EncodeToString(signature) + "\n")
+	if err := verifyUpdateSignature(public, content, encoded); err != nil {
+		t.Fatalf("base64 签名校验失败: %v", err)
+	}
+
+	tampered := append([]byte{}, content...)
+	tampered[len(tampered)-2] = 'X'
+	if err := verifyUpdateSignature(public, tampered, signature); err == nil {
+		t.Fatal("篡改内容后签名校验应失败")
+	}
+	other, _, _ := ed25519.GenerateKey(rand.Reader)
+	if err := verifyUpdateSignature(other, content, signature); err == nil {
+		t.Fatal("使用其他公钥时签名校验应失败")
+	}
+	if err := verifyUpdateSignature(public, content, []byte("not a signature")); err == nil {
+		t.Fatal("无效的签名格式应返回错误")
+	}
+}
+
+func TestParseUpdateChecksums(t *testing.T) {
+	signedVersion, sums := parseUpdateChecksums([]byte("version v1.2.0\nABCDEF *Manifest-windows-amd64.exe\n123456  Manifest-linux-amd64\ninvalid line with fields\n"))
+	if signedVersion != "v1.2.0" {
+		t.Fatalf("版本号 = %q", signedVersion)
+	}
+	if sums["Manifest-windows-amd64.exe"] != "abcdef" || sums["Manifest-linux-amd64"] != "123456" || len(sums) != 2 {
+		t.Fatalf("校验和 = %v", sums)
+	}
+
+	if err := checkSignedVersion("1.2.0", "v1.2.0"); err != nil {
+		t.Fatalf("相同版本应通过: %v", err)
+	}
+	if err := checkSignedVersion("v1.1.0", "v1.2.0"); err == nil {
+		t.Fatal("版本不一致时应返回错误")
+	}
+	if err := checkSignedVersion("", "v1.2.0"); err == nil {
+		t.Fatal("缺少版本号时应返回错误")
+	}
+}
+
+// 旧版本已签名的校验文件被放到新版本中时应拒绝安装
+func TestInstallUpdateRejectsReplayedChecksums(t *testing.T) {
+	setupTestDataDir(t)
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	binaryName := fmt.Sprintf("Manifest-%s-%s", goruntime.GOOS, goruntime.GOARCH)
+	if goruntime.GOOS == "windows" {
+		binaryName += ".exe"
+	}
+	binary := []byte("new binary")
+	sum := sha256.Sum256(binary)
+
+	var server *httptest.Server
+	serve := func(signedVersion string) {
+		sums := []byte(fmt.Sprintf("version %s\n%s  %s\n", signedVersion, hex.EncodeToString(sum[:]), binaryName))
+		files := map[string][]byte{
+			binaryName:           binary,
+			updateChecksumAsset:  sums,
+			updateSignatureAsset: ed25519.Sign(private, sums),
+		}
+		server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/releases" {
+				release := GitHubRelease{TagName: "v1.2.0"}
+				for name := range files {
+					release.Assets = append(release.Assets, GitHubAsset{Name: name, BrowserDownloadURL: server.URL + "/" + name})
+				}
+				json.NewEncoder(w).Encode([]GitHubRelease{release})
+				return
+			}
+			content, ok := files[strings.TrimPrefix(r.URL.Path, "/")]
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			w.Write(content)
+		})
+	}
+	server = httptest.NewServer(nil)
+	defer server.Close()
+
+	settings := UpdateSettings{SourceURL: server.URL + "/releases", Channel: UpdateChannelStable}
+	key := base64.StdEncoding.EncodeToString(public)
+
+	serve("v1.1.0")
+	if _, err := installUpdate(context.Background(), server.Client(), settings, "1.0.0", key, t.TempDir(), time.Now(), nil); err == nil || !strings.Contains(err.Error(), "不一致") {
+		t.Fatalf("版本不一致时应拒绝安装，得到 %v", err)
+	}
+
+	serve("v1.2.0")
+	state, err := installUpdate(context.Background(), server.Client(), settings, "1.0.0", key, t.TempDir(), time.Now(), nil)
+	if err != nil {
+		t.Fatalf("安装更新失败: %v", err)
+	}
+	if state.Status != UpdateInstallStaged || state.Version != "v1.2.0" {
+		t.Fatalf("更新状态 = %+v", state)
+	}
+}
@@ -0,0 +1,428 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+	"golang.org/x/crypto/argon2"
+)
+
+// 账号锁定事件名称
+const accountLockedEvent = "accountLocked"
+
+// 账号锁类型
+const (
+	accountLockNone     = ""
+	accountLockPassword = "password"
+	accountLockPIN      = "pin"
+)
+
+// 密码与PIN长度限制
+const (
+	minAccountPassword = 6
+	minAccountPIN      = 4
+	maxAccountPIN      = 12
+)
+
+// 连续输错后暂停验证，防止穷举PIN
+const (
+	maxUnlockAttempts = 5
+	unlockBackoff     = 30 * time.Second
+)
+
+// 账号密码哈希参数（Argon2id）
+const (
+	accountHashTime    = 1
+	accountHashMemory  = 64 * 1024
+	accountHashThreads = 4
+	accountHashKeyLen  = 32
+)
+
+var (
+	errAccountLocked     = errors.New("账号已锁定，请先验证密码")
+	errWrongCredential   = errors.New("密码或PIN错误")
+	errTooManyAttempts   = errors.New("验证失败次数过多，请稍后再试")
+	errCredentialMissing = errors.New("请输入密码或PIN")
+)
+
+// AutoLockSettings 空闲自动锁定设置，只对设置了密码或PIN的账号生效
+type AutoLockSettings struct {
+	Enabled     bool `json:"enabled"`
+	IdleMinutes int  `json:"idleMinutes"` // 无操作多少分钟后锁定
+}
+
+// SessionStatus 当前会话状态
+type SessionStatus struct {
+	AccountID string `json:"accountId"`
+	LockType  string `json:"lockType"` // 空表示未设置密码
+	Locked    bool   `json:"locked"`
+}
+
+// 当前会话，只保存在内存中
+var session struct {
+	mu         sync.Mutex
+	accountID  string
+	locked     bool
+	lastActive time.Time
+	failures   int
+	retryAt    time.Time
+}
+
+// DefaultAutoLockSettings 默认空闲自动锁定设置
+func DefaultAutoLockSettings() AutoLockSettings {
+	return AutoLockSettings{
+		Enabled:     true,
+		IdleMinutes: 10,
+	}
+}
+
+// GetAutoLockSettings 获取空闲自动锁定设置，未配置时返回默认值
+func GetAutoLockSettings() (AutoLockSettings, error) {
+	config, err := LoadConfig()
+	if err != nil {
+		return AutoLockSettings{}, err
+	}
+
+	if config.AutoLock == nil {
+		return DefaultAutoLockSettings(), nil
+	}
+	return *config.AutoLock, nil
+}
+
+// SaveAutoLockSettings 保存空闲自动锁定设置
+func SaveAutoLockSettings(settings AutoLockSettings) error {
+	if settings.IdleMinutes <= 0 {
+		return fmt.Errorf("空闲时间必须大于0")
+	}
+
+	return UpdateConfig(func(config *Config) error {
+		config.AutoLock = &settings
+		return nil
+	})
+}
+
+// InitSession 初始化会话，上次使用的账号设置了密码时以锁定状态启动
+func InitSession() error {
+	account, err := loadLastUsedAccount()
+	if err != nil {
+		return err
+	}
+
+	session.mu.Lock()
+	defer session.mu.Unlock()
+
+	session.accountID = ""
+	session.locked = false
+	session.lastActive = time.Now()
+	if account != nil {
+		session.accountID = account.ID
+		session.locked = account.LockType != accountLockNone
+	}
+	return nil
+}
+
+// GetSessionStatus 获取当前会话状态
+func GetSessionStatus() (SessionStatus, error) {
+	session.mu.Lock()
+	accountID, locked := session.accountID, session.locked
+	session.mu.Unlock()
+
+	status := SessionStatus{AccountID: accountID, Locked: locked}
+	if accountID == "" {
+		return status, nil
+	}
+	account, err := findAccount(accountID)
+	if err != nil {
+		return SessionStatus{}, err
+	}
+	status.LockType = account.LockType
+	return status, nil
+}
+
+// ensureUnlocked 会话已锁定时返回 errAccountLocked，当前账号的加密数据库尚未解锁时返回 errDatabaseLocked，
+// 数据相关接口在返回数据前调用
+func ensureUnlocked() error {
+	if err := ensureSessionUnlocked(); err != nil {
+		return err
+	}
+	if db == nil {
+		return errDatabaseLocked
+	}
+	return nil
+}
+
+// ensureSessionUnlocked 会话已锁定时返回 errAccountLocked，只读写账号库或配置的接口调用，数据库未解锁时也可使用
+func ensureSessionUnlocked() error {
+	session.mu.Lock()
+	defer session.mu.Unlock()
+
+	if session.locked {
+		return errAccountLocked
+	}
+	return nil
+}
+
+// TouchSession 记录用户操作，用于空闲自动锁定计时
+func TouchSession() {
+	session.mu.Lock()
+	defer session.mu.Unlock()
+
+	if !session.locked {
+		session.lastActive = time.Now()
+	}
+}
+
+// LockSession 立即锁定当前账号，未设置密码的账号无法锁定
+func LockSession() error {
+	session.mu.Lock()
+	accountID := session.accountID
+	session.mu.Unlock()
+
+	if accountID == "" {
+		return fmt.Errorf("当前没有登录的账号")
+	}
+	account, err := findAccount(accountID)
+	if err != nil {
+		return err
+	}
+	if account.LockType == accountLockNone {
+		return fmt.Errorf("请先为账号设置密码或PIN")
+	}
+
+	session.mu.Lock()
+	defer session.mu.Unlock()
+
+	if session.accountID == accountID {
+		session.locked = true
+	}
+	return nil
+}
+
+// UnlockSession 验证当前账号的密码或PIN并解锁
+func UnlockSession(secret string) error {
+	session.mu.Lock()
+	accountID := session.accountID
+	session.mu.Unlock()
+
+	if accountID == "" {
+		return fmt.Errorf("当前没有登录的账号")
+	}
+	account, err := findAccount(accountID)
+	if err != nil {
+		return err
+	}
+	if err := verifyAccountSecret(account, secret); err != nil {
+		return err
+	}
+
+	session.mu.Lock()
+	defer session.mu.Unlock()
+
+	if session.accountID == accountID {
+		session.locked = false
+		session.lastActive = time.Now()
+	}
+	return nil
+}
+
+// SetAccountLock 设置、修改或移除账号的密码/PIN，已设置时需提供原密码
+//
+// lockType 为 password 或 pin，为空时移除密码。
+func SetAccountLock(accountID, lockType, currentSecret, newSecret string) error {
+	account, err := findAccount(accountID)
+	if err != nil {
+		return err
+	}
+	if err := verifyAccountSecret(account, currentSecret); err != nil {
+		return err
+	}
+
+	hash := ""
+	switch lockType {
+	case accountLockNone:
+	case accountLockPassword, accountLockPIN:
+		if err := validateAccountSecret(lockType, newSecret); err != nil {
+			return err
+		}
+		if hash, err = hashAccountSecret(newSecret); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("无效的锁类型: %s", lockType)
+	}
+
+	if err := updateAccountLock(accountID, lockType, hash); err != nil {
+		return err
+	}
+
+	// 移除当前账号的密码后会话不再锁定
+	session.mu.Lock()
+	defer session.mu.Unlock()
+
+	if session.accountID == accountID && lockType == accountLockNone {
+		session.locked = false
+	}
+	return nil
+}
+
+// 辅助函数：切换账号后更新会话，切换前已完成验证
+//
+// verified 表示已验证 fromAccountID 的密码；验证期间会话被其他操作切换，
+// 或当前账号被锁定而未验证时拒绝切换。
+func switchSession(fromAccountID, accountID string, verified bool) error {
+	session.mu.Lock()
+	defer session.mu.Unlock()
+
+	if session.accountID != fromAccountID || (session.locked && !verified && fromAccountID != accountID) {
+		return errAccountLocked
+	}
+	session.accountID = accountID
+	session.locked = false
+	session.lastActive = time.Now()
+	return nil
+}
+
+// 辅助函数：空闲超时后锁定账号，供 Scheduler 调用
+func checkIdleLock(ctx context.Context) time.Duration {
+	const maxInterval = time.Minute
+
+	settings, err := GetAutoLockSettings()
+	if err != nil || !settings.Enabled || settings.IdleMinutes <= 0 {
+		return maxInterval
+	}
+	idle := time.Duration(settings.IdleMinutes) * time.Minute
+
+	session.mu.Lock()
+	accountID, locked, lastActive := session.accountID, session.locked, session.lastActive
+	session.mu.Unlock()
+
+	if accountID == "" || locked {
+		return maxInterval
+	}
+	remaining := idle - time.Since(lastActive)
+	if remaining > 0 {
+		return min(remaining, maxInterval)
+	}
+
+	// 未设置密码的账号无法重新验证，不锁定
+	account, err := findAccount(accountID)
+	if err != nil || account.LockType == accountLockNone {
+		return maxInterval
+	}
+
+	// 检查期间有新的操作时不锁定
+	session.mu.Lock()
+	locked = session.accountID == accountID && session.lastActive.Equal(lastActive)
+	if locked {
+		session.locked = true
+	}
+	session.mu.Unlock()
+
+	if locked {
+		runtime.EventsEmit(ctx, accountLockedEvent, accountID)
+	}
+	return maxInterval
+}
+
+// 辅助函数：验证账号的密码或PIN，账号未设置密码时直接通过
+func verifyAccountSecret(account Account, secret string) error {
+	if account.LockType == accountLockNone {
+		return nil
+	}
+	if secret == "" {
+		return errCredentialMissing
+	}
+
+	// 校验前先计入一次尝试，并发的校验不能在计数前全部通过限制
+	session.mu.Lock()
+	if time.Now().Before(session.retryAt) {
+		session.mu.Unlock()
+		return errTooManyAttempts
+	}
+	session.failures++
+	if session.failures >= maxUnlockAttempts {
+		session.failures = 0
+		session.retryAt = time.Now().Add(unlockBackoff)
+	}
+	session.mu.Unlock()
+
+	if !checkAccountSecret(account.LockHash, secret) {
+		return errWrongCredential
+	}
+
+	// 校验通过后清除失败记录
+	session.mu.Lock()
+	defer session.mu.Unlock()
+
+	session.failures = 0
+	session.retryAt = time.Time{}
+	return nil
+}
+
+// 辅助函数：校验新密码或PIN的格式
+func validateAccountSecret(lockType, secret string) error {
+	if lockType == accountLockPIN {
+		if len(secret) < minAccountPIN || len(secret) > maxAccountPIN {
+			return fmt.Errorf("PIN长度必须为%d-%d位", minAccountPIN, maxAccountPIN)
+		}
+		for _, r := range secret {
+			if r < '0' || r > '9' {
+				return fmt.Errorf("PIN只能包含数字")
+			}
+		}
+		return nil
+	}
+
+	if len([]rune(secret)) < minAccountPassword {
+		return fmt.Errorf("密码至少需要%d个字符", minAccountPassword)
+	}
+	return nil
+}
+
+// 辅助函数：计算密码哈希，格式为 argon2id$迭代次数$内存KiB$并行度$盐$哈希
+func hashAccountSecret(secret string) (string, error) {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("生成随机盐失败: %w", err)
+	}
+
+	key := argon2.IDKey([]byte(secret), salt, accountHashTime, accountHashMemory, accountHashThreads, accountHashKeyLen)
+	return fmt.Sprintf("argon2id$%d$%d$%d$%s$%s",
+		accountHashTime, accountHashMemory, accountHashThreads,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+// 辅助函数：校验密码与哈希是否匹配
+func checkAccountSecret(hash, secret string) bool {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[0] != "argon2id" {
+		return false
+	}
+
+	var iterations, memory uint32
+	var threads uint8
+	if _, err := fmt.Sscanf(parts[1]+" "+parts[2]+" "+parts[3], "%d %d %d", &iterations, &memory, &threads); err != nil {
+		return false
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return false
+	}
+	expected, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return false
+	}
+
+	key := argon2.IDKey([]byte(secret), salt, iterations, memory, threads, uint32(len(expected)))
+	return subtle.ConstantTimeCompare(key, expected) == 1
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"errors"
+	"sync"
+	"testing"
+)
+
+// 已锁定时不能通过切换到其他账号绕过锁定
+func TestSwitchAccountWhileLocked(t *testing.T) {
+	setupTestDB(t)
+	t.Cleanup(func() { InitSession() })
+
+	alice, err := NewAccount("alice", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := SetAccountLock(alice.ID, accountLockPIN, "", "1234"); err != nil {
+		t.Fatal(err)
+	}
+	bob, err := NewAccount("bob", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := SwitchAccount(alice.ID, "1234", ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := LockSession(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := SwitchAccount(bob.ID, "", ""); !errors.Is(err, errAccountLocked) {
+		t.Fatalf("未提供当前账号的PIN时应拒绝切换，得到 %v", err)
+	}
+	if err := SwitchAccount(bob.ID, "", "0000"); !errors.Is(err, errWrongCredential) {
+		t.Fatalf("当前账号的PIN错误时应拒绝切换，得到 %v", err)
+	}
+	if status, _ := GetSessionStatus(); status.AccountID != alice.ID || !status.Locked {
+		t.Fatalf("拒绝切换后会话 = %+v", status)
+	}
+
+	if err := SwitchAccount(bob.ID, "", "1234"); err != nil {
+		t.Fatal(err)
+	}
+	if status, _ := GetSessionStatus(); status.AccountID != bob.ID || status.Locked {
+		t.Fatalf("切换后会话 = %+v", status)
+	}
+
+	// 切换回已锁定的账号只需验证该账号
+	if err := LockSession(); err == nil {
+		t.Fatal("未设置密码的账号不应被锁定")
+	}
+	if err := SwitchAccount(alice.ID, "1234", ""); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// 并发的错误尝试也受次数限制，超过后需等待
+func TestVerifyAccountSecretConcurrentAttempts(t *testing.T) {
+	setupTestDB(t)
+	t.Cleanup(func() { InitSession() })
+
+	account, err := NewAccount("alice", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := SetAccountLock(account.ID, accountLockPIN, "", "1234"); err != nil {
+		t.Fatal(err)
+	}
+	account, err = findAccount(account.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results := make(chan error, maxUnlockAttempts+3)
+	var wg sync.WaitGroup
+	for range cap(results) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results <- verifyAccountSecret(account, "0000")
+		}()
+	}
+	wg.Wait()
+	close(results)
+
+	wrong := 0
+	for err := range results {
+		if errors.Is(err, errWrongCredential) {
+			wrong++
+		} else if !errors.Is(err, errTooManyAttempts) {
+			t.Fatalf("意外的错误: %v", err)
+		}
+	}
+	if wrong > maxUnlockAttempts {
+		t.Fatalf("校验了 %d 次错误的PIN，超过限制 %d", wrong, maxUnlockAttempts)
+	}
+	if err := verifyAccountSecret(account, "1234"); !errors.Is(err, errTooManyAttempts) {
+		t.Fatalf("达到限制后应等待，得到 %v", err)
+	}
+}
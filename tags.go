@@ -0,0 +1,365 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// 新建标签时使用的默认颜色
+const defaultTagColor = "#8c8c8c"
+
+// Tag 任务标签，可跨维度使用
+type Tag struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Color string `json:"color"`
+}
+
+// TagStats 单个标签的统计数据
+type TagStats struct {
+	Tag            Tag     `json:"tag"`
+	TotalTasks     int     `json:"totalTasks"`
+	CompletedTasks int     `json:"completedTasks"`
+	CompletionRate float64 `json:"completionRate"` // 0-100
+	TotalScore     float64 `json:"totalScore"`
+	AverageScore   float64 `json:"averageScore"`
+}
+
+// 辅助函数：创建标签相关的表
+func createTagTables() error {
+	// 创建标签表
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS tags (
+			id TEXT PRIMARY KEY,
+			name TEXT NOT NULL COLLATE NOCASE UNIQUE,
+			color TEXT
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("创建标签表失败: %w", err)
+	}
+
+	// 创建任务标签关联表
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS task_tags (
+			task_id TEXT,
+			tag_id TEXT,
+			PRIMARY KEY (task_id, tag_id)
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("创建任务标签关联表失败: %w", err)
+	}
+
+	_, err = db.Exec(`CREATE INDEX IF NOT EXISTS idx_task_tags_tag_id ON task_tags(tag_id)`)
+	if err != nil {
+		return fmt.Errorf("创建任务标签索引失败: %w", err)
+	}
+
+	return nil
+}
+
+// GetTags 获取所有标签
+func GetTags() ([]Tag, error) {
+	rows, err := db.Query(`SELECT id, name, color FROM tags ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags := []Tag{}
+	for rows.Next() {
+		var tag Tag
+		if err := rows.Scan(&tag.ID, &tag.Name, &tag.Color); err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+
+	return tags, rows.Err()
+}
+
+// SaveTag 创建或更新标签，ID为空时创建
+func SaveTag(tag Tag) (Tag, error) {
+	tag.Name = strings.TrimSpace(tag.Name)
+	if tag.Name == "" {
+		return Tag{}, fmt.Errorf("标签名称不能为空")
+	}
+	if tag.Color == "" {
+		tag.Color = defaultTagColor
+	}
+
+	// 名称不能与其他标签重复
+	existing, err := getTagByName(db, tag.Name)
+	if err != nil {
+		return Tag{}, err
+	}
+	if existing != nil && existing.ID != tag.ID {
+		return Tag{}, fmt.Errorf("标签已存在: %s", existing.Name)
+	}
+
+	if tag.ID == "" {
+		tag.ID = uuid.New().String()
+	}
+
+	_, err = db.Exec(
+		`INSERT INTO tags (id, name, color) VALUES (?, ?, ?) ON CONFLICT(id) DO UPDATE SET name = excluded.name, color = excluded.color`,
+		tag.ID, tag.Name, tag.Color,
+	)
+	if err != nil {
+		return Tag{}, err
+	}
+
+	return tag, nil
+}
+
+// RenameTag 重命名标签
+func RenameTag(tagID, newName string) error {
+	newName = strings.TrimSpace(newName)
+	if newName == "" {
+		return fmt.Errorf("标签名称不能为空")
+	}
+
+	existing, err := getTagByName(db, newName)
+	if err != nil {
+		return err
+	}
+	if existing != nil && existing.ID != tagID {
+		return fmt.Errorf("标签已存在: %s", existing.Name)
+	}
+
+	result, err := db.Exec(`UPDATE tags SET name = ? WHERE id = ?`, newName, tagID)
+	if err != nil {
+		return err
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return fmt.Errorf("标签不存在")
+	}
+
+	return nil
+}
+
+// MergeTags 将多个标签合并到目标标签，源标签被删除
+func MergeTags(sourceIDs []string, targetID string) (err error) {
+	// 开始事务
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	var exists int
+	err = tx.QueryRow(`SELECT COUNT(*) FROM tags WHERE id = ?`, targetID).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if exists == 0 {
+		err = fmt.Errorf("目标标签不存在")
+		return err
+	}
+
+	for _, sourceID := range sourceIDs {
+		if sourceID == targetID {
+			continue
+		}
+
+		// 转移任务关联，已存在的关联忽略
+		_, err = tx.Exec(`INSERT OR IGNORE INTO task_tags (task_id, tag_id) SELECT task_id, ? FROM task_tags WHERE tag_id = ?`, targetID, sourceID)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(`DELETE FROM task_tags WHERE tag_id = ?`, sourceID)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(`DELETE FROM tags WHERE id = ?`, sourceID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// DeleteTag 删除标签及其所有任务关联
+func DeleteTag(tagID string) (err error) {
+	// 开始事务
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	_, err = tx.Exec(`DELETE FROM task_tags WHERE tag_id = ?`, tagID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`DELETE FROM tags WHERE id = ?`, tagID)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// SetTaskTags 设置任务的标签（按名称），不存在的标签会被自动创建
+func SetTaskTags(taskID string, tagNames []string) (err error) {
+	// 开始事务
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	err = saveTaskTags(tx, taskID, tagNames)
+	return err
+}
+
+// GetTagStats 获取标签统计，year为空时统计所有年度
+func GetTagStats(year string) ([]TagStats, error) {
+	query := `
+		SELECT g.id, g.name, g.color,
+			COUNT(t.id),
+			COALESCE(SUM(CASE WHEN t.status = 'completed' THEN 1 ELSE 0 END), 0),
+			COALESCE(SUM(t.score), 0)
+		FROM tags g
+		LEFT JOIN task_tags tt ON tt.tag_id = g.id
+		LEFT JOIN tasks t ON t.id = tt.task_id`
+	args := []interface{}{}
+	if year != "" {
+		query += ` AND t.id IN (SELECT task_id FROM monthly_tasks WHERE year = ?)`
+		args = append(args, year)
+	}
+	query += ` GROUP BY g.id, g.name, g.color ORDER BY g.name`
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats := []TagStats{}
+	for rows.Next() {
+		var s TagStats
+		if err := rows.Scan(&s.Tag.ID, &s.Tag.Name, &s.Tag.Color, &s.TotalTasks, &s.CompletedTasks, &s.TotalScore); err != nil {
+			return nil, err
+		}
+		if s.TotalTasks > 0 {
+			s.CompletionRate = float64(s.CompletedTasks) * 100 / float64(s.TotalTasks)
+			s.AverageScore = s.TotalScore / float64(s.TotalTasks)
+		}
+		stats = append(stats, s)
+	}
+
+	return stats, rows.Err()
+}
+
+// 辅助函数：查询执行器，*sql.DB 与 *sql.Tx 均满足
+type queryer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// 辅助函数：按名称获取标签（不区分大小写）
+func getTagByName(q queryer, name string) (*Tag, error) {
+	var tag Tag
+	err := q.QueryRow(`SELECT id, name, color FROM tags WHERE name = ?`, name).Scan(&tag.ID, &tag.Name, &tag.Color)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+// 辅助函数：替换任务的标签关联
+func saveTaskTags(q queryer, taskID string, tagNames []string) error {
+	_, err := q.Exec(`DELETE FROM task_tags WHERE task_id = ?`, taskID)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range tagNames {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+
+		tag, err := getTagByName(q, name)
+		if err != nil {
+			return err
+		}
+		if tag == nil {
+			tag = &Tag{ID: uuid.New().String(), Name: name, Color: defaultTagColor}
+			_, err = q.Exec(`INSERT INTO tags (id, name, color) VALUES (?, ?, ?)`, tag.ID, tag.Name, tag.Color)
+			if err != nil {
+				return err
+			}
+		}
+
+		_, err = q.Exec(`INSERT OR IGNORE INTO task_tags (task_id, tag_id) VALUES (?, ?)`, taskID, tag.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// 辅助函数：批量获取任务的标签名称
+func getTagNamesByTaskIDs(ids []string) (map[string][]string, error) {
+	tagMap := make(map[string][]string)
+	if len(ids) == 0 {
+		return tagMap, nil
+	}
+
+	args := []interface{}{}
+	for _, id := range ids {
+		args = append(args, id)
+	}
+
+	rows, err := db.Query(
+		`SELECT tt.task_id, g.name FROM task_tags tt JOIN tags g ON g.id = tt.tag_id WHERE tt.task_id IN (`+placeholders(len(ids))+`) ORDER BY g.name`,
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var taskID, name string
+		if err := rows.Scan(&taskID, &name); err != nil {
+			return nil, err
+		}
+		tagMap[taskID] = append(tagMap[taskID], name)
+	}
+
+	return tagMap, rows.Err()
+}
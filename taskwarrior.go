@@ -0,0 +1,371 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Taskwarrior 导出格式中的时间格式（UTC）
+const taskwarriorTimeLayout = "20060102T150405Z"
+
+// Taskwarrior 任务状态
+const (
+	taskwarriorPending   = "pending"
+	taskwarriorCompleted = "completed"
+	taskwarriorDeleted   = "deleted"
+)
+
+// taskwarriorTask Taskwarrior 的 JSON 任务格式
+//
+// manifestid、manifestyear、manifestmonth、manifestscore 为自定义属性（UDA），
+// Taskwarrior 导入时会原样保留，再次导出后据此更新原任务。
+type taskwarriorTask struct {
+	UUID          string                  `json:"uuid"`
+	Description   string                  `json:"description"`
+	Status        string                  `json:"status"`
+	Entry         string                  `json:"entry"`
+	Modified      string                  `json:"modified,omitempty"`
+	Start         string                  `json:"start,omitempty"`
+	End           string                  `json:"end,omitempty"`
+	Scheduled     string                  `json:"scheduled,omitempty"`
+	Due           string                  `json:"due,omitempty"`
+	Project       string                  `json:"project,omitempty"`
+	Priority      string                  `json:"priority,omitempty"`
+	Tags          []string                `json:"tags,omitempty"`
+	Annotations   []taskwarriorAnnotation `json:"annotations,omitempty"`
+	ManifestID    string                  `json:"manifestid,omitempty"`
+	ManifestYear  taskwarriorValue        `json:"manifestyear,omitempty"`
+	ManifestMonth taskwarriorValue        `json:"manifestmonth,omitempty"`
+	ManifestScore taskwarriorValue        `json:"manifestscore,omitempty"`
+}
+
+// taskwarriorAnnotation Taskwarrior 注释
+type taskwarriorAnnotation struct {
+	Entry       string `json:"entry"`
+	Description string `json:"description"`
+}
+
+// taskwarriorValue 自定义属性值，UDA 可能被定义为字符串或数字类型
+type taskwarriorValue string
+
+// UnmarshalJSON 同时接受字符串与数字
+func (v *taskwarriorValue) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*v = taskwarriorValue(s)
+		return nil
+	}
+	var n json.Number
+	if err := json.Unmarshal(data, &n); err != nil {
+		return err
+	}
+	*v = taskwarriorValue(n.String())
+	return nil
+}
+
+// FormatTaskwarrior 将任务转换为 Taskwarrior 导出格式（JSON 数组），year 为空时包含所有年度
+//
+// project 为维度Key，状态为进行中的任务带有 start 时间，开始、结束日期分别对应 scheduled 与 due。
+func FormatTaskwarrior(year string) ([]byte, error) {
+	data, err := GetAllAnnualData()
+	if err != nil {
+		return nil, err
+	}
+	years, err := selectYears(data, year)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	tasks := []taskwarriorTask{}
+	for _, year := range years {
+		annual := data[year]
+		for _, config := range orderedDimensionConfigs(annual) {
+			for month, monthTasks := range annual.Dimensions[config.Key].MonthlyTasks {
+				for _, task := range monthTasks {
+					tasks = append(tasks, toTaskwarriorTask(year, config.Key, month, task, now))
+				}
+			}
+		}
+	}
+
+	content, err := json.MarshalIndent(tasks, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(content, '\n'), nil
+}
+
+// ExportTaskwarrior 导出 Taskwarrior JSON 文件，可通过 task import 导入
+func ExportTaskwarrior(year, path string) error {
+	content, err := FormatTaskwarrior(year)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("创建导出目录失败: %w", err)
+	}
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		return fmt.Errorf("写入Taskwarrior文件失败: %w", err)
+	}
+	return nil
+}
+
+// ImportTaskwarrior 导入 task export 的输出（JSON 数组或每行一个任务），已删除的任务会被忽略
+func ImportTaskwarrior(path string, options TaskFileImportOptions) (*ImportResult, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取Taskwarrior文件失败: %w", err)
+	}
+	tasks, err := parseTaskwarriorTasks(content)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := GetAllAnnualData()
+	if err != nil {
+		return nil, err
+	}
+	locations := locateTasks(data)
+
+	result := &ImportResult{Errors: []ImportRowError{}, Tasks: []TaskRow{}}
+	rows := []importRow{}
+	for i, task := range tasks {
+		if task.Status == taskwarriorDeleted {
+			continue
+		}
+		result.TotalRows++
+
+		row, rowErrors := parseTaskwarriorRow(i+1, task, locations, options.DefaultYear, data)
+		if len(rowErrors) > 0 {
+			result.Errors = append(result.Errors, rowErrors...)
+			continue
+		}
+		rows = append(rows, row)
+	}
+
+	return saveImportRows(result, rows, data, options.DryRun)
+}
+
+// 辅助函数：解析 JSON 数组或每行一个 JSON 对象
+func parseTaskwarriorTasks(content []byte) ([]taskwarriorTask, error) {
+	content = bytes.TrimSpace(content)
+	tasks := []taskwarriorTask{}
+	if len(content) == 0 {
+		return tasks, nil
+	}
+
+	if content[0] == '[' {
+		if err := json.Unmarshal(content, &tasks); err != nil {
+			return nil, fmt.Errorf("解析Taskwarrior文件失败: %w", err)
+		}
+		return tasks, nil
+	}
+
+	for i, line := range bytes.Split(content, []byte("\n")) {
+		line = bytes.TrimRight(bytes.TrimSpace(line), ",")
+		if len(line) == 0 {
+			continue
+		}
+		var task taskwarriorTask
+		if err := json.Unmarshal(line, &task); err != nil {
+			return nil, fmt.Errorf("解析Taskwarrior文件第%d行失败: %w", i+1, err)
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks, nil
+}
+
+// 辅助函数：任务转为 Taskwarrior 格式
+func toTaskwarriorTask(year, dimensionKey string, month int, task Task, now time.Time) taskwarriorTask {
+	result := taskwarriorTask{
+		UUID:          task.ID,
+		Description:   strings.Join(strings.Fields(task.Title), " "),
+		Status:        taskwarriorPending,
+		Entry:         now.UTC().Format(taskwarriorTimeLayout),
+		Modified:      now.UTC().Format(taskwarriorTimeLayout),
+		Project:       dimensionKey,
+		Priority:      taskwarriorPriority(task.Priority),
+		ManifestYear:  taskwarriorValue(year),
+		ManifestMonth: taskwarriorValue(strconv.Itoa(month + 1)),
+	}
+
+	// Taskwarrior 要求 uuid 为合法 UUID，其他格式的任务ID派生固定的 UUID 并保存在 manifestid 中
+	if _, err := uuid.Parse(task.ID); err != nil {
+		result.UUID = uuid.NewSHA1(uuid.NameSpaceURL, []byte("manifest:task:"+task.ID)).String()
+		result.ManifestID = task.ID
+	}
+
+	if task.StartDate != nil && *task.StartDate != "" {
+		result.Scheduled = taskwarriorTime(*task.StartDate)
+		if result.Scheduled != "" {
+			result.Entry = result.Scheduled
+		}
+	}
+	if task.EndDate != nil && *task.EndDate != "" {
+		result.Due = taskwarriorTime(*task.EndDate)
+	}
+
+	switch task.Status {
+	case "completed":
+		result.Status = taskwarriorCompleted
+		result.End = result.Due
+		if result.End == "" {
+			result.End = result.Modified
+		}
+	case "in-progress":
+		result.Start = result.Entry
+	}
+
+	for _, tag := range task.Tags {
+		result.Tags = append(result.Tags, taskFileTag(tag))
+	}
+	for _, line := range strings.Split(task.Description, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			result.Annotations = append(result.Annotations, taskwarriorAnnotation{Entry: result.Entry, Description: line})
+		}
+	}
+	if task.Score != 0 {
+		result.ManifestScore = taskwarriorValue(strconv.FormatFloat(task.Score, 'f', -1, 64))
+	}
+
+	return result
+}
+
+// 辅助函数：解析并校验一个 Taskwarrior 任务，已有任务未包含的字段保持不变
+func parseTaskwarriorRow(index int, item taskwarriorTask, locations map[string]taskLocation, defaultYear string, data SystemData) (importRow, []ImportRowError) {
+	row := importRow{line: index, hasTags: true}
+	errors := []ImportRowError{}
+	fail := func(field, value, message string) {
+		errors = append(errors, ImportRowError{Row: index, Column: field, Value: value, Message: message})
+	}
+
+	id := item.ManifestID
+	if id == "" {
+		id = item.UUID
+	}
+	if id == "" {
+		id = uuid.New().String()
+	}
+
+	var existing *taskLocation
+	if location, ok := locations[id]; ok {
+		existing = &location
+		row.task = location.task
+	}
+	row.task.ID = id
+
+	row.task.Title = strings.TrimSpace(item.Description)
+	if row.task.Title == "" {
+		fail("任务", "", "任务名称不能为空")
+	}
+
+	switch {
+	case item.Status == taskwarriorCompleted:
+		row.task.Status = "completed"
+	case item.Start != "":
+		row.task.Status = "in-progress"
+	default:
+		row.task.Status = "not-started"
+	}
+
+	switch item.Priority {
+	case "H":
+		row.task.Priority = "high"
+	case "M":
+		row.task.Priority = "medium"
+	case "L":
+		row.task.Priority = "low"
+	case "":
+		if existing == nil {
+			row.task.Priority = "medium"
+		}
+	default:
+		fail("优先级", item.Priority, "优先级必须为 H/M/L")
+	}
+
+	row.task.StartDate = nil
+	row.task.EndDate = nil
+	if item.Scheduled != "" {
+		date, ok := taskwarriorDate(item.Scheduled)
+		if !ok {
+			fail("开始日期", item.Scheduled, "无法识别的时间格式")
+		}
+		row.task.StartDate = &date
+	}
+	if item.Due != "" {
+		date, ok := taskwarriorDate(item.Due)
+		if !ok {
+			fail("结束日期", item.Due, "无法识别的时间格式")
+		}
+		row.task.EndDate = &date
+	}
+
+	row.task.Score = 0
+	if item.ManifestScore != "" {
+		score, err := strconv.ParseFloat(string(item.ManifestScore), 64)
+		if err != nil || score < 0 || score > 100 {
+			fail("得分", string(item.ManifestScore), "得分必须为0-100之间的数字")
+		}
+		row.task.Score = score
+	}
+
+	descriptions := []string{}
+	for _, annotation := range item.Annotations {
+		descriptions = append(descriptions, annotation.Description)
+	}
+	row.task.Description = strings.Join(descriptions, "\n")
+	row.task.Tags = restoreTaskFileTags(item.Tags, row.task.Tags)
+
+	placement := taskPlacement{
+		year:      string(item.ManifestYear),
+		dimension: item.Project,
+		month:     string(item.ManifestMonth),
+	}
+	resolveTaskPlacement(&row, placement, existing, defaultYear, data, fail)
+
+	return row, errors
+}
+
+// 辅助函数：优先级转为 Taskwarrior 优先级
+func taskwarriorPriority(priority string) string {
+	switch priority {
+	case "high":
+		return "H"
+	case "medium":
+		return "M"
+	case "low":
+		return "L"
+	}
+	return ""
+}
+
+// 辅助函数：日期（本地时间零点）转为 Taskwarrior 时间
+func taskwarriorTime(date string) string {
+	value, ok := taskDate(date)
+	if !ok {
+		return ""
+	}
+	t, err := time.ParseInLocation(taskDateLayout, value, time.Local)
+	if err != nil {
+		return ""
+	}
+	return t.UTC().Format(taskwarriorTimeLayout)
+}
+
+// 辅助函数：Taskwarrior 时间转为本地日期
+func taskwarriorDate(value string) (string, bool) {
+	t, err := time.Parse(taskwarriorTimeLayout, value)
+	if err != nil {
+		return "", false
+	}
+	return t.Local().Format(taskDateLayout), true
+}
@@ -0,0 +1,259 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// TeamMemberDimension 成员在单个维度上的表现
+type TeamMemberDimension struct {
+	Key            string  `json:"key"`
+	Score          float64 `json:"score"`
+	CompletedTasks int     `json:"completedTasks"`
+	TotalTasks     int     `json:"totalTasks"`
+	CompletionRate float64 `json:"completionRate"` // 0-100
+}
+
+// TeamMember 单个成员的年度汇总
+type TeamMember struct {
+	Name           string                `json:"name"` // 取自文件名
+	File           string                `json:"file"`
+	TotalScore     float64               `json:"totalScore"`
+	Grade          GradeResult           `json:"grade"`
+	CompletedTasks int                   `json:"completedTasks"`
+	TotalTasks     int                   `json:"totalTasks"`
+	CompletionRate float64               `json:"completionRate"` // 0-100
+	Dimensions     []TeamMemberDimension `json:"dimensions"`
+}
+
+// TeamDimension 按维度Key合并后的团队维度汇总
+type TeamDimension struct {
+	Key            string  `json:"key"`
+	Title          string  `json:"title"`
+	Color          string  `json:"color"`
+	MemberCount    int     `json:"memberCount"` // 包含该维度的成员数
+	AverageScore   float64 `json:"averageScore"`
+	CompletedTasks int     `json:"completedTasks"`
+	TotalTasks     int     `json:"totalTasks"`
+	CompletionRate float64 `json:"completionRate"` // 0-100
+}
+
+// TeamGradeCount 某一评级的人数
+type TeamGradeCount struct {
+	Grade string `json:"grade"`
+	Count int    `json:"count"`
+}
+
+// TeamSkippedFile 无法汇总的文件及原因
+type TeamSkippedFile struct {
+	File   string `json:"file"`
+	Reason string `json:"reason"`
+}
+
+// TeamReport 团队汇总报告，只读取导出文件，不修改本地数据
+type TeamReport struct {
+	Year              string            `json:"year"`
+	Years             []string          `json:"years"` // 所有文件中出现的年度，供切换年度
+	GeneratedAt       string            `json:"generatedAt"`
+	Members           []TeamMember      `json:"members"`
+	Dimensions        []TeamDimension   `json:"dimensions"`
+	AverageScore      float64           `json:"averageScore"`
+	CompletedTasks    int               `json:"completedTasks"`
+	TotalTasks        int               `json:"totalTasks"`
+	CompletionRate    float64           `json:"completionRate"` // 0-100
+	GradeDistribution []TeamGradeCount  `json:"gradeDistribution"`
+	Skipped           []TeamSkippedFile `json:"skipped"`
+}
+
+// teamMemberData 读取到的成员数据
+type teamMemberData struct {
+	name string
+	file string
+	data SystemData
+}
+
+// BuildTeamReport 汇总多份导出的数据文件，year 为空时使用所有文件中最新的年度
+//
+// 维度按Key合并，名称与颜色取第一个包含该维度的成员；无法解析或没有该年度数据的文件记录在 Skipped 中。
+func BuildTeamReport(paths []string, year string) (*TeamReport, error) {
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("请选择要汇总的数据文件")
+	}
+
+	members, skipped := loadTeamMembers(paths)
+	return buildTeamReport(members, skipped, year, time.Now())
+}
+
+// 辅助函数：读取所有成员的导出文件，成员名称取文件名，重名时追加序号
+func loadTeamMembers(paths []string) ([]teamMemberData, []TeamSkippedFile) {
+	members := []teamMemberData{}
+	skipped := []TeamSkippedFile{}
+	names := make(map[string]int)
+
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			skipped = append(skipped, TeamSkippedFile{File: path, Reason: fmt.Sprintf("读取文件失败: %v", err)})
+			continue
+		}
+		var data SystemData
+		if err := json.Unmarshal(content, &data); err != nil {
+			skipped = append(skipped, TeamSkippedFile{File: path, Reason: fmt.Sprintf("解析文件失败: %v", err)})
+			continue
+		}
+
+		name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		names[name]++
+		if names[name] > 1 {
+			name = fmt.Sprintf("%s (%d)", name, names[name])
+		}
+		members = append(members, teamMemberData{name: name, file: path, data: data})
+	}
+	return members, skipped
+}
+
+// 辅助函数：按年度生成团队汇总
+func buildTeamReport(members []teamMemberData, skipped []TeamSkippedFile, year string, now time.Time) (*TeamReport, error) {
+	years := make(map[string]bool)
+	for _, member := range members {
+		for key := range member.data {
+			years[key] = true
+		}
+	}
+	if len(years) == 0 {
+		return nil, fmt.Errorf("没有可汇总的数据")
+	}
+
+	report := &TeamReport{
+		Years:             []string{},
+		GeneratedAt:       now.Format("2006-01-02 15:04"),
+		Members:           []TeamMember{},
+		Dimensions:        []TeamDimension{},
+		GradeDistribution: []TeamGradeCount{},
+		Skipped:           skipped,
+	}
+	for key := range years {
+		report.Years = append(report.Years, key)
+	}
+	sort.Strings(report.Years)
+	if year == "" {
+		year = report.Years[len(report.Years)-1]
+	}
+	report.Year = year
+
+	dimensions := make(map[string]*TeamDimension)
+	dimensionScores := make(map[string]float64)
+	dimensionOrder := []string{}
+	grades := make(map[string]int)
+	gradeRank := make(map[string]float64)
+
+	for _, member := range members {
+		annual, ok := member.data[year]
+		if !ok {
+			report.Skipped = append(report.Skipped, TeamSkippedFile{File: member.file, Reason: fmt.Sprintf("没有%s年度的数据", year)})
+			continue
+		}
+		annual.Year = year
+
+		review := buildReviewReport(annual, now)
+		summary := TeamMember{
+			Name:           member.name,
+			File:           member.file,
+			TotalScore:     review.TotalScore,
+			Grade:          review.Grade,
+			CompletedTasks: review.CompletedTasks,
+			TotalTasks:     review.TotalTasks,
+			CompletionRate: completionPercent(review.CompletedTasks, review.TotalTasks),
+			Dimensions:     []TeamMemberDimension{},
+		}
+
+		for _, dimension := range review.Dimensions {
+			summary.Dimensions = append(summary.Dimensions, TeamMemberDimension{
+				Key:            dimension.Key,
+				Score:          dimension.Score,
+				CompletedTasks: dimension.CompletedTasks,
+				TotalTasks:     dimension.TotalTasks,
+				CompletionRate: roundPercent(dimension.CompletionRate),
+			})
+
+			team, ok := dimensions[dimension.Key]
+			if !ok {
+				team = &TeamDimension{Key: dimension.Key, Title: dimension.Title, Color: dimension.Color}
+				dimensions[dimension.Key] = team
+				dimensionOrder = append(dimensionOrder, dimension.Key)
+			}
+			team.MemberCount++
+			team.CompletedTasks += dimension.CompletedTasks
+			team.TotalTasks += dimension.TotalTasks
+			dimensionScores[dimension.Key] += dimension.Score
+		}
+
+		report.Members = append(report.Members, summary)
+		report.AverageScore += summary.TotalScore
+		report.CompletedTasks += summary.CompletedTasks
+		report.TotalTasks += summary.TotalTasks
+
+		// 各成员的评级规则可能不同，按规则中的最低分排列评级
+		grades[summary.Grade.Grade]++
+		rules := annual.Settings.Scoring.GradeRules
+		if len(rules) == 0 {
+			rules = DefaultGradeRules()
+		}
+		for _, rule := range rules {
+			if rule.Grade == summary.Grade.Grade {
+				gradeRank[rule.Grade] = math.Max(gradeRank[rule.Grade], rule.MinScore)
+			}
+		}
+	}
+
+	if len(report.Members) == 0 {
+		return report, nil
+	}
+
+	report.AverageScore = math.Round(report.AverageScore/float64(len(report.Members))*10) / 10
+	report.CompletionRate = completionPercent(report.CompletedTasks, report.TotalTasks)
+
+	for _, key := range dimensionOrder {
+		team := dimensions[key]
+		team.AverageScore = math.Round(dimensionScores[key]/float64(team.MemberCount)*10) / 10
+		team.CompletionRate = completionPercent(team.CompletedTasks, team.TotalTasks)
+		report.Dimensions = append(report.Dimensions, *team)
+	}
+
+	// 按得分从高到低排列成员
+	sort.SliceStable(report.Members, func(i, j int) bool {
+		return report.Members[i].TotalScore > report.Members[j].TotalScore
+	})
+
+	for grade, count := range grades {
+		report.GradeDistribution = append(report.GradeDistribution, TeamGradeCount{Grade: grade, Count: count})
+	}
+	sort.Slice(report.GradeDistribution, func(i, j int) bool {
+		a, b := report.GradeDistribution[i].Grade, report.GradeDistribution[j].Grade
+		if gradeRank[a] != gradeRank[b] {
+			return gradeRank[a] > gradeRank[b]
+		}
+		return a < b
+	})
+
+	return report, nil
+}
+
+// 辅助函数：完成率百分比，保留一位小数
+func completionPercent(completed, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return roundPercent(float64(completed) * 100 / float64(total))
+}
+
+// 辅助函数：保留一位小数
+func roundPercent(value float64) float64 {
+	return math.Round(value*10) / 10
+}
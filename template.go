@@ -0,0 +1,506 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// 模板导出文件的格式标识
+const workspaceTemplateFormat = "manifest-workspace-template"
+
+// 导入模板的结果
+const (
+	templateImportCreated   = "created"
+	templateImportUpdated   = "updated"
+	templateImportUnchanged = "unchanged"
+)
+
+// WorkspaceTemplate 工作区模板，团队成员共用同一套维度结构与计分规则
+//
+// 模板内容修改后版本号自动加一，已按旧版本创建的年度可以重新应用新版本。
+type WorkspaceTemplate struct {
+	ID               string              `json:"id"`
+	Name             string              `json:"name"`
+	Description      string              `json:"description"`
+	Version          int                 `json:"version"`
+	DimensionConfigs []DimensionConfig   `json:"dimensionConfigs"`
+	Scoring          ScoringSettings     `json:"scoring"`                  // 计分规则、维度权重与评级规则
+	QuarterlyGoals   map[string][]string `json:"quarterlyGoals,omitempty"` // 维度Key -> 四个季度目标的默认内容
+	UpdatedAt        string              `json:"updatedAt"`
+}
+
+// TemplateImportResult 导入模板的结果
+type TemplateImportResult struct {
+	Template WorkspaceTemplate `json:"template"`
+	Status   string            `json:"status"` // created, updated, unchanged
+}
+
+// YearTemplateStatus 年度使用的模板及是否有新版本
+type YearTemplateStatus struct {
+	Year            string `json:"year"`
+	TemplateID      string `json:"templateId"`
+	TemplateName    string `json:"templateName"`
+	AppliedVersion  int    `json:"appliedVersion"`
+	LatestVersion   int    `json:"latestVersion"`
+	UpdateAvailable bool   `json:"updateAvailable"`
+}
+
+// workspaceTemplateFile 模板导出文件
+type workspaceTemplateFile struct {
+	Format   string            `json:"format"`
+	Template WorkspaceTemplate `json:"template"`
+}
+
+// 辅助函数：创建工作区模板相关的表
+func createTemplateTables() error {
+	// 创建模板表，模板内容以JSON保存
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS workspace_templates (
+			id TEXT PRIMARY KEY,
+			name TEXT,
+			version INTEGER,
+			content TEXT,
+			updated_at TEXT
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("创建模板表失败: %w", err)
+	}
+
+	// 创建年度模板关联表，记录年度应用的模板版本
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS year_templates (
+			year TEXT PRIMARY KEY,
+			template_id TEXT,
+			template_version INTEGER,
+			applied_at TEXT
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("创建年度模板关联表失败: %w", err)
+	}
+
+	return nil
+}
+
+// GetWorkspaceTemplates 获取所有工作区模板
+func GetWorkspaceTemplates() ([]WorkspaceTemplate, error) {
+	rows, err := db.Query(`SELECT content FROM workspace_templates ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	templates := []WorkspaceTemplate{}
+	for rows.Next() {
+		var content string
+		if err := rows.Scan(&content); err != nil {
+			return nil, err
+		}
+		var template WorkspaceTemplate
+		if err := json.Unmarshal([]byte(content), &template); err != nil {
+			return nil, fmt.Errorf("解析模板失败: %w", err)
+		}
+		templates = append(templates, template)
+	}
+	return templates, rows.Err()
+}
+
+// SaveWorkspaceTemplate 创建或更新模板，ID为空时创建；内容有变化时版本号加一
+func SaveWorkspaceTemplate(template WorkspaceTemplate) (WorkspaceTemplate, error) {
+	if err := validateWorkspaceTemplate(&template); err != nil {
+		return WorkspaceTemplate{}, err
+	}
+
+	if template.ID == "" {
+		template.ID = uuid.New().String()
+		template.Version = 1
+	} else {
+		existing, err := getWorkspaceTemplate(template.ID)
+		if err != nil {
+			return WorkspaceTemplate{}, err
+		}
+		if existing == nil {
+			template.Version = 1
+		} else if sameTemplateDefinition(*existing, template) {
+			// 内容未变化
+			return *existing, nil
+		} else {
+			template.Version = existing.Version + 1
+		}
+	}
+
+	template.UpdatedAt = time.Now().Format(time.RFC3339)
+	if err := putWorkspaceTemplate(template); err != nil {
+		return WorkspaceTemplate{}, err
+	}
+	return template, nil
+}
+
+// DeleteWorkspaceTemplate 删除模板，已创建的年度数据不受影响
+func DeleteWorkspaceTemplate(templateID string) (err error) {
+	// 开始事务
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	if _, err = tx.Exec(`DELETE FROM year_templates WHERE template_id = ?`, templateID); err != nil {
+		return err
+	}
+	_, err = tx.Exec(`DELETE FROM workspace_templates WHERE id = ?`, templateID)
+	return err
+}
+
+// CreateTemplateFromYear 以已有年度的维度结构与计分规则创建模板，不包含季度目标与任务
+func CreateTemplateFromYear(year, name string) (WorkspaceTemplate, error) {
+	annual, err := GetAnnualData(year)
+	if err != nil {
+		return WorkspaceTemplate{}, err
+	}
+	if annual == nil {
+		return WorkspaceTemplate{}, fmt.Errorf("年度不存在: %s", year)
+	}
+
+	return SaveWorkspaceTemplate(WorkspaceTemplate{
+		Name:             name,
+		DimensionConfigs: orderedDimensionConfigs(*annual),
+		Scoring:          annual.Settings.Scoring,
+	})
+}
+
+// CreateYearFromTemplate 按模板创建新的年度
+func CreateYearFromTemplate(year, templateID string) (*AnnualData, error) {
+	if err := validateTemplateYear(year); err != nil {
+		return nil, err
+	}
+	template, err := findWorkspaceTemplate(templateID)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := GetAnnualData(year)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, fmt.Errorf("年度已存在: %s", year)
+	}
+
+	annual := AnnualData{
+		Year:       year,
+		Dimensions: map[string]DimensionData{},
+	}
+	applyTemplateToAnnual(&annual, template)
+
+	if err := SaveAnnualData(annual); err != nil {
+		return nil, fmt.Errorf("创建年度失败: %w", err)
+	}
+	if err := setYearTemplate(year, template); err != nil {
+		return nil, err
+	}
+	return &annual, nil
+}
+
+// ApplyWorkspaceTemplate 将模板的当前版本重新应用到已有年度
+//
+// 更新维度的名称、图标、颜色与计分规则，补充缺少的维度，并用模板内容填充空白的季度目标；
+// 模板中没有的维度、已填写的目标和所有任务保持不变。
+func ApplyWorkspaceTemplate(year, templateID string) (*AnnualData, error) {
+	template, err := findWorkspaceTemplate(templateID)
+	if err != nil {
+		return nil, err
+	}
+
+	annual, err := GetAnnualData(year)
+	if err != nil {
+		return nil, err
+	}
+	if annual == nil {
+		return nil, fmt.Errorf("年度不存在: %s", year)
+	}
+
+	applyTemplateToAnnual(annual, template)
+	if err := SaveAnnualData(*annual); err != nil {
+		return nil, fmt.Errorf("应用模板失败: %w", err)
+	}
+	if err := setYearTemplate(year, template); err != nil {
+		return nil, err
+	}
+	return annual, nil
+}
+
+// GetYearTemplateStatuses 获取各年度使用的模板，以及模板是否有新版本可以重新应用
+func GetYearTemplateStatuses() ([]YearTemplateStatus, error) {
+	rows, err := db.Query(`
+		SELECT y.year, y.template_id, y.template_version, t.name, t.version
+		FROM year_templates y JOIN workspace_templates t ON t.id = y.template_id
+		ORDER BY y.year
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	statuses := []YearTemplateStatus{}
+	for rows.Next() {
+		var status YearTemplateStatus
+		if err := rows.Scan(&status.Year, &status.TemplateID, &status.AppliedVersion, &status.TemplateName, &status.LatestVersion); err != nil {
+			return nil, err
+		}
+		status.UpdateAvailable = status.LatestVersion > status.AppliedVersion
+		statuses = append(statuses, status)
+	}
+	return statuses, rows.Err()
+}
+
+// ExportWorkspaceTemplate 导出模板为JSON文件，便于分发给团队成员
+func ExportWorkspaceTemplate(templateID, path string) error {
+	template, err := findWorkspaceTemplate(templateID)
+	if err != nil {
+		return err
+	}
+
+	content, err := json.MarshalIndent(workspaceTemplateFile{Format: workspaceTemplateFormat, Template: template}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("创建导出目录失败: %w", err)
+	}
+	if err := os.WriteFile(path, append(content, '\n'), 0644); err != nil {
+		return fmt.Errorf("写入模板文件失败: %w", err)
+	}
+	return nil
+}
+
+// ImportWorkspaceTemplate 导入模板文件
+//
+// 同一模板（ID相同）只有版本更高时才会覆盖本地模板，版本相同时保持不变，本地版本更高时返回错误。
+func ImportWorkspaceTemplate(path string) (*TemplateImportResult, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取模板文件失败: %w", err)
+	}
+
+	var file workspaceTemplateFile
+	if err := json.Unmarshal(content, &file); err != nil {
+		return nil, fmt.Errorf("解析模板文件失败: %w", err)
+	}
+	if file.Format != workspaceTemplateFormat {
+		return nil, fmt.Errorf("不是有效的模板文件")
+	}
+
+	template := file.Template
+	if template.ID == "" || template.Version <= 0 {
+		return nil, fmt.Errorf("模板文件缺少ID或版本号")
+	}
+	if err := validateWorkspaceTemplate(&template); err != nil {
+		return nil, err
+	}
+
+	existing, err := getWorkspaceTemplate(template.ID)
+	if err != nil {
+		return nil, err
+	}
+	status := templateImportCreated
+	if existing != nil {
+		switch {
+		case template.Version < existing.Version:
+			return nil, fmt.Errorf("本地模板版本（v%d）比导入的版本（v%d）更新", existing.Version, template.Version)
+		case template.Version == existing.Version:
+			return &TemplateImportResult{Template: *existing, Status: templateImportUnchanged}, nil
+		}
+		status = templateImportUpdated
+	}
+
+	if template.UpdatedAt == "" {
+		template.UpdatedAt = time.Now().Format(time.RFC3339)
+	}
+	if err := putWorkspaceTemplate(template); err != nil {
+		return nil, err
+	}
+	return &TemplateImportResult{Template: template, Status: status}, nil
+}
+
+// 辅助函数：按模板更新年度的维度配置、计分规则与季度目标，并重新计算得分
+func applyTemplateToAnnual(annual *AnnualData, template WorkspaceTemplate) {
+	configs := make(map[string]int)
+	for i, config := range annual.DimensionConfigs {
+		configs[config.Key] = i
+	}
+
+	for _, config := range template.DimensionConfigs {
+		if i, ok := configs[config.Key]; ok {
+			annual.DimensionConfigs[i] = config
+		} else {
+			annual.DimensionConfigs = append(annual.DimensionConfigs, config)
+		}
+
+		dimData, ok := annual.Dimensions[config.Key]
+		if !ok {
+			dimData = newDimensionData()
+		}
+		dimData.Settings.Scoring.CompletedScore = template.Scoring.CompletedScore
+		dimData.Settings.Scoring.InProgressScore = template.Scoring.InProgressScore
+		dimData.Settings.Scoring.NotStartedScore = template.Scoring.NotStartedScore
+
+		// 只填充空白的季度目标
+		for len(dimData.QuarterlyGoals) < 4 {
+			dimData.QuarterlyGoals = append(dimData.QuarterlyGoals, "")
+		}
+		for quarter, goal := range template.QuarterlyGoals[config.Key] {
+			if quarter < len(dimData.QuarterlyGoals) && strings.TrimSpace(dimData.QuarterlyGoals[quarter]) == "" {
+				dimData.QuarterlyGoals[quarter] = goal
+			}
+		}
+
+		updateDimensionStats(&dimData)
+		annual.Dimensions[config.Key] = dimData
+	}
+
+	scoring := &annual.Settings.Scoring
+	scoring.CompletedScore = template.Scoring.CompletedScore
+	scoring.InProgressScore = template.Scoring.InProgressScore
+	scoring.NotStartedScore = template.Scoring.NotStartedScore
+	// 合并模板中的权重，保留已有维度（模板中没有的维度）的权重
+	if scoring.DimensionWeights == nil {
+		scoring.DimensionWeights = make(map[string]float64, len(template.Scoring.DimensionWeights))
+	}
+	for key, weight := range template.Scoring.DimensionWeights {
+		scoring.DimensionWeights[key] = weight
+	}
+	scoring.GradeRules = template.Scoring.GradeRules
+	updateAnnualTotalScore(annual)
+}
+
+// 辅助函数：校验模板内容，并去除名称首尾空白
+func validateWorkspaceTemplate(template *WorkspaceTemplate) error {
+	template.Name = strings.TrimSpace(template.Name)
+	if template.Name == "" {
+		return fmt.Errorf("模板名称不能为空")
+	}
+	if len(template.DimensionConfigs) == 0 {
+		return fmt.Errorf("模板至少需要一个维度")
+	}
+
+	keys := make(map[string]bool)
+	for _, config := range template.DimensionConfigs {
+		if config.Key == "" {
+			return fmt.Errorf("维度Key不能为空")
+		}
+		if keys[config.Key] {
+			return fmt.Errorf("维度Key重复: %s", config.Key)
+		}
+		keys[config.Key] = true
+	}
+
+	for key, weight := range template.Scoring.DimensionWeights {
+		if !keys[key] {
+			return fmt.Errorf("权重对应的维度不存在: %s", key)
+		}
+		if weight < 0 {
+			return fmt.Errorf("维度 %s 的权重不能为负数", key)
+		}
+	}
+	for key, goals := range template.QuarterlyGoals {
+		if !keys[key] {
+			return fmt.Errorf("季度目标对应的维度不存在: %s", key)
+		}
+		if len(goals) > 4 {
+			return fmt.Errorf("维度 %s 的季度目标不能超过4个", key)
+		}
+	}
+	return ValidateGradeRules(template.Scoring.GradeRules)
+}
+
+// 辅助函数：校验年度格式
+func validateTemplateYear(year string) error {
+	if n, err := strconv.Atoi(year); err != nil || len(year) != 4 || n <= 0 {
+		return fmt.Errorf("无效的年度: %s", year)
+	}
+	return nil
+}
+
+// 辅助函数：比较两个模板的内容是否相同（忽略ID、版本号与更新时间）
+func sameTemplateDefinition(a, b WorkspaceTemplate) bool {
+	definition := func(template WorkspaceTemplate) string {
+		template.ID, template.Version, template.UpdatedAt = "", 0, ""
+		content, _ := json.Marshal(template)
+		return string(content)
+	}
+	return definition(a) == definition(b)
+}
+
+// 辅助函数：按ID查找模板，不存在时返回错误
+func findWorkspaceTemplate(templateID string) (WorkspaceTemplate, error) {
+	template, err := getWorkspaceTemplate(templateID)
+	if err != nil {
+		return WorkspaceTemplate{}, err
+	}
+	if template == nil {
+		return WorkspaceTemplate{}, fmt.Errorf("模板不存在")
+	}
+	return *template, nil
+}
+
+// 辅助函数：按ID读取模板，不存在时返回nil
+func getWorkspaceTemplate(templateID string) (*WorkspaceTemplate, error) {
+	var content string
+	err := db.QueryRow(`SELECT content FROM workspace_templates WHERE id = ?`, templateID).Scan(&content)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var template WorkspaceTemplate
+	if err := json.Unmarshal([]byte(content), &template); err != nil {
+		return nil, fmt.Errorf("解析模板失败: %w", err)
+	}
+	return &template, nil
+}
+
+// 辅助函数：保存模板
+func putWorkspaceTemplate(template WorkspaceTemplate) error {
+	content, err := json.Marshal(template)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(
+		`INSERT OR REPLACE INTO workspace_templates (id, name, version, content, updated_at) VALUES (?, ?, ?, ?, ?)`,
+		template.ID, template.Name, template.Version, string(content), template.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("保存模板失败: %w", err)
+	}
+	return nil
+}
+
+// 辅助函数：记录年度应用的模板版本
+func setYearTemplate(year string, template WorkspaceTemplate) error {
+	_, err := db.Exec(
+		`INSERT OR REPLACE INTO year_templates (year, template_id, template_version, applied_at) VALUES (?, ?, ?, ?)`,
+		year, template.ID, template.Version, time.Now().Format(time.RFC3339),
+	)
+	if err != nil {
+		return fmt.Errorf("保存年度模板失败: %w", err)
+	}
+	return nil
+}
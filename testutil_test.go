@@ -0,0 +1,49 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// 辅助函数：测试期间使用临时数据目录，结束后恢复
+func setupTestDataDir(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+
+	dataDirMu.Lock()
+	previous := dataDir
+	dataDir = &DataDirInfo{Path: dir, Source: dataDirEnv}
+	dataDirMu.Unlock()
+
+	t.Cleanup(func() {
+		dataDirMu.Lock()
+		dataDir = previous
+		dataDirMu.Unlock()
+	})
+	return dir
+}
+
+// 辅助函数：测试期间使用临时数据目录中的新账号库与数据库，结束后关闭并恢复
+func setupTestDB(t *testing.T) {
+	t.Helper()
+	dir := setupTestDataDir(t)
+
+	previous, previousAccounts, previousFile := db, accountsDB, currentDBFile
+	db, accountsDB, currentDBFile = nil, nil, legacyDatabaseFile
+	t.Cleanup(func() {
+		CloseDatabase()
+		db, accountsDB, currentDBFile = previous, previousAccounts, previousFile
+	})
+	// 不沿用其他测试留下的会话与失败次数
+	session.mu.Lock()
+	session.accountID, session.locked, session.failures, session.retryAt = "", false, 0, time.Time{}
+	session.mu.Unlock()
+
+	if err := openAccountRegistry(dir); err != nil {
+		t.Fatal(err)
+	}
+	if err := openDatabase(filepath.Join(dir, legacyDatabaseFile)); err != nil {
+		t.Fatal(err)
+	}
+}
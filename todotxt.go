@@ -0,0 +1,322 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// todo.txt 中使用的扩展字段
+const (
+	todoKeyID       = "id"
+	todoKeyYear     = "year"
+	todoKeyMonth    = "month"
+	todoKeyStatus   = "status"
+	todoKeyStart    = "t"   // 开始日期（threshold）
+	todoKeyDue      = "due" // 结束日期
+	todoKeyScore    = "score"
+	todoKeyPriority = "pri" // 已完成任务的优先级
+)
+
+// todoLine 解析后的 todo.txt 行
+type todoLine struct {
+	completed bool
+	priority  string // A-Z
+	text      string
+	projects  []string
+	contexts  []string
+	fields    map[string]string
+}
+
+// FormatTodoTxt 将任务转换为 todo.txt 格式，year 为空时包含所有年度
+//
+// 优先级 A/B/C 分别对应高/中/低，+project 为维度Key，@context 为标签，
+// 任务ID、年度和月份写在 id:、year:、month: 字段中，再次导入时据此更新原任务。
+func FormatTodoTxt(year string) (string, error) {
+	data, err := GetAllAnnualData()
+	if err != nil {
+		return "", err
+	}
+	years, err := selectYears(data, year)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	for _, year := range years {
+		annual := data[year]
+		for _, config := range orderedDimensionConfigs(annual) {
+			for month, tasks := range annual.Dimensions[config.Key].MonthlyTasks {
+				for _, task := range tasks {
+					b.WriteString(formatTodoLine(year, config.Key, month, task))
+					b.WriteString("\n")
+				}
+			}
+		}
+	}
+	return b.String(), nil
+}
+
+// ExportTodoTxt 导出 todo.txt 文件
+func ExportTodoTxt(year, path string) error {
+	content, err := FormatTodoTxt(year)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("创建导出目录失败: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		return fmt.Errorf("写入todo.txt文件失败: %w", err)
+	}
+	return nil
+}
+
+// ImportTodoTxt 从 todo.txt 文件导入任务，带 id: 的行更新已有任务
+func ImportTodoTxt(path string, options TaskFileImportOptions) (*ImportResult, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("打开todo.txt文件失败: %w", err)
+	}
+	defer file.Close()
+
+	data, err := GetAllAnnualData()
+	if err != nil {
+		return nil, err
+	}
+	locations := locateTasks(data)
+
+	result := &ImportResult{Errors: []ImportRowError{}, Tasks: []TaskRow{}}
+	rows := []importRow{}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(strings.TrimPrefix(scanner.Text(), "\ufeff"))
+		if text == "" {
+			continue
+		}
+		result.TotalRows++
+
+		row, rowErrors := parseTodoRow(line, text, locations, options.DefaultYear, data)
+		if len(rowErrors) > 0 {
+			result.Errors = append(result.Errors, rowErrors...)
+			continue
+		}
+		rows = append(rows, row)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("读取todo.txt文件失败: %w", err)
+	}
+
+	return saveImportRows(result, rows, data, options.DryRun)
+}
+
+// 辅助函数：将任务格式化为一行 todo.txt
+func formatTodoLine(year, dimensionKey string, month int, task Task) string {
+	parts := []string{}
+	letter := todoPriorityLetter(task.Priority)
+	if task.Status == "completed" {
+		parts = append(parts, "x")
+	} else if letter != "" {
+		parts = append(parts, "("+letter+")")
+	}
+
+	parts = append(parts, strings.Join(strings.Fields(task.Title), " "), "+"+dimensionKey)
+	for _, tag := range task.Tags {
+		parts = append(parts, "@"+taskFileTag(tag))
+	}
+
+	parts = append(parts, todoKeyID+":"+task.ID, todoKeyYear+":"+year, fmt.Sprintf("%s:%d", todoKeyMonth, month+1))
+	if task.Status == "in-progress" {
+		parts = append(parts, todoKeyStatus+":"+task.Status)
+	}
+	if task.StartDate != nil && *task.StartDate != "" {
+		parts = append(parts, todoKeyStart+":"+*task.StartDate)
+	}
+	if task.EndDate != nil && *task.EndDate != "" {
+		parts = append(parts, todoKeyDue+":"+*task.EndDate)
+	}
+	if task.Score != 0 {
+		parts = append(parts, todoKeyScore+":"+strconv.FormatFloat(task.Score, 'f', -1, 64))
+	}
+	// 已完成任务按惯例去掉行首优先级，改写在 pri: 字段中
+	if task.Status == "completed" && letter != "" {
+		parts = append(parts, todoKeyPriority+":"+letter)
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// 辅助函数：解析一行 todo.txt
+func parseTodoLine(text string) todoLine {
+	line := todoLine{fields: make(map[string]string)}
+	words := strings.Fields(text)
+
+	if len(words) > 0 && words[0] == "x" {
+		line.completed = true
+		words = words[1:]
+		// 完成日期与创建日期
+		for i := 0; i < 2 && len(words) > 0; i++ {
+			if _, ok := taskDate(words[0]); !ok || len(words[0]) != len(taskDateLayout) {
+				break
+			}
+			words = words[1:]
+		}
+	} else if len(words) > 0 && len(words[0]) == 3 && words[0][0] == '(' && words[0][2] == ')' && words[0][1] >= 'A' && words[0][1] <= 'Z' {
+		line.priority = words[0][1:2]
+		words = words[1:]
+		// 创建日期
+		if len(words) > 0 && len(words[0]) == len(taskDateLayout) {
+			if _, ok := taskDate(words[0]); ok {
+				words = words[1:]
+			}
+		}
+	}
+
+	rest := []string{}
+	for _, word := range words {
+		switch {
+		case len(word) > 1 && word[0] == '+':
+			line.projects = append(line.projects, word[1:])
+		case len(word) > 1 && word[0] == '@':
+			line.contexts = append(line.contexts, word[1:])
+		default:
+			key, value, ok := strings.Cut(word, ":")
+			if ok && value != "" && isTodoKey(key) {
+				line.fields[key] = value
+				continue
+			}
+			rest = append(rest, word)
+		}
+	}
+	line.text = strings.Join(rest, " ")
+
+	if line.priority == "" {
+		line.priority = line.fields[todoKeyPriority]
+	}
+	return line
+}
+
+// 辅助函数：判断是否为本应用使用的扩展字段，其他 key:value 保留在任务名称中
+func isTodoKey(key string) bool {
+	switch key {
+	case todoKeyID, todoKeyYear, todoKeyMonth, todoKeyStatus, todoKeyStart, todoKeyDue, todoKeyScore, todoKeyPriority:
+		return true
+	}
+	return false
+}
+
+// 辅助函数：解析并校验一行 todo.txt，已有任务未包含的字段保持不变
+func parseTodoRow(lineNumber int, text string, locations map[string]taskLocation, defaultYear string, data SystemData) (importRow, []ImportRowError) {
+	line := parseTodoLine(text)
+	row := importRow{line: lineNumber, hasTags: true}
+	errors := []ImportRowError{}
+	fail := func(field, value, message string) {
+		errors = append(errors, ImportRowError{Row: lineNumber, Column: field, Value: value, Message: message})
+	}
+
+	var existing *taskLocation
+	if id := line.fields[todoKeyID]; id != "" {
+		if location, ok := locations[id]; ok {
+			existing = &location
+			row.task = location.task
+		}
+		row.task.ID = id
+	}
+
+	row.task.Title = line.text
+	if row.task.Title == "" {
+		fail("任务", "", "任务名称不能为空")
+	}
+
+	switch {
+	case line.completed:
+		row.task.Status = "completed"
+	case line.fields[todoKeyStatus] != "":
+		status, ok := parseImportStatus(line.fields[todoKeyStatus])
+		if !ok {
+			fail("状态", line.fields[todoKeyStatus], "状态必须为 not-started/in-progress/completed")
+		}
+		row.task.Status = status
+	default:
+		row.task.Status = "not-started"
+	}
+
+	if line.priority != "" {
+		row.task.Priority = todoPriority(line.priority)
+		if row.task.Priority == "" {
+			fail("优先级", line.priority, "优先级必须为A-Z")
+		}
+	} else if existing == nil {
+		row.task.Priority = "medium"
+	}
+
+	row.task.StartDate = nil
+	row.task.EndDate = nil
+	dateFields := []struct {
+		key    string
+		label  string
+		target **string
+	}{{todoKeyStart, "开始日期", &row.task.StartDate}, {todoKeyDue, "结束日期", &row.task.EndDate}}
+	for _, field := range dateFields {
+		key, target := field.key, field.target
+		value, ok := line.fields[key]
+		if !ok {
+			continue
+		}
+		date, valid := parseImportDate(value)
+		if !valid {
+			fail(field.label, value, "日期格式无法识别，请使用YYYY-MM-DD")
+			continue
+		}
+		*target = &date
+	}
+
+	row.task.Score = 0
+	if value, ok := line.fields[todoKeyScore]; ok {
+		score, err := strconv.ParseFloat(value, 64)
+		if err != nil || score < 0 || score > 100 {
+			fail("得分", value, "得分必须为0-100之间的数字")
+		}
+		row.task.Score = score
+	}
+
+	row.task.Tags = restoreTaskFileTags(line.contexts, row.task.Tags)
+
+	placement := taskPlacement{year: line.fields[todoKeyYear], month: line.fields[todoKeyMonth]}
+	if len(line.projects) > 0 {
+		placement.dimension = line.projects[0]
+	}
+	resolveTaskPlacement(&row, placement, existing, defaultYear, data, fail)
+
+	return row, errors
+}
+
+// 辅助函数：优先级转为 todo.txt 优先级字母
+func todoPriorityLetter(priority string) string {
+	switch priority {
+	case "high":
+		return "A"
+	case "medium":
+		return "B"
+	case "low":
+		return "C"
+	}
+	return ""
+}
+
+// 辅助函数：todo.txt 优先级字母转为优先级，C 及之后的字母均视为低
+func todoPriority(letter string) string {
+	switch {
+	case letter == "A":
+		return "high"
+	case letter == "B":
+		return "medium"
+	case len(letter) == 1 && letter[0] >= 'C' && letter[0] <= 'Z':
+		return "low"
+	}
+	return ""
+}
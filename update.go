@@ -0,0 +1,329 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-version"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// 默认更新源：GitHub Releases 列表（包含预发布版本，按渠道筛选）
+const defaultUpdateSourceURL = "https://api.github.com/repos/PotatoZhou/Manifest/releases"
+
+// 更新渠道
+const (
+	UpdateChannelStable = "stable" // 只包含正式版本
+	UpdateChannelBeta   = "beta"   // 同时包含预发布版本
+)
+
+// 检查更新结果状态
+const (
+	UpdateStatusAvailable = "available" // 有新版本
+	UpdateStatusLatest    = "latest"    // 已是最新版本
+	UpdateStatusSkipped   = "skipped"   // 最新版本已被跳过
+	UpdateStatusError     = "error"     // 检查失败，见 Error
+)
+
+// 发现新版本事件名称
+const updateAvailableEvent = "updateAvailable"
+
+// 更新源响应的最大长度
+const maxUpdateResponseSize = 8 << 20
+
+// GitHubRelease 结构体用于解析GitHub Releases API返回的JSON数据
+type GitHubRelease struct {
+	TagName     string        `json:"tag_name"`     // 版本标签
+	Body        string        `json:"body"`         // 发布说明
+	HTMLURL     string        `json:"html_url"`     // 发布页面URL
+	Draft       bool          `json:"draft"`        // 草稿不对外发布
+	Prerelease  bool          `json:"prerelease"`   // 预发布版本
+	PublishedAt string        `json:"published_at"` // 发布时间
+	Assets      []GitHubAsset `json:"assets"`       // 发布的文件
+}
+
+// UpdateSettings 检查更新设置
+type UpdateSettings struct {
+	Enabled        bool   `json:"enabled"`        // 是否定时自动检查
+	SourceURL      string `json:"sourceURL"`      // 更新源地址，返回 GitHub Releases 格式的 JSON（列表或单个版本）
+	Channel        string `json:"channel"`        // stable, beta
+	Constraint     string `json:"constraint"`     // 可选的版本约束，例如 "~> 1.2" 表示只更新到 1.x
+	IntervalHours  int    `json:"intervalHours"`  // 自动检查间隔（小时）
+	SkippedVersion string `json:"skippedVersion"` // 跳过的版本，不再自动提醒
+	LastCheckedAt  string `json:"lastCheckedAt"`  // 上次自动检查时间，由检查更新时写入
+}
+
+// CheckUpdateResult 定义更新检查结果的结构体
+type CheckUpdateResult struct {
+	Status          string `json:"status"`          // available, latest, skipped, error
+	UpdateAvailable bool   `json:"updateAvailable"` // 是否有更新可用（跳过的版本也为 true）
+	CurrentVersion  string `json:"currentVersion"`  // 当前版本
+	LatestVersion   string `json:"latestVersion"`   // 最新版本
+	Prerelease      bool   `json:"prerelease"`      // 最新版本是否为预发布版本
+	ReleaseNotes    string `json:"releaseNotes"`    // 发布说明
+	DownloadURL     string `json:"downloadURL"`     // 下载URL
+	Channel         string `json:"channel"`         // 检查时使用的渠道
+	CheckedAt       string `json:"checkedAt"`       // 检查时间
+	Error           string `json:"error,omitempty"` // 检查失败的原因
+}
+
+// DefaultUpdateSettings 默认检查更新设置
+func DefaultUpdateSettings() UpdateSettings {
+	return UpdateSettings{
+		Enabled:       true,
+		SourceURL:     defaultUpdateSourceURL,
+		Channel:       UpdateChannelStable,
+		IntervalHours: 24,
+	}
+}
+
+// GetUpdateSettings 获取检查更新设置，未配置时返回默认值
+func GetUpdateSettings() (UpdateSettings, error) {
+	config, err := LoadConfig()
+	if err != nil {
+		return UpdateSettings{}, err
+	}
+
+	if config.Update == nil {
+		return DefaultUpdateSettings(), nil
+	}
+	return *config.Update, nil
+}
+
+// SaveUpdateSettings 保存检查更新设置，保留上次检查时间
+func SaveUpdateSettings(settings UpdateSettings) error {
+	if settings.SourceURL == "" {
+		settings.SourceURL = defaultUpdateSourceURL
+	}
+	if !strings.HasPrefix(settings.SourceURL, "https://") && !strings.HasPrefix(settings.SourceURL, "http://") {
+		return fmt.Errorf("无效的更新源地址: %s", settings.SourceURL)
+	}
+	if settings.Channel != UpdateChannelStable && settings.Channel != UpdateChannelBeta {
+		return fmt.Errorf("无效的更新渠道: %s", settings.Channel)
+	}
+	if settings.Constraint != "" {
+		if _, err := version.NewConstraint(settings.Constraint); err != nil {
+			return fmt.Errorf("无效的版本约束: %w", err)
+		}
+	}
+	if settings.IntervalHours <= 0 {
+		return fmt.Errorf("检查间隔必须大于0")
+	}
+
+	return UpdateConfig(func(config *Config) error {
+		if config.Update != nil {
+			settings.LastCheckedAt = config.Update.LastCheckedAt
+		}
+		config.Update = &settings
+		return nil
+	})
+}
+
+// SkipUpdateVersion 跳过某个版本，之后自动检查不再提醒该版本，传空字符串取消跳过
+func SkipUpdateVersion(tag string) error {
+	return UpdateConfig(func(config *Config) error {
+		settings := DefaultUpdateSettings()
+		if config.Update != nil {
+			settings = *config.Update
+		}
+		settings.SkippedVersion = tag
+		config.Update = &settings
+		return nil
+	})
+}
+
+// CheckUpdate 立即检查更新（关闭自动检查时也可手动检查），失败时返回 Status 为 error 的结果
+func CheckUpdate(ctx context.Context) (*CheckUpdateResult, error) {
+	settings, err := GetUpdateSettings()
+	if err != nil {
+		return nil, err
+	}
+	client := &http.Client{Timeout: 10 * time.Second}
+	result, releases := checkUpdate(ctx, client, settings, currentVersion, time.Now())
+	cacheReleaseNotes(releases)
+	return result, nil
+}
+
+// 辅助函数：定时检查更新，到期时才请求更新源，供 Scheduler 调用
+func checkUpdates(ctx context.Context) time.Duration {
+	settings, err := GetUpdateSettings()
+	if err != nil {
+		log.Printf("读取检查更新设置失败: %v", err)
+		return time.Hour
+	}
+	interval := time.Duration(settings.IntervalHours) * time.Hour
+	if interval <= 0 {
+		interval = 24 * time.Hour
+	}
+	if !settings.Enabled {
+		return interval
+	}
+
+	now := time.Now()
+	if last, err := time.Parse(time.RFC3339, settings.LastCheckedAt); err == nil {
+		if wait := last.Add(interval).Sub(now); wait > 0 {
+			return wait
+		}
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	result, releases := checkUpdate(ctx, client, settings, currentVersion, now)
+	cacheReleaseNotes(releases)
+	if result.Status == UpdateStatusError {
+		// 离线时不记录检查时间，稍后重试
+		log.Printf("自动检查更新失败: %s", result.Error)
+		return time.Hour
+	}
+	if err := saveUpdateCheckedAt(now); err != nil {
+		log.Printf("保存检查更新时间失败: %v", err)
+	}
+	if result.Status == UpdateStatusAvailable {
+		runtime.EventsEmit(ctx, updateAvailableEvent, result)
+	}
+	return interval
+}
+
+// 辅助函数：请求更新源并按渠道与约束选出最新版本，错误记录在结果中，同时返回获取到的所有版本
+func checkUpdate(ctx context.Context, client *http.Client, settings UpdateSettings, current string, now time.Time) (*CheckUpdateResult, []GitHubRelease) {
+	if settings.Channel == "" {
+		settings.Channel = UpdateChannelStable
+	}
+	result := &CheckUpdateResult{
+		Status:         UpdateStatusLatest,
+		CurrentVersion: current,
+		Channel:        settings.Channel,
+		CheckedAt:      now.Format(time.RFC3339),
+	}
+	fail := func(err error) (*CheckUpdateResult, []GitHubRelease) {
+		result.Status = UpdateStatusError
+		result.Error = err.Error()
+		return result, nil
+	}
+
+	currentParsed, err := version.NewVersion(current)
+	if err != nil {
+		return fail(fmt.Errorf("解析当前版本失败: %w", err))
+	}
+	var constraint version.Constraints
+	if settings.Constraint != "" {
+		if constraint, err = version.NewConstraint(settings.Constraint); err != nil {
+			return fail(fmt.Errorf("无效的版本约束: %w", err))
+		}
+	}
+
+	releases, err := fetchReleases(ctx, client, settings.SourceURL)
+	if err != nil {
+		return fail(err)
+	}
+	release, latest := selectRelease(releases, settings.Channel, constraint)
+	if release == nil || !latest.GreaterThan(currentParsed) {
+		return result, releases
+	}
+
+	result.UpdateAvailable = true
+	result.LatestVersion = release.TagName
+	result.Prerelease = release.Prerelease || latest.Prerelease() != ""
+	result.ReleaseNotes = release.Body
+	result.DownloadURL = release.HTMLURL
+	result.Status = UpdateStatusAvailable
+	if settings.SkippedVersion != "" {
+		if skipped, err := version.NewVersion(settings.SkippedVersion); err == nil && skipped.Equal(latest) {
+			result.Status = UpdateStatusSkipped
+		}
+	}
+	return result, releases
+}
+
+// 辅助函数：请求更新源，兼容返回版本列表或单个版本（releases/latest）
+func fetchReleases(ctx context.Context, client *http.Client, sourceURL string) ([]GitHubRelease, error) {
+	if sourceURL == "" {
+		sourceURL = defaultUpdateSourceURL
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, sourceURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("无效的更新源地址: %w", err)
+	}
+	request.Header.Set("Accept", "application/vnd.github+json")
+	request.Header.Set("User-Agent", "Manifest/"+currentVersion)
+
+	response, err := client.Do(request)
+	if err != nil {
+		var netErr net.Error
+		if errors.As(err, &netErr) && netErr.Timeout() {
+			return nil, fmt.Errorf("连接更新服务器超时，请检查网络")
+		}
+		return nil, fmt.Errorf("无法连接更新服务器: %w", err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("更新服务器返回错误: %s", response.Status)
+	}
+	body, err := io.ReadAll(io.LimitReader(response.Body, maxUpdateResponseSize))
+	if err != nil {
+		return nil, fmt.Errorf("读取更新响应失败: %w", err)
+	}
+
+	body = []byte(strings.TrimSpace(string(body)))
+	if len(body) > 0 && body[0] == '{' {
+		var release GitHubRelease
+		if err := json.Unmarshal(body, &release); err != nil {
+			return nil, fmt.Errorf("解析更新响应失败: %w", err)
+		}
+		return []GitHubRelease{release}, nil
+	}
+	var releases []GitHubRelease
+	if err := json.Unmarshal(body, &releases); err != nil {
+		return nil, fmt.Errorf("解析更新响应失败: %w", err)
+	}
+	return releases, nil
+}
+
+// 辅助函数：选出渠道内满足约束的最高版本，跳过草稿与无法解析的标签
+//
+// 约束按版本的主体部分判断（1.3.0-beta.1 视为 1.3.0），否则 go-version 的约束不会匹配任何预发布版本。
+func selectRelease(releases []GitHubRelease, channel string, constraint version.Constraints) (*GitHubRelease, *version.Version) {
+	var best *GitHubRelease
+	var bestVersion *version.Version
+	for i := range releases {
+		release := &releases[i]
+		if release.Draft {
+			continue
+		}
+		parsed, err := version.NewVersion(release.TagName)
+		if err != nil {
+			continue
+		}
+		if channel != UpdateChannelBeta && (release.Prerelease || parsed.Prerelease() != "") {
+			continue
+		}
+		if constraint != nil && !constraint.Check(parsed.Core()) {
+			continue
+		}
+		if bestVersion == nil || parsed.GreaterThan(bestVersion) {
+			best, bestVersion = release, parsed
+		}
+	}
+	return best, bestVersion
+}
+
+// 辅助函数：记录上次自动检查时间
+func saveUpdateCheckedAt(now time.Time) error {
+	return UpdateConfig(func(config *Config) error {
+		settings := DefaultUpdateSettings()
+		if config.Update != nil {
+			settings = *config.Update
+		}
+		settings.LastCheckedAt = now.Format(time.RFC3339)
+		config.Update = &settings
+		return nil
+	})
+}
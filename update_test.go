@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-version"
+)
+
+// 测试用的版本列表，与 GitHub Releases 接口一样不保证顺序
+var testReleases = []GitHubRelease{
+	{TagName: "v1.2.0"},
+	{TagName: "v1.3.0-beta.1", Prerelease: true},
+	{TagName: "v2.0.0"},
+	{TagName: "v2.1.0", Draft: true},
+	{TagName: "not-a-version"},
+	{TagName: "v1.2.5"},
+}
+
+func TestSelectRelease(t *testing.T) {
+	tests := []struct {
+		channel    string
+		constraint string
+		want       string
+	}{
+		{UpdateChannelStable, "", "v2.0.0"},
+		{UpdateChannelBeta, "", "v2.0.0"},
+		{UpdateChannelStable, "~> 1.2", "v1.2.5"},
+		{UpdateChannelBeta, "~> 1.2", "v1.3.0-beta.1"},
+		{UpdateChannelStable, "< 1.0", ""},
+	}
+	for _, test := range tests {
+		var constraint version.Constraints
+		if test.constraint != "" {
+			var err error
+			if constraint, err = version.NewConstraint(test.constraint); err != nil {
+				t.Fatal(err)
+			}
+		}
+		release, _ := selectRelease(testReleases, test.channel, constraint)
+		got := ""
+		if release != nil {
+			got = release.TagName
+		}
+		if got != test.want {
+			t.Errorf("渠道 %s 约束 %q：得到 %q，期望 %q", test.channel, test.constraint, got, test.want)
+		}
+	}
+}
+
+func TestCheckUpdate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(testReleases)
+	}))
+	defer server.Close()
+
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	check := func(settings UpdateSettings, current string) *CheckUpdateResult {
+		settings.SourceURL = server.URL
+		result, _ := checkUpdate(context.Background(), server.Client(), settings, current, now)
+		return result
+	}
+
+	result := check(UpdateSettings{}, "1.2.0")
+	if result.Status != UpdateStatusAvailable || result.LatestVersion != "v2.0.0" || result.Channel != UpdateChannelStable {
+		t.Fatalf("默认渠道：%+v", result)
+	}
+	if result := check(UpdateSettings{Channel: UpdateChannelBeta, Constraint: "~> 1.2"}, "1.2.5"); result.Status != UpdateStatusAvailable || !result.Prerelease {
+		t.Fatalf("测试渠道：%+v", result)
+	}
+	if result := check(UpdateSettings{Constraint: "~> 1.2"}, "1.2.5"); result.Status != UpdateStatusLatest || result.UpdateAvailable {
+		t.Fatalf("约束内已是最新：%+v", result)
+	}
+	if result := check(UpdateSettings{SkippedVersion: "2.0.0"}, "1.2.0"); result.Status != UpdateStatusSkipped || !result.UpdateAvailable {
+		t.Fatalf("跳过的版本：%+v", result)
+	}
+	if result := check(UpdateSettings{Constraint: "not a constraint"}, "1.2.0"); result.Status != UpdateStatusError {
+		t.Fatalf("无效约束：%+v", result)
+	}
+}
+
+func TestCheckUpdateServerError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	result, releases := checkUpdate(context.Background(), server.Client(), UpdateSettings{SourceURL: server.URL}, "1.0.0", time.Now())
+	if result.Status != UpdateStatusError || result.Error == "" || releases != nil {
+		t.Fatalf("服务器错误：%+v", result)
+	}
+}
@@ -0,0 +1,827 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// Markdown 同步完成事件名称
+const markdownSyncedEvent = "markdownSynced"
+
+// 冲突处理方式
+const (
+	markdownKeepApp  = "app"  // 以应用内数据覆盖 Markdown 文件
+	markdownKeepFile = "file" // 以 Markdown 文件覆盖应用内数据
+)
+
+// 任务复选框状态
+const (
+	markdownCheckNotStarted = " "
+	markdownCheckInProgress = "/"
+	markdownCheckCompleted  = "x"
+)
+
+// Markdown 文件中的小节标题
+const (
+	markdownAnnualGoalHeading    = "年度目标"
+	markdownQuarterlyGoalHeading = "季度目标"
+)
+
+var (
+	markdownTaskPattern      = regexp.MustCompile(`^[-*] \[([ xX/])\] (.*?)\s*(?:<!--(.*?)-->)?\s*$`)
+	markdownQuarterPattern   = regexp.MustCompile(`^[-*] Q([1-4])[:：]\s*(.*)$`)
+	markdownMonthPattern     = regexp.MustCompile(`^(\d{1,2})月$`)
+	markdownFrontMatterField = regexp.MustCompile(`^([\w-]+):\s*"?(.*?)"?\s*$`)
+	// 年度目标中以 # 开头的行（包括已转义的），写入时加一个反斜杠，读取时去掉，避免被当作标题
+	markdownHeadingLike = regexp.MustCompile(`^\\*#`)
+)
+
+// MarkdownSyncSettings Markdown/Obsidian 同步设置
+type MarkdownSyncSettings struct {
+	Enabled         bool   `json:"enabled"`         // 是否定时自动同步
+	VaultDir        string `json:"vaultDir"`        // Obsidian 库或任意 Markdown 目录
+	Folder          string `json:"folder"`          // 库中存放文件的子目录
+	IntervalMinutes int    `json:"intervalMinutes"` // 自动同步间隔（分钟）
+}
+
+// MarkdownConflict 双方都有修改的文件
+type MarkdownConflict struct {
+	Year         string `json:"year"`
+	DimensionKey string `json:"dimensionKey"`
+	Path         string `json:"path"`
+}
+
+// MarkdownSyncError 同步单个文件时的错误
+type MarkdownSyncError struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// MarkdownSyncResult 同步结果
+type MarkdownSyncResult struct {
+	SyncedAt  string              `json:"syncedAt"`
+	Written   []string            `json:"written"`  // 由应用写入的文件
+	Imported  []string            `json:"imported"` // 读回应用的文件
+	Unchanged int                 `json:"unchanged"`
+	Conflicts []MarkdownConflict  `json:"conflicts"`
+	Errors    []MarkdownSyncError `json:"errors"`
+}
+
+// markdownDimension 从 Markdown 文件解析出的维度内容
+type markdownDimension struct {
+	year           string
+	dimensionKey   string
+	annualGoal     string
+	quarterlyGoals []string
+	monthlyTasks   [][]markdownTask
+}
+
+// markdownTask 从 Markdown 文件解析出的任务
+type markdownTask struct {
+	task     Task
+	metadata map[string]string
+}
+
+// DefaultMarkdownSyncSettings 默认 Markdown 同步设置
+func DefaultMarkdownSyncSettings() MarkdownSyncSettings {
+	return MarkdownSyncSettings{
+		Enabled:         false,
+		Folder:          "Manifest",
+		IntervalMinutes: 10,
+	}
+}
+
+// GetMarkdownSyncSettings 获取 Markdown 同步设置，未配置时返回默认值
+func GetMarkdownSyncSettings() (MarkdownSyncSettings, error) {
+	config, err := LoadConfig()
+	if err != nil {
+		return MarkdownSyncSettings{}, err
+	}
+
+	if config.MarkdownSync == nil {
+		return DefaultMarkdownSyncSettings(), nil
+	}
+	return *config.MarkdownSync, nil
+}
+
+// SaveMarkdownSyncSettings 保存 Markdown 同步设置
+func SaveMarkdownSyncSettings(settings MarkdownSyncSettings) error {
+	if settings.Enabled && settings.VaultDir == "" {
+		return fmt.Errorf("请先设置Markdown库目录")
+	}
+	if settings.IntervalMinutes <= 0 {
+		return fmt.Errorf("同步间隔必须大于0")
+	}
+	if strings.Contains(settings.Folder, "..") {
+		return fmt.Errorf("无效的子目录: %s", settings.Folder)
+	}
+
+	return UpdateConfig(func(config *Config) error {
+		config.MarkdownSync = &settings
+		return nil
+	})
+}
+
+// 辅助函数：创建 Markdown 同步状态表
+func createMarkdownSyncTables() error {
+	// 记录每个文件上次同步时的内容哈希，用于判断哪一方发生了修改
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS markdown_sync_state (
+			year TEXT,
+			dimension_key TEXT,
+			content_hash TEXT,
+			synced_at TEXT,
+			PRIMARY KEY (year, dimension_key)
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("创建Markdown同步状态表失败: %w", err)
+	}
+
+	return nil
+}
+
+// SyncMarkdownVault 与 Markdown 库进行双向同步
+//
+// 每个年度的每个维度对应一个文件。只有一方修改时以修改方为准，
+// 双方都修改且内容不同时记为冲突，需调用 ResolveMarkdownConflict 处理。
+// 库中没有对应维度的文件会被忽略。
+func SyncMarkdownVault() (*MarkdownSyncResult, error) {
+	settings, err := GetMarkdownSyncSettings()
+	if err != nil {
+		return nil, err
+	}
+	if settings.VaultDir == "" {
+		return nil, fmt.Errorf("请先设置Markdown库目录")
+	}
+	return syncMarkdownVault(markdownSyncDir(settings), time.Now())
+}
+
+// ResolveMarkdownConflict 处理同步冲突，keep 为 app 时保留应用内数据，为 file 时保留文件内容
+func ResolveMarkdownConflict(year, dimensionKey, keep string) error {
+	settings, err := GetMarkdownSyncSettings()
+	if err != nil {
+		return err
+	}
+	if settings.VaultDir == "" {
+		return fmt.Errorf("请先设置Markdown库目录")
+	}
+	dir := markdownSyncDir(settings)
+
+	data, err := GetAnnualData(year)
+	if err != nil {
+		return err
+	}
+	if data == nil {
+		return fmt.Errorf("年度数据不存在: %s", year)
+	}
+	config, ok := findDimensionConfig(*data, dimensionKey)
+	if !ok {
+		return fmt.Errorf("维度不存在: %s", dimensionKey)
+	}
+	path := markdownFilePath(dir, year, dimensionKey)
+
+	switch keep {
+	case markdownKeepApp:
+	case markdownKeepFile:
+		content, err := readMarkdownFile(path)
+		if err != nil {
+			return err
+		}
+		parsed, err := parseDimensionMarkdown(content)
+		if err != nil {
+			return err
+		}
+		if err := checkMarkdownTarget(parsed, year, dimensionKey); err != nil {
+			return err
+		}
+		known := markdownKnownTasks(*data)
+		if err := applyDimensionMarkdown(data, parsed, known); err != nil {
+			return err
+		}
+		if err := SaveAnnualData(*data); err != nil {
+			return err
+		}
+		if err := deleteRemovedMarkdownTasks(known, *data); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("无效的冲突处理方式: %s", keep)
+	}
+
+	content := renderDimensionMarkdown(year, config, data.Dimensions[dimensionKey])
+	if err := writeMarkdownFile(path, content); err != nil {
+		return err
+	}
+	return saveMarkdownSyncState(year, dimensionKey, contentHash(content), time.Now())
+}
+
+// 辅助函数：执行一次双向同步
+func syncMarkdownVault(dir string, now time.Time) (*MarkdownSyncResult, error) {
+	data, err := GetAllAnnualData()
+	if err != nil {
+		return nil, err
+	}
+	states, err := getMarkdownSyncStates()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &MarkdownSyncResult{
+		SyncedAt:  now.Format(time.RFC3339),
+		Written:   []string{},
+		Imported:  []string{},
+		Conflicts: []MarkdownConflict{},
+		Errors:    []MarkdownSyncError{},
+	}
+	fail := func(path string, err error) {
+		result.Errors = append(result.Errors, MarkdownSyncError{Path: path, Message: err.Error()})
+	}
+
+	years := []string{}
+	for year := range data {
+		years = append(years, year)
+	}
+	sort.Strings(years)
+
+	// 先将文件中的修改合并到年度数据，同一年度只保存一次
+	type pendingWrite struct {
+		year, key, path string
+		imported        bool
+	}
+	writes := []pendingWrite{}
+	for _, year := range years {
+		annual := data[year]
+		known := markdownKnownTasks(annual)
+		changed := false
+
+		// 合并文件会修改其他维度（任务移动），先按合并前的数据计算各维度的内容
+		configs := orderedDimensionConfigs(annual)
+		appHashes := make(map[string]string, len(configs))
+		for _, config := range configs {
+			appHashes[config.Key] = contentHash(renderDimensionMarkdown(year, config, annual.Dimensions[config.Key]))
+		}
+		unchanged := []DimensionConfig{}
+
+		for _, config := range configs {
+			path := markdownFilePath(dir, year, config.Key)
+			appHash := appHashes[config.Key]
+
+			fileContent, err := readMarkdownFile(path)
+			if os.IsNotExist(err) {
+				writes = append(writes, pendingWrite{year: year, key: config.Key, path: path})
+				continue
+			}
+			if err != nil {
+				fail(path, err)
+				continue
+			}
+			fileHash := contentHash(fileContent)
+			state, hasState := states[year+"/"+config.Key]
+
+			switch {
+			case fileHash == appHash:
+				// 两边一致，只需更新同步状态
+				if state != appHash {
+					if err := saveMarkdownSyncState(year, config.Key, appHash, now); err != nil {
+						fail(path, err)
+					}
+				}
+				unchanged = append(unchanged, config)
+				result.Unchanged++
+			case hasState && fileHash == state:
+				// 只有应用内数据修改
+				writes = append(writes, pendingWrite{year: year, key: config.Key, path: path})
+			case hasState && appHash == state:
+				// 只有文件修改
+				parsed, err := parseDimensionMarkdown(fileContent)
+				if err == nil {
+					err = checkMarkdownTarget(parsed, year, config.Key)
+				}
+				if err == nil {
+					err = applyDimensionMarkdown(&annual, parsed, known)
+				}
+				if err != nil {
+					fail(path, err)
+					continue
+				}
+				changed = true
+				writes = append(writes, pendingWrite{year: year, key: config.Key, path: path, imported: true})
+			default:
+				result.Conflicts = append(result.Conflicts, MarkdownConflict{Year: year, DimensionKey: config.Key, Path: path})
+			}
+		}
+
+		if changed {
+			if err := SaveAnnualData(annual); err != nil {
+				return nil, fmt.Errorf("保存年度数据失败: %w", err)
+			}
+			if err := deleteRemovedMarkdownTasks(known, annual); err != nil {
+				return nil, err
+			}
+			data[year] = annual
+
+			// 任务移走后原维度的文件也需要重写
+			for _, config := range unchanged {
+				if contentHash(renderDimensionMarkdown(year, config, annual.Dimensions[config.Key])) != appHashes[config.Key] {
+					writes = append(writes, pendingWrite{year: year, key: config.Key, path: markdownFilePath(dir, year, config.Key)})
+					result.Unchanged--
+				}
+			}
+		}
+	}
+
+	// 再写出文件，读回的文件也会按统一格式重写
+	for _, write := range writes {
+		annual := data[write.year]
+		config, _ := findDimensionConfig(annual, write.key)
+		content := renderDimensionMarkdown(write.year, config, annual.Dimensions[write.key])
+		if err := writeMarkdownFile(write.path, content); err != nil {
+			fail(write.path, err)
+			continue
+		}
+		if err := saveMarkdownSyncState(write.year, write.key, contentHash(content), now); err != nil {
+			fail(write.path, err)
+			continue
+		}
+
+		if write.imported {
+			result.Imported = append(result.Imported, write.path)
+		} else {
+			result.Written = append(result.Written, write.path)
+		}
+	}
+
+	return result, nil
+}
+
+// 辅助函数：定时同步，供 Scheduler 调用
+func checkMarkdownSync(ctx context.Context) time.Duration {
+	settings, err := GetMarkdownSyncSettings()
+	if err != nil {
+		log.Printf("读取Markdown同步设置失败: %v", err)
+		return 10 * time.Minute
+	}
+	interval := time.Duration(settings.IntervalMinutes) * time.Minute
+	if interval <= 0 {
+		interval = 10 * time.Minute
+	}
+
+	if db == nil || !settings.Enabled || settings.VaultDir == "" {
+		return interval
+	}
+
+	result, err := syncMarkdownVault(markdownSyncDir(settings), time.Now())
+	if err != nil {
+		log.Printf("Markdown同步失败: %v", err)
+		return interval
+	}
+	if len(result.Written)+len(result.Imported)+len(result.Conflicts)+len(result.Errors) > 0 {
+		runtime.EventsEmit(ctx, markdownSyncedEvent, result)
+	}
+
+	return interval
+}
+
+// 辅助函数：同步文件所在目录
+func markdownSyncDir(settings MarkdownSyncSettings) string {
+	return filepath.Join(settings.VaultDir, settings.Folder)
+}
+
+// 辅助函数：维度对应的 Markdown 文件路径
+func markdownFilePath(dir, year, dimensionKey string) string {
+	return filepath.Join(dir, year, dimensionKey+".md")
+}
+
+// 辅助函数：按 key 查找维度配置
+func findDimensionConfig(data AnnualData, dimensionKey string) (DimensionConfig, bool) {
+	for _, config := range orderedDimensionConfigs(data) {
+		if config.Key == dimensionKey {
+			return config, true
+		}
+	}
+	return DimensionConfig{}, false
+}
+
+// 辅助函数：读取 Markdown 文件，统一换行符
+func readMarkdownFile(path string) (string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.ReplaceAll(string(content), "\r\n", "\n"), nil
+}
+
+// 辅助函数：写入 Markdown 文件
+func writeMarkdownFile(path, content string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("创建目录失败: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		return fmt.Errorf("写入Markdown文件失败: %w", err)
+	}
+	return nil
+}
+
+// 辅助函数：计算内容哈希
+func contentHash(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+// 辅助函数：获取所有文件的同步状态，key 为 “年度/维度Key”
+func getMarkdownSyncStates() (map[string]string, error) {
+	rows, err := db.Query(`SELECT year, dimension_key, content_hash FROM markdown_sync_state`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	states := make(map[string]string)
+	for rows.Next() {
+		var year, key, hash string
+		if err := rows.Scan(&year, &key, &hash); err != nil {
+			return nil, err
+		}
+		states[year+"/"+key] = hash
+	}
+	return states, rows.Err()
+}
+
+// 辅助函数：保存文件的同步状态
+func saveMarkdownSyncState(year, dimensionKey, hash string, now time.Time) error {
+	_, err := db.Exec(
+		`INSERT OR REPLACE INTO markdown_sync_state (year, dimension_key, content_hash, synced_at) VALUES (?, ?, ?, ?)`,
+		year, dimensionKey, hash, now.Format(time.RFC3339),
+	)
+	return err
+}
+
+// 辅助函数：将维度数据渲染为 Markdown
+//
+// 任务以复选框表示状态：[ ] 未开始、[/] 进行中、[x] 已完成，
+// 任务ID及优先级、日期、得分写在行尾的 HTML 注释中，阅读视图下不可见。
+func renderDimensionMarkdown(year string, config DimensionConfig, dimData DimensionData) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "---\nmanifest-year: \"%s\"\nmanifest-dimension: %s\n---\n\n", year, config.Key)
+	fmt.Fprintf(&b, "# %s\n\n", config.Title)
+
+	fmt.Fprintf(&b, "## %s\n\n", markdownAnnualGoalHeading)
+	if goal := strings.TrimSpace(dimData.AnnualGoal); goal != "" {
+		lines := strings.Split(goal, "\n")
+		for i, line := range lines {
+			if markdownHeadingLike.MatchString(line) {
+				lines[i] = `\` + line
+			}
+		}
+		b.WriteString(strings.Join(lines, "\n") + "\n\n")
+	}
+
+	fmt.Fprintf(&b, "## %s\n\n", markdownQuarterlyGoalHeading)
+	for quarter := 0; quarter < 4; quarter++ {
+		goal := ""
+		if quarter < len(dimData.QuarterlyGoals) {
+			goal = strings.TrimSpace(dimData.QuarterlyGoals[quarter])
+		}
+		fmt.Fprintf(&b, "- Q%d: %s\n", quarter+1, strings.ReplaceAll(goal, "\n", " "))
+	}
+	b.WriteString("\n")
+
+	for month := 0; month < 12; month++ {
+		fmt.Fprintf(&b, "## %s\n\n", reportMonthNames[month])
+		if month >= len(dimData.MonthlyTasks) || len(dimData.MonthlyTasks[month]) == 0 {
+			continue
+		}
+		for _, task := range dimData.MonthlyTasks[month] {
+			b.WriteString(renderMarkdownTask(task))
+		}
+		b.WriteString("\n")
+	}
+
+	return strings.TrimRight(b.String(), "\n") + "\n"
+}
+
+// 辅助函数：渲染单个任务及其描述
+func renderMarkdownTask(task Task) string {
+	check := markdownCheckNotStarted
+	switch task.Status {
+	case "in-progress":
+		check = markdownCheckInProgress
+	case "completed":
+		check = markdownCheckCompleted
+	}
+
+	metadata := []string{"id:" + task.ID}
+	if task.Priority != "" {
+		metadata = append(metadata, "priority:"+task.Priority)
+	}
+	if task.StartDate != nil && *task.StartDate != "" {
+		metadata = append(metadata, "start:"+*task.StartDate)
+	}
+	if task.EndDate != nil && *task.EndDate != "" {
+		metadata = append(metadata, "end:"+*task.EndDate)
+	}
+	if task.Score != 0 {
+		metadata = append(metadata, "score:"+strconv.FormatFloat(task.Score, 'f', -1, 64))
+	}
+
+	title := strings.ReplaceAll(task.Title, "\n", " ")
+	line := fmt.Sprintf("- [%s] %s <!-- %s -->\n", check, title, strings.Join(metadata, " "))
+	for _, description := range strings.Split(task.Description, "\n") {
+		if description = strings.TrimSpace(description); description != "" {
+			line += "  " + description + "\n"
+		}
+	}
+	return line
+}
+
+// 辅助函数：解析 Markdown 文件
+func parseDimensionMarkdown(content string) (*markdownDimension, error) {
+	parsed := &markdownDimension{
+		quarterlyGoals: []string{"", "", "", ""},
+		monthlyTasks:   make([][]markdownTask, 12),
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	section := ""
+	month := -1
+	inFrontMatter := false
+	annualGoal := []string{}
+	var lastTask *markdownTask
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+
+		// YAML 头信息
+		if lineNumber == 1 && trimmed == "---" {
+			inFrontMatter = true
+			continue
+		}
+		if inFrontMatter {
+			if trimmed == "---" {
+				inFrontMatter = false
+				continue
+			}
+			if match := markdownFrontMatterField.FindStringSubmatch(trimmed); match != nil {
+				switch match[1] {
+				case "manifest-year":
+					parsed.year = match[2]
+				case "manifest-dimension":
+					parsed.dimensionKey = match[2]
+				}
+			}
+			continue
+		}
+
+		// 小节标题，只识别没有缩进的行，任务描述中缩进的 # 开头内容不是标题
+		if strings.HasPrefix(line, "## ") {
+			section = strings.TrimSpace(strings.TrimPrefix(trimmed, "## "))
+			month = -1
+			lastTask = nil
+			if match := markdownMonthPattern.FindStringSubmatch(section); match != nil {
+				value, _ := strconv.Atoi(match[1])
+				if value < 1 || value > 12 {
+					return nil, fmt.Errorf("第%d行: 无效的月份: %s", lineNumber, section)
+				}
+				month = value - 1
+			}
+			continue
+		}
+		if strings.HasPrefix(line, "# ") {
+			section = ""
+			month = -1
+			lastTask = nil
+			continue
+		}
+
+		switch {
+		case section == markdownAnnualGoalHeading:
+			if strings.HasPrefix(line, `\`) && markdownHeadingLike.MatchString(line[1:]) {
+				line = line[1:]
+			}
+			annualGoal = append(annualGoal, line)
+		case section == markdownQuarterlyGoalHeading:
+			if match := markdownQuarterPattern.FindStringSubmatch(trimmed); match != nil {
+				quarter, _ := strconv.Atoi(match[1])
+				parsed.quarterlyGoals[quarter-1] = strings.TrimSpace(match[2])
+			}
+		case month >= 0:
+			if match := markdownTaskPattern.FindStringSubmatch(trimmed); match != nil && line == strings.TrimLeft(line, " \t") {
+				task, err := parseMarkdownTask(match)
+				if err != nil {
+					return nil, fmt.Errorf("第%d行: %w", lineNumber, err)
+				}
+				parsed.monthlyTasks[month] = append(parsed.monthlyTasks[month], task)
+				lastTask = &parsed.monthlyTasks[month][len(parsed.monthlyTasks[month])-1]
+				continue
+			}
+			// 任务下方缩进的内容作为描述
+			if lastTask != nil && trimmed != "" && line != trimmed {
+				if lastTask.task.Description != "" {
+					lastTask.task.Description += "\n"
+				}
+				lastTask.task.Description += trimmed
+				continue
+			}
+			lastTask = nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("读取Markdown文件失败: %w", err)
+	}
+
+	parsed.annualGoal = strings.TrimSpace(strings.Join(annualGoal, "\n"))
+	return parsed, nil
+}
+
+// 辅助函数：解析任务行
+func parseMarkdownTask(match []string) (markdownTask, error) {
+	task := markdownTask{metadata: make(map[string]string)}
+	task.task.Title = strings.TrimSpace(match[2])
+	if task.task.Title == "" {
+		return task, fmt.Errorf("任务名称不能为空")
+	}
+
+	switch match[1] {
+	case markdownCheckInProgress:
+		task.task.Status = "in-progress"
+	case markdownCheckCompleted, "X":
+		task.task.Status = "completed"
+	default:
+		task.task.Status = "not-started"
+	}
+
+	for _, field := range strings.Fields(match[3]) {
+		key, value, ok := strings.Cut(field, ":")
+		if ok {
+			task.metadata[key] = value
+		}
+	}
+	task.task.ID = task.metadata["id"]
+
+	if priority, ok := task.metadata["priority"]; ok && !isValidTaskPriority(priority) {
+		return task, fmt.Errorf("无效的优先级: %s", priority)
+	}
+	for _, key := range []string{"start", "end"} {
+		if _, err := markdownTaskDate(task.metadata, key); err != nil {
+			return task, err
+		}
+	}
+	if value, ok := task.metadata["score"]; ok {
+		score, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return task, fmt.Errorf("无效的得分: %s", value)
+		}
+		task.task.Score = score
+	}
+
+	return task, nil
+}
+
+// 辅助函数：检查文件头信息是否与文件路径对应，缺少头信息时按路径补全
+func checkMarkdownTarget(parsed *markdownDimension, year, dimensionKey string) error {
+	if parsed.year != "" && parsed.year != year {
+		return fmt.Errorf("文件中的年度(%s)与路径不一致", parsed.year)
+	}
+	if parsed.dimensionKey != "" && parsed.dimensionKey != dimensionKey {
+		return fmt.Errorf("文件中的维度(%s)与路径不一致", parsed.dimensionKey)
+	}
+	parsed.year = year
+	parsed.dimensionKey = dimensionKey
+	return nil
+}
+
+// 辅助函数：将解析结果合并到年度数据
+//
+// 已有任务保留标签等文件中没有的字段，没有ID的任务视为新建，文件中删除的任务从该维度移除。
+// known 为合并前年度中的所有任务：从其他维度移到本维度的任务会从原维度移除。
+func applyDimensionMarkdown(annual *AnnualData, parsed *markdownDimension, known map[string]Task) error {
+	if annual.Dimensions == nil {
+		annual.Dimensions = make(map[string]DimensionData)
+	}
+	dimData, ok := annual.Dimensions[parsed.dimensionKey]
+	if !ok {
+		dimData = newDimensionData()
+	}
+
+	// 先生成全部任务，日期无效时不修改年度数据
+	monthlyTasks := make([][]Task, 12)
+	placed := make(map[string]bool)
+	for month, tasks := range parsed.monthlyTasks {
+		monthlyTasks[month] = []Task{}
+		for _, item := range tasks {
+			task, found := known[item.task.ID]
+			if !found || item.task.ID == "" {
+				task = Task{ID: uuid.New().String(), Priority: "medium"}
+			} else {
+				task.Tags = nil
+			}
+
+			task.Title = item.task.Title
+			task.Description = item.task.Description
+			task.Status = item.task.Status
+			task.Score = item.task.Score
+			if priority, ok := item.metadata["priority"]; ok {
+				task.Priority = priority
+			}
+			var err error
+			if task.StartDate, err = markdownTaskDate(item.metadata, "start"); err != nil {
+				return err
+			}
+			if task.EndDate, err = markdownTaskDate(item.metadata, "end"); err != nil {
+				return err
+			}
+
+			monthlyTasks[month] = append(monthlyTasks[month], task)
+			placed[task.ID] = true
+		}
+	}
+
+	// 从其他维度移除移到本维度的任务
+	for key, other := range annual.Dimensions {
+		if key == parsed.dimensionKey {
+			continue
+		}
+		moved := false
+		for month, tasks := range other.MonthlyTasks {
+			kept := tasks[:0:0]
+			for _, task := range tasks {
+				if placed[task.ID] {
+					moved = true
+					continue
+				}
+				kept = append(kept, task)
+			}
+			other.MonthlyTasks[month] = kept
+		}
+		if moved {
+			updateDimensionStats(&other)
+			annual.Dimensions[key] = other
+		}
+	}
+
+	dimData.AnnualGoal = parsed.annualGoal
+	dimData.QuarterlyGoals = parsed.quarterlyGoals
+	dimData.MonthlyTasks = monthlyTasks
+	updateDimensionStats(&dimData)
+	annual.Dimensions[parsed.dimensionKey] = dimData
+	updateAnnualTotalScore(annual)
+	return nil
+}
+
+// 辅助函数：收集年度中的所有任务，合并文件时按ID沿用已有任务
+func markdownKnownTasks(annual AnnualData) map[string]Task {
+	known := make(map[string]Task)
+	for _, dimension := range annual.Dimensions {
+		for _, monthTasks := range dimension.MonthlyTasks {
+			for _, task := range monthTasks {
+				known[task.ID] = task
+			}
+		}
+	}
+	return known
+}
+
+// 辅助函数：删除合并前存在、合并后已从年度中移除的任务，年度数据保存后调用
+func deleteRemovedMarkdownTasks(known map[string]Task, annual AnnualData) error {
+	remaining := markdownKnownTasks(annual)
+	for id := range known {
+		if _, ok := remaining[id]; ok {
+			continue
+		}
+		if err := DeleteTask(id); err != nil {
+			return fmt.Errorf("删除任务失败: %w", err)
+		}
+	}
+	return nil
+}
+
+// 辅助函数：读取任务元数据中的日期，未设置时返回 nil
+func markdownTaskDate(metadata map[string]string, key string) (*string, error) {
+	value, ok := metadata[key]
+	if !ok {
+		return nil, nil
+	}
+	date, valid := taskDate(value)
+	if !valid {
+		return nil, fmt.Errorf("无效的日期: %s", value)
+	}
+	return &date, nil
+}
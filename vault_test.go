@@ -0,0 +1,131 @@
+package main
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// 自由文本中类似标题的内容写入后能原样读回，不会打断小节
+func TestDimensionMarkdownHeadingLikeText(t *testing.T) {
+	goal := "第一行\n## 3月\n# 标题\n\\# 已转义"
+	monthly := make([][]Task, 12)
+	monthly[0] = []Task{{ID: "t1", Title: "任务", Status: "in-progress", Description: "## 备注\n# 其他"}}
+	content := renderDimensionMarkdown("2026", DimensionConfig{Key: "work", Title: "工作"}, DimensionData{
+		AnnualGoal:     goal,
+		QuarterlyGoals: []string{"季度", "", "", ""},
+		MonthlyTasks:   monthly,
+	})
+
+	parsed, err := parseDimensionMarkdown(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.annualGoal != goal {
+		t.Fatalf("年度目标 = %q\n%s", parsed.annualGoal, content)
+	}
+	if parsed.quarterlyGoals[0] != "季度" {
+		t.Fatalf("季度目标 = %q", parsed.quarterlyGoals)
+	}
+	if len(parsed.monthlyTasks[0]) != 1 || parsed.monthlyTasks[0][0].task.Description != "## 备注\n# 其他" {
+		t.Fatalf("1月任务 = %+v\n%s", parsed.monthlyTasks[0], content)
+	}
+	for month := 1; month < 12; month++ {
+		if len(parsed.monthlyTasks[month]) != 0 {
+			t.Fatalf("%d月出现了任务: %+v", month+1, parsed.monthlyTasks[month])
+		}
+	}
+}
+
+// 文件中移到其他维度的任务只保留一份，从文件中删除的任务从数据库中删除
+func TestSyncMarkdownVaultMovesAndDeletesTasks(t *testing.T) {
+	setupTestDB(t)
+	monthly := make([][]Task, 12)
+	monthly[0] = []Task{
+		{ID: "t1", Title: "移动", Status: "not-started", Priority: "medium"},
+		{ID: "t2", Title: "删除", Status: "not-started", Priority: "medium"},
+	}
+	err := SaveAnnualData(AnnualData{
+		Year:             "2026",
+		DimensionConfigs: []DimensionConfig{{Key: "work", Title: "工作"}, {Key: "life", Title: "生活"}},
+		Dimensions: map[string]DimensionData{
+			"work": {QuarterlyGoals: make([]string, 4), MonthlyTasks: monthly},
+			"life": {QuarterlyGoals: make([]string, 4), MonthlyTasks: make([][]Task, 12)},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	if _, err := syncMarkdownVault(dir, now); err != nil {
+		t.Fatal(err)
+	}
+
+	// 把 t1 从工作移到生活的 1 月，并删除 t2
+	workPath := markdownFilePath(dir, "2026", "work")
+	lifePath := markdownFilePath(dir, "2026", "life")
+	work, _ := readMarkdownFile(workPath)
+	life, _ := readMarkdownFile(lifePath)
+	var moved string
+	kept := []string{}
+	for _, line := range strings.SplitAfter(work, "\n") {
+		switch {
+		case strings.Contains(line, "id:t1"):
+			moved = line
+		case strings.Contains(line, "id:t2"):
+		default:
+			kept = append(kept, line)
+		}
+	}
+	life = strings.Replace(life, "## 1月\n", "## 1月\n"+moved, 1)
+	if err := os.WriteFile(workPath, []byte(strings.Join(kept, "")), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(lifePath, []byte(life), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := syncMarkdownVault(dir, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Errors) != 0 || len(result.Imported) != 2 {
+		t.Fatalf("同步结果 = %+v", result)
+	}
+	annual := mustGetAllAnnualData(t)["2026"]
+	if tasks := annual.Dimensions["work"].MonthlyTasks[0]; len(tasks) != 0 {
+		t.Fatalf("工作 1 月任务 = %+v", tasks)
+	}
+	if tasks := annual.Dimensions["life"].MonthlyTasks[0]; len(tasks) != 1 || tasks[0].ID != "t1" {
+		t.Fatalf("生活 1 月任务 = %+v", tasks)
+	}
+	var count int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM tasks WHERE id = 't2'`).Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	if count != 0 {
+		t.Fatal("从文件中删除的任务仍在数据库中")
+	}
+}
+
+// 无效的日期报告为错误，不清空任务的日期
+func TestApplyDimensionMarkdownRejectsBadDate(t *testing.T) {
+	start := "2026-01-05"
+	annual := AnnualData{Year: "2026", Dimensions: map[string]DimensionData{}}
+	parsed := &markdownDimension{dimensionKey: "work", quarterlyGoals: make([]string, 4), monthlyTasks: make([][]markdownTask, 12)}
+	parsed.monthlyTasks[0] = []markdownTask{{
+		task:     Task{ID: "t1", Title: "任务", Status: "not-started"},
+		metadata: map[string]string{"id": "t1", "start": "2026-13-40"},
+	}}
+	known := map[string]Task{"t1": {ID: "t1", StartDate: &start}}
+
+	if err := applyDimensionMarkdown(&annual, parsed, known); err == nil {
+		t.Fatal("无效的日期应返回错误")
+	}
+	if _, ok := annual.Dimensions["work"]; ok {
+		t.Fatal("日期无效时修改了年度数据")
+	}
+}
@@ -0,0 +1,254 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// 系统钥匙串中保存 WebDAV 密码的服务名称
+const webdavKeyringService = "Manifest WebDAV Backup"
+
+// WebDAVSettings WebDAV 备份位置
+type WebDAVSettings struct {
+	URL        string `json:"url"` // 存放备份的目录地址，例如 http://localhost:8080/manifest/
+	Username   string `json:"username"`
+	Password   string `json:"password"`   // 保存在系统钥匙串时为空
+	UseKeyring bool   `json:"useKeyring"` // 密码保存在系统钥匙串中，不写入配置文件
+}
+
+// webdavClient 极简 WebDAV 客户端，只操作备份目录下的文件
+type webdavClient struct {
+	folder   *url.URL
+	username string
+	password string
+	http     *http.Client
+}
+
+// 列出目录内容的 PROPFIND 请求体
+const webdavListQuery = `<?xml version="1.0" encoding="utf-8"?>
+<d:propfind xmlns:d="DAV:"><d:prop><d:getcontentlength/><d:getlastmodified/></d:prop></d:propfind>`
+
+// 辅助函数：校验备份目录地址，并确保以 / 结尾
+func parseWebDAVURL(raw string) (*url.URL, error) {
+	folder, err := url.Parse(raw)
+	if err != nil || (folder.Scheme != "http" && folder.Scheme != "https") || folder.Host == "" {
+		return nil, fmt.Errorf("无效的WebDAV地址: %s", raw)
+	}
+	if !strings.HasSuffix(folder.Path, "/") {
+		folder.Path += "/"
+	}
+	return folder, nil
+}
+
+// 辅助函数：钥匙串中的账号名称，按用户名与目录地址区分
+func webdavKeyringUser(settings WebDAVSettings) string {
+	return settings.Username + "@" + settings.URL
+}
+
+// 辅助函数：创建 WebDAV 客户端，设置中没有密码时从系统钥匙串读取，未设置用户名时不认证
+func newWebDAVClient(settings WebDAVSettings) (*webdavClient, error) {
+	if settings.URL == "" {
+		return nil, fmt.Errorf("请先设置WebDAV地址")
+	}
+	folder, err := parseWebDAVURL(settings.URL)
+	if err != nil {
+		return nil, err
+	}
+	password := settings.Password
+	if settings.UseKeyring && settings.Username != "" {
+		if password, err = loadKeyringSecret(webdavKeyringService, webdavKeyringUser(settings), password); err != nil {
+			return nil, err
+		}
+	}
+	return &webdavClient{
+		folder:   folder,
+		username: settings.Username,
+		password: password,
+		http:     &http.Client{Timeout: backupRequestTimeout},
+	}, nil
+}
+
+// 辅助函数：发送请求，name 为空时请求备份目录本身
+func (c *webdavClient) do(ctx context.Context, method, name string, headers map[string]string, body io.Reader, length int64) (*http.Response, error) {
+	target := *c.folder
+	if name != "" {
+		target.Path += name
+		target.RawPath = ""
+	}
+
+	request, err := http.NewRequestWithContext(ctx, method, target.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		request.ContentLength = length
+	}
+	for key, value := range headers {
+		request.Header.Set(key, value)
+	}
+	if c.username != "" || c.password != "" {
+		request.SetBasicAuth(c.username, c.password)
+	}
+
+	response, err := c.http.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("连接WebDAV服务器失败: %w", err)
+	}
+	if response.StatusCode == http.StatusUnauthorized {
+		response.Body.Close()
+		return nil, fmt.Errorf("WebDAV认证失败，请检查用户名和密码")
+	}
+	return response, nil
+}
+
+// 辅助函数：列出备份目录中的快照，目录不存在时返回空列表
+func (c *webdavClient) list(ctx context.Context) ([]BackupSnapshot, error) {
+	response, err := c.do(ctx, "PROPFIND", "", map[string]string{
+		"Depth":        "1",
+		"Content-Type": "application/xml; charset=utf-8",
+	}, strings.NewReader(webdavListQuery), int64(len(webdavListQuery)))
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusNotFound {
+		return []BackupSnapshot{}, nil
+	}
+	if response.StatusCode != http.StatusMultiStatus {
+		return nil, fmt.Errorf("WebDAV请求失败: %s", response.Status)
+	}
+
+	content, err := io.ReadAll(io.LimitReader(response.Body, maxBackupResponseSize))
+	if err != nil {
+		return nil, err
+	}
+	var status davMultistatus
+	if err := xml.Unmarshal(content, &status); err != nil {
+		return nil, fmt.Errorf("解析WebDAV响应失败: %w", err)
+	}
+
+	snapshots := []BackupSnapshot{}
+	for _, item := range status.Responses {
+		href, err := url.PathUnescape(item.Href)
+		if err != nil {
+			continue
+		}
+		name := path.Base(strings.TrimSuffix(href, "/"))
+		created, ok := parseBackupName(name)
+		if !ok {
+			continue
+		}
+		snapshot := BackupSnapshot{Name: name, CreatedAt: created.Format(backupDisplayLayout)}
+		for _, propstat := range item.Propstats {
+			if strings.Contains(propstat.Status, " 200 ") {
+				snapshot.Size = propstat.Prop.ContentLength
+			}
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	return snapshots, nil
+}
+
+// 辅助函数：上传快照，备份目录不存在时先创建
+func (c *webdavClient) upload(ctx context.Context, name string, content []byte, progress func(done int64)) error {
+	if err := c.ensureFolder(ctx); err != nil {
+		return err
+	}
+
+	body := &progressReader{reader: bytes.NewReader(content), report: progress}
+	response, err := c.do(ctx, http.MethodPut, name, map[string]string{
+		"Content-Type": "application/octet-stream",
+	}, body, int64(len(content)))
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	io.Copy(io.Discard, response.Body)
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return fmt.Errorf("上传备份失败: %s", response.Status)
+	}
+	return nil
+}
+
+// 辅助函数：下载快照
+func (c *webdavClient) download(ctx context.Context, name string, progress func(done, total int64)) ([]byte, error) {
+	response, err := c.do(ctx, http.MethodGet, name, nil, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("备份不存在: %s", name)
+	}
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("下载备份失败: %s", response.Status)
+	}
+
+	return readBackupDownload(response, progress)
+}
+
+// 辅助函数：删除快照，已不存在时忽略
+func (c *webdavClient) remove(ctx context.Context, name string) error {
+	response, err := c.do(ctx, http.MethodDelete, name, nil, nil, 0)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	io.Copy(io.Discard, response.Body)
+
+	if response.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return fmt.Errorf("删除备份失败: %s", response.Status)
+	}
+	return nil
+}
+
+// 辅助函数：逐级创建备份目录，已存在时服务器返回 405
+//
+// 上级目录可能不允许创建（例如网盘的根目录），只检查最后一级的结果。
+func (c *webdavClient) ensureFolder(ctx context.Context) error {
+	segments := strings.Split(strings.Trim(c.folder.Path, "/"), "/")
+	for i := range segments {
+		folder := *c.folder
+		folder.Path = "/" + strings.Join(segments[:i+1], "/") + "/"
+		folder.RawPath = ""
+
+		request, err := http.NewRequestWithContext(ctx, "MKCOL", folder.String(), nil)
+		if err != nil {
+			return err
+		}
+		if c.username != "" || c.password != "" {
+			request.SetBasicAuth(c.username, c.password)
+		}
+		response, err := c.http.Do(request)
+		if err != nil {
+			return fmt.Errorf("连接WebDAV服务器失败: %w", err)
+		}
+		io.Copy(io.Discard, response.Body)
+		response.Body.Close()
+
+		if i < len(segments)-1 {
+			continue
+		}
+		switch {
+		case response.StatusCode == http.StatusUnauthorized:
+			return fmt.Errorf("WebDAV认证失败，请检查用户名和密码")
+		case response.StatusCode == http.StatusMethodNotAllowed:
+		case response.StatusCode < 200 || response.StatusCode >= 300:
+			return fmt.Errorf("创建WebDAV备份目录失败: %s", response.Status)
+		}
+	}
+	return nil
+}
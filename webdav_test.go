@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/zalando/go-keyring"
+)
+
+// 辅助函数：只支持备份所需方法的内存 WebDAV 服务器，要求 Basic 认证
+func newTestWebDAVServer(t *testing.T, username, password string) (*httptest.Server, map[string][]byte) {
+	t.Helper()
+	var mu sync.Mutex
+	files := map[string][]byte{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, ok := r.BasicAuth(); !ok || user != username || pass != password {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.Method {
+		case "MKCOL":
+			w.WriteHeader(http.StatusCreated)
+		case "PROPFIND":
+			w.WriteHeader(http.StatusMultiStatus)
+			fmt.Fprint(w, `<?xml version="1.0"?><d:multistatus xmlns:d="DAV:">`)
+			fmt.Fprintf(w, `<d:response><d:href>%s</d:href></d:response>`, r.URL.Path)
+			for name, content := range files {
+				fmt.Fprintf(w, `<d:response><d:href>%s</d:href><d:propstat><d:status>HTTP/1.1 200 OK</d:status>`+
+					`<d:prop><d:getcontentlength>%d</d:getcontentlength></d:prop></d:propstat></d:response>`, name, len(content))
+			}
+			fmt.Fprint(w, `</d:multistatus>`)
+		case http.MethodPut:
+			content, _ := io.ReadAll(r.Body)
+			files[r.URL.Path] = content
+			w.WriteHeader(http.StatusCreated)
+		case http.MethodGet:
+			content, ok := files[r.URL.Path]
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			w.Write(content)
+		case http.MethodDelete:
+			delete(files, r.URL.Path)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, files
+}
+
+// 备份上传、列出与下载的完整流程，密码与备份密码只保存在系统钥匙串中
+func TestWebDAVBackupRoundTrip(t *testing.T) {
+	setupTestDB(t)
+	keyring.MockInit()
+	server, files := newTestWebDAVServer(t, "user", "secret")
+
+	settings := DefaultBackupSettings()
+	settings.Enabled = true
+	settings.WebDAV.URL = server.URL + "/backups/manifest"
+	settings.WebDAV.Username = "user"
+	settings.WebDAV.Password = "secret"
+	settings.Passphrase = "correct horse battery"
+	if err := SaveBackupSettings(settings); err != nil {
+		t.Fatal(err)
+	}
+	saved, err := GetBackupSettings()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.WebDAV.Password != "" || saved.Passphrase != "" {
+		t.Fatal("密码被写入配置文件")
+	}
+	// 再次保存未填写密码的设置时沿用钥匙串中的密码
+	if err := SaveBackupSettings(saved); err != nil {
+		t.Fatalf("再次保存设置失败: %v", err)
+	}
+
+	result, err := CreateBackup(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 {
+		t.Fatalf("服务器上的文件: %d", len(files))
+	}
+	snapshots, err := GetBackupSnapshots(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snapshots) != 1 || snapshots[0].Name != result.Snapshot.Name || snapshots[0].Size != result.Snapshot.Size {
+		t.Fatalf("快照列表: %+v，上传的快照: %+v", snapshots, result.Snapshot)
+	}
+
+	client, err := newWebDAVClient(saved.WebDAV)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err := client.download(context.Background(), result.Snapshot.Name, func(done, total int64) {})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := decryptBackup(content, "correct horse battery"); err != nil {
+		t.Fatalf("解密下载的快照失败: %v", err)
+	}
+	if _, err := client.download(context.Background(), "missing"+backupNameSuffix, func(done, total int64) {}); err == nil {
+		t.Fatal("不存在的快照应返回错误")
+	}
+
+	saved.WebDAV.Password = "wrong"
+	if _, err := TestBackupConnection(saved); err == nil || !strings.Contains(err.Error(), "认证失败") {
+		t.Fatalf("密码错误时应认证失败，得到 %v", err)
+	}
+}
+
+// 声明的长度超过限制时不读取内容
+func TestReadBackupDownloadLimit(t *testing.T) {
+	response := &http.Response{
+		ContentLength: maxBackupDownloadSize + 1,
+		Body:          io.NopCloser(strings.NewReader("")),
+	}
+	if _, err := readBackupDownload(response, func(done, total int64) {}); err == nil {
+		t.Fatal("超过大小限制时应返回错误")
+	}
+}